@@ -9,6 +9,7 @@ import (
 	"github.com/spolu/settle/lib/env"
 	"github.com/spolu/settle/lib/errors"
 	"github.com/spolu/settle/lib/out"
+	"github.com/spolu/settle/lib/svc"
 )
 
 // CmdName represents a command name.
@@ -42,6 +43,23 @@ type Cli struct {
 	Args  []string
 }
 
+const (
+	// EnvCfgEncoding is the encoding of the bodies of requests to mints and
+	// registers (`form` or `json`), set with the `--encoding` flag.
+	EnvCfgEncoding env.ConfigKey = "encoding"
+)
+
+// GetContentType returns the content type of the bodies of requests to mints
+// and registers, defaulting to form encoded bodies.
+func GetContentType(
+	ctx context.Context,
+) string {
+	if env.Get(ctx).Config[EnvCfgEncoding] == "json" {
+		return svc.ContentTypeJSON
+	}
+	return svc.ContentTypeForm
+}
+
 // flagFilterRegexp filters out flags from arguments.
 var flagFilterRegexp = regexp.MustCompile("^-+")
 
@@ -75,6 +93,10 @@ func New(
 	if e, ok := flags["env"]; ok && e == "qa" {
 		cliEnv.Environment = env.QA
 	}
+	// Encoding flag.
+	if e, ok := flags["encoding"]; ok {
+		cliEnv.Config[EnvCfgEncoding] = e
+	}
 	ctx = env.With(ctx, &cliEnv)
 
 	creds, err := CurrentUser(ctx)
@@ -2,12 +2,11 @@ package command
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
-	"fmt"
 	"math/big"
 	"net/http"
-	"net/url"
 	"os"
 	"strings"
 
@@ -18,6 +17,7 @@ import (
 	"github.com/spolu/settle/lib/out"
 	"github.com/spolu/settle/lib/svc"
 	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/sdk"
 	"github.com/spolu/settle/register"
 )
 
@@ -74,17 +74,22 @@ func RegisterUser(
 	out.Statf("[Registering user] username=%s email=%s mint=%s\n",
 		username, email, reg.Host)
 
-	params := url.Values{}
+	params := svc.Params{}
 	params["username"] = []string{username}
 	params["email"] = []string{email}
 
+	body, err := params.Encode(cli.GetContentType(ctx))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
 	req, err := http.NewRequest("POST",
 		reg.RegisterURL[env.Get(ctx).Environment],
-		strings.NewReader(params.Encode()))
+		bytes.NewReader(body))
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Content-Type", cli.GetContentType(ctx))
 
 	r, err := client.Default(ctx).Do(req)
 	if err != nil {
@@ -121,13 +126,13 @@ func CreateAsset(
 	ctx context.Context,
 	name string,
 ) (*mint.AssetResource, error) {
-	m, err := cli.MintFromContextCredentials(ctx)
+	m, err := cli.ClientFromContextCredentials(ctx)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 
 	out.Statf("[Creating asset] user=%s@%s asset=%s\n",
-		m.Credentials.Username, m.Credentials.Host,
+		m.Credentials.Username, m.Host,
 		name)
 
 	a, err := mint.AssetResourceFromName(ctx, name)
@@ -135,34 +140,15 @@ func CreateAsset(
 		return nil, errors.Trace(err)
 	}
 
-	status, raw, err := m.Post(ctx,
-		"/assets",
-		url.Values{},
-		url.Values{
-			"code":  {a.Code},
-			"scale": {fmt.Sprintf("%d", a.Scale)},
-		})
-	if err != nil {
-		return nil, errors.Trace(err)
-	}
-
-	if *status != http.StatusCreated {
-		var e errors.ConcreteUserError
-		err = raw.Extract("error", &e)
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-		return nil, errors.Trace(
-			errors.Newf("(%s) %s", e.ErrCode, e.ErrMessage))
-	}
-
-	var asset mint.AssetResource
-	err = raw.Extract("asset", &asset)
+	asset, err := m.CreateAsset(ctx, &sdk.CreateAssetParams{
+		Code:  a.Code,
+		Scale: a.Scale,
+	})
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 
-	return &asset, nil
+	return asset, nil
 }
 
 // CreateOffer creates an offer for the currently authenticated user.
@@ -172,44 +158,25 @@ func CreateOffer(
 	amount big.Int,
 	price string,
 ) (*mint.OfferResource, error) {
-	m, err := cli.MintFromContextCredentials(ctx)
+	m, err := cli.ClientFromContextCredentials(ctx)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 
 	out.Statf("[Creating offer] user=%s@%s pair=%s amount=%s price=%s\n",
-		m.Credentials.Username, m.Credentials.Host,
+		m.Credentials.Username, m.Host,
 		pair, amount.String(), price)
 
-	status, raw, err := m.Post(ctx,
-		"/offers",
-		url.Values{},
-		url.Values{
-			"pair":   {pair},
-			"amount": {amount.String()},
-			"price":  {price},
-		})
+	offer, err := m.CreateOffer(ctx, &sdk.CreateOfferParams{
+		Pair:   pair,
+		Price:  price,
+		Amount: &amount,
+	})
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 
-	if *status != http.StatusCreated {
-		var e errors.ConcreteUserError
-		err = raw.Extract("error", &e)
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-		return nil, errors.Trace(
-			errors.Newf("(%s) %s", e.ErrCode, e.ErrMessage))
-	}
-
-	var offer mint.OfferResource
-	err = raw.Extract("offer", &offer)
-	if err != nil {
-		return nil, errors.Trace(err)
-	}
-
-	return &offer, nil
+	return offer, nil
 }
 
 // CreateTransaction creates a transaction for the currently authenticated
@@ -221,46 +188,27 @@ func CreateTransaction(
 	destination string,
 	path []string,
 ) (*mint.TransactionResource, error) {
-	m, err := cli.MintFromContextCredentials(ctx)
+	m, err := cli.ClientFromContextCredentials(ctx)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 
 	out.Statf("[Creating transaction] user=%s@%s pair=%s amount=%s "+
 		"destination=%s\n",
-		m.Credentials.Username, m.Credentials.Host, pair, amount.String(),
+		m.Credentials.Username, m.Host, pair, amount.String(),
 		destination)
 
-	status, raw, err := m.Post(ctx,
-		"/transactions",
-		url.Values{},
-		url.Values{
-			"pair":        {pair},
-			"amount":      {amount.String()},
-			"destination": {destination},
-			"path[]":      path,
-		})
-	if err != nil {
-		return nil, errors.Trace(err)
-	}
-
-	if *status != http.StatusCreated {
-		var e errors.ConcreteUserError
-		err = raw.Extract("error", &e)
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-		return nil, errors.Trace(
-			errors.Newf("(%s) %s", e.ErrCode, e.ErrMessage))
-	}
-
-	var transaction mint.TransactionResource
-	err = raw.Extract("transaction", &transaction)
+	transaction, err := m.CreateTransaction(ctx, &sdk.CreateTransactionParams{
+		Pair:        pair,
+		Amount:      &amount,
+		Destination: destination,
+		Path:        path,
+	})
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 
-	return &transaction, nil
+	return transaction, nil
 }
 
 // SettleTransaction settles a transaction for the currently authenticated
@@ -269,39 +217,20 @@ func SettleTransaction(
 	ctx context.Context,
 	id string,
 ) (*mint.TransactionResource, error) {
-	m, err := cli.MintFromContextCredentials(ctx)
+	m, err := cli.ClientFromContextCredentials(ctx)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 
 	out.Statf("[Settling transaction] user=%s@%s transaction=%s\n",
-		m.Credentials.Username, m.Credentials.Host, id)
+		m.Credentials.Username, m.Host, id)
 
-	status, raw, err := m.Post(ctx,
-		fmt.Sprintf("/transactions/%s/settle", id),
-		url.Values{},
-		url.Values{})
+	transaction, err := m.SettleTransaction(ctx, id)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 
-	if *status != http.StatusOK {
-		var e errors.ConcreteUserError
-		err = raw.Extract("error", &e)
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-		return nil, errors.Trace(
-			errors.Newf("(%s) %s", e.ErrCode, e.ErrMessage))
-	}
-
-	var transaction mint.TransactionResource
-	err = raw.Extract("transaction", &transaction)
-	if err != nil {
-		return nil, errors.Trace(err)
-	}
-
-	return &transaction, nil
+	return transaction, nil
 }
 
 // CloseOffer closes an offer specified by token.
@@ -309,73 +238,40 @@ func CloseOffer(
 	ctx context.Context,
 	id string,
 ) (*mint.OfferResource, error) {
-	m, err := cli.MintFromContextCredentials(ctx)
+	m, err := cli.ClientFromContextCredentials(ctx)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 
 	out.Statf("[Closing offer] user=%s@%s offer=%s\n",
-		m.Credentials.Username, m.Credentials.Host, id)
+		m.Credentials.Username, m.Host, id)
 
-	status, raw, err := m.Post(ctx,
-		fmt.Sprintf("/offers/%s/close", id),
-		url.Values{},
-		url.Values{})
+	offer, err := m.CloseOffer(ctx, id)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 
-	if *status != http.StatusOK {
-		var e errors.ConcreteUserError
-		err = raw.Extract("error", &e)
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-		return nil, errors.Trace(
-			errors.Newf("(%s) %s", e.ErrCode, e.ErrMessage))
-	}
-
-	var offer mint.OfferResource
-	err = raw.Extract("offer", &offer)
-	if err != nil {
-		return nil, errors.Trace(err)
-	}
-
-	return &offer, nil
+	return offer, nil
 }
 
 // ListAssets list assets for the current user.
 func ListAssets(
 	ctx context.Context,
 ) ([]mint.AssetResource, error) {
-	m, err := cli.MintFromContextCredentials(ctx)
+	m, err := cli.ClientFromContextCredentials(ctx)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 
 	out.Statf("[Listing assets] user=%s@%s\n",
-		m.Credentials.Username, m.Credentials.Host)
+		m.Credentials.Username, m.Host)
 
-	status, raw, err := m.Get(ctx,
-		"/assets",
-		url.Values{})
-	if err != nil {
-		return nil, errors.Trace(err)
+	assets := []mint.AssetResource{}
+	it := m.ListAssets(ctx, nil)
+	for it.Next() {
+		assets = append(assets, it.Asset())
 	}
-
-	if *status != http.StatusOK {
-		var e errors.ConcreteUserError
-		err = raw.Extract("error", &e)
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-		return nil, errors.Trace(
-			errors.Newf("(%s) %s", e.ErrCode, e.ErrMessage))
-	}
-
-	var assets []mint.AssetResource
-	err = raw.Extract("assets", &assets)
-	if err != nil {
+	if err := it.Err(); err != nil {
 		return nil, errors.Trace(err)
 	}
 
@@ -386,34 +282,20 @@ func ListAssets(
 func ListBalances(
 	ctx context.Context,
 ) ([]mint.BalanceResource, error) {
-	m, err := cli.MintFromContextCredentials(ctx)
+	m, err := cli.ClientFromContextCredentials(ctx)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 
 	out.Statf("[Listing balances] user=%s@%s\n",
-		m.Credentials.Username, m.Credentials.Host)
-
-	status, raw, err := m.Get(ctx,
-		"/balances",
-		url.Values{})
-	if err != nil {
-		return nil, errors.Trace(err)
-	}
+		m.Credentials.Username, m.Host)
 
-	if *status != http.StatusOK {
-		var e errors.ConcreteUserError
-		err = raw.Extract("error", &e)
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-		return nil, errors.Trace(
-			errors.Newf("(%s) %s", e.ErrCode, e.ErrMessage))
+	balances := []mint.BalanceResource{}
+	it := m.ListBalances(ctx, nil)
+	for it.Next() {
+		balances = append(balances, it.Balance())
 	}
-
-	var balances []mint.BalanceResource
-	err = raw.Extract("balances", &balances)
-	if err != nil {
+	if err := it.Err(); err != nil {
 		return nil, errors.Trace(err)
 	}
 
@@ -425,34 +307,20 @@ func ListAssetBalances(
 	ctx context.Context,
 	asset string,
 ) ([]mint.BalanceResource, error) {
-	m, err := cli.MintFromContextCredentials(ctx)
+	m, err := cli.ClientFromContextCredentials(ctx)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 
 	out.Statf("[Listing asset balances] user=%s@%s asset=%s\n",
-		m.Credentials.Username, m.Credentials.Host, asset)
-
-	status, raw, err := m.Get(ctx,
-		fmt.Sprintf("/assets/%s/balances", asset),
-		url.Values{})
-	if err != nil {
-		return nil, errors.Trace(err)
-	}
+		m.Credentials.Username, m.Host, asset)
 
-	if *status != http.StatusOK {
-		var e errors.ConcreteUserError
-		err = raw.Extract("error", &e)
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-		return nil, errors.Trace(
-			errors.Newf("(%s) %s", e.ErrCode, e.ErrMessage))
+	balances := []mint.BalanceResource{}
+	it := m.ListAssetBalances(ctx, asset, nil)
+	for it.Next() {
+		balances = append(balances, it.Balance())
 	}
-
-	var balances []mint.BalanceResource
-	err = raw.Extract("balances", &balances)
-	if err != nil {
+	if err := it.Err(); err != nil {
 		return nil, errors.Trace(err)
 	}
 
@@ -469,48 +337,50 @@ func ListAssetOffers(
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	m, err := cli.MintFromContextCredentials(ctx)
+	m, err := cli.ClientFromContextCredentials(ctx)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 
-	if a.Owner !=
-		fmt.Sprintf("%s@%s", m.Credentials.Username, m.Credentials.Host) {
-		_, host, err := mint.UsernameAndMintHostFromAddress(ctx, a.Owner)
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-		m = &cli.Mint{Host: host}
-	}
-
 	out.Statf("[Listing asset offers] user=%s asset=%s propagation=%s\n",
 		a.Owner, asset, propagation)
 
-	status, raw, err := m.Get(ctx,
-		fmt.Sprintf("/assets/%s/offers", asset),
-		url.Values{
-			"propagation": {string(propagation)},
-		})
-	if err != nil {
+	offers := []mint.OfferResource{}
+	it := m.ListAssetOffers(ctx, asset, &sdk.OfferListParams{
+		Propagation: propagation,
+	})
+	for it.Next() {
+		offers = append(offers, it.Offer())
+	}
+	if err := it.Err(); err != nil {
 		return nil, errors.Trace(err)
 	}
 
-	if *status != http.StatusOK {
-		var e errors.ConcreteUserError
-		err = raw.Extract("error", &e)
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-		return nil, errors.Trace(
-			errors.Newf("(%s) %s", e.ErrCode, e.ErrMessage))
-	}
+	return offers, nil
+}
 
-	var offers []mint.OfferResource
-	err = raw.Extract("offers", &offers)
+// ListOffers list the offers of the current user matching the params filters.
+func ListOffers(
+	ctx context.Context,
+	params *sdk.OfferListParams,
+) ([]mint.OfferResource, error) {
+	m, err := cli.ClientFromContextCredentials(ctx)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 
+	out.Statf("[Listing offers] user=%s@%s status=%s asset_code=%s\n",
+		m.Credentials.Username, m.Host, params.Status, params.AssetCode)
+
+	offers := []mint.OfferResource{}
+	it := m.ListOffers(ctx, params)
+	for it.Next() {
+		offers = append(offers, it.Offer())
+	}
+	if err := it.Err(); err != nil {
+		return nil, errors.Trace(err)
+	}
+
 	return offers, nil
 }
 
@@ -523,49 +393,21 @@ func RetrieveAsset(
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	m, err := cli.MintFromContextCredentials(ctx)
+	m, err := cli.ClientFromContextCredentials(ctx)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 
-	if a.Owner !=
-		fmt.Sprintf("%s@%s", m.Credentials.Username, m.Credentials.Host) {
-		_, host, err := mint.UsernameAndMintHostFromAddress(ctx, a.Owner)
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-		m = &cli.Mint{Host: host}
-	}
-
 	out.Statf("[Retrieving asset] user=%s asset=%s\n", a.Owner, name)
 
-	status, raw, err := m.Get(ctx,
-		fmt.Sprintf("/assets/%s", name),
-		url.Values{})
-	if err != nil {
+	asset, err := m.RetrieveAsset(ctx, name)
+	if sdk.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
 		return nil, errors.Trace(err)
 	}
 
-	if *status != http.StatusOK {
-		var e errors.ConcreteUserError
-		err = raw.Extract("error", &e)
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-		if e.ErrCode == "asset_not_found" {
-			return nil, nil
-		}
-		return nil, errors.Trace(
-			errors.Newf("(%s) %s", e.ErrCode, e.ErrMessage))
-	}
-
-	var asset mint.AssetResource
-	err = raw.Extract("asset", &asset)
-	if err != nil {
-		return nil, errors.Trace(err)
-	}
-
-	return &asset, nil
+	return asset, nil
 }
 
 // RetrieveOffer retrieves an offer, returning nil if it does not exist.
@@ -577,47 +419,19 @@ func RetrieveOffer(
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	m, err := cli.MintFromContextCredentials(ctx)
+	m, err := cli.ClientFromContextCredentials(ctx)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 
-	if owner !=
-		fmt.Sprintf("%s@%s", m.Credentials.Username, m.Credentials.Host) {
-		_, host, err := mint.UsernameAndMintHostFromAddress(ctx, owner)
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-		m = &cli.Mint{Host: host}
-	}
-
 	out.Statf("[Retrieving offer] user=%s offer=%s\n", owner, id)
 
-	status, raw, err := m.Get(ctx,
-		fmt.Sprintf("/offers/%s", id),
-		url.Values{})
-	if err != nil {
-		return nil, errors.Trace(err)
-	}
-
-	if *status != http.StatusOK {
-		var e errors.ConcreteUserError
-		err = raw.Extract("error", &e)
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-		if e.ErrCode == "offer_not_found" {
-			return nil, nil
-		}
-		return nil, errors.Trace(
-			errors.Newf("(%s) %s", e.ErrCode, e.ErrMessage))
-	}
-
-	var offer mint.OfferResource
-	err = raw.Extract("offer", &offer)
-	if err != nil {
+	offer, err := m.RetrieveOffer(ctx, id)
+	if sdk.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
 		return nil, errors.Trace(err)
 	}
 
-	return &offer, nil
+	return offer, nil
 }
@@ -3,11 +3,13 @@ package command
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/spolu/settle/cli"
 	"github.com/spolu/settle/lib/errors"
 	"github.com/spolu/settle/lib/out"
 	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/sdk"
 )
 
 // ObjType reperesents a list object type.
@@ -23,16 +25,19 @@ const (
 	ObjTpBalance ObjType = "balance"
 	// ObjTpTrustline trustline object type.
 	ObjTpTrustline ObjType = "trustline"
+	// ObjTpOffer offer object type.
+	ObjTpOffer ObjType = "offer"
 )
 
 func init() {
 	cli.Registrar[CmdNmList] = NewList
 }
 
-// List assets, balances, balances for an asset and trustlines.
+// List assets, balances, balances for an asset, offers and trustlines.
 type List struct {
 	Type      ObjType
 	AssetName *string
+	Status    *mint.OfStatus
 }
 
 // NewList constructs and initializes the command.
@@ -50,15 +55,15 @@ func (c *List) Help(
 	ctx context.Context,
 ) {
 	out.Normf("\nUsage: ")
-	out.Boldf("settle list <type> [<asset>]\n")
+	out.Boldf("settle list <type> [<asset>|<status>]\n")
 	out.Normf("\n")
-	out.Normf("  Lists assets, balances (yours or related to one of your assets) or trustlines\n")
-	out.Normf("  (from you, and to you for a particular asset).\n")
+	out.Normf("  Lists assets, balances (yours or related to one of your assets), your offers\n")
+	out.Normf("  or trustlines (from you, and to you for a particular asset).\n")
 	out.Normf("\n")
 	out.Normf("Arguments:\n")
 	out.Boldf("  type\n")
 	out.Normf("    The type of object to retrieve and list.\n")
-	out.Valuf("    assets balances trustlines\n")
+	out.Valuf("    assets balances offers trustlines\n")
 	out.Normf("\n")
 	out.Boldf("  asset\n")
 	out.Normf("    Applicable for balances and required for trustlines. If used with balances,\n")
@@ -66,10 +71,15 @@ func (c *List) Help(
 	out.Normf("    when used with trustlines, list all the trustlines for a particular asset.\n")
 	out.Valuf("    USD.2 HOUR-OF-WORK.0 BTC.7 EUR.2 DRINK.0\n")
 	out.Normf("\n")
+	out.Boldf("  status\n")
+	out.Normf("    Applicable for offers, list only your offers with that status.\n")
+	out.Valuf("    active closed consumed\n")
+	out.Normf("\n")
 	out.Normf("Examples:\n")
 	out.Valuf("  settle list assets\n")
 	out.Valuf("  settle list balances\n")
 	out.Valuf("  settle list balances USD.2\n")
+	out.Valuf("  settle list offers active\n")
 	out.Valuf("  settle list trustlines EUR.2\n")
 	out.Normf("\n")
 }
@@ -87,7 +97,8 @@ func (c *List) Parse(
 
 	if len(args) == 0 {
 		return errors.Trace(
-			errors.Newf("Object required (assets, balances, or trustlines)."))
+			errors.Newf("Object required (assets, balances, offers, or " +
+				"trustlines)."))
 	}
 	typ, args := args[0], args[1:]
 
@@ -98,10 +109,12 @@ func (c *List) Parse(
 		c.Type = ObjTpBalance
 	case "trustlines", "trustline", "trusts", "trust":
 		c.Type = ObjTpTrustline
+	case "offers", "offer":
+		c.Type = ObjTpOffer
 	default:
 		return errors.Trace(
 			errors.Newf("Invalid object type: %s expected assets balances, "+
-				"or trustlines.", typ))
+				"offers, or trustlines.", typ))
 	}
 
 	if len(args) > 0 {
@@ -115,6 +128,16 @@ func (c *List) Parse(
 				return errors.Trace(err)
 			}
 			c.AssetName = &a.Name
+		case ObjTpOffer:
+			status := mint.OfStatus(args[0])
+			switch status {
+			case mint.OfStActive, mint.OfStClosed, mint.OfStConsumed:
+				c.Status = &status
+			default:
+				return errors.Trace(
+					errors.Newf("Invalid offer status: %s expected active, "+
+						"closed, or consumed.", args[0]))
+			}
 		}
 	} else {
 		switch c.Type {
@@ -138,6 +161,8 @@ func (c *List) Execute(
 		return c.ExecuteBalances(ctx)
 	case ObjTpTrustline:
 		return c.ExecuteTrustlines(ctx)
+	case ObjTpOffer:
+		return c.ExecuteOffers(ctx)
 	}
 	return nil
 }
@@ -228,10 +253,20 @@ func (c *List) ExecuteBalances(
 func (c *List) ExecuteTrustlines(
 	ctx context.Context,
 ) error {
-	cOffers, err := ListAssetOffers(ctx, *c.AssetName, mint.PgTpCanonical)
+	a, err := mint.AssetResourceFromName(ctx, *c.AssetName)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	offers, err := ListOffers(ctx, &sdk.OfferListParams{AssetCode: a.Code})
 	if err != nil {
 		return errors.Trace(err)
 	}
+	cOffers := []mint.OfferResource{}
+	for _, o := range offers {
+		if strings.HasPrefix(o.Pair, *c.AssetName+"/") {
+			cOffers = append(cOffers, o)
+		}
+	}
 	pOffers, err := ListAssetOffers(ctx, *c.AssetName, mint.PgTpPropagated)
 	if err != nil {
 		return errors.Trace(err)
@@ -275,3 +310,37 @@ func (c *List) ExecuteTrustlines(
 
 	return nil
 }
+
+// ExecuteOffers the list command for offers.
+func (c *List) ExecuteOffers(
+	ctx context.Context,
+) error {
+	params := &sdk.OfferListParams{}
+	if c.Status != nil {
+		params.Status = *c.Status
+	}
+	offers, err := ListOffers(ctx, params)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	out.Boldf("Offers:\n")
+	data := [][][2]string{}
+	for _, o := range offers {
+		data = append(data, [][2]string{
+			[2]string{"ID", o.ID},
+			[2]string{"Pair", o.Pair},
+			[2]string{"Price", o.Price},
+			[2]string{"Amount", o.Amount.String()},
+			[2]string{"Status", string(o.Status)},
+			[2]string{"Remainder", o.Remainder.String()},
+		})
+	}
+	if len(offers) == 0 {
+		out.Normf("  No offer.\n")
+	} else {
+		c.OutList(ctx, data)
+	}
+
+	return nil
+}
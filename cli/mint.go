@@ -2,100 +2,41 @@ package cli
 
 import (
 	"context"
-	"encoding/json"
-	"net/http"
-	"net/url"
-	"strings"
 
-	"github.com/spolu/settle/lib/client"
 	"github.com/spolu/settle/lib/errors"
 	"github.com/spolu/settle/lib/svc"
-	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/sdk"
 )
 
-// Mint represents a mint
-type Mint struct {
-	Host        string
-	Credentials *Credentials
-}
-
-// MintFromContextCredentials returns a mint object from the credentials stored
-// in the current context.
-func MintFromContextCredentials(
+// ClientFromContextCredentials returns a mint SDK client authenticated with
+// the credentials stored in the current context.
+func ClientFromContextCredentials(
 	ctx context.Context,
-) (*Mint, error) {
+) (*sdk.Client, error) {
 	c := GetCredentials(ctx)
 	if c == nil {
 		return nil, errors.Trace(
 			errors.Newf("Not logged in (see `settle login`)"))
 	}
-	return &Mint{
-		Host:        c.Host,
-		Credentials: c,
-	}, nil
+	return newClient(ctx, c)
 }
 
-// Post performs a POST request to the mint.
-func (m *Mint) Post(
+// newClient returns a mint SDK client authenticated with the provided
+// credentials, encoding request bodies as configured (see GetContentType).
+func newClient(
 	ctx context.Context,
-	path string,
-	query url.Values,
-	params url.Values,
-) (*int, *svc.Resp, error) {
-	req, err := http.NewRequest("POST",
-		mint.FullMintURL(ctx, m.Host, path, query).String(),
-		strings.NewReader(params.Encode()))
-	if err != nil {
-		return nil, nil, errors.Trace(err)
-	}
-
-	req.Header.Add("Mint-Protocol-Version", mint.ProtocolVersion)
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	if m.Credentials != nil {
-		req.SetBasicAuth(m.Credentials.Username, m.Credentials.Password)
-	}
-
-	r, err := client.Default(ctx).Do(req)
-	if err != nil {
-		return nil, nil, errors.Trace(err)
-	}
-	defer r.Body.Close()
-
-	var raw svc.Resp
-	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
-		return nil, nil, errors.Trace(err)
-	}
-
-	return &r.StatusCode, &raw, nil
-}
-
-// Get performs a GET request to the mint.
-func (m *Mint) Get(
-	ctx context.Context,
-	path string,
-	query url.Values,
-) (*int, *svc.Resp, error) {
-	req, err := http.NewRequest("GET",
-		mint.FullMintURL(ctx, m.Host, path, query).String(), nil)
-	if err != nil {
-		return nil, nil, errors.Trace(err)
-	}
-
-	req.Header.Add("Mint-Protocol-Version", mint.ProtocolVersion)
-	if m.Credentials != nil {
-		req.SetBasicAuth(m.Credentials.Username, m.Credentials.Password)
-	}
-
-	r, err := client.Default(ctx).Do(req)
-	if err != nil {
-		return nil, nil, errors.Trace(err)
-	}
-	defer r.Body.Close()
-
-	var raw svc.Resp
-	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
-		return nil, nil, errors.Trace(err)
-	}
-
-	return &r.StatusCode, &raw, nil
+	c *Credentials,
+) (*sdk.Client, error) {
+	m := &sdk.Client{
+		Host: c.Host,
+		Credentials: &sdk.Credentials{
+			Username: c.Username,
+			Password: c.Password,
+		},
+		JSON: GetContentType(ctx) == svc.ContentTypeJSON,
+	}
+	if err := m.Init(ctx); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return m, nil
 }
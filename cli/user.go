@@ -5,8 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"net/http"
-	"net/url"
 	"os"
 	"path/filepath"
 
@@ -15,6 +13,7 @@ import (
 	"github.com/spolu/settle/lib/errors"
 	"github.com/spolu/settle/lib/out"
 	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/sdk"
 )
 
 // Credentials rerpesents the credentials of the currently logged in user.
@@ -107,22 +106,16 @@ func Login(
 	}
 
 	// Check the credentials validity.
-	m := &Mint{
-		Host:        creds.Host,
-		Credentials: creds,
-	}
-	status, raw, err := m.Get(ctx, "/balances", url.Values{})
+	m, err := newClient(ctx, creds)
 	if err != nil {
 		return errors.Trace(err)
 	}
-	if *status != http.StatusOK {
-		var e errors.ConcreteUserError
-		err = raw.Extract("error", &e)
-		if err != nil {
-			return errors.Trace(err)
-		}
-		return errors.Trace(
-			errors.Newf("(%s) %s", e.ErrCode, e.ErrMessage))
+	it := m.ListBalances(ctx, &sdk.BalanceListParams{
+		ListParams: sdk.ListParams{Limit: 1},
+	})
+	it.Next()
+	if err := it.Err(); err != nil {
+		return errors.Trace(err)
 	}
 
 	path, err := CredentialsPath(ctx)
@@ -58,8 +58,8 @@ func GetCertificateFromFiles(
 		return nil, err
 	}
 
-	logging.Logf(ctx,
-		"Loading certificate: crt_file=%s, key_file=%s", certFile, keyFile)
+	logging.Info(ctx, "Loading certificate",
+		"crt_file", certFile, "key_file", keyFile)
 
 	return &cert, nil
 }
@@ -104,11 +104,11 @@ func GetSelfSignedQACertificate(
 	h := strings.Split(host, ":")[0]
 	if ip := net.ParseIP(h); ip != nil {
 		template.IPAddresses = append(template.IPAddresses, ip)
-		logging.Logf(ctx, "Self-signing QA certificate: ip=%s", ip)
+		logging.Info(ctx, "Self-signing QA certificate", "ip", ip)
 
 	} else {
 		template.DNSNames = append(template.DNSNames, h)
-		logging.Logf(ctx, "Self-signing QA certificate: dns=%s", h)
+		logging.Info(ctx, "Self-signing QA certificate", "dns", h)
 	}
 
 	bytes, err := x509.CreateCertificate(
@@ -2,6 +2,8 @@ package db
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/spolu/settle/lib/errors"
@@ -10,6 +12,29 @@ import (
 
 var schemas = map[string]map[string]string{}
 
+// column is a column added to a table after its creation.
+type column struct {
+	Table      string
+	Name       string
+	Definition string
+}
+
+var columns = map[string][]column{}
+
+// backfills are the functions filling the registered columns for the rows
+// existing when they are added, by tag, table and column name.
+var backfills = map[string]func(context.Context, *sqlx.DB) error{}
+
+// index is an index created once the registered columns are added.
+type index struct {
+	Table   string
+	Name    string
+	Unique  bool
+	Columns []string
+}
+
+var indexes = map[string][]index{}
+
 // RegisterSchema lets schemas register themselves.
 func RegisterSchema(
 	tag string,
@@ -22,18 +47,121 @@ func RegisterSchema(
 	schemas[tag][table] = schema
 }
 
-// CreateDBTables creates the Mint DB tables if they don't exist.
+// RegisterColumn lets schemas register the columns added to their table after
+// its creation. The column must also be part of the table schema (for new
+// DBs) and its definition must be valid for `ALTER TABLE ... ADD COLUMN`
+// (NOT NULL columns require a default).
+func RegisterColumn(
+	tag string,
+	table string,
+	name string,
+	definition string,
+) {
+	columns[tag] = append(columns[tag], column{table, name, definition})
+}
+
+// RegisterBackfill lets schemas register the function filling a column
+// registered with RegisterColumn for the rows existing when it is added. It
+// runs once, right after the column is added.
+func RegisterBackfill(
+	tag string,
+	table string,
+	name string,
+	backfill func(context.Context, *sqlx.DB) error,
+) {
+	backfills[tag+"."+table+"."+name] = backfill
+}
+
+// RegisterIndex lets schemas register the indexes of their table. Indexes are
+// created after the registered columns are added so that they can cover
+// columns added after the creation of the table.
+func RegisterIndex(
+	tag string,
+	table string,
+	name string,
+	unique bool,
+	columns ...string,
+) {
+	indexes[tag] = append(indexes[tag], index{table, name, unique, columns})
+}
+
+// CreateDBTables creates the Mint DB tables if they don't exist, adds (and
+// backfills) the registered columns missing from existing tables and creates
+// the registered indexes if they don't exist.
 func CreateDBTables(
 	ctx context.Context,
 	tag string,
 	db *sqlx.DB,
 ) error {
 	for name, sch := range schemas[tag] {
-		logging.Logf(ctx, "Executing schema: tag=%s name=%s\n", tag, name)
+		logging.Info(ctx, "Executing schema", "tag", tag, "name", name)
 		_, err := db.Exec(sch)
 		if err != nil {
 			return errors.Trace(err)
 		}
 	}
+
+	for _, c := range columns[tag] {
+		exists, err := hasColumn(db, c.Table, c.Name)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if exists {
+			continue
+		}
+		logging.Info(ctx, "Adding column",
+			"tag", tag, "table", c.Table, "column", c.Name)
+		_, err = db.Exec(fmt.Sprintf(
+			"ALTER TABLE %s ADD COLUMN %s %s", c.Table, c.Name, c.Definition))
+		if err != nil {
+			return errors.Trace(err)
+		}
+
+		if backfill, ok := backfills[tag+"."+c.Table+"."+c.Name]; ok {
+			logging.Info(ctx, "Backfilling column",
+				"tag", tag, "table", c.Table, "column", c.Name)
+			err = backfill(ctx, db)
+			if err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+
+	for _, i := range indexes[tag] {
+		unique := ""
+		if i.Unique {
+			unique = "UNIQUE "
+		}
+		_, err := db.Exec(fmt.Sprintf(
+			"CREATE %sINDEX IF NOT EXISTS %s ON %s(%s)",
+			unique, i.Name, i.Table, strings.Join(i.Columns, ", ")))
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
 	return nil
 }
+
+// hasColumn returns whether a table has the provided column.
+func hasColumn(
+	db *sqlx.DB,
+	table string,
+	name string,
+) (bool, error) {
+	rows, err := db.Queryx(fmt.Sprintf("SELECT * FROM %s LIMIT 0", table))
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	defer rows.Close()
+
+	names, err := rows.Columns()
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	for _, n := range names {
+		if n == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/spolu/settle/lib/logging"
+	"github.com/spolu/settle/lib/metrics"
 	"github.com/spolu/settle/lib/token"
+	"github.com/spolu/settle/lib/trace"
 )
 
 const (
@@ -15,10 +18,23 @@ const (
 	transactionKey ContextKey = "db.transaction"
 )
 
-// Transaction stores the current mintDB transaction.
+// TransactionDuration is the duration of transactions by tag and result
+// (commit or rollback).
+var TransactionDuration = metrics.NewHistogram(
+	"db_transaction_duration_seconds",
+	"Duration of database transactions by tag and result.",
+	nil, "tag", "result",
+)
+
+// Transaction stores the current mintDB transaction. Span times the
+// transaction until it is committed or rolled back.
 type Transaction struct {
 	Tx    *sqlx.Tx
 	Token string
+	Tag   string
+	Start time.Time
+	Hooks *[]func()
+	Span  *trace.Span
 }
 
 // WithTransaction stores the transaction in the provided context.
@@ -52,22 +68,48 @@ func Begin(
 	token := token.New("tx")
 	logging.Logf(ctx,
 		"Transaction BEGIN: tag=%s token=%s", tag, token)
+	_, span := trace.Start(ctx, "db.transaction", "tag", tag, "token", token)
 	return WithTransaction(ctx, Transaction{
 		Tx:    GetDB(ctx, tag).MustBegin(),
 		Token: token,
+		Tag:   tag,
+		Start: time.Now(),
+		Hooks: &[]func(){},
+		Span:  span,
 	})
 }
 
-// Commit commits the transaction in the current context.
+// Commit commits the transaction in the current context and runs the hooks
+// registered with OnCommit.
 func Commit(
 	ctx context.Context,
 ) {
 	logging.Logf(ctx,
 		"Transaction COMMIT: token=%s", GetTransaction(ctx).Token)
 	err := GetTransaction(ctx).Tx.Commit()
+	GetTransaction(ctx).Span.Finish(err)
 	if err != nil {
 		panic(err)
 	}
+	observe(ctx, "commit")
+	for _, hook := range *GetTransaction(ctx).Hooks {
+		hook()
+	}
+}
+
+// OnCommit registers a function to run once the transaction in the current
+// context is committed (it is dropped if the transaction is rolled back). If
+// no transaction has begun, the function is run immediately.
+func OnCommit(
+	ctx context.Context,
+	hook func(),
+) {
+	if ctx.Value(transactionKey) != nil && GetTransaction(ctx).Tx != nil {
+		hooks := GetTransaction(ctx).Hooks
+		*hooks = append(*hooks, hook)
+		return
+	}
+	hook()
 }
 
 // LoggedRollback logs a rollback a commit or another rollback didn't take
@@ -84,9 +126,22 @@ func LoggedRollback(ctx context.Context) {
 	} else if err == nil {
 		logging.Logf(ctx,
 			"Transaction ROLLBACK: token=%s", GetTransaction(ctx).Token)
+		GetTransaction(ctx).Span.Set("rolled_back", true)
+		GetTransaction(ctx).Span.Finish(nil)
+		observe(ctx, "rollback")
 	}
 }
 
+// observe records the duration of the transaction in the current context.
+func observe(
+	ctx context.Context,
+	result string,
+) {
+	t := GetTransaction(ctx)
+	metrics.Observe(ctx, TransactionDuration,
+		time.Now().Sub(t.Start).Seconds(), t.Tag, result)
+}
+
 // Ext returns the current Ext (a transaction if one has begin, or the DB
 // otherwise).
 func Ext(
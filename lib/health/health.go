@@ -0,0 +1,142 @@
+package health
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+)
+
+// Status is the status of a health check.
+type Status string
+
+const (
+	// HlStOK is the status of a passing check.
+	HlStOK Status = "ok"
+	// HlStFailing is the status of a failing check.
+	HlStFailing Status = "failing"
+)
+
+const (
+	// PingTimeout is the deadline of the database connectivity check.
+	PingTimeout time.Duration = 2 * time.Second
+)
+
+// Check is the result of a health check. Details are free form values
+// describing the state checked (and the error of a failing check).
+type Check struct {
+	Name    string            `json:"name"`
+	Status  Status            `json:"status"`
+	Details map[string]string `json:"details"`
+}
+
+// NewCheck constructs the result of a check, failing if err is not nil, with
+// details from the provided key value pairs.
+func NewCheck(
+	name string,
+	err error,
+	kv ...interface{},
+) Check {
+	c := Check{
+		Name:    name,
+		Status:  HlStOK,
+		Details: map[string]string{},
+	}
+	for i := 0; i+1 < len(kv); i += 2 {
+		c.Details[fmt.Sprint(kv[i])] = fmt.Sprint(kv[i+1])
+	}
+	if err != nil {
+		c.Status = HlStFailing
+		c.Details["error"] = errors.Cause(err).Error()
+	}
+	return c
+}
+
+// Evaluate returns the overall status of a set of checks (failing if any of
+// them is failing) and the matching HTTP status (200 or 503).
+func Evaluate(
+	checks []Check,
+) (Status, int) {
+	for _, c := range checks {
+		if c.Status != HlStOK {
+			return HlStFailing, http.StatusServiceUnavailable
+		}
+	}
+	return HlStOK, http.StatusOK
+}
+
+// DB checks the connectivity to the database for the provided tag.
+func DB(
+	ctx context.Context,
+	tag string,
+) Check {
+	start := time.Now()
+
+	var err error
+	if d := db.GetDB(ctx, tag); d == nil {
+		err = errors.Newf("No database for tag: %s", tag)
+	} else {
+		pctx, cancel := context.WithTimeout(ctx, PingTimeout)
+		defer cancel()
+		err = d.PingContext(pctx)
+	}
+
+	return NewCheck("db."+tag, err,
+		"latency_ms", time.Now().Sub(start)/time.Millisecond)
+}
+
+// Certificate checks that the first certificate of the PEM encoded file
+// provided remains valid for at least minValidity.
+func Certificate(
+	ctx context.Context,
+	file string,
+	minValidity time.Duration,
+) Check {
+	cert, err := loadCertificate(file)
+	if err != nil {
+		return NewCheck("certificate", err, "file", file)
+	}
+
+	validity := cert.NotAfter.Sub(time.Now())
+	if validity < minValidity {
+		err = errors.Newf(
+			"Certificate expires in less than %s", minValidity.String())
+	}
+
+	return NewCheck("certificate", err,
+		"file", file,
+		"not_after", cert.NotAfter.UTC().Format(time.RFC3339),
+		"expires_in_h", int64(validity/time.Hour))
+}
+
+// loadCertificate loads and parses the first certificate of a PEM encoded
+// file.
+func loadCertificate(
+	file string,
+) (*x509.Certificate, error) {
+	raw, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for {
+		var block *pem.Block
+		block, raw = pem.Decode(raw)
+		if block == nil {
+			return nil, errors.Trace(errors.Newf(
+				"No certificate found in: %s", file))
+		}
+		if block.Type == "CERTIFICATE" {
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			return cert, nil
+		}
+	}
+}
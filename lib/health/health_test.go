@@ -0,0 +1,58 @@
+package health
+
+import (
+	"context"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/spolu/settle/lib/cert"
+	"github.com/spolu/settle/lib/errors"
+)
+
+func TestEvaluate(t *testing.T) {
+	checks := []Check{NewCheck("a", nil, "count", 1)}
+	if status, code := Evaluate(checks); status != HlStOK || code != 200 {
+		t.Errorf("Expected ok/200, got %s/%d", status, code)
+	}
+	if checks[0].Details["count"] != "1" {
+		t.Errorf("Expected count detail, got %v", checks[0].Details)
+	}
+
+	checks = append(checks, NewCheck("b", errors.Newf("down")))
+	if status, code := Evaluate(checks); status != HlStFailing || code != 503 {
+		t.Errorf("Expected failing/503, got %s/%d", status, code)
+	}
+	if checks[1].Details["error"] != "down" {
+		t.Errorf("Expected error detail, got %v", checks[1].Details)
+	}
+}
+
+func TestCertificate(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := cert.GetSelfSignedQACertificate(ctx, "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := ioutil.TempFile("", "health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	pem.Encode(f, &pem.Block{Type: "CERTIFICATE", Bytes: c.Certificate[0]})
+	f.Close()
+
+	// Self signed QA certificates are valid for a year.
+	if c := Certificate(ctx, f.Name(), 24*time.Hour); c.Status != HlStOK {
+		t.Errorf("Expected valid certificate, got %v", c.Details)
+	}
+	if c := Certificate(ctx, f.Name(), 400*24*time.Hour); c.Status != HlStFailing {
+		t.Errorf("Expected expiring certificate, got %v", c.Details)
+	}
+	if c := Certificate(ctx, f.Name()+".missing", time.Hour); c.Status != HlStFailing {
+		t.Errorf("Expected missing certificate to fail")
+	}
+}
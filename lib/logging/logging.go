@@ -2,7 +2,9 @@ package logging
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"strings"
+	"time"
 )
 
 var silentKey = new(int)
@@ -19,26 +21,120 @@ func Silent(ctx context.Context) bool {
 	return ok && val
 }
 
-// Log shells out to log.Print if Silent is not set.
-func Log(c context.Context, v ...interface{}) {
-	if c != nil {
-		if !Silent(c) {
-			log.Print(v...)
+var fieldsKey = new(int)
+
+// With returns a context carrying the provided fields (alternating keys and
+// values) in addition to the fields already carried by ctx. Fields carried by
+// the context are added to all the entries logged with it.
+func With(
+	ctx context.Context,
+	kv ...interface{},
+) context.Context {
+	return context.WithValue(ctx, fieldsKey,
+		merge(contextFields(ctx), toFields(kv)))
+}
+
+// contextFields returns the fields carried by a context.
+func contextFields(
+	ctx context.Context,
+) []Field {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsKey).([]Field)
+	return fields
+}
+
+// toFields converts alternating keys and values to fields. A trailing key
+// without value is logged with an empty value.
+func toFields(
+	kv []interface{},
+) []Field {
+	fields := []Field{}
+	for i := 0; i < len(kv); i += 2 {
+		f := Field{Key: fmt.Sprint(kv[i])}
+		if i+1 < len(kv) {
+			f.Value = kv[i+1]
 		}
-	} else {
-		log.Print(v...)
+		fields = append(fields, f)
 	}
+	return fields
 }
 
-// Logf shells out to log.Printf if Silent is not set.
-func Logf(c context.Context, format string, v ...interface{}) {
-	if c != nil {
-		if !Silent(c) {
-			log.Printf(format, v...)
+// merge returns the fields followed by the extra fields. Extra fields replace
+// the fields with the same key, in place.
+func merge(
+	fields []Field,
+	extra []Field,
+) []Field {
+	merged := append([]Field{}, fields...)
+EXTRA:
+	for _, e := range extra {
+		for i, f := range merged {
+			if f.Key == e.Key {
+				merged[i] = e
+				continue EXTRA
+			}
 		}
-	} else {
-		log.Printf(format, v...)
+		merged = append(merged, e)
+	}
+	return merged
+}
+
+// entry logs an entry at the provided level, unless ctx is silent.
+func entry(
+	ctx context.Context,
+	level Level,
+	msg string,
+	kv []interface{},
+) {
+	if ctx != nil && Silent(ctx) {
+		return
+	}
+	s := getSink()
+	if !s.Enabled(level) {
+		return
 	}
+	s.Write(&Entry{
+		Time:    time.Now().UTC(),
+		Level:   level,
+		Message: msg,
+		Fields:  merge(contextFields(ctx), toFields(kv)),
+	})
+}
+
+// Debug logs a message at the debug level with the provided fields
+// (alternating keys and values).
+func Debug(ctx context.Context, msg string, kv ...interface{}) {
+	entry(ctx, LvDebug, msg, kv)
+}
+
+// Info logs a message at the info level with the provided fields
+// (alternating keys and values).
+func Info(ctx context.Context, msg string, kv ...interface{}) {
+	entry(ctx, LvInfo, msg, kv)
+}
+
+// Warn logs a message at the warn level with the provided fields
+// (alternating keys and values).
+func Warn(ctx context.Context, msg string, kv ...interface{}) {
+	entry(ctx, LvWarn, msg, kv)
+}
+
+// Error logs a message at the error level with the provided fields
+// (alternating keys and values).
+func Error(ctx context.Context, msg string, kv ...interface{}) {
+	entry(ctx, LvError, msg, kv)
+}
+
+// Log logs its arguments at the info level if Silent is not set.
+func Log(c context.Context, v ...interface{}) {
+	entry(c, LvInfo, strings.TrimSuffix(fmt.Sprint(v...), "\n"), nil)
+}
+
+// Logf logs a formatted message at the info level if Silent is not set.
+func Logf(c context.Context, format string, v ...interface{}) {
+	entry(c, LvInfo, strings.TrimSuffix(fmt.Sprintf(format, v...), "\n"), nil)
 }
 
 // PadRight right-pads a string.
@@ -0,0 +1,94 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLogFormats(t *testing.T) {
+	defer SetSink(getSink())
+
+	ctx := With(context.Background(), "request_id", "request_1", "hop", 0)
+
+	type testCase struct {
+		format   Format
+		expected string
+	}
+
+	tests := []testCase{
+		testCase{
+			format: FmLogfmt,
+			expected: `level=warn msg="Propagation failed" request_id=request_1 ` +
+				`hop=1 error="no route" peer=mint.example.com`,
+		},
+		testCase{
+			format: FmJSON,
+			expected: `"level":"warn","msg":"Propagation failed",` +
+				`"request_id":"request_1","hop":1,"error":"no route",` +
+				`"peer":"mint.example.com"}`,
+		},
+		testCase{
+			format: FmText,
+			expected: `WARN  Propagation failed request_id=request_1 hop=1 ` +
+				`error="no route" peer=mint.example.com`,
+		},
+	}
+	for _, test := range tests {
+		var b bytes.Buffer
+		SetSink(NewSink(&b, test.format, LvInfo))
+
+		Debug(ctx, "Propagating")
+		Warn(ctx, "Propagation failed",
+			"hop", 1, "error", errors.New("no route"), "peer", "mint.example.com")
+
+		lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+		if len(lines) != 1 {
+			t.Errorf("Expected 1 entry (%s), got %d: %q",
+				test.format, len(lines), b.String())
+			continue
+		}
+		if !strings.HasSuffix(lines[0], test.expected) {
+			t.Errorf("Expected entry (%s) ending with %q, got %q",
+				test.format, test.expected, lines[0])
+		}
+		if test.format == FmJSON {
+			var object map[string]interface{}
+			if err := json.Unmarshal([]byte(lines[0]), &object); err != nil {
+				t.Errorf("Expected valid JSON entry, got %q: %s", lines[0], err)
+			}
+		}
+	}
+}
+
+func TestLogSilent(t *testing.T) {
+	defer SetSink(getSink())
+
+	var b bytes.Buffer
+	SetSink(NewSink(&b, FmLogfmt, LvDebug))
+
+	Info(SetSilent(context.Background(), true), "Silenced")
+	if b.Len() != 0 {
+		t.Errorf("Expected no entry, got %q", b.String())
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, name := range []string{"debug", "INFO", "Warn", "error"} {
+		l, err := ParseLevel(name)
+		if err != nil {
+			t.Errorf("Expected level %s to parse: %s", name, err)
+		} else if l.String() != strings.ToLower(name) {
+			t.Errorf("Expected level %s, got %s", strings.ToLower(name), l)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Errorf("Expected level verbose to be invalid")
+	}
+	if err := Configure("xml", "", ""); err == nil {
+		t.Errorf("Expected format xml to be invalid")
+	}
+}
@@ -0,0 +1,293 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spolu/settle/lib/errors"
+)
+
+// Level is the severity of a log entry.
+type Level int
+
+const (
+	// LvDebug is the level of verbose entries useful to debug.
+	LvDebug Level = iota
+	// LvInfo is the level of entries describing normal operation.
+	LvInfo
+	// LvWarn is the level of entries reporting recoverable failures.
+	LvWarn
+	// LvError is the level of entries reporting failures.
+	LvError
+)
+
+// levelNames maps levels to their names.
+var levelNames = map[Level]string{
+	LvDebug: "debug",
+	LvInfo:  "info",
+	LvWarn:  "warn",
+	LvError: "error",
+}
+
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+// ParseLevel parses a level from its name.
+func ParseLevel(
+	name string,
+) (Level, error) {
+	for l, n := range levelNames {
+		if n == strings.ToLower(name) {
+			return l, nil
+		}
+	}
+	return LvInfo, errors.Trace(errors.Newf(
+		"Invalid log level `%s`, valid levels are: debug, info, warn, error",
+		name))
+}
+
+// Format is the format of the entries written by a sink.
+type Format string
+
+const (
+	// FmText formats entries as a message followed by its fields, for humans.
+	FmText Format = "text"
+	// FmLogfmt formats entries as logfmt lines.
+	FmLogfmt Format = "logfmt"
+	// FmJSON formats entries as JSON objects, one per line.
+	FmJSON Format = "json"
+)
+
+// Field is a key value pair attached to a log entry.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Entry is a log entry.
+type Entry struct {
+	Time    time.Time
+	Level   Level
+	Message string
+	Fields  []Field
+}
+
+// Sink receives the log entries.
+type Sink interface {
+	// Enabled returns whether entries of the level are written.
+	Enabled(Level) bool
+	// Write writes an entry.
+	Write(*Entry)
+}
+
+// writerSink writes entries to a writer in the provided format. Text entries
+// are written through the standard logger if no writer is set, so that they
+// respect its flags.
+type writerSink struct {
+	mutex  sync.Mutex
+	w      io.Writer
+	format Format
+	level  Level
+}
+
+// NewSink returns a sink writing the entries of at least the provided level to
+// w in the provided format. Text entries are written through the standard
+// logger if w is nil.
+func NewSink(
+	w io.Writer,
+	format Format,
+	level Level,
+) Sink {
+	return &writerSink{
+		w:      w,
+		format: format,
+		level:  level,
+	}
+}
+
+// Enabled returns whether entries of the level are written.
+func (s *writerSink) Enabled(
+	level Level,
+) bool {
+	return level >= s.level
+}
+
+// Write writes an entry.
+func (s *writerSink) Write(
+	e *Entry,
+) {
+	var b bytes.Buffer
+	switch s.format {
+	case FmJSON:
+		writeJSON(&b, e)
+	case FmLogfmt:
+		writeLogfmt(&b, e)
+	default:
+		if s.w == nil {
+			writeText(&b, e)
+			log.Print(b.String())
+			return
+		}
+		b.WriteString(e.Time.Format(time.RFC3339Nano))
+		b.WriteString(" ")
+		writeText(&b, e)
+	}
+	b.WriteString("\n")
+
+	w := s.w
+	if w == nil {
+		w = os.Stderr
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	w.Write(b.Bytes())
+}
+
+// writeText writes the level, message and fields of an entry.
+func writeText(
+	b *bytes.Buffer,
+	e *Entry,
+) {
+	b.WriteString(PadRight(strings.ToUpper(e.Level.String()), " ", 6))
+	b.WriteString(e.Message)
+	for _, f := range e.Fields {
+		fmt.Fprintf(b, " %s=%s", f.Key, logfmtValue(f.Value))
+	}
+}
+
+// writeLogfmt writes an entry as a logfmt line.
+func writeLogfmt(
+	b *bytes.Buffer,
+	e *Entry,
+) {
+	fmt.Fprintf(b, "time=%s level=%s msg=%s",
+		e.Time.Format(time.RFC3339Nano), e.Level, logfmtValue(e.Message))
+	for _, f := range e.Fields {
+		fmt.Fprintf(b, " %s=%s", f.Key, logfmtValue(f.Value))
+	}
+}
+
+// writeJSON writes an entry as a JSON object, preserving the order of its
+// fields.
+func writeJSON(
+	b *bytes.Buffer,
+	e *Entry,
+) {
+	fmt.Fprintf(b, "{\"time\":%s,\"level\":%s,\"msg\":%s",
+		jsonValue(e.Time.Format(time.RFC3339Nano)), jsonValue(e.Level.String()),
+		jsonValue(e.Message))
+	for _, f := range e.Fields {
+		fmt.Fprintf(b, ",%s:%s", jsonValue(f.Key), jsonValue(f.Value))
+	}
+	b.WriteString("}")
+}
+
+// value returns the value logged for a field: errors and stringers are logged
+// as strings.
+func value(
+	v interface{},
+) interface{} {
+	switch v := v.(type) {
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	}
+	return v
+}
+
+// logfmtValue returns the logfmt representation of a value, quoted if needed.
+func logfmtValue(
+	v interface{},
+) string {
+	s, ok := value(v).(string)
+	if !ok {
+		s = fmt.Sprint(value(v))
+	}
+	if s == "" || strings.ContainsAny(s, " =\"\t\r\n") ||
+		strconv.Quote(s) != "\""+s+"\"" {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// jsonValue returns the JSON representation of a value.
+func jsonValue(
+	v interface{},
+) string {
+	b, err := json.Marshal(value(v))
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprint(v))
+	}
+	return string(b)
+}
+
+var sink Sink = NewSink(nil, FmText, LvInfo)
+var sinkMutex sync.RWMutex
+
+// getSink returns the sink entries are written to.
+func getSink() Sink {
+	sinkMutex.RLock()
+	defer sinkMutex.RUnlock()
+	return sink
+}
+
+// SetSink sets the sink entries are written to.
+func SetSink(
+	s Sink,
+) {
+	sinkMutex.Lock()
+	defer sinkMutex.Unlock()
+	sink = s
+}
+
+// Configure sets the sink from flags: the format (text, logfmt, json), the
+// minimum level (debug, info, warn, error) and the file entries are appended
+// to (standard error if empty). Empty values default to text and info.
+func Configure(
+	format string,
+	level string,
+	file string,
+) error {
+	f := FmText
+	switch Format(format) {
+	case "", FmText:
+	case FmLogfmt, FmJSON:
+		f = Format(format)
+	default:
+		return errors.Trace(errors.Newf(
+			"Invalid log format `%s`, valid formats are: text, logfmt, json",
+			format))
+	}
+
+	l := LvInfo
+	if level != "" {
+		var err error
+		l, err = ParseLevel(level)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	var w io.Writer
+	if file != "" {
+		fd, err := os.OpenFile(file,
+			os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		w = fd
+	}
+
+	SetSink(NewSink(w, f, l))
+	return nil
+}
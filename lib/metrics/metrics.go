@@ -0,0 +1,323 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Type is the type of a metric.
+type Type string
+
+const (
+	// TpCounter is the type of metrics that only increase.
+	TpCounter Type = "counter"
+	// TpGauge is the type of metrics that can go up and down.
+	TpGauge Type = "gauge"
+	// TpHistogram is the type of metrics counting observations in buckets.
+	TpHistogram Type = "histogram"
+)
+
+// DefaultBuckets are the default histogram buckets, suited to latencies
+// expressed in seconds.
+var DefaultBuckets = []float64{
+	.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10,
+}
+
+// Desc describes a metric.
+type Desc struct {
+	Name    string
+	Help    string
+	Type    Type
+	Labels  []string
+	Buckets []float64
+}
+
+// NewCounter returns the description of a counter with the provided label
+// names.
+func NewCounter(
+	name string,
+	help string,
+	labels ...string,
+) *Desc {
+	return &Desc{Name: name, Help: help, Type: TpCounter, Labels: labels}
+}
+
+// NewGauge returns the description of a gauge with the provided label names.
+func NewGauge(
+	name string,
+	help string,
+	labels ...string,
+) *Desc {
+	return &Desc{Name: name, Help: help, Type: TpGauge, Labels: labels}
+}
+
+// NewHistogram returns the description of a histogram with the provided
+// buckets (DefaultBuckets if nil) and label names.
+func NewHistogram(
+	name string,
+	help string,
+	buckets []float64,
+	labels ...string,
+) *Desc {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	return &Desc{
+		Name: name, Help: help, Type: TpHistogram, Labels: labels,
+		Buckets: buckets,
+	}
+}
+
+// series is the state of a metric for a set of label values.
+type series struct {
+	values []string
+
+	value  float64
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// family is the state of a metric for all its label values.
+type family struct {
+	desc   *Desc
+	series map[string]*series
+}
+
+// Registry stores the state of metrics and renders them in the Prometheus
+// text format. Metrics are created the first time they are updated.
+// Collectors registered with OnCollect are run before each rendering to
+// update the metrics computed on demand. A nil registry ignores all updates.
+type Registry struct {
+	mutex      sync.Mutex
+	families   map[string]*family
+	collectors []func(*Registry)
+}
+
+// NewRegistry constructs a new empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		families:   map[string]*family{},
+		collectors: []func(*Registry){},
+	}
+}
+
+// get returns the series of a metric for the provided label values, creating
+// it if needed. r.mutex must be held.
+func (r *Registry) get(
+	d *Desc,
+	values []string,
+) *series {
+	if len(values) != len(d.Labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d",
+			d.Name, len(d.Labels), len(values)))
+	}
+
+	f, ok := r.families[d.Name]
+	if !ok {
+		f = &family{desc: d, series: map[string]*series{}}
+		r.families[d.Name] = f
+	}
+
+	key := strings.Join(values, "\xff")
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{values: values}
+		if d.Type == TpHistogram {
+			s.counts = make([]uint64, len(d.Buckets))
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// Add adds a value to a counter or gauge.
+func (r *Registry) Add(
+	d *Desc,
+	v float64,
+	values ...string,
+) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.get(d, values).value += v
+}
+
+// Set sets the value of a gauge.
+func (r *Registry) Set(
+	d *Desc,
+	v float64,
+	values ...string,
+) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.get(d, values).value = v
+}
+
+// Reset drops all the series of a metric, used by collectors to drop the
+// label values that are not current anymore.
+func (r *Registry) Reset(
+	d *Desc,
+) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.families, d.Name)
+}
+
+// Observe records an observation in a histogram.
+func (r *Registry) Observe(
+	d *Desc,
+	v float64,
+	values ...string,
+) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	s := r.get(d, values)
+	for i, b := range d.Buckets {
+		if v <= b {
+			s.counts[i]++
+		}
+	}
+	s.sum += v
+	s.count++
+}
+
+// OnCollect registers a collector run before each rendering of the metrics.
+func (r *Registry) OnCollect(
+	collector func(*Registry),
+) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.collectors = append(r.collectors, collector)
+}
+
+// Write runs the collectors and renders the metrics in the Prometheus text
+// format, ordered by name and label values.
+func (r *Registry) Write(
+	w io.Writer,
+) error {
+	r.mutex.Lock()
+	collectors := append([]func(*Registry){}, r.collectors...)
+	r.mutex.Unlock()
+
+	for _, c := range collectors {
+		c(r)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	names := []string{}
+	for name := range r.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b bytes.Buffer
+	for _, name := range names {
+		f := r.families[name]
+		fmt.Fprintf(&b, "# HELP %s %s\n", name, escape(f.desc.Help, false))
+		fmt.Fprintf(&b, "# TYPE %s %s\n", name, f.desc.Type)
+
+		keys := []string{}
+		for k := range f.series {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			s := f.series[k]
+			switch f.desc.Type {
+			case TpHistogram:
+				for i, bound := range f.desc.Buckets {
+					fmt.Fprintf(&b, "%s_bucket%s %d\n", name,
+						labels(f.desc.Labels, s.values, "le", number(bound)),
+						s.counts[i])
+				}
+				fmt.Fprintf(&b, "%s_bucket%s %d\n", name,
+					labels(f.desc.Labels, s.values, "le", "+Inf"), s.count)
+				fmt.Fprintf(&b, "%s_sum%s %s\n", name,
+					labels(f.desc.Labels, s.values), number(s.sum))
+				fmt.Fprintf(&b, "%s_count%s %d\n", name,
+					labels(f.desc.Labels, s.values), s.count)
+			default:
+				fmt.Fprintf(&b, "%s%s %s\n", name,
+					labels(f.desc.Labels, s.values), number(s.value))
+			}
+		}
+	}
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// labels renders the labels of a series, followed by the extra label name
+// and value pairs provided.
+func labels(
+	names []string,
+	values []string,
+	extra ...string,
+) string {
+	pairs := []string{}
+	for i, n := range names {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", n, escape(values[i], true)))
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", extra[i], extra[i+1]))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// escape escapes a help text or label value (quote set).
+func escape(
+	s string,
+	quote bool,
+) string {
+	s = strings.Replace(s, "\\", "\\\\", -1)
+	s = strings.Replace(s, "\n", "\\n", -1)
+	if quote {
+		s = strings.Replace(s, "\"", "\\\"", -1)
+	}
+	return s
+}
+
+// number renders a sample value.
+func number(
+	v float64,
+) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRegistryWrite(t *testing.T) {
+	requests := NewCounter("requests_total", "Requests.", "endpoint")
+	pending := NewGauge("pending", "Pending \"tasks\".")
+	duration := NewHistogram("duration_seconds", "Duration.", []float64{.1, 1})
+
+	r := NewRegistry()
+	r.Add(requests, 1, "B")
+	r.Add(requests, 2, "A\"")
+	r.Add(requests, 1, "B")
+	r.OnCollect(func(r *Registry) {
+		r.Set(pending, 3)
+	})
+	r.Observe(duration, .05)
+	r.Observe(duration, .5)
+	r.Observe(duration, 5)
+
+	var b bytes.Buffer
+	if err := r.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := strings.Join([]string{
+		"# HELP duration_seconds Duration.",
+		"# TYPE duration_seconds histogram",
+		"duration_seconds_bucket{le=\"0.1\"} 1",
+		"duration_seconds_bucket{le=\"1\"} 2",
+		"duration_seconds_bucket{le=\"+Inf\"} 3",
+		"duration_seconds_sum 5.55",
+		"duration_seconds_count 3",
+		"# HELP pending Pending \"tasks\".",
+		"# TYPE pending gauge",
+		"pending 3",
+		"# HELP requests_total Requests.",
+		"# TYPE requests_total counter",
+		"requests_total{endpoint=\"A\\\"\"} 2",
+		"requests_total{endpoint=\"B\"} 2",
+		"",
+	}, "\n")
+	if b.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+}
+
+func TestNilRegistry(t *testing.T) {
+	var r *Registry
+	r.Add(NewCounter("requests_total", "Requests."), 1)
+	r.Observe(NewHistogram("duration_seconds", "Duration.", nil), 1)
+	r.OnCollect(func(r *Registry) {})
+}
@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+)
+
+// ContentType is the content type of the Prometheus text format.
+const ContentType string = "text/plain; version=0.0.4; charset=utf-8"
+
+// Handler returns an handler serving the metrics of the registry in the
+// Prometheus text format.
+func Handler(
+	r *Registry,
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var b bytes.Buffer
+		if err := r.Write(&b); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", ContentType)
+		w.Write(b.Bytes())
+	})
+}
+
+// ContextKey is the type of the key used with context to carry contextual
+// metrics registry.
+type ContextKey string
+
+const (
+	// registryKey the context.Context key to store the registry.
+	registryKey ContextKey = "metrics.registry"
+)
+
+// With stores the registry in the provided context.
+func With(
+	ctx context.Context,
+	registry *Registry,
+) context.Context {
+	return context.WithValue(ctx, registryKey, registry)
+}
+
+// Get returns the registry currently stored in the context (nil if none).
+func Get(
+	ctx context.Context,
+) *Registry {
+	registry, _ := ctx.Value(registryKey).(*Registry)
+	return registry
+}
+
+// Inc increments a counter of the registry of the context.
+func Inc(
+	ctx context.Context,
+	d *Desc,
+	values ...string,
+) {
+	Get(ctx).Add(d, 1, values...)
+}
+
+// Observe records an observation in a histogram of the registry of the
+// context.
+func Observe(
+	ctx context.Context,
+	d *Desc,
+	v float64,
+	values ...string,
+) {
+	Get(ctx).Observe(d, v, values...)
+}
+
+type middleware struct {
+	http.Handler
+	*Registry
+}
+
+// ServeHTTP handles incoming HTTP requests and injects the registry in their
+// context.
+func (m middleware) ServeHTTP(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	m.Handler.ServeHTTP(w, r.WithContext(With(r.Context(), m.Registry)))
+}
+
+// Middleware returns a middleware that injects the specified registry in
+// requests.
+func Middleware(
+	registry *Registry,
+) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return middleware{h, registry}
+	}
+}
@@ -0,0 +1,986 @@
+package openapi
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spolu/settle/lib/errors"
+)
+
+// The analysis relies on the conventions of the endpoint packages:
+//
+//	const EndPtCreateOffer EndPtName = "CreateOffer"
+//	registrar[EndPtCreateOffer] = NewCreateOffer
+//	mux.HandleFunc(pat.Post("/offers"), endpoint.HandlerFor(endpoint.EndPtCreateOffer))
+//
+// Parameters are collected from the calls reading the request (such as
+// `params.Get("pair")` on the result of `svc.ParseParams`) and errors from the calls constructing user
+// errors, following the calls to the functions and methods of the package
+// from the constructor, Validate and Execute (or Stream) of each endpoint.
+
+// httpStatuses maps the net/http status constants used by endpoints to their
+// value.
+var httpStatuses = map[string]int{
+	"StatusOK":                  http.StatusOK,
+	"StatusCreated":             http.StatusCreated,
+	"StatusAccepted":            http.StatusAccepted,
+	"StatusBadRequest":          http.StatusBadRequest,
+	"StatusUnauthorized":        http.StatusUnauthorized,
+	"StatusPaymentRequired":     http.StatusPaymentRequired,
+	"StatusForbidden":           http.StatusForbidden,
+	"StatusNotFound":            http.StatusNotFound,
+	"StatusConflict":            http.StatusConflict,
+	"StatusPreconditionFailed":  http.StatusPreconditionFailed,
+	"StatusTooManyRequests":     http.StatusTooManyRequests,
+	"StatusInternalServerError": http.StatusInternalServerError,
+	"StatusServiceUnavailable":  http.StatusServiceUnavailable,
+}
+
+// ParseRoutes extracts the routes bound by the controller source file.
+func ParseRoutes(
+	file string,
+) ([]Route, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), file, nil, 0)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	routes := []Route{}
+	ast.Inspect(f, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || selectorName(call.Fun) != "HandleFunc" || len(call.Args) != 2 {
+			return true
+		}
+		pattern, ok := call.Args[0].(*ast.CallExpr)
+		if !ok || len(pattern.Args) != 1 {
+			return true
+		}
+		path, ok := stringLit(pattern.Args[0])
+		if !ok {
+			return true
+		}
+		handler, ok := call.Args[1].(*ast.CallExpr)
+		if !ok || len(handler.Args) != 1 {
+			return true
+		}
+		routes = append(routes, Route{
+			Method: strings.ToUpper(selectorName(pattern.Fun)),
+			Path:   path,
+			Endpoint: strings.TrimPrefix(
+				selectorName(handler.Args[0]), "EndPt"),
+		})
+		return true
+	})
+
+	return routes, nil
+}
+
+// ParseResources returns the names of the resource struct types (whose name
+// ends with `Resource`) declared in the protocol source file.
+func ParseResources(
+	file string,
+) ([]string, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), file, nil, 0)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	resources := []string{}
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			t := spec.(*ast.TypeSpec)
+			if _, ok := t.Type.(*ast.StructType); ok &&
+				strings.HasSuffix(t.Name.Name, "Resource") {
+				resources = append(resources, t.Name.Name)
+			}
+		}
+	}
+
+	return resources, nil
+}
+
+// pkg is the parsed source of an endpoint package.
+type pkg struct {
+	consts   map[string]string        // EndPtCreateOffer -> CreateOffer
+	types    map[string]*ast.TypeSpec // CreateOffer -> type spec
+	docs     map[string]string        // CreateOffer -> doc comment
+	funcs    map[string]*ast.FuncDecl // ValidateAmount, CreateOffer.Validate
+	facts    map[string]*facts
+	registry []registration
+}
+
+// registration is the registration of an endpoint constructor.
+type registration struct {
+	Const       string
+	Constructor string
+	Stream      bool
+}
+
+// facts are the facts collected from a function and the functions it calls.
+// Parameterized fields are response fields whose key and value are
+// parameters of the function, resolved at call sites.
+type facts struct {
+	Params  []Param
+	Errors  []Error
+	Fields  []Field
+	Status  int
+	PFields [][2]int
+}
+
+// ParseEndpoints analyzes the sources of the endpoint package in dir and
+// returns the description of its registered endpoints by name. Response
+// fields whose content cannot be inferred are matched by name against the
+// provided resources (`book` holding an `OrderBookResource`).
+func ParseEndpoints(
+	dir string,
+	resources []string,
+) (map[string]Endpoint, error) {
+	p := &pkg{
+		consts: map[string]string{},
+		types:  map[string]*ast.TypeSpec{},
+		docs:   map[string]string{},
+		funcs:  map[string]*ast.FuncDecl{},
+		facts:  map[string]*facts{},
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") || generated(file) {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		p.add(f)
+	}
+
+	endpoints := map[string]Endpoint{}
+	for _, r := range p.registry {
+		name, ok := p.consts[r.Const]
+		if !ok {
+			return nil, errors.Trace(errors.Newf(
+				"Unknown endpoint constant: %s", r.Const))
+		}
+		typ := p.constructed(r.Constructor)
+
+		f := &facts{}
+		roots := []string{r.Constructor, typ + ".Validate", typ + ".Execute"}
+		if r.Stream {
+			roots = []string{r.Constructor, typ + ".Validate", typ + ".Stream"}
+		}
+		for _, root := range roots {
+			if fn, ok := p.funcs[root]; ok {
+				f.merge(p.analyze(root, fn), nil)
+			}
+		}
+		if fn, ok := p.funcs[roots[2]]; ok {
+			f.Status = p.analyze(roots[2], fn).Status
+		}
+
+		e := Endpoint{
+			Name:        name,
+			Description: p.docs[typ],
+			Status:      f.Status,
+			Stream:      r.Stream,
+			Params:      f.Params,
+			Errors:      f.Errors,
+			Fields:      f.Fields,
+		}
+		sort.Slice(e.Errors, func(i, j int) bool {
+			if e.Errors[i].Status != e.Errors[j].Status {
+				return e.Errors[i].Status < e.Errors[j].Status
+			}
+			return e.Errors[i].Code < e.Errors[j].Code
+		})
+		for i, field := range e.Fields {
+			if field.Resource == "" && field.Type == "" {
+				e.Fields[i] = resourceField(field.Key, resources)
+			}
+		}
+		sort.Slice(e.Fields, func(i, j int) bool {
+			return e.Fields[i].Key < e.Fields[j].Key
+		})
+		endpoints[name] = e
+	}
+
+	return endpoints, nil
+}
+
+// resourceField returns the response field holding the resource (or list of
+// resources for plural keys) whose name matches the key, an untyped field if
+// none does.
+func resourceField(
+	key string,
+	resources []string,
+) Field {
+	name := strings.Replace(key, "_", "", -1)
+	candidates := []Field{{Key: name}}
+	if strings.HasSuffix(name, "ies") {
+		candidates = append(candidates,
+			Field{Key: strings.TrimSuffix(name, "ies") + "y", List: true})
+	} else if strings.HasSuffix(name, "s") {
+		candidates = append(candidates,
+			Field{Key: strings.TrimSuffix(name, "s"), List: true})
+	}
+
+	// Exact matches are preferred to suffix matches.
+	for _, exact := range []bool{true, false} {
+		for _, c := range candidates {
+			for _, r := range resources {
+				n := strings.ToLower(strings.TrimSuffix(r, "Resource"))
+				if n == c.Key || (!exact && strings.HasSuffix(n, c.Key)) {
+					return Field{Key: key, Resource: r, List: c.List}
+				}
+			}
+		}
+	}
+	return Field{Key: key}
+}
+
+// generatedRegexp matches the comment identifying generated files.
+var generatedRegexp = regexp.MustCompile(
+	`(?m)^// Code generated .* DO NOT EDIT\.$`)
+
+// generated returns whether a source file is generated.
+func generated(
+	file string,
+) bool {
+	f, err := os.Open(file)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	head := make([]byte, 256)
+	n, _ := f.Read(head)
+	return generatedRegexp.Match(head[:n])
+}
+
+// add adds the declarations of a file to the package.
+func (p *pkg) add(
+	f *ast.File,
+) {
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.ValueSpec:
+					if d.Tok != token.CONST || len(s.Values) != len(s.Names) {
+						continue
+					}
+					for i, n := range s.Names {
+						if v, ok := stringLit(s.Values[i]); ok &&
+							strings.HasPrefix(n.Name, "EndPt") {
+							p.consts[n.Name] = v
+						}
+					}
+				case *ast.TypeSpec:
+					p.types[s.Name.Name] = s
+					doc := s.Doc
+					if doc == nil {
+						doc = d.Doc
+					}
+					p.docs[s.Name.Name] = strings.Join(
+						strings.Fields(doc.Text()), " ")
+				}
+			}
+		case *ast.FuncDecl:
+			name := d.Name.Name
+			if d.Recv != nil && len(d.Recv.List) == 1 {
+				name = typeName(d.Recv.List[0].Type) + "." + name
+			}
+			p.funcs[name] = d
+			if d.Name.Name == "init" && d.Recv == nil {
+				p.register(d)
+			}
+		}
+	}
+}
+
+// register records the endpoint registrations of an init function.
+func (p *pkg) register(
+	fn *ast.FuncDecl,
+) {
+	for _, stmt := range fn.Body.List {
+		assign, ok := stmt.(*ast.AssignStmt)
+		if !ok || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+			continue
+		}
+		index, ok := assign.Lhs[0].(*ast.IndexExpr)
+		if !ok {
+			continue
+		}
+		registrar, ok := index.X.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		constant, ok := index.Index.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		constructor, ok := assign.Rhs[0].(*ast.Ident)
+		if !ok {
+			continue
+		}
+		p.registry = append(p.registry, registration{
+			Const:       constant.Name,
+			Constructor: constructor.Name,
+			Stream:      registrar.Name == "streamRegistrar",
+		})
+	}
+}
+
+// constructed returns the name of the endpoint type constructed by a
+// constructor (`return &CreateOffer{...}`), defaulting to the name of the
+// constructor without its `New` prefix.
+func (p *pkg) constructed(
+	constructor string,
+) string {
+	typ := strings.TrimPrefix(constructor, "New")
+	fn, ok := p.funcs[constructor]
+	if !ok || fn.Body == nil {
+		return typ
+	}
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		ret, ok := n.(*ast.ReturnStmt)
+		if !ok || len(ret.Results) == 0 {
+			return true
+		}
+		if u, ok := ret.Results[0].(*ast.UnaryExpr); ok {
+			if lit, ok := u.X.(*ast.CompositeLit); ok {
+				typ = typeName(lit.Type)
+			}
+		}
+		return true
+	})
+	return typ
+}
+
+// merge merges the facts of a callee into f, resolving its parameterized
+// fields with the arguments of the call if any.
+func (f *facts) merge(
+	callee *facts,
+	resolve func(key int, value int) *Field,
+) {
+	for _, p := range callee.Params {
+		found := false
+		for _, q := range f.Params {
+			found = found || q == p
+		}
+		if !found {
+			f.Params = append(f.Params, p)
+		}
+	}
+	for _, e := range callee.Errors {
+		found := false
+		for _, g := range f.Errors {
+			found = found || g == e
+		}
+		if !found {
+			f.Errors = append(f.Errors, e)
+		}
+	}
+	for _, field := range callee.Fields {
+		f.addField(field)
+	}
+	if f.Status == 0 {
+		f.Status = callee.Status
+	}
+	if resolve != nil {
+		for _, pf := range callee.PFields {
+			if field := resolve(pf[0], pf[1]); field != nil {
+				f.addField(*field)
+			}
+		}
+	}
+}
+
+// addField adds a response field if not already present.
+func (f *facts) addField(
+	field Field,
+) {
+	for _, g := range f.Fields {
+		if g.Key == field.Key {
+			return
+		}
+	}
+	f.Fields = append(f.Fields, field)
+}
+
+// analyze collects the facts of a function and of the functions it calls.
+func (p *pkg) analyze(
+	name string,
+	fn *ast.FuncDecl,
+) *facts {
+	if f, ok := p.facts[name]; ok {
+		return f
+	}
+	f := &facts{}
+	// Registered before the body is walked to terminate on recursion.
+	p.facts[name] = f
+	if fn.Body == nil {
+		return f
+	}
+
+	params := funcParams(fn)
+	recv, recvType := "", ""
+	if fn.Recv != nil && len(fn.Recv.List) == 1 {
+		recvType = typeName(fn.Recv.List[0].Type)
+		if len(fn.Recv.List[0].Names) == 1 {
+			recv = fn.Recv.List[0].Names[0].Name
+		}
+	}
+
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.IndexExpr:
+			// r.PostForm["path[]"]
+			if s, ok := stringLit(x.Index); ok {
+				switch selectorName(x.X) {
+				case "PostForm", "Form":
+					f.merge(&facts{Params: []Param{{s, "form"}}}, nil)
+				}
+			}
+
+		case *ast.CompositeLit:
+			// svc.Resp{"offer": ...}
+			if selectorName(x.Type) != "Resp" {
+				return true
+			}
+			for _, elt := range x.Elts {
+				kv, ok := elt.(*ast.KeyValueExpr)
+				if !ok {
+					continue
+				}
+				if key, ok := stringLit(kv.Key); ok {
+					field := p.infer(fn, params, kv.Value, 0)
+					field.Key = key
+					f.addField(field)
+				} else if k, ok := kv.Key.(*ast.Ident); ok {
+					if ki, ok := params[k.Name]; ok {
+						vi := -1
+						if v := unwrap(kv.Value); v != nil {
+							if i, ok := params[v.Name]; ok {
+								vi = i
+							}
+						}
+						f.PFields = append(f.PFields, [2]int{ki, vi})
+					}
+				}
+			}
+
+		case *ast.CallExpr:
+			p.call(f, fn, params, recv, recvType, x)
+		}
+		return true
+	})
+
+	return f
+}
+
+// call collects the facts of a call expression.
+func (p *pkg) call(
+	f *facts,
+	fn *ast.FuncDecl,
+	params map[string]int,
+	recv string,
+	recvType string,
+	call *ast.CallExpr,
+) {
+	name := selectorName(call.Fun)
+	arg := func(i int) (string, bool) {
+		if i >= len(call.Args) {
+			return "", false
+		}
+		return stringLit(call.Args[i])
+	}
+
+	switch name {
+	case "PostFormValue", "FormValue":
+		if s, ok := arg(0); ok {
+			f.merge(&facts{Params: []Param{{s, "form"}}}, nil)
+		}
+		return
+	case "Param":
+		if s, ok := arg(1); ok {
+			f.merge(&facts{Params: []Param{{s, "path"}}}, nil)
+		}
+		return
+	case "Get":
+		s, ok := arg(0)
+		if !ok {
+			return
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return
+		}
+		switch x := sel.X.(type) {
+		case *ast.CallExpr:
+			// r.URL.Query().Get("limit")
+			if selectorName(x.Fun) == "Query" {
+				f.merge(&facts{Params: []Param{{s, "query"}}}, nil)
+			}
+		case *ast.SelectorExpr:
+			// r.Header.Get("Last-Event-ID")
+			if x.Sel.Name == "Header" {
+				f.merge(&facts{Params: []Param{{s, "header"}}}, nil)
+			}
+		case *ast.Ident:
+			// query.Get("code") with query a url.Values parameter.
+			if i, ok := params[x.Name]; ok &&
+				paramTypeName(fn.Type.Params, i) == "url.Values" {
+				f.merge(&facts{Params: []Param{{s, "query"}}}, nil)
+			}
+			// params.Get("pair") with params the body parameters.
+			if bodyParams(fn, params, x) {
+				f.merge(&facts{Params: []Param{{s, "form"}}}, nil)
+			}
+		}
+		return
+	case "List":
+		// params.List("path") with params the body parameters.
+		s, ok := arg(0)
+		if !ok {
+			return
+		}
+		if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+			if x, ok := sel.X.(*ast.Ident); ok && bodyParams(fn, params, x) {
+				f.merge(&facts{Params: []Param{{s + "[]", "form"}}}, nil)
+			}
+		}
+		return
+	case "Int":
+		// ptr.Int(http.StatusCreated)
+		if len(call.Args) != 1 || f.Status != 0 {
+			return
+		}
+		expr := call.Args[0]
+		if id, ok := expr.(*ast.Ident); ok {
+			if d, ok := declaration(fn, id).(*ast.AssignStmt); ok &&
+				len(d.Rhs) == 1 {
+				expr = d.Rhs[0]
+			}
+		}
+		if status, ok := statusValue(expr); ok {
+			f.Status = status
+		}
+		return
+	case "NewUserError", "NewUserErrorf":
+		if len(call.Args) < 3 {
+			return
+		}
+		status, ok := statusValue(call.Args[1])
+		code, cok := stringLit(call.Args[2])
+		if ok && cok {
+			f.merge(&facts{Errors: []Error{{status, code}}}, nil)
+		}
+		return
+	}
+
+	callee := p.resolve(call.Fun, recv, recvType)
+	if callee == "" {
+		return
+	}
+	c := p.analyze(callee, p.funcs[callee])
+	f.merge(c, func(key int, value int) *Field {
+		if key >= len(call.Args) {
+			return nil
+		}
+		k, ok := stringLit(call.Args[key])
+		if !ok {
+			return nil
+		}
+		field := Field{}
+		if value >= 0 && value < len(call.Args) {
+			field = p.infer(fn, params, call.Args[value], 0)
+		}
+		field.Key = k
+		return &field
+	})
+}
+
+// resolve returns the name of the function or method of the package called,
+// empty if the callee is not part of the package.
+func (p *pkg) resolve(
+	fun ast.Expr,
+	recv string,
+	recvType string,
+) string {
+	switch x := fun.(type) {
+	case *ast.Ident:
+		if _, ok := p.funcs[x.Name]; ok {
+			return x.Name
+		}
+	case *ast.SelectorExpr:
+		typ := ""
+		switch y := x.X.(type) {
+		case *ast.Ident:
+			// e.status(ctx)
+			if y.Name == recv {
+				typ = recvType
+			}
+		case *ast.SelectorExpr:
+			// e.ListEndpoint.Validate(r)
+			if z, ok := y.X.(*ast.Ident); ok && z.Name == recv {
+				typ = p.fieldType(recvType, y.Sel.Name)
+			}
+		}
+		if typ != "" {
+			return p.method(typ, x.Sel.Name)
+		}
+	}
+	return ""
+}
+
+// method returns the name of the method of a type, including the methods
+// promoted from its embedded fields.
+func (p *pkg) method(
+	typ string,
+	name string,
+) string {
+	if _, ok := p.funcs[typ+"."+name]; ok {
+		return typ + "." + name
+	}
+	spec, ok := p.types[typ]
+	if !ok {
+		return ""
+	}
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return ""
+	}
+	for _, field := range st.Fields.List {
+		if len(field.Names) == 0 {
+			if m := p.method(typeName(field.Type), name); m != "" {
+				return m
+			}
+		}
+	}
+	return ""
+}
+
+// fieldType returns the name of the type of a field of a struct type.
+func (p *pkg) fieldType(
+	typ string,
+	field string,
+) string {
+	spec, ok := p.types[typ]
+	if !ok {
+		return ""
+	}
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return ""
+	}
+	for _, f := range st.Fields.List {
+		if len(f.Names) == 0 && typeName(f.Type) == field {
+			return field
+		}
+		for _, n := range f.Names {
+			if n.Name == field {
+				return typeName(f.Type)
+			}
+		}
+	}
+	return ""
+}
+
+// resourceConstructorRegexp matches the functions constructing resources.
+var resourceConstructorRegexp = regexp.MustCompile(`^New(\w+Resource)$`)
+
+// infer infers the content of a response field from its value expression.
+func (p *pkg) infer(
+	fn *ast.FuncDecl,
+	params map[string]int,
+	expr ast.Expr,
+	depth int,
+) Field {
+	if depth > 4 {
+		return Field{}
+	}
+	switch x := expr.(type) {
+	case *ast.CallExpr:
+		name := selectorName(x.Fun)
+		if m := resourceConstructorRegexp.FindStringSubmatch(name); m != nil {
+			return Field{Resource: m[1]}
+		}
+		if name == "JSONPtr" && len(x.Args) == 1 {
+			return p.infer(fn, params, x.Args[0], depth+1)
+		}
+	case *ast.UnaryExpr:
+		return p.infer(fn, params, x.X, depth+1)
+	case *ast.CompositeLit:
+		return typeField(x.Type)
+	case *ast.Ident:
+		if x.Name == "true" || x.Name == "false" {
+			return Field{Type: "boolean"}
+		}
+		if i, ok := params[x.Name]; ok {
+			return typeField(paramType(fn.Type.Params, i))
+		}
+		switch d := declaration(fn, x).(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range d.Lhs {
+				if id, ok := lhs.(*ast.Ident); ok && id.Name == x.Name &&
+					len(d.Rhs) == len(d.Lhs) {
+					return p.infer(fn, params, d.Rhs[i], depth+1)
+				}
+			}
+		case *ast.ValueSpec:
+			if d.Type != nil {
+				return typeField(d.Type)
+			}
+		}
+	}
+	return Field{}
+}
+
+// declaration returns the declaration (`:=` assignment or var spec) of the
+// variable used by ident within a function: the last one preceding it in a
+// block enclosing it.
+func declaration(
+	fn *ast.FuncDecl,
+	ident *ast.Ident,
+) ast.Node {
+	var decl ast.Node
+	stack := []ast.Node{}
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		stack = append(stack, n)
+		if n.Pos() >= ident.Pos() {
+			return true
+		}
+
+		declares := false
+		switch s := n.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range s.Lhs {
+				if id, ok := lhs.(*ast.Ident); ok &&
+					id.Name == ident.Name && s.Tok == token.DEFINE {
+					declares = true
+				}
+			}
+		case *ast.ValueSpec:
+			for _, id := range s.Names {
+				declares = declares || id.Name == ident.Name
+			}
+		}
+		if !declares {
+			return true
+		}
+
+		// The innermost scope of the declaration must enclose the ident.
+		for i := len(stack) - 2; i >= 0; i-- {
+			switch b := stack[i].(type) {
+			case *ast.BlockStmt, *ast.CaseClause, *ast.CommClause:
+				if b.End() > ident.Pos() {
+					decl = n
+				}
+				return true
+			}
+		}
+		return true
+	})
+	return decl
+}
+
+// bodyParams returns whether ident refers to the body parameters of a
+// request: a `svc.Params` parameter or a variable declared from the result of
+// `svc.ParseParams`.
+func bodyParams(
+	fn *ast.FuncDecl,
+	params map[string]int,
+	ident *ast.Ident,
+) bool {
+	if i, ok := params[ident.Name]; ok {
+		return paramTypeName(fn.Type.Params, i) == "svc.Params"
+	}
+	d, ok := declaration(fn, ident).(*ast.AssignStmt)
+	if !ok || len(d.Rhs) != 1 {
+		return false
+	}
+	call, ok := d.Rhs[0].(*ast.CallExpr)
+	return ok && selectorName(call.Fun) == "ParseParams"
+}
+
+// typeField returns the content of a response field of the provided type.
+func typeField(
+	expr ast.Expr,
+) Field {
+	switch x := expr.(type) {
+	case *ast.StarExpr:
+		return typeField(x.X)
+	case *ast.ArrayType:
+		f := typeField(x.Elt)
+		f.List = true
+		return f
+	case *ast.Ident:
+		switch x.Name {
+		case "bool":
+			return Field{Type: "boolean"}
+		case "string":
+			return Field{Type: "string"}
+		case "int", "int8", "int16", "int32", "int64",
+			"uint", "uint8", "uint16", "uint32", "uint64":
+			return Field{Type: "integer"}
+		}
+		if strings.HasSuffix(x.Name, "Resource") {
+			return Field{Resource: x.Name}
+		}
+	case *ast.SelectorExpr:
+		if strings.HasSuffix(x.Sel.Name, "Resource") {
+			return Field{Resource: x.Sel.Name}
+		}
+	}
+	return Field{}
+}
+
+// funcParams returns the index of the parameters of a function by name.
+func funcParams(
+	fn *ast.FuncDecl,
+) map[string]int {
+	params := map[string]int{}
+	i := 0
+	for _, field := range fn.Type.Params.List {
+		if len(field.Names) == 0 {
+			i++
+		}
+		for _, n := range field.Names {
+			params[n.Name] = i
+			i++
+		}
+	}
+	return params
+}
+
+// paramType returns the type expression of the i-th parameter of a function.
+func paramType(
+	params *ast.FieldList,
+	i int,
+) ast.Expr {
+	j := 0
+	for _, field := range params.List {
+		n := len(field.Names)
+		if n == 0 {
+			n = 1
+		}
+		if i < j+n {
+			return field.Type
+		}
+		j += n
+	}
+	return nil
+}
+
+// paramTypeName returns the qualified type name of the i-th parameter of a
+// function (`url.Values`).
+func paramTypeName(
+	params *ast.FieldList,
+	i int,
+) string {
+	switch x := paramType(params, i).(type) {
+	case *ast.Ident:
+		return x.Name
+	case *ast.SelectorExpr:
+		if pkg, ok := x.X.(*ast.Ident); ok {
+			return pkg.Name + "." + x.Sel.Name
+		}
+	}
+	return ""
+}
+
+// unwrap returns the identifier wrapped by a value expression, as in
+// `format.JSONPtr(list)`.
+func unwrap(
+	expr ast.Expr,
+) *ast.Ident {
+	switch x := expr.(type) {
+	case *ast.Ident:
+		return x
+	case *ast.CallExpr:
+		if len(x.Args) == 1 {
+			return unwrap(x.Args[0])
+		}
+	}
+	return nil
+}
+
+// statusValue returns the HTTP status of an expression, either an integer
+// literal or a net/http status constant.
+func statusValue(
+	expr ast.Expr,
+) (int, bool) {
+	switch x := expr.(type) {
+	case *ast.BasicLit:
+		if x.Kind == token.INT {
+			s, err := strconv.Atoi(x.Value)
+			return s, err == nil
+		}
+	case *ast.SelectorExpr:
+		s, ok := httpStatuses[x.Sel.Name]
+		return s, ok
+	}
+	return 0, false
+}
+
+// selectorName returns the name of an identifier or the selected name of a
+// selector expression.
+func selectorName(
+	expr ast.Expr,
+) string {
+	switch x := expr.(type) {
+	case *ast.Ident:
+		return x.Name
+	case *ast.SelectorExpr:
+		return x.Sel.Name
+	}
+	return ""
+}
+
+// typeName returns the name of a (pointer to a) named type.
+func typeName(
+	expr ast.Expr,
+) string {
+	switch x := expr.(type) {
+	case *ast.StarExpr:
+		return typeName(x.X)
+	case *ast.Ident:
+		return x.Name
+	case *ast.SelectorExpr:
+		return x.Sel.Name
+	}
+	return ""
+}
+
+// stringLit returns the value of a string literal.
+func stringLit(
+	expr ast.Expr,
+) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"path"
+	"sort"
+
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/openapi"
+)
+
+var rteFlag string
+var endFlag string
+var proFlag string
+var impFlag string
+var pkgFlag string
+var outFlag string
+
+func init() {
+	flag.StringVar(&rteFlag, "routes",
+		"", "The controller source file binding the API routes")
+	flag.StringVar(&endFlag, "endpoints",
+		".", "The directory of the endpoint package, default: .")
+	flag.StringVar(&proFlag, "protocol",
+		"", "The source file declaring the resources")
+	flag.StringVar(&impFlag, "protocol_import",
+		"", "The import path of the package declaring the resources")
+	flag.StringVar(&pkgFlag, "package",
+		"endpoint", "The package of the generated file, default: endpoint")
+	flag.StringVar(&outFlag, "out",
+		"openapi_gen.go", "The generated file, default: openapi_gen.go")
+
+	flag.Parse()
+}
+
+func main() {
+	if err := generate(); err != nil {
+		log.Fatal(errors.Details(err))
+	}
+}
+
+// generate generates the file declaring the spec of the API.
+func generate() error {
+	routes, err := openapi.ParseRoutes(rteFlag)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	resources, err := openapi.ParseResources(proFlag)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	endpoints, err := openapi.ParseEndpoints(endFlag, resources)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	protocol := path.Base(impFlag)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by openapi-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkgFlag)
+	fmt.Fprintf(&b, "import (\n%q\n%q\n)\n\n",
+		"github.com/spolu/settle/lib/openapi", impFlag)
+	fmt.Fprintf(&b, "// openAPISpec is the spec of the API extracted from "+
+		"the routes and endpoints.\n")
+	fmt.Fprintf(&b, "var openAPISpec = openapi.Spec{\n")
+
+	fmt.Fprintf(&b, "Routes: []openapi.Route{\n")
+	for _, r := range routes {
+		fmt.Fprintf(&b, "{Method: %q, Path: %q, Endpoint: %q},\n",
+			r.Method, r.Path, r.Endpoint)
+	}
+	fmt.Fprintf(&b, "},\n")
+
+	names := []string{}
+	for n := range endpoints {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(&b, "Endpoints: map[string]openapi.Endpoint{\n")
+	for _, n := range names {
+		e := endpoints[n]
+		fmt.Fprintf(&b, "%q: openapi.Endpoint{\n", n)
+		fmt.Fprintf(&b, "Name: %q,\n", e.Name)
+		if e.Description != "" {
+			fmt.Fprintf(&b, "Description: %q,\n", e.Description)
+		}
+		if e.Status != 0 {
+			fmt.Fprintf(&b, "Status: %d,\n", e.Status)
+		}
+		if e.Stream {
+			fmt.Fprintf(&b, "Stream: true,\n")
+		}
+		if len(e.Params) > 0 {
+			fmt.Fprintf(&b, "Params: []openapi.Param{\n")
+			for _, p := range e.Params {
+				fmt.Fprintf(&b, "{Name: %q, In: %q},\n", p.Name, p.In)
+			}
+			fmt.Fprintf(&b, "},\n")
+		}
+		if len(e.Errors) > 0 {
+			fmt.Fprintf(&b, "Errors: []openapi.Error{\n")
+			for _, e := range e.Errors {
+				fmt.Fprintf(&b, "{Status: %d, Code: %q},\n", e.Status, e.Code)
+			}
+			fmt.Fprintf(&b, "},\n")
+		}
+		if len(e.Fields) > 0 {
+			fmt.Fprintf(&b, "Fields: []openapi.Field{\n")
+			for _, f := range e.Fields {
+				fmt.Fprintf(&b, "{Key: %q", f.Key)
+				if f.Resource != "" {
+					fmt.Fprintf(&b, ", Resource: %q", f.Resource)
+				}
+				if f.List {
+					fmt.Fprintf(&b, ", List: true")
+				}
+				if f.Type != "" {
+					fmt.Fprintf(&b, ", Type: %q", f.Type)
+				}
+				fmt.Fprintf(&b, "},\n")
+			}
+			fmt.Fprintf(&b, "},\n")
+		}
+		fmt.Fprintf(&b, "},\n")
+	}
+	fmt.Fprintf(&b, "},\n")
+
+	fmt.Fprintf(&b, "Resources: []interface{}{\n")
+	for _, r := range resources {
+		fmt.Fprintf(&b, "%s.%s{},\n", protocol, r)
+	}
+	fmt.Fprintf(&b, "},\n")
+	fmt.Fprintf(&b, "}\n")
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err := ioutil.WriteFile(outFlag, src, 0644); err != nil {
+		return errors.Trace(err)
+	}
+
+	return nil
+}
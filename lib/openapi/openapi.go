@@ -0,0 +1,389 @@
+// Package openapi constructs OpenAPI 3 documents describing the APIs of the
+// services. The routes and endpoints of a service are extracted from its
+// source code (see ParseEndpoints) by the openapi-gen command into a Spec from which
+// the document is built at runtime.
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spolu/settle/lib/svc"
+)
+
+// Version is the version of the OpenAPI specification documents conform to.
+const Version = "3.0.3"
+
+// Route is a route bound by the controller of a service to an endpoint.
+type Route struct {
+	Method   string
+	Path     string
+	Endpoint string
+}
+
+// Param is a request parameter read by an endpoint. In is one of `path`,
+// `query`, `header` or `form`.
+type Param struct {
+	Name string
+	In   string
+}
+
+// Error is a user error returned by an endpoint.
+type Error struct {
+	Status int
+	Code   string
+}
+
+// Field is a top-level field of the response of an endpoint, holding either
+// a resource (or a list of them) or a value of a primitive Type.
+type Field struct {
+	Key      string
+	Resource string
+	List     bool
+	Type     string
+}
+
+// Endpoint describes an endpoint. Stream endpoints respond with a stream of
+// server-sent events.
+type Endpoint struct {
+	Name        string
+	Description string
+	Status      int
+	Stream      bool
+	Params      []Param
+	Errors      []Error
+	Fields      []Field
+}
+
+// Spec is the specification of the API of a service from which the OpenAPI
+// document is built. Resources are values of the resource types returned by
+// the endpoints.
+type Spec struct {
+	Routes    []Route
+	Endpoints map[string]Endpoint
+	Resources []interface{}
+}
+
+// Document is an OpenAPI document.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Servers    []Server            `json:"servers,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info is the metadata of an API.
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// Server is a server serving an API.
+type Server struct {
+	URL string `json:"url"`
+}
+
+// PathItem maps the lower-cased methods of a path to their operation.
+type PathItem map[string]*Operation
+
+// Operation describes an operation on a path.
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Description string                `json:"description,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter describes a path, query or header parameter.
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody describes the body of a request.
+type RequestBody struct {
+	Content map[string]MediaType `json:"content"`
+}
+
+// Response describes a response.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType describes the content of a body for a media type.
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Components holds the schemas and security schemes referenced by the
+// operations.
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme describes an authentication scheme.
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme"`
+}
+
+// Schema is a JSON schema.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Operation returns the operation of the document for the method and route
+// path (`/offers/:offer`), nil if there is none.
+func (d *Document) Operation(
+	method string,
+	path string,
+) *Operation {
+	item, ok := d.Paths[PathFor(path)]
+	if !ok {
+		return nil
+	}
+	return item[strings.ToLower(method)]
+}
+
+// routeParamRegexp matches the named parameters of route paths.
+var routeParamRegexp = regexp.MustCompile(":([a-zA-Z0-9_]+)")
+
+// PathFor converts a route path (`/offers/:offer`) to an OpenAPI path
+// (`/offers/{offer}`).
+func PathFor(
+	path string,
+) string {
+	return routeParamRegexp.ReplaceAllString(path, "{$1}")
+}
+
+// Document builds the OpenAPI document of the spec. If public is not nil, it
+// is called with the method and an example path of each route to determine
+// whether it can be accessed without authentication; other operations
+// require basic or bearer authentication.
+func (s *Spec) Document(
+	info Info,
+	servers []Server,
+	public func(method string, path string) bool,
+) *Document {
+	d := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Servers: servers,
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			Schemas: map[string]*Schema{
+				"Error": errorSchema(),
+			},
+		},
+	}
+
+	for _, r := range s.Resources {
+		schemaFor(d.Components.Schemas, r)
+	}
+
+	if public != nil {
+		d.Components.SecuritySchemes = map[string]SecurityScheme{
+			"basic":  SecurityScheme{Type: "http", Scheme: "basic"},
+			"bearer": SecurityScheme{Type: "http", Scheme: "bearer"},
+		}
+	}
+
+	for _, r := range s.Routes {
+		e, ok := s.Endpoints[r.Endpoint]
+		if !ok {
+			e = Endpoint{Name: r.Endpoint}
+		}
+		op := operation(r, e)
+		if public != nil &&
+			!public(r.Method, routeParamRegexp.ReplaceAllString(r.Path, "$1")) {
+			op.Security = []map[string][]string{
+				map[string][]string{"basic": []string{}},
+				map[string][]string{"bearer": []string{}},
+			}
+		}
+
+		path := PathFor(r.Path)
+		if _, ok := d.Paths[path]; !ok {
+			d.Paths[path] = PathItem{}
+		}
+		d.Paths[path][strings.ToLower(r.Method)] = op
+	}
+
+	return d
+}
+
+// operation builds the operation of a route.
+func operation(
+	r Route,
+	e Endpoint,
+) *Operation {
+	op := &Operation{
+		OperationID: e.Name,
+		Description: e.Description,
+		Responses:   map[string]Response{},
+	}
+
+	// Path parameters are taken from the route as some endpoints share their
+	// implementation between routes.
+	for _, m := range routeParamRegexp.FindAllStringSubmatch(r.Path, -1) {
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:     m[1],
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+
+	// Body parameters are accepted form encoded or as a JSON object in which
+	// list parameters (`path[]`) are arrays (`path`).
+	form := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	json := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for _, p := range e.Params {
+		switch p.In {
+		case "form":
+			form.Properties[p.Name] = paramSchema(p.Name)
+			json.Properties[strings.TrimSuffix(p.Name, "[]")] =
+				paramSchema(p.Name)
+		case "query", "header":
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:   p.Name,
+				In:     p.In,
+				Schema: paramSchema(p.Name),
+			})
+		}
+	}
+	if len(form.Properties) > 0 {
+		op.RequestBody = &RequestBody{
+			Content: map[string]MediaType{
+				svc.ContentTypeForm: MediaType{Schema: form},
+				svc.ContentTypeJSON: MediaType{Schema: json},
+			},
+		}
+	}
+
+	status := e.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if e.Stream {
+		op.Responses[fmt.Sprintf("%d", status)] = Response{
+			Description: http.StatusText(status),
+			Content: map[string]MediaType{
+				"text/event-stream": MediaType{
+					Schema: &Schema{Type: "string"},
+				},
+			},
+		}
+	} else {
+		body := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		for _, f := range e.Fields {
+			body.Properties[f.Key] = fieldSchema(f)
+		}
+		op.Responses[fmt.Sprintf("%d", status)] = Response{
+			Description: http.StatusText(status),
+			Content: map[string]MediaType{
+				"application/json": MediaType{Schema: body},
+			},
+		}
+	}
+
+	codes := map[int][]string{}
+	for _, err := range e.Errors {
+		codes[err.Status] = append(codes[err.Status], err.Code)
+	}
+	for status, c := range codes {
+		sort.Strings(c)
+		op.Responses[fmt.Sprintf("%d", status)] = Response{
+			Description: http.StatusText(status),
+			Content: map[string]MediaType{
+				"application/json": MediaType{
+					Schema: &Schema{
+						Type: "object",
+						Properties: map[string]*Schema{
+							"error": &Schema{
+								Type: "object",
+								Properties: map[string]*Schema{
+									"code": &Schema{
+										Type: "string",
+										Enum: c,
+									},
+									"message": &Schema{Type: "string"},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	op.Responses["default"] = Response{
+		Description: "Error",
+		Content: map[string]MediaType{
+			"application/json": MediaType{
+				Schema: &Schema{Ref: "#/components/schemas/Error"},
+			},
+		},
+	}
+
+	return op
+}
+
+// paramSchema returns the schema of a parameter, parameters whose name ends
+// with `[]` being repeated.
+func paramSchema(
+	name string,
+) *Schema {
+	if strings.HasSuffix(name, "[]") {
+		return &Schema{Type: "array", Items: &Schema{Type: "string"}}
+	}
+	return &Schema{Type: "string"}
+}
+
+// fieldSchema returns the schema of a response field.
+func fieldSchema(
+	f Field,
+) *Schema {
+	s := &Schema{}
+	if f.Resource != "" {
+		s.Ref = "#/components/schemas/" + f.Resource
+	} else {
+		s.Type = f.Type
+	}
+	if f.List {
+		return &Schema{Type: "array", Items: s}
+	}
+	return s
+}
+
+// errorSchema returns the schema of error responses.
+func errorSchema() *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"error": &Schema{
+				Type: "object",
+				Properties: map[string]*Schema{
+					"code":    &Schema{Type: "string"},
+					"message": &Schema{Type: "string"},
+				},
+			},
+		},
+	}
+}
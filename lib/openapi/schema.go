@@ -0,0 +1,99 @@
+package openapi
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strings"
+)
+
+var (
+	bigIntType     = reflect.TypeOf(big.Int{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaFor registers the schema of the type of v (and of the named struct
+// types it references) in schemas, returning a reference to it.
+func schemaFor(
+	schemas map[string]*Schema,
+	v interface{},
+) *Schema {
+	return typeSchema(schemas, reflect.TypeOf(v))
+}
+
+// typeSchema returns the schema of a type, registering the named struct types
+// in schemas and referencing them.
+func typeSchema(
+	schemas map[string]*Schema,
+	t reflect.Type,
+) *Schema {
+	switch t {
+	case bigIntType:
+		return &Schema{Type: "integer"}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		s := typeSchema(schemas, t.Elem())
+		if s.Ref != "" {
+			return s
+		}
+		s.Nullable = true
+		return s
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: typeSchema(schemas, t.Elem())}
+	case reflect.Map:
+		return &Schema{
+			Type:                 "object",
+			AdditionalProperties: typeSchema(schemas, t.Elem()),
+		}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return structSchema(schemas, t)
+		}
+		if _, ok := schemas[t.Name()]; !ok {
+			// Registered before its fields are walked for recursive types.
+			schemas[t.Name()] = &Schema{}
+			*schemas[t.Name()] = *structSchema(schemas, t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + t.Name()}
+	}
+
+	return &Schema{}
+}
+
+// structSchema returns the schema of the JSON encoding of a struct type.
+func structSchema(
+	schemas map[string]*Schema,
+	t reflect.Type,
+) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := f.Name
+		if tag := f.Tag.Get("json"); tag != "" {
+			name = strings.Split(tag, ",")[0]
+		}
+		if name == "-" {
+			continue
+		}
+		s.Properties[name] = typeSchema(schemas, f.Type)
+	}
+	return s
+}
@@ -0,0 +1,143 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/spolu/settle/lib/errors"
+)
+
+// Proxies is a list of trusted proxies, whose X-Forwarded-For header is
+// honored to determine the IP of the clients they forward requests for.
+type Proxies []*net.IPNet
+
+// ParseProxies parses a comma separated list of IPs or CIDRs
+// (`10.0.0.1,192.168.0.0/16`).
+func ParseProxies(
+	spec string,
+) (Proxies, error) {
+	proxies := Proxies{}
+	for _, s := range strings.Split(spec, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if !strings.Contains(s, "/") {
+			if ip := net.ParseIP(s); ip != nil && ip.To4() != nil {
+				s += "/32"
+			} else {
+				s += "/128"
+			}
+		}
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, errors.Trace(errors.Newf(
+				"Invalid trusted proxy: %s", s))
+		}
+		proxies = append(proxies, n)
+	}
+	return proxies, nil
+}
+
+// trusted returns whether ip is the IP of a trusted proxy.
+func (p Proxies) trusted(
+	ip string,
+) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, n := range p {
+		if n.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the IP of the client that emitted the request. If the
+// request comes from a trusted proxy, the X-Forwarded-For header is walked
+// from the right, skipping trusted proxies, up to the first untrusted IP (the
+// IPs on its left are provided by the client and can't be trusted).
+func (p Proxies) ClientIP(
+	r *http.Request,
+) string {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		ip = host
+	}
+	if !p.trusted(ip) {
+		return ip
+	}
+
+	forwarded := []string{}
+	for _, h := range r.Header["X-Forwarded-For"] {
+		forwarded = append(forwarded, strings.Split(h, ",")...)
+	}
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		f := strings.TrimSpace(forwarded[i])
+		if net.ParseIP(f) == nil {
+			break
+		}
+		ip = f
+		if !p.trusted(f) {
+			break
+		}
+	}
+	return ip
+}
+
+// ContextKey is the type of the key used with context to carry the client IP.
+type ContextKey string
+
+const (
+	// clientIPKey the context.Context key to store the client IP.
+	clientIPKey ContextKey = "ratelimit.client_ip"
+)
+
+// WithClientIP stores the client IP in the context.
+func WithClientIP(
+	ctx context.Context,
+	ip string,
+) context.Context {
+	return context.WithValue(ctx, clientIPKey, ip)
+}
+
+// IP returns the IP of the client that emitted the request, as resolved by
+// the client IP middleware if mounted, the remote address of the request
+// otherwise.
+func IP(
+	r *http.Request,
+) string {
+	if ip, ok := r.Context().Value(clientIPKey).(string); ok {
+		return ip
+	}
+	return Proxies{}.ClientIP(r)
+}
+
+type clientIPMiddleware struct {
+	http.Handler
+	proxies Proxies
+}
+
+// ServeHTTP handles incoming HTTP requests and resolves their client IP.
+func (m clientIPMiddleware) ServeHTTP(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	m.Handler.ServeHTTP(w, r.WithContext(
+		WithClientIP(r.Context(), m.proxies.ClientIP(r))))
+}
+
+// ClientIPMiddleware returns a middleware that resolves the IP of the client
+// of requests, honoring the X-Forwarded-For header of the trusted proxies
+// provided. It must be mounted before the rate limiting middlewares.
+func ClientIPMiddleware(
+	proxies Proxies,
+) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return clientIPMiddleware{h, proxies}
+	}
+}
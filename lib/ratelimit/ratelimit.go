@@ -0,0 +1,197 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/logging"
+	"github.com/spolu/settle/lib/respond"
+)
+
+// Limit is a token bucket limit. Requests consume one token each, tokens are
+// refilled at Rate per second up to Burst.
+type Limit struct {
+	Rate  float64
+	Burst float64
+}
+
+// ParseLimits parses a comma separated list of per route group limits of the
+// form `group=rate:burst` (`public=10:20,propagation=5:10`), overriding the
+// provided defaults. A rate of 0 disables the limit of a group.
+func ParseLimits(
+	spec string,
+	defaults map[string]Limit,
+) (map[string]Limit, error) {
+	limits := map[string]Limit{}
+	for g, l := range defaults {
+		limits[g] = l
+	}
+
+	for _, s := range strings.Split(spec, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		ss := strings.Split(s, "=")
+		if len(ss) != 2 {
+			return nil, errors.Trace(errors.Newf("Invalid rate limit: %s", s))
+		}
+		if _, ok := defaults[ss[0]]; !ok {
+			return nil, errors.Trace(errors.Newf(
+				"Unknown rate limit group: %s", ss[0]))
+		}
+		rb := strings.Split(ss[1], ":")
+		rate, err := strconv.ParseFloat(rb[0], 64)
+		if err != nil || rate < 0 {
+			return nil, errors.Trace(errors.Newf("Invalid rate limit: %s", s))
+		}
+		burst := math.Max(rate, 1)
+		if len(rb) == 2 {
+			burst, err = strconv.ParseFloat(rb[1], 64)
+			if err != nil || burst < 1 {
+				return nil, errors.Trace(errors.Newf(
+					"Invalid rate limit: %s", s))
+			}
+		} else if len(rb) > 2 {
+			return nil, errors.Trace(errors.Newf("Invalid rate limit: %s", s))
+		}
+		limits[ss[0]] = Limit{rate, burst}
+	}
+
+	return limits, nil
+}
+
+// bucket is the token bucket of a key within a route group.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// sweepInterval is the interval at which idle buckets are evicted.
+const sweepInterval = time.Minute
+
+// Limiter rate limits requests using one token bucket per route group and
+// key. Group returns the route group of a request (requests of groups with no
+// limit are not limited) and Key the key by which it is limited (client IP,
+// user, ...).
+type Limiter struct {
+	Limits map[string]Limit
+	Group  func(r *http.Request) string
+	Key    func(r *http.Request) string
+
+	buckets map[string]*bucket
+	swept   time.Time
+	mutex   *sync.Mutex
+}
+
+// NewLimiter constructs and initializes a Limiter.
+func NewLimiter(
+	limits map[string]Limit,
+	group func(r *http.Request) string,
+	key func(r *http.Request) string,
+) *Limiter {
+	return &Limiter{
+		Limits:  limits,
+		Group:   group,
+		Key:     key,
+		buckets: map[string]*bucket{},
+		swept:   time.Now(),
+		mutex:   &sync.Mutex{},
+	}
+}
+
+// take attempts to consume a token from the bucket of the provided group and
+// key. It returns the time to wait for a token to be available if none is.
+func (l *Limiter) take(
+	group string,
+	key string,
+	limit Limit,
+) (bool, time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+
+	// Evict the buckets that are full again.
+	if now.Sub(l.swept) > sweepInterval {
+		for k, b := range l.buckets {
+			g := strings.SplitN(k, " ", 2)[0]
+			if lim, ok := l.Limits[g]; !ok || lim.Rate == 0 ||
+				b.tokens+now.Sub(b.updated).Seconds()*lim.Rate >= lim.Burst {
+				delete(l.buckets, k)
+			}
+		}
+		l.swept = now
+	}
+
+	b, ok := l.buckets[group+" "+key]
+	if !ok {
+		b = &bucket{limit.Burst, now}
+		l.buckets[group+" "+key] = b
+	}
+
+	b.tokens = math.Min(limit.Burst,
+		b.tokens+now.Sub(b.updated).Seconds()*limit.Rate)
+	b.updated = now
+
+	if b.tokens < 1 {
+		return false, time.Duration(
+			(1 - b.tokens) / limit.Rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+type middleware struct {
+	http.Handler
+	limiter *Limiter
+}
+
+// ServeHTTP handles incoming HTTP requests and rejects them with a 429 if
+// their route group and key exceeded its limit.
+func (m middleware) ServeHTTP(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	ctx := r.Context()
+
+	group := m.limiter.Group(r)
+	limit, ok := m.limiter.Limits[group]
+	if !ok || limit.Rate == 0 {
+		m.Handler.ServeHTTP(w, r)
+		return
+	}
+
+	key := m.limiter.Key(r)
+	if allowed, wait := m.limiter.take(group, key, limit); !allowed {
+		retry := int64(math.Ceil(wait.Seconds()))
+		logging.Info(ctx, "Rate limited",
+			"group", group, "key", key, "retry_after", retry)
+
+		w.Header().Set("Retry-After", fmt.Sprintf("%d", retry))
+		respond.Error(ctx, w, errors.Trace(errors.NewUserErrorf(nil,
+			429, "rate_limited",
+			"You have exceeded the rate limit for this endpoint. Please "+
+				"retry in %d seconds.", retry,
+		)))
+		return
+	}
+
+	m.Handler.ServeHTTP(w, r)
+}
+
+// Middleware returns a middleware that rate limits requests using the
+// provided limiter.
+func Middleware(
+	limiter *Limiter,
+) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return middleware{h, limiter}
+	}
+}
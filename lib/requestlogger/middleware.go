@@ -6,9 +6,16 @@ import (
 	"time"
 
 	"github.com/spolu/settle/lib/logging"
+	"github.com/spolu/settle/lib/token"
 	"github.com/zenazn/goji/web/mutil"
 )
 
+const (
+	// HeaderRequestID carries the ID assigned to a request, returned with its
+	// response.
+	HeaderRequestID string = "Request-Id"
+)
+
 func init() {
 	log.SetFlags(0)
 }
@@ -17,23 +24,30 @@ type middleware struct {
 	http.Handler
 }
 
-// ServeHTTP handles incoming HTTP requests and attempt to log them.
+// ServeHTTP handles incoming HTTP requests and attempt to log them. Each
+// request is assigned an ID carried as `request_id` field by the entries
+// logged with its context.
 func (m middleware) ServeHTTP(
 	w http.ResponseWriter,
 	r *http.Request,
 ) {
-	ctx := r.Context()
+	id := token.New("request")
+	ctx := logging.With(r.Context(), "request_id", id)
+	r = r.WithContext(ctx)
+
 	start := time.Now()
 	url := *r.URL
+	w.Header().Set(HeaderRequestID, id)
 	wp := mutil.WrapWriter(w)
 
-	logging.Logf(ctx, "HTTP Request: method=%q path=%q remote=%q",
-		r.Method, url.RequestURI(), r.RemoteAddr)
+	logging.Info(ctx, "HTTP request",
+		"method", r.Method, "path", url.RequestURI(), "remote", r.RemoteAddr)
 
 	defer func() {
 		wp.WriteHeader(http.StatusOK)
-		logging.Logf(ctx, "HTTP Response: status=%d latency=%d",
-			wp.Status(), time.Now().Sub(start)/time.Millisecond)
+		logging.Info(ctx, "HTTP response",
+			"status", wp.Status(),
+			"latency_ms", int64(time.Now().Sub(start)/time.Millisecond))
 	}()
 
 	m.Handler.ServeHTTP(wp, r)
@@ -69,19 +69,29 @@ func Error(
 		for _, line := range errors.ErrorStack(e.Cause()) {
 			logging.Logf(ctx, "    %s", line)
 		}
-
-		resp := errorResponse(ctx, errors.Build(e))
-		Respond(ctx, w, e.Status(), nil, resp)
 	} else {
 		logging.Logf(ctx,
 			"Unexpected error: error=%q", err.Error())
 		for _, line := range errors.ErrorStack(err) {
 			logging.Logf(ctx, "  %s", line)
 		}
+	}
+
+	status, resp := ErrorResponse(ctx, err)
+	Respond(ctx, w, status, nil, resp)
+}
 
-		resp := errorResponse(ctx, errors.Build(panicError()))
-		Respond(ctx, w, http.StatusInternalServerError, nil, resp)
+// ErrorResponse returns the status and content of the response to an error as
+// served by Error, without responding.
+func ErrorResponse(
+	ctx context.Context,
+	err error,
+) (int, svc.Resp) {
+	if e := errors.ExtractUserError(err); e != nil {
+		return e.Status(), errorResponse(ctx, errors.Build(e))
 	}
+	return http.StatusInternalServerError,
+		errorResponse(ctx, errors.Build(panicError()))
 }
 
 // Respond is used to generate a response manually setting the status code,
@@ -0,0 +1,245 @@
+package svc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/spolu/settle/lib/errors"
+)
+
+const (
+	// ContentTypeForm is the content type of form encoded request bodies.
+	ContentTypeForm string = "application/x-www-form-urlencoded"
+	// ContentTypeMultipart is the content type of multipart request bodies.
+	ContentTypeMultipart string = "multipart/form-data"
+	// ContentTypeJSON is the content type of JSON request bodies.
+	ContentTypeJSON string = "application/json"
+
+	// MaxBodySize is the maximum size of the request bodies parsed.
+	MaxBodySize = 10 << 20 // 10 MB
+	// maxMemory is the maximum memory used to parse multipart request bodies.
+	maxMemory = 32 << 20 // 32 MB
+)
+
+// Params are the parameters carried by the body of a request. List parameters
+// are stored under their key suffixed by `[]`, following the form convention
+// (`path[]=a&path[]=b`). In JSON bodies they are represented by arrays
+// (`{"path": ["a", "b"]}`).
+type Params url.Values
+
+// ParseParams parses the parameters of the body of a request, form encoded,
+// multipart or JSON depending on its content type. The parameters are stored
+// in the `PostForm` (and merged in the `Form`) of the request so that parsing
+// them again is a no-op.
+func ParseParams(
+	r *http.Request,
+) (Params, error) {
+	if r.PostForm != nil {
+		return Params(r.PostForm), nil
+	}
+
+	ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch ct {
+	case ContentTypeJSON:
+		if err := parseJSON(r); err != nil {
+			return nil, errors.Trace(err)
+		}
+	case ContentTypeMultipart:
+		err := r.ParseMultipartForm(maxMemory)
+		if err != nil {
+			return nil, errors.Trace(errors.NewUserErrorf(err,
+				400, "body_invalid",
+				"The multipart body of your request is malformed: %s.",
+				err.Error(),
+			))
+		}
+	default:
+		err := r.ParseForm()
+		if err != nil {
+			return nil, errors.Trace(errors.NewUserErrorf(err,
+				400, "body_invalid",
+				"The form body of your request is malformed: %s.",
+				err.Error(),
+			))
+		}
+	}
+
+	return Params(r.PostForm), nil
+}
+
+// parseJSON parses the JSON object body of a request into its `PostForm`.
+// Values must be strings, numbers, booleans or arrays of those.
+func parseJSON(
+	r *http.Request,
+) error {
+	values := url.Values{}
+
+	switch r.Method {
+	case "POST", "PUT", "PATCH":
+		if r.Body == nil {
+			break
+		}
+		b, err := ioutil.ReadAll(io.LimitReader(r.Body, MaxBodySize+1))
+		if err != nil {
+			return errors.Trace(err) // 500
+		}
+		if len(b) > MaxBodySize {
+			return errors.Trace(errors.NewUserErrorf(nil,
+				413, "body_too_large",
+				"The body of your request is too large, the maximum size "+
+					"is %d bytes.",
+				MaxBodySize,
+			))
+		}
+		if len(bytes.TrimSpace(b)) > 0 {
+			values, err = decodeJSON(b)
+			if err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+
+	query, err := url.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		return errors.Trace(errors.NewUserErrorf(err,
+			400, "query_invalid",
+			"The query string of your request is malformed: %s.",
+			err.Error(),
+		))
+	}
+
+	r.PostForm = values
+	r.Form = url.Values{}
+	for k, v := range values {
+		r.Form[k] = append(r.Form[k], v...)
+	}
+	for k, v := range query {
+		r.Form[k] = append(r.Form[k], v...)
+	}
+
+	return nil
+}
+
+// decodeJSON decodes a JSON object into form values.
+func decodeJSON(
+	b []byte,
+) (url.Values, error) {
+	var object map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&object); err != nil {
+		return nil, errors.Trace(errors.NewUserErrorf(err,
+			400, "body_invalid",
+			"The JSON body of your request is malformed: %s. It must be a "+
+				"JSON object.",
+			err.Error(),
+		))
+	}
+	if d.More() {
+		return nil, errors.Trace(errors.NewUserErrorf(nil,
+			400, "body_invalid",
+			"The JSON body of your request is malformed: unexpected data "+
+				"after the top-level object.",
+		))
+	}
+
+	values := url.Values{}
+	for k, v := range object {
+		switch v := v.(type) {
+		case nil:
+			continue
+		case []interface{}:
+			key := strings.TrimSuffix(k, "[]") + "[]"
+			values[key] = []string{}
+			for _, e := range v {
+				s, ok := scalar(e)
+				if !ok {
+					return nil, errors.Trace(errJSONValue(k))
+				}
+				values[key] = append(values[key], s)
+			}
+		default:
+			s, ok := scalar(v)
+			if !ok {
+				return nil, errors.Trace(errJSONValue(k))
+			}
+			values.Set(k, s)
+		}
+	}
+
+	return values, nil
+}
+
+// scalar returns the form representation of a scalar JSON value.
+func scalar(
+	v interface{},
+) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return fmt.Sprintf("%t", v), true
+	}
+	return "", false
+}
+
+// errJSONValue returns the error for a JSON body value of unsupported type.
+func errJSONValue(
+	key string,
+) error {
+	return errors.NewUserErrorf(nil,
+		400, "body_invalid",
+		"The JSON body of your request is invalid: the value of `%s` must "+
+			"be a string, a number, a boolean or an array of those.",
+		key,
+	)
+}
+
+// Get returns the value of a parameter, empty if it is not present.
+func (p Params) Get(
+	key string,
+) string {
+	return url.Values(p).Get(key)
+}
+
+// List returns the values of a list parameter (`key[]` in forms).
+func (p Params) List(
+	key string,
+) []string {
+	return p[key+"[]"]
+}
+
+// Encode encodes the parameters as a request body of the provided content
+// type (ContentTypeForm or ContentTypeJSON).
+func (p Params) Encode(
+	contentType string,
+) ([]byte, error) {
+	switch contentType {
+	case ContentTypeForm:
+		return []byte(url.Values(p).Encode()), nil
+	case ContentTypeJSON:
+		object := map[string]interface{}{}
+		for k, v := range p {
+			if strings.HasSuffix(k, "[]") {
+				object[strings.TrimSuffix(k, "[]")] = v
+			} else if len(v) > 0 {
+				object[k] = v[0]
+			}
+		}
+		b, err := json.Marshal(object)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return b, nil
+	}
+	return nil, errors.Newf("Unsupported content type: %s", contentType)
+}
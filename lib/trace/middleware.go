@@ -0,0 +1,68 @@
+package trace
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/spolu/settle/lib/logging"
+	"github.com/zenazn/goji/web/mutil"
+)
+
+type middleware struct {
+	http.Handler
+	*Recorder
+	linked func(*http.Request) bool
+}
+
+// ServeHTTP handles incoming HTTP requests, injects the recorder in their
+// context and records a span for each of them. The span is a child of the
+// span propagated in the HeaderTraceParent header only if the request is
+// accepted by linked, so that arbitrary clients can't add spans to the traces
+// of others. Entries logged with the request context carry its trace as
+// `trace_id` field.
+func (m middleware) ServeHTTP(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	ctx := With(r.Context(), m.Recorder)
+	if m.linked(r) {
+		sc, ok := ParseSpanContext(r.Header.Get(HeaderTraceParent))
+		if ok {
+			ctx = WithSpanContext(ctx, sc)
+		}
+	}
+
+	ctx, span := Start(ctx, r.Method+" "+r.URL.Path)
+	ctx = logging.With(ctx, "trace_id", span.TraceID)
+	wp := mutil.WrapWriter(w)
+
+	defer func() {
+		span.Set("status", wp.Status())
+		span.Finish(nil)
+	}()
+
+	m.Handler.ServeHTTP(wp, r.WithContext(ctx))
+}
+
+// Middleware returns a middleware that injects the specified recorder in
+// requests and records their spans, linked to the trace propagated by the
+// requests accepted by linked.
+func Middleware(
+	recorder *Recorder,
+	linked func(*http.Request) bool,
+) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return middleware{h, recorder, linked}
+	}
+}
+
+// Inject sets the HeaderTraceParent header of an outgoing request to the span
+// context of the context, if any.
+func Inject(
+	ctx context.Context,
+	req *http.Request,
+) {
+	if sc, ok := GetSpanContext(ctx); ok {
+		req.Header.Set(HeaderTraceParent, sc.String())
+	}
+}
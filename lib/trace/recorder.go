@@ -0,0 +1,146 @@
+package trace
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+const (
+	// recorderKey the context.Context key to store the recorder.
+	recorderKey ContextKey = "trace.recorder"
+)
+
+// Recorder keeps the spans finished in the process in memory, indexed by
+// trace. Traces are linked to keys (generally object IDs) so that the spans
+// concerning an object can be retrieved. Only the spans of the most recent
+// traces and the most recently linked keys are kept, and only the first spans
+// of each trace up to a maximum.
+type Recorder struct {
+	max      int
+	maxSpans int
+
+	mutex  sync.Mutex
+	traces map[string][]SpanData
+	order  []string
+	links  map[string][]string
+	keys   []string
+}
+
+// New constructs a new recorder keeping the spans of the provided maximum
+// number of traces (and as many linked keys), up to maxSpans spans per trace.
+func New(
+	max int,
+	maxSpans int,
+) *Recorder {
+	return &Recorder{
+		max:      max,
+		maxSpans: maxSpans,
+		traces:   map[string][]SpanData{},
+		order:    []string{},
+		links:    map[string][]string{},
+		keys:     []string{},
+	}
+}
+
+// record stores a finished span. It is a no-op on a nil recorder or if the
+// trace of the span already has the maximum number of spans.
+func (r *Recorder) record(
+	s SpanData,
+) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.traces[s.TraceID]; !ok {
+		r.order = append(r.order, s.TraceID)
+		if len(r.order) > r.max {
+			delete(r.traces, r.order[0])
+			r.order = r.order[1:]
+		}
+	}
+
+	if len(r.traces[s.TraceID]) >= r.maxSpans {
+		return
+	}
+	r.traces[s.TraceID] = append(r.traces[s.TraceID], s)
+}
+
+// Link links a trace to a key. It is a no-op on a nil recorder.
+func (r *Recorder) Link(
+	key string,
+	traceID string,
+) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	traces, ok := r.links[key]
+	if !ok {
+		r.keys = append(r.keys, key)
+		if len(r.keys) > r.max {
+			delete(r.links, r.keys[0])
+			r.keys = r.keys[1:]
+		}
+	}
+	for _, t := range traces {
+		if t == traceID {
+			return
+		}
+	}
+	r.links[key] = append(traces, traceID)
+}
+
+// Spans returns the spans of the traces linked to a key, ordered by start
+// time.
+func (r *Recorder) Spans(
+	key string,
+) []SpanData {
+	spans := []SpanData{}
+	if r == nil {
+		return spans
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, t := range r.links[key] {
+		spans = append(spans, r.traces[t]...)
+	}
+	sort.SliceStable(spans, func(i, j int) bool {
+		return spans[i].Start.Before(spans[j].Start)
+	})
+
+	return spans
+}
+
+// With stores the recorder in the provided context.
+func With(
+	ctx context.Context,
+	recorder *Recorder,
+) context.Context {
+	return context.WithValue(ctx, recorderKey, recorder)
+}
+
+// Get returns the recorder currently stored in the context (nil if none).
+func Get(
+	ctx context.Context,
+) *Recorder {
+	recorder, _ := ctx.Value(recorderKey).(*Recorder)
+	return recorder
+}
+
+// Link links the trace of the span of the context to a key, so that its
+// spans are returned by Spans for that key. It is a no-op if the context
+// carries no span.
+func Link(
+	ctx context.Context,
+	key string,
+) {
+	if sc, ok := GetSpanContext(ctx); ok {
+		Get(ctx).Link(key, sc.TraceID)
+	}
+}
@@ -0,0 +1,178 @@
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+)
+
+const (
+	// HeaderTraceParent carries the trace context of a request, following the
+	// W3C Trace Context format (`00-<trace_id>-<parent_id>-01`).
+	HeaderTraceParent string = "Traceparent"
+)
+
+// traceParentRegexp is used to validate and parse trace parent headers.
+var traceParentRegexp = regexp.MustCompile(
+	"^00-([0-9a-f]{32})-([0-9a-f]{16})-[0-9a-f]{2}$",
+)
+
+// SpanContext identifies a span within a trace. It is what is propagated
+// across requests and tasks so that the spans they record join the trace.
+type SpanContext struct {
+	TraceID string
+	SpanID  string
+}
+
+// String returns the trace parent representation of the span context.
+func (c SpanContext) String() string {
+	return fmt.Sprintf("00-%s-%s-01", c.TraceID, c.SpanID)
+}
+
+// ParseSpanContext parses a span context from its trace parent
+// representation. It returns false if the representation is invalid.
+func ParseSpanContext(
+	s string,
+) (SpanContext, bool) {
+	m := traceParentRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return SpanContext{}, false
+	}
+	return SpanContext{TraceID: m[1], SpanID: m[2]}, true
+}
+
+// randomID returns a random hex encoded ID of n bytes.
+func randomID(
+	n int,
+) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// SpanData is the data of a span, as recorded once finished.
+type SpanData struct {
+	TraceID  string
+	ID       string
+	ParentID *string
+
+	Name       string
+	Start      time.Time
+	End        time.Time
+	Error      *string
+	Attributes map[string]string
+}
+
+// Span represents a timed operation within a trace. Spans are recorded by the
+// recorder of the context they were started with once finished.
+type Span struct {
+	SpanData
+
+	mutex    sync.Mutex
+	recorder *Recorder
+	ended    bool
+}
+
+// Context returns the span context of the span.
+func (s *Span) Context() SpanContext {
+	return SpanContext{TraceID: s.TraceID, SpanID: s.ID}
+}
+
+// Set sets attributes (alternating keys and values) on the span.
+func (s *Span) Set(
+	kv ...interface{},
+) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i := 0; i+1 < len(kv); i += 2 {
+		s.Attributes[fmt.Sprint(kv[i])] = fmt.Sprint(kv[i+1])
+	}
+}
+
+// Finish ends the span, marking it as failed if err is not nil, and records
+// it. Finishing a span more than once is a no-op.
+func (s *Span) Finish(
+	err error,
+) {
+	s.mutex.Lock()
+	if s.ended {
+		s.mutex.Unlock()
+		return
+	}
+	s.ended = true
+	s.End = time.Now().UTC()
+	if err != nil {
+		e := err.Error()
+		s.Error = &e
+	}
+	data := s.SpanData
+	data.Attributes = map[string]string{}
+	for k, v := range s.Attributes {
+		data.Attributes[k] = v
+	}
+	s.mutex.Unlock()
+
+	s.recorder.record(data)
+}
+
+// ContextKey is the type of the key used with context to carry contextual
+// trace state.
+type ContextKey string
+
+const (
+	// spanKey the context.Context key to store the current span context.
+	spanKey ContextKey = "trace.span"
+)
+
+// WithSpanContext stores the span context in the provided context. Spans
+// started with the returned context are its children.
+func WithSpanContext(
+	ctx context.Context,
+	sc SpanContext,
+) context.Context {
+	return context.WithValue(ctx, spanKey, sc)
+}
+
+// GetSpanContext returns the span context currently stored in the context.
+// It returns false if there is none.
+func GetSpanContext(
+	ctx context.Context,
+) (SpanContext, bool) {
+	sc, ok := ctx.Value(spanKey).(SpanContext)
+	return sc, ok
+}
+
+// Start starts a new span with the provided attributes (alternating keys and
+// values), child of the span of the context if any or starting a new trace
+// otherwise. The returned context carries the new span. The span must be
+// finished with Finish.
+func Start(
+	ctx context.Context,
+	name string,
+	kv ...interface{},
+) (context.Context, *Span) {
+	s := &Span{
+		SpanData: SpanData{
+			TraceID:    randomID(16),
+			ID:         randomID(8),
+			Name:       name,
+			Start:      time.Now().UTC(),
+			Attributes: map[string]string{},
+		},
+		recorder: Get(ctx),
+	}
+	if parent, ok := GetSpanContext(ctx); ok {
+		s.TraceID = parent.TraceID
+		s.ParentID = &parent.SpanID
+	}
+	s.Set(kv...)
+
+	return WithSpanContext(ctx, s.Context()), s
+}
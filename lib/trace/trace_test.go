@@ -0,0 +1,82 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSpanContext(t *testing.T) {
+	sc := SpanContext{
+		TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:  "00f067aa0ba902b7",
+	}
+	parsed, ok := ParseSpanContext(sc.String())
+	if !ok || parsed != sc {
+		t.Errorf("Expected %v to parse, got %v", sc, parsed)
+	}
+
+	for _, s := range []string{
+		"",
+		"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+	} {
+		if _, ok := ParseSpanContext(s); ok {
+			t.Errorf("Expected %q to be invalid", s)
+		}
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	r := New(2, 3)
+	ctx := With(context.Background(), r)
+
+	ctx, parent := Start(ctx, "parent")
+	_, child := Start(ctx, "child", "hop", 1)
+	child.Finish(errors.New("failed"))
+	parent.Finish(nil)
+	parent.Finish(nil)
+	Link(ctx, "transaction")
+
+	spans := r.Spans("transaction")
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+	if spans[0].Name != "parent" || spans[1].Name != "child" {
+		t.Errorf("Expected spans ordered by start, got %s, %s",
+			spans[0].Name, spans[1].Name)
+	}
+	if spans[1].TraceID != spans[0].TraceID ||
+		spans[1].ParentID == nil || *spans[1].ParentID != spans[0].ID {
+		t.Errorf("Expected child span of parent span")
+	}
+	if spans[1].Error == nil || spans[1].Attributes["hop"] != "1" {
+		t.Errorf("Expected failed child span with hop attribute")
+	}
+
+	// Only the spans of the 2 most recent traces are kept.
+	for i := 0; i < 2; i++ {
+		_, s := Start(With(context.Background(), r), "other")
+		s.Finish(nil)
+	}
+	if len(r.Spans("transaction")) != 0 {
+		t.Errorf("Expected the spans of the oldest trace to be dropped")
+	}
+}
+
+func TestRecorderMaxSpans(t *testing.T) {
+	r := New(2, 3)
+	ctx, parent := Start(With(context.Background(), r), "parent")
+	for i := 0; i < 5; i++ {
+		_, s := Start(ctx, "child")
+		s.Finish(nil)
+	}
+	parent.Finish(nil)
+	Link(ctx, "transaction")
+
+	// Only the first 3 spans of the trace are kept.
+	if spans := r.Spans("transaction"); len(spans) != 3 {
+		t.Errorf("Expected 3 spans, got %d", len(spans))
+	}
+}
@@ -7,29 +7,67 @@ import (
 	"time"
 
 	"goji.io"
+	"goji.io/pat"
 
 	"github.com/facebookgo/grace/gracehttp"
 	"github.com/spolu/settle/lib/db"
 	"github.com/spolu/settle/lib/env"
 	"github.com/spolu/settle/lib/errors"
 	"github.com/spolu/settle/lib/logging"
+	"github.com/spolu/settle/lib/metrics"
+	"github.com/spolu/settle/lib/ratelimit"
 	"github.com/spolu/settle/lib/recoverer"
 	"github.com/spolu/settle/lib/requestlogger"
+	"github.com/spolu/settle/lib/trace"
 	"github.com/spolu/settle/mint"
 	"github.com/spolu/settle/mint/async"
+	"github.com/spolu/settle/mint/async/task"
+	"github.com/spolu/settle/mint/endpoint"
 	"github.com/spolu/settle/mint/lib/authentication"
+	"github.com/spolu/settle/mint/lib/hub"
+	"github.com/spolu/settle/mint/lib/signature"
+	"github.com/spolu/settle/mint/lib/version"
+	"github.com/spolu/settle/mint/model"
 
 	// force initialization of schemas
 	_ "github.com/spolu/settle/mint/model/schemas"
 )
 
+// Config holds the values of the flags the mint is run with. Empty values
+// fall back to the defaults of the corresponding environment configuration.
+type Config struct {
+	Env string
+	DSN string
+
+	Host       string
+	Port       string
+	APIBaseURL string
+	CrtFile    string
+
+	OfferSuspectFailures string
+
+	ClientTimeoutMs string
+	ClientRetries   string
+	ClientEncoding  string
+	BreakerFailures string
+	BreakerProbeMs  string
+
+	PeerAllowList    string
+	PeerDenyList     string
+	APIHostAllowList string
+
+	RateLimits     string
+	TrustedProxies string
+	MetricsAddr    string
+
+	HealthMaxPendingTasks string
+	HealthMaxFailedTasks  string
+}
+
 // BackgroundContextFromFlags initializes a background context fully loaded
 // with everything that could be extracted from the flags.
 func BackgroundContextFromFlags(
-	envFlag string,
-	dsnFlag string,
-	hstFlag string,
-	prtFlag string,
+	cfg Config,
 ) (context.Context, error) {
 	ctx := context.Background()
 
@@ -37,21 +75,37 @@ func BackgroundContextFromFlags(
 		Environment: env.QA,
 		Config:      map[env.ConfigKey]string{},
 	}
-	if envFlag == "production" || envFlag == "prod" {
+	if cfg.Env == "production" || cfg.Env == "prod" {
 		mintEnv.Environment = env.Production
 	}
-	mintEnv.Config[mint.EnvCfgHost] = hstFlag
+	mintEnv.Config[mint.EnvCfgHost] = cfg.Host
 
 	port := fmt.Sprintf("%d", mint.DefaultPort[mintEnv.Environment])
-	if prtFlag != "" {
-		port = prtFlag
+	if cfg.Port != "" {
+		port = cfg.Port
 	}
 	mintEnv.Config[mint.EnvCfgPort] = port
+	mintEnv.Config[mint.EnvCfgAPIBaseURL] = cfg.APIBaseURL
+	mintEnv.Config[mint.EnvCfgCrtFile] = cfg.CrtFile
+	mintEnv.Config[mint.EnvCfgOfferSuspectFailures] = cfg.OfferSuspectFailures
+	mintEnv.Config[mint.EnvCfgClientTimeoutMs] = cfg.ClientTimeoutMs
+	mintEnv.Config[mint.EnvCfgClientRetries] = cfg.ClientRetries
+	mintEnv.Config[mint.EnvCfgClientEncoding] = cfg.ClientEncoding
+	mintEnv.Config[mint.EnvCfgBreakerFailures] = cfg.BreakerFailures
+	mintEnv.Config[mint.EnvCfgBreakerProbeMs] = cfg.BreakerProbeMs
+	mintEnv.Config[mint.EnvCfgPeerAllowList] = cfg.PeerAllowList
+	mintEnv.Config[mint.EnvCfgPeerDenyList] = cfg.PeerDenyList
+	mintEnv.Config[mint.EnvCfgAPIHostAllowList] = cfg.APIHostAllowList
+	mintEnv.Config[mint.EnvCfgRateLimits] = cfg.RateLimits
+	mintEnv.Config[mint.EnvCfgTrustedProxies] = cfg.TrustedProxies
+	mintEnv.Config[mint.EnvCfgMetricsAddr] = cfg.MetricsAddr
+	mintEnv.Config[mint.EnvCfgHealthMaxPendingTasks] = cfg.HealthMaxPendingTasks
+	mintEnv.Config[mint.EnvCfgHealthMaxFailedTasks] = cfg.HealthMaxFailedTasks
 
 	ctx = env.With(ctx, &mintEnv)
 
 	mintDB, err := db.NewDBForDSN(ctx,
-		dsnFlag,
+		cfg.DSN,
 		fmt.Sprintf("sqlite3://~/.mint/mint-%s.db",
 			env.Get(ctx).Environment))
 	if err != nil {
@@ -63,6 +117,22 @@ func BackgroundContextFromFlags(
 	}
 	ctx = db.WithDB(ctx, "mint", mintDB)
 
+	key, err := model.LoadOrCreateIdentityKey(ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	mintEnv.Config[mint.EnvCfgIdentityKey] = key.PrivateKey
+
+	hosts, err := model.LoadPeerHosts(ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	mint.AddKnownPeers(ctx, hosts...)
+
+	ctx = hub.With(ctx, hub.New())
+	ctx = metrics.With(ctx, metrics.NewRegistry())
+	ctx = trace.With(ctx, trace.New(mint.TraceMaxTraces, mint.TraceMaxSpans))
+
 	a, err := async.NewAsync(ctx)
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -87,19 +157,62 @@ func Build(
 		))
 	}
 
+	clientIP, err := NewClientIPMiddleware(ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ipLimiter, limiter, err := NewRateLimiters(ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
 	mux := goji.NewMux()
 	mux.Use(requestlogger.Middleware)
+	mux.Use(trace.Middleware(trace.Get(ctx), signature.Signed))
+	mux.Use(metrics.Middleware(metrics.Get(ctx)))
+	mux.Use(endpoint.Instrument)
 	mux.Use(recoverer.Middleware)
+	// Requests are first rate limited per client IP so that failed
+	// authentications and forged signatures are limited as well.
+	mux.Use(clientIP)
+	mux.Use(ratelimit.Middleware(ipLimiter))
 	mux.Use(db.Middleware(db.GetDBMap(ctx)))
 	mux.Use(env.Middleware(env.Get(ctx)))
 	mux.Use(async.Middleware(async.Get(ctx)))
+	mux.Use(hub.Middleware(hub.Get(ctx)))
+	mux.Use(version.Middleware)
+	mux.Use(signature.Middleware)
 	mux.Use(authentication.Middleware)
+	// Authenticated and signed requests are then rate limited per user or
+	// peer mint.
+	mux.Use(ratelimit.Middleware(limiter))
 
-	logging.Logf(ctx, "Initializing: environment=%s host=%s port=%s",
-		env.Get(ctx).Environment, mint.GetHost(ctx), mint.GetPort(ctx))
+	logging.Info(ctx, "Initializing",
+		"environment", env.Get(ctx).Environment, "host", mint.GetHost(ctx),
+		"port", mint.GetPort(ctx))
 
 	(&Controller{}).Bind(mux)
 
+	// Metrics are served on the API if no separate address is configured.
+	if mint.GetMetricsAddr(ctx) == "" {
+		mux.Handle(pat.Get("/metrics"), metrics.Handler(metrics.Get(ctx)))
+	}
+
+	// Schedule the periodic reconciliation of propagated balances,
+	// synchronization of propagated offers and recording of peer contacts.
+	err = task.EnsureReconcileBalances(ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	err = task.EnsureSyncOffers(ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	err = task.EnsureRecordPeers(ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
 	// Start on async worker.
 	go func() {
 		async.Get(ctx).Run()
@@ -114,16 +227,34 @@ func Serve(
 	mux *goji.Mux,
 ) error {
 
+	// The write timeout accounts for requests waiting on transactions.
 	s := &http.Server{
-		Addr:         fmt.Sprintf(":%s", mint.GetPort(ctx)),
-		ReadTimeout:  5 * time.Second,
-		WriteTimeout: 5 * time.Second,
-		Handler:      mux,
+		Addr:        fmt.Sprintf(":%s", mint.GetPort(ctx)),
+		ReadTimeout: 5 * time.Second,
+		WriteTimeout: 5*time.Second +
+			time.Duration(mint.TransactionWaitMaxMs)*time.Millisecond,
+		Handler: mux,
+	}
+
+	servers := []*http.Server{s}
+
+	// Metrics are served on a separate address, if configured, so that they
+	// are not exposed publicly.
+	if addr := mint.GetMetricsAddr(ctx); addr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler(metrics.Get(ctx)))
+		servers = append(servers, &http.Server{
+			Addr:         addr,
+			ReadTimeout:  5 * time.Second,
+			WriteTimeout: 5 * time.Second,
+			Handler:      mux,
+		})
+		logging.Info(ctx, "Serving metrics", "addr", addr)
 	}
 
-	logging.Logf(ctx, "Listening: port=%s", mint.GetPort(ctx))
+	logging.Info(ctx, "Listening", "port", mint.GetPort(ctx))
 
-	err := gracehttp.Serve(s)
+	err := gracehttp.Serve(servers...)
 	if err != nil {
 		return errors.Trace(err)
 	}
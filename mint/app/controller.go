@@ -18,12 +18,30 @@ func (c *Controller) Bind(
 	mux.HandleFunc(pat.Post("/offers"), endpoint.HandlerFor(endpoint.EndPtCreateOffer))
 	mux.HandleFunc(pat.Post("/transactions"), endpoint.HandlerFor(endpoint.EndPtCreateTransaction))
 	mux.HandleFunc(pat.Post("/offers/:offer/close"), endpoint.HandlerFor(endpoint.EndPtCloseOffer))
+	mux.HandleFunc(pat.Post("/keys"), endpoint.HandlerFor(endpoint.EndPtCreateAPIKey))
+	mux.HandleFunc(pat.Post("/keys/:key/revoke"), endpoint.HandlerFor(endpoint.EndPtRevokeAPIKey))
 
 	mux.HandleFunc(pat.Get("/assets"), endpoint.HandlerFor(endpoint.EndPtListAssets))
 	mux.HandleFunc(pat.Get("/balances"), endpoint.HandlerFor(endpoint.EndPtListBalances))
+	mux.HandleFunc(pat.Get("/offers"), endpoint.HandlerFor(endpoint.EndPtListOffers))
 	mux.HandleFunc(pat.Get("/assets/:asset/balances"), endpoint.HandlerFor(endpoint.EndPtListAssetBalances))
+	mux.HandleFunc(pat.Get("/peers"), endpoint.HandlerFor(endpoint.EndPtListPeers))
+	mux.HandleFunc(pat.Get("/keys"), endpoint.HandlerFor(endpoint.EndPtListAPIKeys))
+	mux.HandleFunc(pat.Get("/usage"), endpoint.HandlerFor(endpoint.EndPtRetrieveUsage))
+	mux.HandleFunc(pat.Get("/events"), endpoint.StreamHandlerFor(endpoint.EndPtStreamEvents))
 	// mux.HandleFunc(pat.Get("/assets/:asset/operations"), endpoint.HandlerFor(endpoint.EndPtListOperations))
 
+	// Admin.
+	mux.HandleFunc(pat.Get("/admin/users"), endpoint.HandlerFor(endpoint.EndPtAdminListUsers))
+	mux.HandleFunc(pat.Post("/admin/users"), endpoint.HandlerFor(endpoint.EndPtAdminCreateUser))
+	mux.HandleFunc(pat.Post("/admin/users/:user/disable"), endpoint.HandlerFor(endpoint.EndPtAdminDisableUser))
+	mux.HandleFunc(pat.Post("/admin/users/:user/enable"), endpoint.HandlerFor(endpoint.EndPtAdminEnableUser))
+	mux.HandleFunc(pat.Post("/admin/users/:user/password"), endpoint.HandlerFor(endpoint.EndPtAdminResetPassword))
+	mux.HandleFunc(pat.Get("/admin/users/:user/assets"), endpoint.HandlerFor(endpoint.EndPtAdminListUserAssets))
+	mux.HandleFunc(pat.Get("/admin/users/:user/offers"), endpoint.HandlerFor(endpoint.EndPtAdminListUserOffers))
+	mux.HandleFunc(pat.Get("/admin/users/:user/usage"), endpoint.HandlerFor(endpoint.EndPtAdminRetrieveUserUsage))
+	mux.HandleFunc(pat.Post("/admin/users/:user/policies"), endpoint.HandlerFor(endpoint.EndPtAdminSetSpendingPolicy))
+
 	// Mixed.
 	mux.HandleFunc(pat.Post("/transactions/:transaction/settle"), endpoint.HandlerFor(endpoint.EndPtSettleTransaction))
 	mux.HandleFunc(pat.Post("/transactions/:transaction/cancel"), endpoint.HandlerFor(endpoint.EndPtCancelTransaction))
@@ -32,7 +50,13 @@ func (c *Controller) Bind(
 	mux.HandleFunc(pat.Get("/offers/:offer"), endpoint.HandlerFor(endpoint.EndPtRetrieveOffer))
 	mux.HandleFunc(pat.Get("/operations/:operation"), endpoint.HandlerFor(endpoint.EndPtRetrieveOperation))
 	mux.HandleFunc(pat.Get("/transactions/:transaction"), endpoint.HandlerFor(endpoint.EndPtRetrieveTransaction))
+	mux.HandleFunc(pat.Get("/transactions/:transaction/trace"), endpoint.HandlerFor(endpoint.EndPtRetrieveTransactionTrace))
 	mux.HandleFunc(pat.Get("/balances/:balance"), endpoint.HandlerFor(endpoint.EndPtRetrieveBalance))
+	mux.HandleFunc(pat.Get("/key"), endpoint.HandlerFor(endpoint.EndPtRetrieveKey))
+	mux.HandleFunc(pat.Get("/.well-known/settle-mint"), endpoint.HandlerFor(endpoint.EndPtRetrieveMint))
+	mux.HandleFunc(pat.Get("/openapi.json"), endpoint.HandlerFor(endpoint.EndPtRetrieveOpenAPI))
+	mux.HandleFunc(pat.Get("/healthz"), endpoint.HandlerFor(endpoint.EndPtRetrieveHealth))
+	mux.HandleFunc(pat.Get("/readyz"), endpoint.HandlerFor(endpoint.EndPtRetrieveReadiness))
 
 	mux.HandleFunc(pat.Post("/transactions/:transaction"), endpoint.HandlerFor(endpoint.EndPtCreateTransaction))
 	mux.HandleFunc(pat.Post("/operations/:operation"), endpoint.HandlerFor(endpoint.EndPtPropagateOperation))
@@ -41,4 +65,5 @@ func (c *Controller) Bind(
 
 	mux.HandleFunc(pat.Get("/assets/:asset"), endpoint.HandlerFor(endpoint.EndPtRetrieveAsset))
 	mux.HandleFunc(pat.Get("/assets/:asset/offers"), endpoint.HandlerFor(endpoint.EndPtListAssetOffers))
+	mux.HandleFunc(pat.Get("/assets/:asset/book"), endpoint.HandlerFor(endpoint.EndPtRetrieveOrderBook))
 }
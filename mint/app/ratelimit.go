@@ -0,0 +1,122 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/ratelimit"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/lib/authentication"
+	"github.com/spolu/settle/mint/lib/signature"
+)
+
+const (
+	// RlGrIP is the rate limit group of all requests, limited per client IP
+	// before they are authenticated.
+	RlGrIP string = "ip"
+	// RlGrPublic is the rate limit group of anonymous read requests.
+	RlGrPublic string = "public"
+	// RlGrPropagation is the rate limit group of anonymous write requests,
+	// emitted by peer mints to propagate objects.
+	RlGrPropagation string = "propagation"
+	// RlGrAuthenticated is the rate limit group of authenticated requests.
+	RlGrAuthenticated string = "authenticated"
+)
+
+// DefaultRateLimits are the default rate limits per route group.
+var DefaultRateLimits = map[string]ratelimit.Limit{
+	RlGrIP:            ratelimit.Limit{Rate: 100, Burst: 200},
+	RlGrPublic:        ratelimit.Limit{Rate: 20, Burst: 40},
+	RlGrPropagation:   ratelimit.Limit{Rate: 20, Burst: 100},
+	RlGrAuthenticated: ratelimit.Limit{Rate: 50, Burst: 100},
+}
+
+// exemptPaths are the paths of the requests that are never rate limited
+// (health checks and metrics scraping).
+var exemptPaths = map[string]bool{
+	"/healthz": true,
+	"/readyz":  true,
+	"/metrics": true,
+}
+
+// ipRateLimitGroup returns the rate limit group of a request for the IP
+// limiter, mounted before requests are authenticated or their signature
+// verified.
+func ipRateLimitGroup(
+	r *http.Request,
+) string {
+	if exemptPaths[r.URL.Path] {
+		return ""
+	}
+	return RlGrIP
+}
+
+// ipRateLimitKey returns the key by which a request is rate limited by the IP
+// limiter: the client IP.
+func ipRateLimitKey(
+	r *http.Request,
+) string {
+	return fmt.Sprintf("ip:%s", ratelimit.IP(r))
+}
+
+// rateLimitGroup returns the rate limit group of a request. It relies on the
+// authentication status and must run after the authentication middleware.
+func rateLimitGroup(
+	r *http.Request,
+) string {
+	if exemptPaths[r.URL.Path] {
+		return ""
+	}
+	if authentication.Get(r.Context()).Status == authentication.AutStSucceeded {
+		return RlGrAuthenticated
+	}
+	if r.Method == "POST" {
+		return RlGrPropagation
+	}
+	return RlGrPublic
+}
+
+// rateLimitKey returns the key by which a request is rate limited: the
+// authenticated user, the peer mint that signed the request or the client IP.
+func rateLimitKey(
+	r *http.Request,
+) string {
+	ctx := r.Context()
+	if user := authentication.Get(ctx).User; user != nil {
+		return fmt.Sprintf("user:%s", user.Token)
+	}
+	if host := signature.Get(ctx).Host; host != "" {
+		return fmt.Sprintf("mint:%s", host)
+	}
+	return fmt.Sprintf("ip:%s", ratelimit.IP(r))
+}
+
+// NewRateLimiters constructs the mint rate limiters based on the rate limits
+// configured in the context: the IP limiter, mounted before requests are
+// authenticated so that failed authentications and forged signatures are
+// limited, and the limiter keyed by user or peer mint, mounted after.
+func NewRateLimiters(
+	ctx context.Context,
+) (*ratelimit.Limiter, *ratelimit.Limiter, error) {
+	limits, err := ratelimit.ParseLimits(
+		mint.GetRateLimits(ctx), DefaultRateLimits)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	return ratelimit.NewLimiter(limits, ipRateLimitGroup, ipRateLimitKey),
+		ratelimit.NewLimiter(limits, rateLimitGroup, rateLimitKey), nil
+}
+
+// NewClientIPMiddleware constructs the middleware resolving the IP of
+// clients based on the trusted proxies configured in the context.
+func NewClientIPMiddleware(
+	ctx context.Context,
+) (func(http.Handler) http.Handler, error) {
+	proxies, err := ratelimit.ParseProxies(mint.GetTrustedProxies(ctx))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return ratelimit.ClientIPMiddleware(proxies), nil
+}
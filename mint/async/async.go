@@ -8,6 +8,10 @@ import (
 
 	"github.com/spolu/settle/lib/db"
 	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/health"
+	"github.com/spolu/settle/lib/logging"
+	"github.com/spolu/settle/lib/metrics"
+	"github.com/spolu/settle/lib/trace"
 	"github.com/spolu/settle/mint"
 	"github.com/spolu/settle/mint/model"
 )
@@ -33,6 +37,14 @@ type Task interface {
 	Execute(ctx context.Context) error
 }
 
+// Periodic is implemented by the tasks that run periodically. Once such a task
+// succeeded or ran out of retries, the worker queues the task returned by Next
+// so that a failed run does not stop the following ones.
+type Periodic interface {
+	// Next returns the next run of the task.
+	Next(ctx context.Context) Task
+}
+
 // Registrar is used to register task generators within the module. The role of
 // the generator for a given mint.TkName is to reconstruct a task from its
 // subject, status and retry.
@@ -71,13 +83,21 @@ func (d Deadline) Deadline() time.Time {
 	return d.Task.DeadlineForRetry(d.Model.Retry)
 }
 
-// Async represents the state of an async queue.
+// Async represents the state of an async queue. The times of the last loop of
+// the worker, of the last task execution completed and the start of the task
+// being executed (zero if none) are tracked to report the worker health. The
+// last count of failed tasks is cached for metrics along with its time.
 type Async struct {
 	Ctx       context.Context
 	Pending   Deadlines
 	Scheduled chan Deadline
 
-	mutex *sync.Mutex
+	mutex   *sync.Mutex
+	looped  time.Time
+	ran     time.Time
+	running time.Time
+	failed  int
+	counted time.Time
 }
 
 // NewAsync constructs a new async state.
@@ -114,9 +134,9 @@ func NewAsync(
 			Model: m,
 		}
 		deadlines = append(deadlines, d)
-		mint.Logf(ctx, "Retrieved task: "+
-			"name=%s subject=%s retry=%d deadline=%q",
-			d.Task.Name(), d.Task.Subject(), d.Model.Retry, d.Deadline())
+		mint.Debug(ctx, "Retrieved task",
+			"task", d.Task.Name(), "subject", d.Task.Subject(),
+			"retry", d.Model.Retry, "deadline", d.Deadline())
 	}
 
 	a.Pending = deadlines
@@ -124,9 +144,44 @@ func NewAsync(
 
 	a.schedule(ctx)
 
+	metrics.Get(ctx).OnCollect(a.collect)
+
 	return a, nil
 }
 
+// collect reports the number of pending tasks by task name and the cached
+// count of failed tasks, refreshed after AsyncFailedTasksRefreshMs.
+func (a *Async) collect(
+	r *metrics.Registry,
+) {
+	a.mutex.Lock()
+	stale := time.Since(a.counted) >
+		time.Duration(mint.AsyncFailedTasksRefreshMs)*time.Millisecond
+	a.mutex.Unlock()
+
+	if stale {
+		_, err := a.countFailed(a.Ctx)
+		if err != nil {
+			mint.Warn(a.Ctx, "Failed to count failed tasks", "error", err)
+		}
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	r.Set(mint.MtAsyncFailed, float64(a.failed))
+
+	pending := map[mint.TkName]int{}
+	for _, d := range a.Pending {
+		pending[d.Task.Name()]++
+	}
+
+	r.Reset(mint.MtAsyncPending)
+	for name, count := range pending {
+		r.Set(mint.MtAsyncPending, float64(count), string(name))
+	}
+}
+
 // schedule attempts to schedule an eligible task in a non blocking way. If
 // there is no task to schedule or the Scheduled channel is blocked, it's a
 // no-op. Can be called as often as needed.
@@ -143,17 +198,16 @@ func (a *Async) schedule(
 		case a.Scheduled <- d:
 			a.Pending = a.Pending[:len(a.Pending)-1]
 
-			mint.Logf(ctx, "Scheduled task: "+
-				"name=%s subject=%s retry=%d deadline=%s",
-				d.Task.Name(), d.Task.Subject(), d.Model.Retry,
-				d.Deadline().String())
+			mint.Debug(ctx, "Scheduled task",
+				"task", d.Task.Name(), "subject", d.Task.Subject(),
+				"retry", d.Model.Retry, "deadline", d.Deadline().String())
 		default:
 		}
 	} else {
-		mint.Logf(ctx, "Scheduler next task: "+
-			"name=%s subject=%s retry=%d deadline=%s duration=%s",
-			d.Task.Name(), d.Task.Subject(), d.Model.Retry,
-			d.Deadline().String(), d.Deadline().Sub(time.Now()).String())
+		mint.Debug(ctx, "Scheduler next task",
+			"task", d.Task.Name(), "subject", d.Task.Subject(),
+			"retry", d.Model.Retry, "deadline", d.Deadline().String(),
+			"duration", d.Deadline().Sub(time.Now()).String())
 	}
 
 }
@@ -161,17 +215,23 @@ func (a *Async) schedule(
 // Queue queues a new task by adding it to the list of pending tasks and
 // calling Schedule. Queue does not begin a new transaction as it is meant to
 // be called within a transaction block (offer creation, operation creation,
-// ...).
+// ...). The task executions join the trace of ctx.
 func (a *Async) Queue(
 	ctx context.Context,
 	t Task,
 ) error {
+	sc := ""
+	if s, ok := trace.GetSpanContext(ctx); ok {
+		sc = s.String()
+	}
+
 	m, err := model.CreateTask(ctx,
 		t.Created(),
 		t.Name(),
 		t.Subject(),
 		mint.TkStPending,
 		0,
+		sc,
 	)
 	if err != nil {
 		return errors.Trace(err)
@@ -185,6 +245,22 @@ func (a *Async) Queue(
 	return nil
 }
 
+// HasPending returns whether a task with the provided name is currently
+// pending (used to avoid queueing periodic tasks more than once).
+func (a *Async) HasPending(
+	name mint.TkName,
+) bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	for _, d := range a.Pending {
+		if d.Task.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
 // LockAndSchedule locks and schedule a task if possible
 func (a *Async) LockAndSchedule(
 	ctx context.Context,
@@ -209,9 +285,9 @@ func (a *Async) AppendAndSchedule(
 	a.Pending = append(a.Pending, d)
 	sort.Sort(a.Pending)
 
-	mint.Logf(ctx, "Queued task: "+
-		"name=%s subject=%s retry=%d deadline=%q",
-		d.Task.Name(), d.Task.Subject(), d.Model.Retry, d.Deadline())
+	mint.Info(ctx, "Queued task",
+		"task", d.Task.Name(), "subject", d.Task.Subject(),
+		"retry", d.Model.Retry, "deadline", d.Deadline())
 
 	a.schedule(ctx)
 }
@@ -221,40 +297,63 @@ func (a *Async) AppendAndSchedule(
 func (a *Async) RunOne(
 	d Deadline,
 ) {
-	mint.Logf(a.Ctx, "Executing task: "+
-		"name=%s subject=%s retry=%d deadline=%q",
-		d.Task.Name(), d.Task.Subject(), d.Model.Retry, d.Deadline())
+	// Entries logged during the execution carry the task as fields and its
+	// execution is recorded as a span of the trace that queued it.
+	tCtx := logging.With(a.Ctx,
+		"task", d.Task.Name(), "subject", d.Task.Subject(),
+		"retry", d.Model.Retry)
+	if sc, ok := trace.ParseSpanContext(d.Model.Trace); ok {
+		tCtx = trace.WithSpanContext(tCtx, sc)
+	}
+	tCtx, span := trace.Start(tCtx, "task "+string(d.Task.Name()),
+		"subject", d.Task.Subject(), "retry", d.Model.Retry)
+	tCtx = logging.With(tCtx, "trace_id", span.TraceID)
 
-	err := d.Task.Execute(With(a.Ctx, a))
+	mint.Info(tCtx, "Executing task", "deadline", d.Deadline())
 
-	ctx := db.Begin(a.Ctx, "mint")
+	a.mutex.Lock()
+	a.running = time.Now()
+	a.mutex.Unlock()
+
+	err := d.Task.Execute(With(tCtx, a))
+	span.Finish(err)
+
+	a.mutex.Lock()
+	a.ran = time.Now()
+	a.running = time.Time{}
+	a.mutex.Unlock()
+
+	ctx := db.Begin(tCtx, "mint")
 	defer db.LoggedRollback(ctx)
 
+	result := "succeeded"
 	if err != nil {
-		mint.Logf(ctx, "Error executing task: "+
-			"name=%s subject=%s retry=%d error=%s",
-			d.Task.Name(), d.Task.Subject(), d.Model.Retry, err.Error())
-		for _, line := range errors.ErrorStack(err) {
-			mint.Logf(ctx, "  %s", line)
-		}
+		mint.Warn(ctx, "Error executing task",
+			"error", err, "stack", errors.ErrorStack(err))
 
+		result = "retried"
 		d.Model.Retry++
 		if d.Model.Retry > d.Task.MaxRetries() {
+			result = "failed"
 			d.Model.Status = mint.TkStFailed
 		}
 	} else {
-		mint.Logf(ctx, "Successfuly executed task: "+
-			"name=%s subject=%s retry=%d",
-			d.Task.Name(), d.Task.Subject(), d.Model.Retry)
+		mint.Info(ctx, "Successfuly executed task")
 
 		d.Model.Status = mint.TkStSucceeded
 	}
+	metrics.Inc(ctx, mint.MtAsyncRuns, string(d.Task.Name()), result)
+
+	if p, ok := d.Task.(Periodic); ok && d.Model.Status != mint.TkStPending {
+		err = a.Queue(ctx, p.Next(ctx))
+		if err != nil {
+			mint.Error(ctx, "Error queueing next task run", "error", err)
+		}
+	}
 
 	err = d.Model.Save(ctx)
 	if err != nil {
-		mint.Logf(ctx, "Error saving task: "+
-			"name=%s subject=%s retry=%d error=%s",
-			d.Task.Name(), d.Task.Subject(), d.Model.Retry, err.Error())
+		mint.Error(ctx, "Error saving task", "error", err)
 	}
 
 	db.Commit(ctx)
@@ -274,9 +373,11 @@ func (a *Async) RunOne(
 // Run should be called from a go routine to execute task as a worker. Multiple
 // worker can be run concurrently.
 func (a *Async) Run() {
+	a.loop()
 	go func() {
 		for {
 			time.Sleep(10 * time.Second)
+			a.loop()
 			a.LockAndSchedule(a.Ctx)
 		}
 	}()
@@ -285,6 +386,103 @@ func (a *Async) Run() {
 	}
 }
 
+// loop records a loop of the worker.
+func (a *Async) loop() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.looped = time.Now()
+}
+
+// Health checks that the worker is running: its loop ran and its current
+// task started within HealthAsyncStaleMs.
+func (a *Async) Health(
+	ctx context.Context,
+) health.Check {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	now := time.Now()
+	stale := time.Duration(mint.HealthAsyncStaleMs) * time.Millisecond
+
+	var err error
+	switch {
+	case a.looped.IsZero():
+		err = errors.Newf("Async worker not running")
+	case now.Sub(a.looped) > stale:
+		err = errors.Newf("Async worker loop stale since: %s",
+			a.looped.UTC().Format(time.RFC3339))
+	case !a.running.IsZero() && now.Sub(a.running) > stale:
+		err = errors.Newf("Async worker stuck on a task since: %s",
+			a.running.UTC().Format(time.RFC3339))
+	}
+
+	return health.NewCheck("async.worker", err,
+		"last_loop", format(a.looped),
+		"last_run", format(a.ran),
+		"running_since", format(a.running))
+}
+
+// countFailed counts the tasks created within HealthFailedTasksWindowMs that
+// failed out of retries and caches the count for metrics.
+func (a *Async) countFailed(
+	ctx context.Context,
+) (int, error) {
+	failed, err := model.CountTasks(ctx, mint.TkStFailed, time.Now().Add(
+		-time.Duration(mint.HealthFailedTasksWindowMs)*time.Millisecond))
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.failed = failed
+	a.counted = time.Now()
+
+	return failed, nil
+}
+
+// TasksHealth checks that the number of pending tasks and of failed tasks
+// created within HealthFailedTasksWindowMs are under their thresholds.
+func (a *Async) TasksHealth(
+	ctx context.Context,
+) health.Check {
+	pending, err := model.CountTasks(ctx, mint.TkStPending, time.Time{})
+	if err != nil {
+		return health.NewCheck("async.tasks", err)
+	}
+	failed, err := a.countFailed(ctx)
+	if err != nil {
+		return health.NewCheck("async.tasks", err)
+	}
+
+	maxPending := mint.GetHealthMaxPendingTasks(ctx)
+	maxFailed := mint.GetHealthMaxFailedTasks(ctx)
+
+	switch {
+	case pending > maxPending:
+		err = errors.Newf("Too many pending tasks: %d > %d",
+			pending, maxPending)
+	case failed > maxFailed:
+		err = errors.Newf("Too many failed tasks: %d > %d",
+			failed, maxFailed)
+	}
+
+	return health.NewCheck("async.tasks", err,
+		"pending", pending, "max_pending", maxPending,
+		"failed", failed, "max_failed", maxFailed)
+}
+
+// format formats a time for health check details ("none" if zero).
+func format(
+	t time.Time,
+) string {
+	if t.IsZero() {
+		return "none"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
 // ContextKey is the type of the key used with context to carry contextual
 // async state.
 type ContextKey string
@@ -319,11 +517,13 @@ func Queue(
 }
 
 // TestRunOne runs one task off of the list of pending tasks, In tests we don't
-// have any worker so we use this ot run tasks syncrhonously as needed.
+// have any worker so we use this ot run tasks syncrhonously as needed (and it
+// counts as a loop of the worker).
 func TestRunOne(
 	ctx context.Context,
 ) {
 	a := Get(ctx)
+	a.loop()
 	var d Deadline
 
 	a.mutex.Lock()
@@ -92,9 +92,8 @@ func (t *ExpireTransaction) Execute(
 	db.Commit(ctx)
 
 	if tx.Status == mint.TxStSettled {
-		mint.Logf(ctx,
-			"Skipping settled transaction expiry: transaction=%s status=%s",
-			tx.ID(), tx.Status)
+		mint.Info(ctx, "Skipping settled transaction expiry",
+			"transaction", tx.ID(), "status", tx.Status)
 		return nil
 	}
 
@@ -0,0 +1,152 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/async"
+)
+
+// periodic implements the scheduling shared by the tasks that run
+// periodically. Each run processes a bounded batch of work and the worker
+// queues the next run once it succeeded or ran out of retries (see
+// async.Periodic). The task subject stores the cursor of the batch to process:
+// runs continuing a walk are scheduled right away while the ones starting a
+// new walk (empty cursor) wait for the task interval. A run that ran out of
+// retries is followed by a new walk.
+type periodic struct {
+	name      mint.TkName
+	created   time.Time
+	cursor    string
+	interval  int64
+	generator func(context.Context, time.Time, string) async.Task
+
+	// run processes the batch at the provided cursor and returns the cursor
+	// of the next batch, empty if the walk is complete.
+	run func(context.Context, string) (string, error)
+
+	ran  bool
+	next string
+}
+
+// Name returns the task name.
+func (t *periodic) Name() mint.TkName {
+	return t.name
+}
+
+// Created returns the task creation time.
+func (t *periodic) Created() time.Time {
+	return t.created
+}
+
+// Subject returns the task subject, the cursor of its batch.
+func (t *periodic) Subject() string {
+	return t.cursor
+}
+
+// MaxRetries returns the max retries for the task.
+func (t *periodic) MaxRetries() uint {
+	return 8
+}
+
+// DeadlineForRetry returns the deadline for the provided retry count.
+func (t *periodic) DeadlineForRetry(
+	retry uint,
+) time.Time {
+	interval := time.Duration(0)
+	if t.cursor == "" {
+		interval = time.Duration(t.interval) * time.Millisecond
+	}
+	return t.Created().Add(interval + (1<<retry-1)*time.Second)
+}
+
+// Execute idempotently runs the task to completion or errors.
+func (t *periodic) Execute(
+	ctx context.Context,
+) error {
+	next, err := t.run(ctx, t.cursor)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	t.ran = true
+	t.next = next
+
+	return nil
+}
+
+// Next returns the next run of the task.
+func (t *periodic) Next(
+	ctx context.Context,
+) async.Task {
+	cursor := ""
+	if t.ran {
+		cursor = t.next
+	}
+	return t.generator(ctx, time.Now(), cursor)
+}
+
+// ensurePeriodic queues a run of the periodic task with the provided name
+// unless one is already pending.
+func ensurePeriodic(
+	ctx context.Context,
+	name mint.TkName,
+) error {
+	if async.Get(ctx).HasPending(name) {
+		return nil
+	}
+
+	ctx = db.Begin(ctx, "mint")
+	defer db.LoggedRollback(ctx)
+
+	err := async.Queue(ctx, async.Registrar[name](ctx, time.Now(), ""))
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	db.Commit(ctx)
+
+	return nil
+}
+
+// listCursor is the position of a walk over a list ordered by creation time
+// and token (most recent first), as stored in the subject of periodic tasks.
+type listCursor struct {
+	createdBefore time.Time
+	tokenBefore   string
+}
+
+// parseListCursor parses a cursor, starting from the most recent entry if it
+// is empty or invalid.
+func parseListCursor(
+	cursor string,
+) listCursor {
+	ss := strings.SplitN(cursor, ":", 2)
+	if len(ss) == 2 {
+		if ns, err := strconv.ParseInt(ss[0], 10, 64); err == nil {
+			return listCursor{
+				createdBefore: time.Unix(0, ns).UTC(),
+				tokenBefore:   ss[1],
+			}
+		}
+	}
+	return listCursor{
+		createdBefore: time.Now(),
+		tokenBefore:   "",
+	}
+}
+
+// cursorAfter returns the cursor of the walk continuing after the provided
+// entry.
+func cursorAfter(
+	created time.Time,
+	token string,
+) string {
+	return fmt.Sprintf("%d:%s", created.UnixNano(), token)
+}
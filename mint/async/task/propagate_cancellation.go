@@ -113,9 +113,8 @@ func (t *PropagateCancellation) Execute(
 	if int(t.hop)-1 >= 0 {
 		m := plan.Hops[t.hop-1].Mint
 
-		mint.Logf(ctx,
-			"Propagating cancellation: transaction=%s hop=%d mint=%s",
-			tx.ID(), t.hop, m)
+		mint.Info(ctx, "Propagating cancellation",
+			"transaction", tx.ID(), "hop", t.hop, "mint", m)
 
 		_, err := client.CancelTransaction(ctx, tx.ID(), t.hop-1, m)
 		if err != nil {
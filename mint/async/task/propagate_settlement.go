@@ -122,9 +122,8 @@ func (t *PropagateSettlement) Execute(
 	if int(t.hop)-1 >= 0 {
 		m := plan.Hops[t.hop-1].Mint
 
-		mint.Logf(ctx,
-			"Propagating settlement: transaction=%s hop=%d mint=%s",
-			tx.ID(), t.hop, m)
+		mint.Info(ctx, "Propagating settlement",
+			"transaction", tx.ID(), "hop", t.hop, "mint", m)
 
 		hop := t.hop - 1
 		_, err := client.SettleTransaction(ctx, tx.ID(), &hop, tx.Secret, &m)
@@ -0,0 +1,214 @@
+package task
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/async"
+	"github.com/spolu/settle/mint/model"
+)
+
+const (
+	// TkReconcileBalances reconciles propagated balances.
+	TkReconcileBalances mint.TkName = "ReconcileBalances"
+
+	// reconcileBatchSize is the number of propagated balances checked per
+	// reconciliation run.
+	reconcileBatchSize uint = 100
+)
+
+func init() {
+	async.Registrar[TkReconcileBalances] = NewReconcileBalances
+}
+
+// ReconcileBalances is in charge of periodically checking the propagated
+// balances stored on this mint against their canonical version, repairing
+// the copies that went stale (generally after PropagateBalance ran out of
+// retries). Each run checks one batch of balances and queues the next run.
+type ReconcileBalances struct {
+	periodic
+}
+
+// NewReconcileBalances constructs and initializes the task.
+func NewReconcileBalances(
+	ctx context.Context,
+	created time.Time,
+	subject string,
+) async.Task {
+	return &ReconcileBalances{periodic{
+		name:      TkReconcileBalances,
+		created:   created,
+		cursor:    subject,
+		interval:  mint.ReconciliationIntervalMs,
+		generator: NewReconcileBalances,
+		run: func(ctx context.Context, cursor string) (string, error) {
+			next, _, _, err := ReconcilePropagatedBalanceBatch(ctx, cursor)
+			return next, err
+		},
+	}}
+}
+
+// EnsureReconcileBalances queues a balance reconciliation unless one is
+// already pending.
+func EnsureReconcileBalances(
+	ctx context.Context,
+) error {
+	return ensurePeriodic(ctx, TkReconcileBalances)
+}
+
+// ReconcilePropagatedBalances walks all the propagated balances stored on
+// this mint, retrieves their canonical version and repairs the ones that
+// diverged. It returns the number of balances checked and the discrepancies
+// recorded. Failures to reach a canonical mint are logged and skipped, only
+// local errors are returned.
+func ReconcilePropagatedBalances(
+	ctx context.Context,
+) (int, []*model.BalanceDiscrepancy, error) {
+	checked := 0
+	discrepancies := []*model.BalanceDiscrepancy{}
+
+	cursor := ""
+	for {
+		next, c, d, err := ReconcilePropagatedBalanceBatch(ctx, cursor)
+		if err != nil {
+			return 0, nil, errors.Trace(err)
+		}
+		checked += c
+		discrepancies = append(discrepancies, d...)
+
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	return checked, discrepancies, nil
+}
+
+// ReconcilePropagatedBalanceBatch reconciles the batch of propagated balances
+// at the provided cursor (see ReconcilePropagatedBalances). It returns the
+// cursor of the next batch (empty once all balances were checked), the number
+// of balances checked and the discrepancies recorded.
+func ReconcilePropagatedBalanceBatch(
+	ctx context.Context,
+	cursor string,
+) (string, int, []*model.BalanceDiscrepancy, error) {
+	client := &mint.Client{}
+	err := client.Init(ctx)
+	if err != nil {
+		return "", 0, nil, errors.Trace(err)
+	}
+
+	c := parseListCursor(cursor)
+	balances, err := model.LoadPropagatedBalanceList(ctx,
+		c.createdBefore, c.tokenBefore, reconcileBatchSize)
+	if err != nil {
+		return "", 0, nil, errors.Trace(err)
+	}
+
+	discrepancies := []*model.BalanceDiscrepancy{}
+	for _, b := range balances {
+		b := b
+		d, err := reconcileBalance(ctx, client, &b)
+		if err != nil {
+			return "", 0, nil, errors.Trace(err)
+		}
+		if d != nil {
+			discrepancies = append(discrepancies, d)
+		}
+	}
+
+	next := ""
+	if uint(len(balances)) == reconcileBatchSize {
+		last := balances[len(balances)-1]
+		next = cursorAfter(last.Created, last.Token)
+	}
+
+	mint.Info(ctx, "Reconciled propagated balances",
+		"checked", len(balances), "discrepancies", len(discrepancies),
+		"cursor", cursor, "next", next)
+
+	return next, len(balances), discrepancies, nil
+}
+
+// reconcileBalance checks one propagated balance against its canonical
+// version, records a discrepancy and repairs the local copy if needed.
+func reconcileBalance(
+	ctx context.Context,
+	client *mint.Client,
+	balance *model.Balance,
+) (*model.BalanceDiscrepancy, error) {
+	typ := mint.DsTpValueMismatch
+	var canonicalValue *model.Amount
+
+	canonical, err := client.RetrieveBalance(ctx, balance.ID())
+	if err != nil {
+		switch e := errors.Cause(err).(type) {
+		case mint.ErrMintClient:
+			if e.ErrCode != "balance_not_found" {
+				mint.Info(ctx, "Skipping balance reconciliation",
+					"balance", balance.ID(), "error", err)
+				return nil, nil
+			}
+			typ = mint.DsTpCanonicalMissing
+		default:
+			mint.Info(ctx, "Skipping balance reconciliation",
+				"balance", balance.ID(), "error", err)
+			return nil, nil
+		}
+	} else {
+		if canonical.ID != balance.ID() ||
+			canonical.Asset != balance.Asset ||
+			canonical.Holder != balance.Holder ||
+			canonical.Value == nil {
+			mint.Info(ctx, "Skipping balance reconciliation",
+				"balance", balance.ID(), "error", "unexpected canonical balance")
+			return nil, nil
+		}
+		if canonical.Value.Cmp((*big.Int)(&balance.Value)) == 0 {
+			return nil, nil
+		}
+		v := model.Amount(*canonical.Value)
+		canonicalValue = &v
+	}
+
+	ctx = db.Begin(ctx, "mint")
+	defer db.LoggedRollback(ctx)
+
+	// Reload the balance to avoid overwriting a propagation that happened
+	// while we were retrieving the canonical version.
+	bal, err := model.LoadPropagatedBalanceByOwnerToken(ctx,
+		balance.Owner, balance.Token)
+	if err != nil {
+		return nil, errors.Trace(err)
+	} else if bal == nil ||
+		(*big.Int)(&bal.Value).Cmp((*big.Int)(&balance.Value)) != 0 {
+		return nil, nil
+	}
+
+	d, err := model.CreateBalanceDiscrepancy(ctx, bal, typ, canonicalValue)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	if canonicalValue != nil {
+		bal.Value = *canonicalValue
+		err = bal.Save(ctx)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+
+	db.Commit(ctx)
+
+	mint.Info(ctx, "Recorded balance discrepancy",
+		"balance", d.Balance, "type", d.Type,
+		"local_value", (*big.Int)(&d.LocalValue),
+		"repaired", canonicalValue != nil)
+
+	return d, nil
+}
@@ -0,0 +1,150 @@
+package task
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/async"
+	"github.com/spolu/settle/mint/model"
+)
+
+const (
+	// TkRecordPeers records the peer contacts in the peer registry.
+	TkRecordPeers mint.TkName = "RecordPeers"
+
+	// recordPeersBatchSize is the number of peer contacts recorded per run.
+	recordPeersBatchSize int = 1000
+)
+
+func init() {
+	async.Registrar[TkRecordPeers] = NewRecordPeers
+}
+
+// RecordPeers is in charge of periodically recording the contacts with peer
+// mints buffered in memory into the peer registry. Each run records one batch
+// of contacts and queues the next run (right away if contacts are left
+// buffered, the cursor being their number).
+type RecordPeers struct {
+	periodic
+}
+
+// NewRecordPeers constructs and initializes the task.
+func NewRecordPeers(
+	ctx context.Context,
+	created time.Time,
+	subject string,
+) async.Task {
+	return &RecordPeers{periodic{
+		name:      TkRecordPeers,
+		created:   created,
+		cursor:    subject,
+		interval:  mint.PeerRecordIntervalMs,
+		generator: NewRecordPeers,
+		run: func(ctx context.Context, cursor string) (string, error) {
+			_, left, err := RecordPeerContactBatch(ctx)
+			if err != nil || left == 0 {
+				return "", err
+			}
+			return strconv.Itoa(left), nil
+		},
+	}}
+}
+
+// EnsureRecordPeers queues a peer contacts recording unless one is already
+// pending.
+func EnsureRecordPeers(
+	ctx context.Context,
+) error {
+	return ensurePeriodic(ctx, TkRecordPeers)
+}
+
+// RecordPeerContacts records all the pending peer contacts in the peer
+// registry by batches (see RecordPeerContactBatch). It returns the number of
+// contacts recorded.
+func RecordPeerContacts(
+	ctx context.Context,
+) (int, error) {
+	recorded := 0
+	for {
+		r, left, err := RecordPeerContactBatch(ctx)
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+		recorded += r
+		if left == 0 {
+			break
+		}
+	}
+	return recorded, nil
+}
+
+// RecordPeerContactBatch records a batch of the pending peer contacts in the
+// peer registry, creating peers upon their first successful contact (failed
+// contacts with unknown hosts are dropped so that requests naming unreachable
+// mints don't fill the registry). It returns the number of contacts recorded
+// and the number of contacts left pending. Contacts are dropped if they fail
+// to be recorded.
+func RecordPeerContactBatch(
+	ctx context.Context,
+) (int, int, error) {
+	contacts, left := mint.DrainPeerContacts(ctx, recordPeersBatchSize)
+	if len(contacts) == 0 {
+		return 0, left, nil
+	}
+
+	ctx = db.Begin(ctx, "mint")
+	defer db.LoggedRollback(ctx)
+
+	peers := map[string]*model.Peer{}
+	created := map[string]bool{}
+	recorded := 0
+	for _, c := range contacts {
+		p, ok := peers[c.Host]
+		if !ok {
+			var err error
+			p, err = model.LoadPeerByHost(ctx, c.Host)
+			if err != nil {
+				return 0, 0, errors.Trace(err)
+			} else if p == nil {
+				if c.Failed {
+					continue
+				}
+				recorded++
+				p, err = model.CreatePeer(ctx, c)
+				if err != nil {
+					return 0, 0, errors.Trace(err)
+				}
+				peers[c.Host] = p
+				created[c.Host] = true
+				continue
+			}
+			peers[c.Host] = p
+		}
+		recorded++
+		p.Record(c)
+	}
+
+	for host, p := range peers {
+		err := p.Save(ctx)
+		if err != nil {
+			return 0, 0, errors.Trace(err)
+		}
+		if created[host] {
+			mint.Info(ctx, "Peer recorded", "peer", host)
+		}
+	}
+
+	db.Commit(ctx)
+
+	hosts := []string{}
+	for host := range peers {
+		hosts = append(hosts, host)
+	}
+	mint.AddKnownPeers(ctx, hosts...)
+
+	return recorded, left, nil
+}
@@ -0,0 +1,267 @@
+package task
+
+import (
+	"context"
+	"time"
+
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/async"
+	"github.com/spolu/settle/mint/model"
+)
+
+const (
+	// TkSyncOffers refreshes propagated offers.
+	TkSyncOffers mint.TkName = "SyncOffers"
+
+	// syncOffersBatchSize is the number of propagated offers refreshed per
+	// synchronization run.
+	syncOffersBatchSize uint = 100
+)
+
+func init() {
+	async.Registrar[TkSyncOffers] = NewSyncOffers
+}
+
+// SyncOffers is in charge of periodically refreshing the active propagated
+// offers stored on this mint from their canonical version. Remote mints whose
+// offers repeatedly fail to be refreshed are marked as suspect and their
+// offers stop being advertised until a refresh succeeds. Each run refreshes
+// one batch of offers and queues the next run.
+type SyncOffers struct {
+	periodic
+}
+
+// NewSyncOffers constructs and initializes the task.
+func NewSyncOffers(
+	ctx context.Context,
+	created time.Time,
+	subject string,
+) async.Task {
+	return &SyncOffers{periodic{
+		name:      TkSyncOffers,
+		created:   created,
+		cursor:    subject,
+		interval:  mint.ReconciliationIntervalMs,
+		generator: NewSyncOffers,
+		run: func(ctx context.Context, cursor string) (string, error) {
+			next, _, _, err := SyncPropagatedOfferBatch(ctx, cursor)
+			return next, err
+		},
+	}}
+}
+
+// EnsureSyncOffers queues a propagated offers synchronization unless one is
+// already pending.
+func EnsureSyncOffers(
+	ctx context.Context,
+) error {
+	return ensurePeriodic(ctx, TkSyncOffers)
+}
+
+// SyncPropagatedOffers walks all the active propagated offers stored on this
+// mint and refreshes them from their canonical version. It returns the number
+// of offers refreshed and the number of offers that failed to be refreshed.
+// Only local errors are returned.
+func SyncPropagatedOffers(
+	ctx context.Context,
+) (int, int, error) {
+	refreshed := 0
+	failed := 0
+
+	cursor := ""
+	for {
+		next, r, f, err := SyncPropagatedOfferBatch(ctx, cursor)
+		if err != nil {
+			return 0, 0, errors.Trace(err)
+		}
+		refreshed += r
+		failed += f
+
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	return refreshed, failed, nil
+}
+
+// SyncPropagatedOfferBatch refreshes the batch of active propagated offers at
+// the provided cursor (see SyncPropagatedOffers) and updates the sync state of
+// their mints: a mint none of whose offers could be refreshed records a
+// failure. The remaining offers of a mint that can't be reached are skipped.
+// It returns the cursor of the next batch (empty once all offers were
+// processed), the number of offers refreshed and the number of offers that
+// failed to be refreshed.
+func SyncPropagatedOfferBatch(
+	ctx context.Context,
+	cursor string,
+) (string, int, int, error) {
+	client := &mint.Client{}
+	err := client.Init(ctx)
+	if err != nil {
+		return "", 0, 0, errors.Trace(err)
+	}
+
+	c := parseListCursor(cursor)
+	offers, err := model.LoadActivePropagatedOfferList(ctx,
+		c.createdBefore, c.tokenBefore, syncOffersBatchSize)
+	if err != nil {
+		return "", 0, 0, errors.Trace(err)
+	}
+
+	refreshed := 0
+	failed := 0
+
+	hosts := []string{}
+	synced := map[string]bool{}
+	unreachable := map[string]bool{}
+	for _, o := range offers {
+		o := o
+		_, host, err := mint.UsernameAndMintHostFromAddress(ctx, o.Owner)
+		if err != nil {
+			return "", 0, 0, errors.Trace(err)
+		}
+		if _, ok := synced[host]; !ok {
+			hosts = append(hosts, host)
+			synced[host] = false
+		}
+
+		if unreachable[host] {
+			failed++
+			continue
+		}
+
+		ok, reached, err := syncOffer(ctx, client, &o)
+		if err != nil {
+			return "", 0, 0, errors.Trace(err)
+		}
+		if ok {
+			refreshed++
+			synced[host] = true
+		} else {
+			failed++
+		}
+		if !reached {
+			unreachable[host] = true
+		}
+	}
+
+	for _, host := range hosts {
+		err := syncMint(ctx, host, synced[host])
+		if err != nil {
+			return "", 0, 0, errors.Trace(err)
+		}
+	}
+
+	next := ""
+	if uint(len(offers)) == syncOffersBatchSize {
+		last := offers[len(offers)-1]
+		next = cursorAfter(last.Created, last.Token)
+	}
+
+	mint.Info(ctx, "Synchronized propagated offers",
+		"refreshed", refreshed, "failed", failed,
+		"cursor", cursor, "next", next)
+
+	return next, refreshed, failed, nil
+}
+
+// syncOffer refreshes one propagated offer from its canonical version. It
+// returns whether the refresh succeeded and whether the canonical mint could
+// be reached.
+func syncOffer(
+	ctx context.Context,
+	client *mint.Client,
+	offer *model.Offer,
+) (bool, bool, error) {
+	canonical, err := client.RetrieveOffer(ctx, offer.ID())
+	if err != nil {
+		mint.Warn(ctx, "Failed to refresh propagated offer",
+			"offer", offer.ID(), "error", err)
+		_, reached := errors.Cause(err).(mint.ErrMintClient)
+		return false, reached, nil
+	} else if canonical.ID != offer.ID() ||
+		canonical.Owner != offer.Owner ||
+		canonical.Remainder == nil {
+		mint.Warn(ctx, "Failed to refresh propagated offer",
+			"offer", offer.ID(), "error", "unexpected canonical offer")
+		return false, true, nil
+	}
+	switch canonical.Status {
+	case mint.OfStActive, mint.OfStClosed, mint.OfStConsumed:
+	default:
+		mint.Warn(ctx, "Failed to refresh propagated offer",
+			"offer", offer.ID(), "error", "invalid canonical status",
+			"status", canonical.Status)
+		return false, true, nil
+	}
+
+	ctx = db.Begin(ctx, "mint")
+	defer db.LoggedRollback(ctx)
+
+	of, err := model.LoadPropagatedOfferByOwnerToken(ctx,
+		offer.Owner, offer.Token)
+	if err != nil {
+		return false, true, errors.Trace(err)
+	} else if of == nil {
+		return false, true, errors.Trace(errors.Newf(
+			"Propagated offer not found: %s", offer.ID()))
+	}
+
+	// Only the offer status and remainder are mutable.
+	of.Status = canonical.Status
+	of.Remainder = model.Amount(*canonical.Remainder)
+	err = of.Save(ctx)
+	if err != nil {
+		return false, true, errors.Trace(err)
+	}
+
+	db.Commit(ctx)
+
+	return true, true, nil
+}
+
+// syncMint updates the sync state of a remote mint after the refresh of a
+// batch of its propagated offers.
+func syncMint(
+	ctx context.Context,
+	host string,
+	synced bool,
+) error {
+	ctx = db.Begin(ctx, "mint")
+	defer db.LoggedRollback(ctx)
+
+	sync, err := model.LoadOrCreateMintSyncByHost(ctx, host)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	if synced {
+		if sync.Status == mint.SyStSuspect {
+			mint.Info(ctx, "Remote mint recovered",
+				"peer", host, "failures", sync.Failures)
+		}
+		sync.Failures = 0
+		sync.Status = mint.SyStHealthy
+	} else {
+		sync.Failures++
+		if sync.Status != mint.SyStSuspect &&
+			sync.Failures >= mint.GetOfferSuspectFailures(ctx) {
+			mint.Info(ctx, "Remote mint marked as suspect",
+				"peer", host, "failures", sync.Failures)
+			sync.Status = mint.SyStSuspect
+		}
+	}
+
+	err = sync.Save(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	db.Commit(ctx)
+
+	return nil
+}
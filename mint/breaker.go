@@ -0,0 +1,107 @@
+package mint
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BkState is the state of the circuit breaker of a mint.
+type BkState string
+
+const (
+	// BkStClosed lets requests through to the mint.
+	BkStClosed BkState = "closed"
+	// BkStOpen fails requests to the mint fast.
+	BkStOpen BkState = "open"
+	// BkStHalfOpen lets one probe request through to the mint to check
+	// whether it recovered.
+	BkStHalfOpen BkState = "half_open"
+)
+
+// breaker tracks the consecutive failures to reach a mint.
+type breaker struct {
+	state    BkState
+	failures uint
+	opened   time.Time
+}
+
+var breakers = map[string]*breaker{}
+var breakersMutex = &sync.Mutex{}
+
+// BreakerState returns the current state of the circuit breaker of the
+// specified mint.
+func BreakerState(
+	host string,
+) BkState {
+	breakersMutex.Lock()
+	defer breakersMutex.Unlock()
+
+	if b, ok := breakers[host]; ok {
+		return b.state
+	}
+	return BkStClosed
+}
+
+// breakerAllow returns whether a request to the specified mint should be
+// attempted. Once the probe interval has elapsed, an open breaker lets one
+// request through (half-open), whose outcome closes or reopens it.
+func breakerAllow(
+	ctx context.Context,
+	host string,
+) bool {
+	breakersMutex.Lock()
+	defer breakersMutex.Unlock()
+
+	b, ok := breakers[host]
+	if !ok {
+		return true
+	}
+	switch b.state {
+	case BkStOpen:
+		if time.Now().Sub(b.opened) < GetBreakerProbe(ctx) {
+			return false
+		}
+		b.state = BkStHalfOpen
+		Info(ctx, "Circuit breaker probing", "peer", host)
+		return true
+	case BkStHalfOpen:
+		// A probe is already in flight.
+		return false
+	}
+	return true
+}
+
+// breakerRecord records the outcome of a request to the specified mint.
+func breakerRecord(
+	ctx context.Context,
+	host string,
+	success bool,
+) {
+	breakersMutex.Lock()
+	defer breakersMutex.Unlock()
+
+	b, ok := breakers[host]
+	if !ok {
+		b = &breaker{state: BkStClosed}
+		breakers[host] = b
+	}
+
+	if success {
+		if b.state != BkStClosed {
+			Info(ctx, "Circuit breaker closed", "peer", host)
+		}
+		b.state = BkStClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == BkStHalfOpen ||
+		(b.state == BkStClosed && b.failures >= GetBreakerFailures(ctx)) {
+		Warn(ctx, "Circuit breaker opened",
+			"peer", host, "failures", b.failures)
+		b.state = BkStOpen
+		b.opened = time.Now()
+	}
+}
@@ -1,19 +1,25 @@
 package mint
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"math/rand"
 	"net/http"
 	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spolu/settle/lib/client"
 	"github.com/spolu/settle/lib/env"
 	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/metrics"
 	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/lib/trace"
 )
 
 // Client expose an interface to perform queries on remote mints.
@@ -29,6 +35,166 @@ func (c *Client) Init(
 	return nil
 }
 
+// cancelBody cancels the context of a request once its response body is
+// closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// do performs a request to the specified mint (see send), recording a span for
+// it whose context is propagated to the mint in the HeaderTraceParent header.
+func (c *Client) do(
+	ctx context.Context,
+	host string,
+	req *http.Request,
+) (*http.Response, error) {
+	ctx, span := trace.Start(ctx, "client "+req.Method+" "+req.URL.Path,
+		"peer", host)
+	trace.Inject(ctx, req)
+
+	r, err := c.send(ctx, host, req)
+	if r != nil {
+		span.Set("status", r.StatusCode)
+	}
+	span.Finish(err)
+
+	return r, err
+}
+
+// send sends a request to the specified mint. Each attempt is bounded by the
+// client timeout and fails fast if the circuit breaker of the mint is open.
+// Idempotent requests are retried upon transport errors or gateway statuses
+// with a jittered exponential backoff. Signed requests are signed again for
+// each retry as their nonce can't be reused.
+func (c *Client) send(
+	ctx context.Context,
+	host string,
+	req *http.Request,
+) (*http.Response, error) {
+	retries := uint(0)
+	if req.Method == "GET" || req.Method == "HEAD" {
+		retries = GetClientRetries(ctx)
+	}
+
+	var err error
+	for attempt := uint(0); ; attempt++ {
+		if attempt > 0 {
+			backoff := time.Duration(ClientRetryBackoffMs) *
+				time.Millisecond << (attempt - 1)
+			timer := time.NewTimer(
+				backoff/2 + time.Duration(rand.Int63n(int64(backoff/2))))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, errors.Trace(ctx.Err())
+			case <-timer.C:
+			}
+
+			if req.GetBody != nil {
+				req.Body, err = req.GetBody()
+				if err != nil {
+					return nil, errors.Trace(err)
+				}
+			}
+			if req.Header.Get(HeaderSignature) != "" {
+				if err := SignRequest(ctx, req, host); err != nil {
+					return nil, errors.Trace(err)
+				}
+			}
+		}
+
+		if err := ctx.Err(); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if !breakerAllow(ctx, host) {
+			metrics.Inc(ctx, MtClientErrors, PeerLabel(ctx, host))
+			return nil, errors.Trace(ErrCircuitOpen{host})
+		}
+
+		start := time.Now()
+		actx, cancel := context.WithTimeout(ctx, GetClientTimeout(ctx))
+		r, err := c.httpClient.Do(req.WithContext(actx))
+		latency := time.Now().Sub(start)
+
+		failed := false
+		if err != nil {
+			cancel()
+			failed = true
+		} else {
+			switch r.StatusCode {
+			case http.StatusBadGateway,
+				http.StatusServiceUnavailable,
+				http.StatusGatewayTimeout:
+				failed = true
+			}
+			r.Body = cancelBody{r.Body, cancel}
+		}
+		breakerRecord(ctx, host, !failed)
+		RecordPeerContact(ctx, PeerContact{
+			Host:            host,
+			Time:            start,
+			Latency:         &latency,
+			Failed:          failed,
+			ProtocolVersion: AdvertisedProtocolVersion(host),
+		})
+		metrics.Observe(ctx, MtClientDuration, latency.Seconds(),
+			PeerLabel(ctx, host))
+		if failed {
+			metrics.Inc(ctx, MtClientErrors, PeerLabel(ctx, host))
+		}
+
+		if !failed || attempt >= retries {
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			return r, nil
+		}
+
+		if err != nil {
+			Warn(ctx, "Retrying request",
+				"peer", host, "method", req.Method, "attempt", attempt+1,
+				"error", err)
+		} else {
+			Warn(ctx, "Retrying request",
+				"peer", host, "method", req.Method, "attempt", attempt+1,
+				"status", r.StatusCode)
+			r.Body.Close()
+		}
+	}
+}
+
+// newPostRequest constructs a POST request to the specified mint with the
+// provided parameters encoded in its body following the client encoding
+// configured (see GetClientContentType).
+func (c *Client) newPostRequest(
+	ctx context.Context,
+	host string,
+	path string,
+	params svc.Params,
+) (*http.Request, error) {
+	contentType := GetClientContentType(ctx)
+	body, err := params.Encode(contentType)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	req, err := http.NewRequest("POST",
+		c.mintURL(ctx, host, path, url.Values{}).String(),
+		bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	return req, nil
+}
+
 // DefaultPort is the mint default port by environment.
 var DefaultPort = map[env.Environment]int64{
 	env.Production: 2406,
@@ -149,13 +315,31 @@ func NormalizedOwnerAndTokenFromID(
 	return owner, m[2], nil
 }
 
-// FullMintURL constructs a fully qualified URL to contact a mint defaulting to
-// the correct scheme and port based on the current environment.
+// FullMintURL constructs a fully qualified URL to contact a mint. It relies on
+// the API base URL of the mint discovery document if it was cached (see
+// Client.Discover) and defaults to the correct scheme and port based on the
+// current environment otherwise.
 func FullMintURL(
 	ctx context.Context,
 	host string,
 	path string,
 	query url.Values,
+) *url.URL {
+	if m := CachedDiscovery(host); m != nil && m.APIBaseURL != "" {
+		if base, err := url.Parse(m.APIBaseURL); err == nil {
+			return apiURL(base, path, query)
+		}
+	}
+	return DefaultMintURL(ctx, host, path, query)
+}
+
+// DefaultMintURL constructs a fully qualified URL to contact a mint using the
+// default scheme and port based on the current environment.
+func DefaultMintURL(
+	ctx context.Context,
+	host string,
+	path string,
+	query url.Values,
 ) *url.URL {
 	if len(strings.Split(host, ":")) == 1 {
 		host += fmt.Sprintf(":%d", DefaultPort[env.Get(ctx).Environment])
@@ -185,13 +369,16 @@ func (c *Client) RetrieveBalance(
 	}
 
 	req, err := http.NewRequest("GET",
-		FullMintURL(ctx,
+		c.mintURL(ctx,
 			host, fmt.Sprintf("/balances/%s", id), url.Values{}).String(), nil)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	req.Header.Add("Mint-Protocol-Version", ProtocolVersion)
-	r, err := c.httpClient.Do(req)
+	req.Header.Add("Mint-Protocol-Version", NegotiatedProtocolVersion(host))
+	if err := SignRequest(ctx, req, host); err != nil {
+		return nil, errors.Trace(err)
+	}
+	r, err := c.do(ctx, host, req)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -221,6 +408,56 @@ func (c *Client) RetrieveBalance(
 	return &balance, nil
 }
 
+// RetrieveKey retrieves the identity key of the specified mint. It returns nil
+// if the mint does not expose any identity key (and therefore does not sign
+// its requests).
+func (c *Client) RetrieveKey(
+	ctx context.Context,
+	mint string,
+) (*KeyResource, error) {
+	req, err := http.NewRequest("GET",
+		c.mintURL(ctx, mint, "/key", url.Values{}).String(), nil)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	// Key retrievals are not signed as verifying them would require the
+	// retrieval of our own key by the remote mint.
+	req.Header.Add("Mint-Protocol-Version", NegotiatedProtocolVersion(mint))
+	r, err := c.do(ctx, mint, req)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer r.Body.Close()
+
+	// Mints predating identity keys do not expose the route at all.
+	if r.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	var raw svc.Resp
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	if r.StatusCode != http.StatusOK {
+		var e errors.ConcreteUserError
+		err = raw.Extract("error", &e)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return nil, errors.Trace(ErrMintClient{
+			r.StatusCode, e.ErrCode, e.ErrMessage,
+		})
+	}
+
+	var key KeyResource
+	if err := raw.Extract("key", &key); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	return &key, nil
+}
+
 // RetrieveOffer retrieves an offer given its ID by extracting the mint and
 // retrieving it from there.
 func (c *Client) RetrieveOffer(
@@ -237,13 +474,16 @@ func (c *Client) RetrieveOffer(
 	}
 
 	req, err := http.NewRequest("GET",
-		FullMintURL(ctx,
+		c.mintURL(ctx,
 			host, fmt.Sprintf("/offers/%s", id), url.Values{}).String(), nil)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	req.Header.Add("Mint-Protocol-Version", ProtocolVersion)
-	r, err := c.httpClient.Do(req)
+	req.Header.Add("Mint-Protocol-Version", NegotiatedProtocolVersion(host))
+	if err := SignRequest(ctx, req, host); err != nil {
+		return nil, errors.Trace(err)
+	}
+	r, err := c.do(ctx, host, req)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -289,13 +529,16 @@ func (c *Client) RetrieveOperation(
 	}
 
 	req, err := http.NewRequest("GET",
-		FullMintURL(ctx,
+		c.mintURL(ctx,
 			host, fmt.Sprintf("/operations/%s", id), url.Values{}).String(), nil)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	req.Header.Add("Mint-Protocol-Version", ProtocolVersion)
-	r, err := c.httpClient.Do(req)
+	req.Header.Add("Mint-Protocol-Version", NegotiatedProtocolVersion(host))
+	if err := SignRequest(ctx, req, host); err != nil {
+		return nil, errors.Trace(err)
+	}
+	r, err := c.do(ctx, host, req)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -346,13 +589,16 @@ func (c *Client) RetrieveTransaction(
 	}
 
 	req, err := http.NewRequest("GET",
-		FullMintURL(ctx,
+		c.mintURL(ctx,
 			*mint, fmt.Sprintf("/transactions/%s", id), url.Values{}).String(), nil)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	req.Header.Add("Mint-Protocol-Version", ProtocolVersion)
-	r, err := c.httpClient.Do(req)
+	req.Header.Add("Mint-Protocol-Version", NegotiatedProtocolVersion(*mint))
+	if err := SignRequest(ctx, req, *mint); err != nil {
+		return nil, errors.Trace(err)
+	}
+	r, err := c.do(ctx, *mint, req)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -388,15 +634,16 @@ func (c *Client) PropagateBalance(
 	id string,
 	mint string,
 ) (*BalanceResource, error) {
-	req, err := http.NewRequest("POST",
-		FullMintURL(ctx, mint,
-			fmt.Sprintf("/balances/%s", id), url.Values{}).String(), nil)
+	req, err := c.newPostRequest(ctx, mint,
+		fmt.Sprintf("/balances/%s", id), nil)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Add("Mint-Protocol-Version", ProtocolVersion)
-	r, err := c.httpClient.Do(req)
+	req.Header.Add("Mint-Protocol-Version", NegotiatedProtocolVersion(mint))
+	if err := SignRequest(ctx, req, mint); err != nil {
+		return nil, errors.Trace(err)
+	}
+	r, err := c.do(ctx, mint, req)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -432,15 +679,16 @@ func (c *Client) PropagateOffer(
 	id string,
 	mint string,
 ) (*OfferResource, error) {
-	req, err := http.NewRequest("POST",
-		FullMintURL(ctx, mint,
-			fmt.Sprintf("/offers/%s", id), url.Values{}).String(), nil)
+	req, err := c.newPostRequest(ctx, mint,
+		fmt.Sprintf("/offers/%s", id), nil)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Add("Mint-Protocol-Version", ProtocolVersion)
-	r, err := c.httpClient.Do(req)
+	req.Header.Add("Mint-Protocol-Version", NegotiatedProtocolVersion(mint))
+	if err := SignRequest(ctx, req, mint); err != nil {
+		return nil, errors.Trace(err)
+	}
+	r, err := c.do(ctx, mint, req)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -476,15 +724,16 @@ func (c *Client) PropagateOperation(
 	id string,
 	mint string,
 ) (*OperationResource, error) {
-	req, err := http.NewRequest("POST",
-		FullMintURL(ctx, mint,
-			fmt.Sprintf("/operations/%s", id), url.Values{}).String(), nil)
+	req, err := c.newPostRequest(ctx, mint,
+		fmt.Sprintf("/operations/%s", id), nil)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Add("Mint-Protocol-Version", ProtocolVersion)
-	r, err := c.httpClient.Do(req)
+	req.Header.Add("Mint-Protocol-Version", NegotiatedProtocolVersion(mint))
+	if err := SignRequest(ctx, req, mint); err != nil {
+		return nil, errors.Trace(err)
+	}
+	r, err := c.do(ctx, mint, req)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -514,6 +763,54 @@ func (c *Client) PropagateOperation(
 	return &operation, nil
 }
 
+// RetrieveTransactionTrace retrieves the spans recorded by the specified mint
+// for a transaction.
+func (c *Client) RetrieveTransactionTrace(
+	ctx context.Context,
+	id string,
+	mint string,
+) (*TraceResource, error) {
+	req, err := http.NewRequest("GET",
+		c.mintURL(ctx,
+			mint, fmt.Sprintf("/transactions/%s/trace", id), url.Values{}).String(),
+		nil)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	req.Header.Add("Mint-Protocol-Version", NegotiatedProtocolVersion(mint))
+	if err := SignRequest(ctx, req, mint); err != nil {
+		return nil, errors.Trace(err)
+	}
+	r, err := c.do(ctx, mint, req)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer r.Body.Close()
+
+	var raw svc.Resp
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	if r.StatusCode != http.StatusOK {
+		var e errors.ConcreteUserError
+		err = raw.Extract("error", &e)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return nil, errors.Trace(ErrMintClient{
+			r.StatusCode, e.ErrCode, e.ErrMessage,
+		})
+	}
+
+	var t TraceResource
+	if err := raw.Extract("trace", &t); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	return &t, nil
+}
+
 // PropagateTransaction propagates a transaction to the specified mint.
 func (c *Client) PropagateTransaction(
 	ctx context.Context,
@@ -521,18 +818,18 @@ func (c *Client) PropagateTransaction(
 	hop int8,
 	mint string,
 ) (*TransactionResource, error) {
-	req, err := http.NewRequest("POST",
-		FullMintURL(ctx, mint,
-			fmt.Sprintf("/transactions/%s", id), url.Values{}).String(),
-		strings.NewReader(url.Values{
+	req, err := c.newPostRequest(ctx, mint,
+		fmt.Sprintf("/transactions/%s", id), svc.Params{
 			"hop": {fmt.Sprintf("%d", hop)},
-		}.Encode()))
+		})
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Add("Mint-Protocol-Version", ProtocolVersion)
-	r, err := c.httpClient.Do(req)
+	req.Header.Add("Mint-Protocol-Version", NegotiatedProtocolVersion(mint))
+	if err := SignRequest(ctx, req, mint); err != nil {
+		return nil, errors.Trace(err)
+	}
+	r, err := c.do(ctx, mint, req)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -584,24 +881,24 @@ func (c *Client) SettleTransaction(
 		mint = &host
 	}
 
-	body := url.Values{}
+	params := svc.Params{}
 	if hop != nil {
-		body["hop"] = []string{fmt.Sprintf("%d", *hop)}
+		params["hop"] = []string{fmt.Sprintf("%d", *hop)}
 	}
 	if secret != nil {
-		body["secret"] = []string{*secret}
+		params["secret"] = []string{*secret}
 	}
 
-	req, err := http.NewRequest("POST",
-		FullMintURL(ctx, *mint,
-			fmt.Sprintf("/transactions/%s/settle", id), url.Values{}).String(),
-		strings.NewReader(body.Encode()))
+	req, err := c.newPostRequest(ctx, *mint,
+		fmt.Sprintf("/transactions/%s/settle", id), params)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Add("Mint-Protocol-Version", ProtocolVersion)
-	r, err := c.httpClient.Do(req)
+	req.Header.Add("Mint-Protocol-Version", NegotiatedProtocolVersion(*mint))
+	if err := SignRequest(ctx, req, *mint); err != nil {
+		return nil, errors.Trace(err)
+	}
+	r, err := c.do(ctx, *mint, req)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -639,19 +936,18 @@ func (c *Client) CancelTransaction(
 	hop int8,
 	mint string,
 ) (*TransactionResource, error) {
-	body := url.Values{
-		"hop": []string{fmt.Sprintf("%d", hop)},
-	}
-	req, err := http.NewRequest("POST",
-		FullMintURL(ctx, mint,
-			fmt.Sprintf("/transactions/%s/cancel", id), url.Values{}).String(),
-		strings.NewReader(body.Encode()))
+	req, err := c.newPostRequest(ctx, mint,
+		fmt.Sprintf("/transactions/%s/cancel", id), svc.Params{
+			"hop": []string{fmt.Sprintf("%d", hop)},
+		})
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Add("Mint-Protocol-Version", ProtocolVersion)
-	r, err := c.httpClient.Do(req)
+	req.Header.Add("Mint-Protocol-Version", NegotiatedProtocolVersion(mint))
+	if err := SignRequest(ctx, req, mint); err != nil {
+		return nil, errors.Trace(err)
+	}
+	r, err := c.do(ctx, mint, req)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
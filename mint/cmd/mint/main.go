@@ -8,7 +8,9 @@ import (
 
 	"github.com/spolu/settle/lib/errors"
 	"github.com/spolu/settle/lib/logging"
+	"github.com/spolu/settle/mint"
 	"github.com/spolu/settle/mint/app"
+	"github.com/spolu/settle/mint/async/task"
 	"github.com/spolu/settle/mint/model"
 )
 
@@ -19,13 +21,39 @@ var dsnFlag string
 
 var hstFlag string
 var prtFlag string
+var burFlag string
+var crfFlag string
+
+var osfFlag string
+
+var ctoFlag string
+var crtFlag string
+var cenFlag string
+var bkfFlag string
+var bkpFlag string
+
+var palFlag string
+var pdlFlag string
+var aalFlag string
+
+var rtlFlag string
+var tprFlag string
+var mtaFlag string
+
+var hmpFlag string
+var hmfFlag string
+
+var lgfFlag string
+var lglFlag string
+var lgoFlag string
 
 var usrFlag string
 var pasFlag string
+var admFlag bool
 
 func init() {
 	flag.StringVar(&actFlag, "action",
-		"run", "The action to perform (run, create_user), default: run")
+		"run", "The action to perform (run, create_user, reconcile_balances), default: run")
 
 	flag.StringVar(&envFlag, "env",
 		"qa", "The environment to run in (qa, production), default: qa")
@@ -35,11 +63,57 @@ func init() {
 		"", "The externally accessible host name of this mint, default: none (required for production)")
 	flag.StringVar(&prtFlag, "port",
 		"", "The port on which the mint will listen, default: 2406 in qa and 2407 in production")
+	flag.StringVar(&burFlag, "api_base_url",
+		"", "The externally accessible base URL of the mint API advertised to other mints, default: derived from the host")
+	flag.StringVar(&crfFlag, "crt_file",
+		"", "The production TLS certificate file whose expiry is checked by /readyz, default: none")
+
+	flag.StringVar(&osfFlag, "offer_suspect_failures",
+		"", "The number of consecutive failures to refresh the offers propagated from a mint after which they are not advertised anymore, default: 3")
+
+	flag.StringVar(&ctoFlag, "client_timeout_ms",
+		"", "The deadline of requests to other mints in milliseconds, default: 10000")
+	flag.StringVar(&crtFlag, "client_retries",
+		"", "The number of retries of idempotent requests to other mints, default: 2")
+	flag.StringVar(&cenFlag, "client_encoding",
+		"", "The encoding of the bodies of requests to other mints (form, json), default: form")
+	flag.StringVar(&bkfFlag, "breaker_failures",
+		"", "The number of consecutive failures to reach a mint after which requests to it fail fast, default: 5")
+	flag.StringVar(&bkpFlag, "breaker_probe_ms",
+		"", "The time after which a mint failing fast is probed again in milliseconds, default: 30000")
+
+	flag.StringVar(&palFlag, "peer_allow",
+		"", "Comma separated list of the mints allowed to propagate objects to this mint and whose offers it crosses, as hosts or wildcard domains (*.example.com), default: all")
+	flag.StringVar(&pdlFlag, "peer_deny",
+		"", "Comma separated list of the mints denied to propagate objects to this mint and whose offers it never crosses, as hosts or wildcard domains (*.example.com), default: none")
+	flag.StringVar(&aalFlag, "api_host_allow",
+		"", "Comma separated list of the hosts, besides their own, that other mints may advertise in the API base URL of their discovery document, as hosts or wildcard domains (*.example.com), default: none")
+
+	flag.StringVar(&rtlFlag, "rate_limits",
+		"", "Comma separated list of rate limits per route group (ip, public, propagation, authenticated) in requests per second with an optional burst (public=20:40), 0 disables a limit, default: ip=100:200,public=20:40,propagation=20:100,authenticated=50:100")
+	flag.StringVar(&mtaFlag, "metrics_addr",
+		"", "The address on which Prometheus metrics are served at /metrics, separately from the API (127.0.0.1:9406), default: none (metrics served on the API)")
+	flag.StringVar(&tprFlag, "trusted_proxies",
+		"", "Comma separated list of the IPs or CIDRs of the proxies whose X-Forwarded-For header is trusted to determine the client IP, default: none")
+
+	flag.StringVar(&hmpFlag, "health_max_pending_tasks",
+		"", "The number of pending tasks above which /readyz reports the mint as not ready, default: 1000")
+	flag.StringVar(&hmfFlag, "health_max_failed_tasks",
+		"", "The number of tasks failed within the last 24 hours above which /readyz reports the mint as not ready, default: 10")
 
 	flag.StringVar(&usrFlag, "username",
 		"foo", "The user name of the user for the create_user action")
 	flag.StringVar(&pasFlag, "password",
 		"bar", "The password of the user for the create_user action")
+	flag.BoolVar(&admFlag, "admin",
+		false, "Whether the user is granted the admin role for the create_user action (an existing user keeps its role otherwise), default: false")
+
+	flag.StringVar(&lgfFlag, "log_format",
+		"", "The format of the logs (text, logfmt, json), default: text")
+	flag.StringVar(&lglFlag, "log_level",
+		"", "The minimum level of the logs (debug, info, warn, error), default: info")
+	flag.StringVar(&lgoFlag, "log_file",
+		"", "The file the logs are appended to, default: standard error")
 
 	if fl := log.Flags(); fl&log.Ltime != 0 {
 		log.SetFlags(fl | log.Lmicroseconds)
@@ -51,16 +125,44 @@ func main() {
 		flag.Parse()
 	}
 
-	ctx, err := app.BackgroundContextFromFlags(
-		envFlag,
-		dsnFlag,
-		hstFlag, prtFlag,
-	)
+	err := logging.Configure(lgfFlag, lglFlag, lgoFlag)
+	if err != nil {
+		log.Fatal(errors.Details(err))
+	}
+
+	ctx, err := app.BackgroundContextFromFlags(app.Config{
+		Env: envFlag,
+		DSN: dsnFlag,
+
+		Host:       hstFlag,
+		Port:       prtFlag,
+		APIBaseURL: burFlag,
+		CrtFile:    crfFlag,
+
+		OfferSuspectFailures: osfFlag,
+
+		ClientTimeoutMs: ctoFlag,
+		ClientRetries:   crtFlag,
+		ClientEncoding:  cenFlag,
+		BreakerFailures: bkfFlag,
+		BreakerProbeMs:  bkpFlag,
+
+		PeerAllowList:    palFlag,
+		PeerDenyList:     pdlFlag,
+		APIHostAllowList: aalFlag,
+
+		RateLimits:     rtlFlag,
+		TrustedProxies: tprFlag,
+		MetricsAddr:    mtaFlag,
+
+		HealthMaxPendingTasks: hmpFlag,
+		HealthMaxFailedTasks:  hmfFlag,
+	})
 	if err != nil {
 		log.Fatal(errors.Details(err))
 	}
 
-	validActions := []string{"run", "create_user"}
+	validActions := []string{"run", "create_user", "reconcile_balances"}
 	switch actFlag {
 	case "run":
 		mux, err := app.Build(ctx)
@@ -72,7 +174,9 @@ func main() {
 			log.Fatal(errors.Details(err))
 		}
 	case "create_user":
-		CreateUser(ctx, usrFlag, pasFlag)
+		CreateUser(ctx, usrFlag, pasFlag, admFlag)
+	case "reconcile_balances":
+		ReconcileBalances(ctx)
 	default:
 		log.Fatalf("Invalid action `%s`, valid actions are: %s",
 			actFlag, strings.Join(validActions, ", "))
@@ -84,27 +188,56 @@ func CreateUser(
 	ctx context.Context,
 	username string,
 	password string,
+	admin bool,
 ) {
+	role := mint.UsRlUser
+	if admin {
+		role = mint.UsRlAdmin
+	}
+
 	user, err := model.LoadUserByUsername(ctx, username)
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	if user != nil {
-		logging.Logf(ctx, "Updating user: %s", username)
+		logging.Info(ctx, "Updating user", "username", username)
 		err := user.UpdatePassword(ctx, password)
 		if err != nil {
 			log.Fatal(errors.Details(err))
 		}
+		// The role of an existing user is only changed to grant the admin role
+		// so that re-running the action without -admin never demotes an admin.
+		if admin {
+			user.Role = mint.UsRlAdmin
+		}
+		user.Status = mint.UsStActive
 		err = user.Save(ctx)
 		if err != nil {
 			log.Fatal(errors.Details(err))
 		}
 	} else {
-		logging.Logf(ctx, "Creating user: %s", username)
-		_, err := model.CreateUser(ctx, username, password)
+		logging.Info(ctx, "Creating user", "username", username)
+		_, err := model.CreateUser(ctx, username, password, role)
 		if err != nil {
 			log.Fatal(errors.Details(err))
 		}
 	}
 }
+
+// ReconcileBalances is a convenience function exposed on the command line to
+// run a reconciliation of the propagated balances on demand.
+func ReconcileBalances(
+	ctx context.Context,
+) {
+	checked, discrepancies, err := task.ReconcilePropagatedBalances(ctx)
+	if err != nil {
+		log.Fatal(errors.Details(err))
+	}
+
+	logging.Info(ctx, "Reconciled balances",
+		"checked", checked, "discrepancies", len(discrepancies))
+	for _, d := range discrepancies {
+		logging.Warn(ctx, "Balance discrepancy", "balance", d.Balance, "type", d.Type)
+	}
+}
@@ -0,0 +1,239 @@
+package mint
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spolu/settle/lib/env"
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/svc"
+)
+
+// cachedDiscovery is a cached discovery document. A nil document indicates
+// that the mint does not serve any discovery document.
+type cachedDiscovery struct {
+	mint    *MintResource
+	fetched time.Time
+}
+
+var discoveries = map[string]cachedDiscovery{}
+var discoveriesMutex = &sync.Mutex{}
+
+// CachedDiscovery returns the cached discovery document for the specified
+// mint host if it was retrieved and has not expired yet.
+func CachedDiscovery(
+	host string,
+) *MintResource {
+	discoveriesMutex.Lock()
+	defer discoveriesMutex.Unlock()
+
+	cached, ok := discoveries[host]
+	expiry := time.Duration(DiscoveryCacheMs) * time.Millisecond
+	if !ok || time.Now().Sub(cached.fetched) >= expiry {
+		return nil
+	}
+	return cached.mint
+}
+
+// Discover retrieves and caches the discovery document of the specified mint.
+// It returns nil if the mint does not serve any discovery document, in which
+// case the default scheme and port are used to contact it.
+func (c *Client) Discover(
+	ctx context.Context,
+	host string,
+) (*MintResource, error) {
+	discoveriesMutex.Lock()
+	cached, ok := discoveries[host]
+	discoveriesMutex.Unlock()
+
+	expiry := time.Duration(DiscoveryCacheMs) * time.Millisecond
+	if ok && time.Now().Sub(cached.fetched) < expiry {
+		return cached.mint, nil
+	}
+
+	req, err := http.NewRequest("GET",
+		DefaultMintURL(ctx, host, DiscoveryPath, url.Values{}).String(), nil)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	req.Header.Add("Mint-Protocol-Version", ProtocolVersion)
+	r, err := c.do(ctx, host, req)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer r.Body.Close()
+
+	var mint *MintResource
+
+	// Mints predating discovery documents do not expose the route at all.
+	if r.StatusCode != http.StatusNotFound {
+		var raw svc.Resp
+		if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+			return nil, errors.Trace(err)
+		}
+
+		if r.StatusCode != http.StatusOK {
+			var e errors.ConcreteUserError
+			err = raw.Extract("error", &e)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			return nil, errors.Trace(ErrMintClient{
+				r.StatusCode, e.ErrCode, e.ErrMessage,
+			})
+		}
+
+		var m MintResource
+		if err := raw.Extract("mint", &m); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if m.Host != host {
+			return nil, errors.Trace(errors.Newf(
+				"Discovery document host mismatch: %s expected %s",
+				m.Host, host))
+		}
+		if m.APIBaseURL != "" {
+			if err := CheckAPIBaseURL(ctx, host, m.APIBaseURL); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		mint = &m
+	}
+
+	discoveriesMutex.Lock()
+	discoveries[host] = cachedDiscovery{mint, time.Now()}
+	discoveriesMutex.Unlock()
+
+	return mint, nil
+}
+
+// CheckAPIBaseURL checks the API base URL advertised by a mint. It must use
+// HTTPS (unless running in QA) and its host must be the host of the mint
+// (on any port) or match one of the rules of the API host allow list, so
+// that a mint can't redirect the requests meant to it to arbitrary hosts.
+func CheckAPIBaseURL(
+	ctx context.Context,
+	host string,
+	apiBaseURL string,
+) error {
+	base, err := url.Parse(apiBaseURL)
+	if err != nil || base.Host == "" {
+		return errors.Trace(errors.Newf(
+			"Invalid API base URL for mint %s: %s", host, apiBaseURL))
+	}
+
+	switch base.Scheme {
+	case "https":
+	case "http":
+		if env.Get(ctx).Environment != env.QA {
+			return errors.Trace(errors.Newf(
+				"Insecure API base URL for mint %s: %s", host, apiBaseURL))
+		}
+	default:
+		return errors.Trace(errors.Newf(
+			"Invalid API base URL scheme for mint %s: %s", host, apiBaseURL))
+	}
+
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	if strings.EqualFold(base.Hostname(), hostname) {
+		return nil
+	}
+	for _, r := range GetAPIHostAllowList(ctx) {
+		if peerRuleMatches(r, base.Host) {
+			return nil
+		}
+	}
+	return errors.Trace(errors.Newf(
+		"API base URL host not allowed for mint %s: %s", host, apiBaseURL))
+}
+
+// Supports returns whether the specified mint advertises support for the
+// provided feature in its discovery document.
+func (c *Client) Supports(
+	ctx context.Context,
+	host string,
+	feature string,
+) (bool, error) {
+	mint, err := c.Discover(ctx, host)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	if mint == nil {
+		return false, nil
+	}
+	for _, f := range mint.Features {
+		if f == feature {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// NegotiatedProtocolVersion returns the highest protocol version supported by
+// both this mint and the specified mint based on its cached discovery
+// document, defaulting to ProtocolVersion if unknown.
+func NegotiatedProtocolVersion(
+	host string,
+) string {
+	m := CachedDiscovery(host)
+	if m == nil {
+		return ProtocolVersion
+	}
+
+	negotiated := ""
+	best := int64(-1)
+	for _, v := range m.ProtocolVersions {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= best {
+			continue
+		}
+		for _, s := range ProtocolVersions {
+			if s == v {
+				negotiated = v
+				best = n
+			}
+		}
+	}
+	if negotiated == "" {
+		return ProtocolVersion
+	}
+	return negotiated
+}
+
+// mintURL attempts to discover the specified mint before constructing the
+// URL to contact it. Discovery failures are logged and the URL falls back to
+// the environment defaults.
+func (c *Client) mintURL(
+	ctx context.Context,
+	host string,
+	path string,
+	query url.Values,
+) *url.URL {
+	if _, err := c.Discover(ctx, host); err != nil {
+		Warn(ctx, "Mint discovery failed", "peer", host, "error", err)
+	}
+	return FullMintURL(ctx, host, path, query)
+}
+
+// apiURL constructs a URL from an API base URL.
+func apiURL(
+	base *url.URL,
+	path string,
+	query url.Values,
+) *url.URL {
+	url := *base
+	url.Path = strings.TrimRight(base.Path, "/") + path
+	url.RawPath = ""
+	url.RawQuery = query.Encode()
+	return &url
+}
@@ -0,0 +1,110 @@
+package endpoint
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/format"
+	"github.com/spolu/settle/lib/ptr"
+	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/model"
+)
+
+const (
+	// EndPtAdminCreateUser creates a new user.
+	EndPtAdminCreateUser EndPtName = "AdminCreateUser"
+)
+
+func init() {
+	registrar[EndPtAdminCreateUser] = NewAdminCreateUser
+}
+
+// AdminCreateUser controls the creation of new users by admins.
+type AdminCreateUser struct {
+	Username string
+	Password string
+	Role     mint.UsRole
+}
+
+// NewAdminCreateUser constructs and initialiezes the endpoint.
+func NewAdminCreateUser(
+	r *http.Request,
+) (Endpoint, error) {
+	return &AdminCreateUser{}, nil
+}
+
+// Validate validates the input parameters.
+func (e *AdminCreateUser) Validate(
+	r *http.Request,
+) error {
+	ctx := r.Context()
+
+	params, err := svc.ParseParams(r)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	username, err := ValidateUsername(ctx, params.Get("username"))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.Username = *username
+
+	password, err := ValidatePassword(ctx, params.Get("password"))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.Password = *password
+
+	e.Role = mint.UsRlUser
+	if role := params.Get("role"); role != "" {
+		switch mint.UsRole(role) {
+		case mint.UsRlUser, mint.UsRlAdmin:
+			e.Role = mint.UsRole(role)
+		default:
+			return errors.Trace(errors.NewUserErrorf(nil,
+				400, "role_invalid",
+				"The role you provided is invalid: %s. Roles must be one of "+
+					"`%s` or `%s`.",
+				role, mint.UsRlUser, mint.UsRlAdmin,
+			))
+		}
+	}
+
+	return nil
+}
+
+// Execute executes the endpoint.
+func (e *AdminCreateUser) Execute(
+	ctx context.Context,
+) (*int, *svc.Resp, error) {
+	ctx = db.Begin(ctx, "mint")
+	defer db.LoggedRollback(ctx)
+
+	user, err := model.CreateUser(ctx,
+		e.Username,
+		e.Password,
+		e.Role,
+	)
+	if err != nil {
+		switch err := errors.Cause(err).(type) {
+		case model.ErrUniqueConstraintViolation:
+			return nil, nil, errors.Trace(errors.NewUserErrorf(err,
+				400, "user_already_exists",
+				"A user with the same username already exists: %s.",
+				e.Username,
+			))
+		default:
+			return nil, nil, errors.Trace(err) // 500
+		}
+	}
+
+	db.Commit(ctx)
+
+	return ptr.Int(http.StatusCreated), &svc.Resp{
+		"user": format.JSONPtr(model.NewUserResource(ctx, user)),
+	}, nil
+}
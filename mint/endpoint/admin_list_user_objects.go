@@ -0,0 +1,137 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/ptr"
+	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/model"
+	"goji.io/pat"
+)
+
+const (
+	// EndPtAdminListUserAssets lists the assets of a user.
+	EndPtAdminListUserAssets EndPtName = "AdminListUserAssets"
+	// EndPtAdminListUserOffers lists the canonical offers of a user.
+	EndPtAdminListUserOffers EndPtName = "AdminListUserOffers"
+)
+
+func init() {
+	registrar[EndPtAdminListUserAssets] = NewAdminListUserAssets
+	registrar[EndPtAdminListUserOffers] = NewAdminListUserOffers
+}
+
+// AdminListUserObjects returns a list of assets or offers owned by a user.
+type AdminListUserObjects struct {
+	ListEndpoint
+	Offers   bool
+	Username string
+	Owner    string
+}
+
+// NewAdminListUserAssets constructs and initialiezes the endpoint.
+func NewAdminListUserAssets(
+	r *http.Request,
+) (Endpoint, error) {
+	return &AdminListUserObjects{
+		ListEndpoint: ListEndpoint{},
+	}, nil
+}
+
+// NewAdminListUserOffers constructs and initialiezes the endpoint.
+func NewAdminListUserOffers(
+	r *http.Request,
+) (Endpoint, error) {
+	return &AdminListUserObjects{
+		ListEndpoint: ListEndpoint{},
+		Offers:       true,
+	}, nil
+}
+
+// Validate validates the input parameters.
+func (e *AdminListUserObjects) Validate(
+	r *http.Request,
+) error {
+	ctx := r.Context()
+
+	username, err := ValidateUsername(ctx, pat.Param(r, "user"))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.Username = *username
+	e.Owner = fmt.Sprintf("%s@%s", e.Username, mint.GetHost(ctx))
+
+	return e.ListEndpoint.Validate(r)
+}
+
+// Execute executes the endpoint.
+func (e *AdminListUserObjects) Execute(
+	ctx context.Context,
+) (*int, *svc.Resp, error) {
+	ctx = db.Begin(ctx, "mint")
+	defer db.LoggedRollback(ctx)
+
+	user, err := model.LoadUserByUsername(ctx, e.Username)
+	if err != nil {
+		return nil, nil, errors.Trace(err) // 500
+	} else if user == nil {
+		return nil, nil, errors.Trace(errors.NewUserErrorf(nil,
+			404, "user_not_found",
+			"The user you are trying to retrieve does not exist: %s.",
+			e.Username,
+		))
+	}
+
+	if e.Offers {
+		offers, more, err := model.LoadCanonicalOfferListByOwner(ctx,
+			e.ListEndpoint.Page(),
+			model.OfferFilter{},
+			e.Owner,
+		)
+		if err != nil {
+			return nil, nil, errors.Trace(err) // 500
+		}
+
+		db.Commit(ctx)
+
+		l := []mint.OfferResource{}
+		cursors := []model.Cursor{}
+		for _, o := range offers {
+			o := o
+			l = append(l, model.NewOfferResource(ctx, &o))
+			cursors = append(cursors, o.Cursor(e.Sort))
+		}
+
+		return ptr.Int(http.StatusOK), e.ListEndpoint.Resp(
+			"offers", l, cursors, more,
+		), nil
+	}
+
+	assets, more, err := model.LoadAssetListByOwner(ctx,
+		e.ListEndpoint.Page(),
+		model.AssetFilter{},
+		e.Owner,
+	)
+	if err != nil {
+		return nil, nil, errors.Trace(err) // 500
+	}
+
+	db.Commit(ctx)
+
+	l := []mint.AssetResource{}
+	cursors := []model.Cursor{}
+	for _, a := range assets {
+		a := a
+		l = append(l, model.NewAssetResource(ctx, &a))
+		cursors = append(cursors, a.Cursor())
+	}
+
+	return ptr.Int(http.StatusOK), e.ListEndpoint.Resp(
+		"assets", l, cursors, more,
+	), nil
+}
@@ -0,0 +1,72 @@
+package endpoint
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/ptr"
+	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/model"
+)
+
+const (
+	// EndPtAdminListUsers lists the users of the mint.
+	EndPtAdminListUsers EndPtName = "AdminListUsers"
+)
+
+func init() {
+	registrar[EndPtAdminListUsers] = NewAdminListUsers
+}
+
+// AdminListUsers returns a list of users.
+type AdminListUsers struct {
+	ListEndpoint
+}
+
+// NewAdminListUsers constructs and initialiezes the endpoint.
+func NewAdminListUsers(
+	r *http.Request,
+) (Endpoint, error) {
+	return &AdminListUsers{
+		ListEndpoint: ListEndpoint{},
+	}, nil
+}
+
+// Validate validates the input parameters.
+func (e *AdminListUsers) Validate(
+	r *http.Request,
+) error {
+	return e.ListEndpoint.Validate(r)
+}
+
+// Execute executes the endpoint.
+func (e *AdminListUsers) Execute(
+	ctx context.Context,
+) (*int, *svc.Resp, error) {
+	ctx = db.Begin(ctx, "mint")
+	defer db.LoggedRollback(ctx)
+
+	users, more, err := model.LoadUserList(ctx,
+		e.ListEndpoint.Page(),
+	)
+	if err != nil {
+		return nil, nil, errors.Trace(err) // 500
+	}
+
+	db.Commit(ctx)
+
+	l := []mint.UserResource{}
+	cursors := []model.Cursor{}
+	for _, u := range users {
+		u := u
+		l = append(l, model.NewUserResource(ctx, &u))
+		cursors = append(cursors, u.Cursor())
+	}
+
+	return ptr.Int(http.StatusOK), e.ListEndpoint.Resp(
+		"users", l, cursors, more,
+	), nil
+}
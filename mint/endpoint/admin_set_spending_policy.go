@@ -0,0 +1,151 @@
+package endpoint
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/format"
+	"github.com/spolu/settle/lib/ptr"
+	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/mint/model"
+	"goji.io/pat"
+)
+
+const (
+	// EndPtAdminSetSpendingPolicy sets the spending policy of a user for an
+	// asset.
+	EndPtAdminSetSpendingPolicy EndPtName = "AdminSetSpendingPolicy"
+)
+
+func init() {
+	registrar[EndPtAdminSetSpendingPolicy] = NewAdminSetSpendingPolicy
+}
+
+// AdminSetSpendingPolicy sets the spending policy of a user for an asset.
+// Omitted limits are not enforced.
+type AdminSetSpendingPolicy struct {
+	Username string
+	Asset    string
+
+	PerTransaction *big.Int
+	PerDay         *big.Int
+	PerWindow      *big.Int
+	WindowMs       *int64
+}
+
+// NewAdminSetSpendingPolicy constructs and initialiezes the endpoint.
+func NewAdminSetSpendingPolicy(
+	r *http.Request,
+) (Endpoint, error) {
+	return &AdminSetSpendingPolicy{}, nil
+}
+
+// Validate validates the input parameters.
+func (e *AdminSetSpendingPolicy) Validate(
+	r *http.Request,
+) error {
+	ctx := r.Context()
+
+	params, err := svc.ParseParams(r)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	username, err := ValidateUsername(ctx, pat.Param(r, "user"))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.Username = *username
+
+	asset, err := ValidateAsset(ctx, params.Get("asset"))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.Asset = asset.Name
+
+	for _, l := range []struct {
+		param string
+		limit **big.Int
+	}{
+		{"per_transaction", &e.PerTransaction},
+		{"per_day", &e.PerDay},
+		{"per_window", &e.PerWindow},
+	} {
+		if v := params.Get(l.param); v != "" {
+			*l.limit, err = ValidateAmount(ctx, v)
+			if err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+
+	if window := params.Get("window_ms"); window != "" {
+		ms, err := strconv.ParseInt(window, 10, 64)
+		if err != nil || ms <= 0 || ms > int64(30*24*time.Hour/time.Millisecond) {
+			return errors.Trace(errors.NewUserErrorf(err,
+				400, "window_invalid",
+				"The window you provided is invalid: %s. Windows "+
+					"are expressed in ms and must be positive and at most 30 "+
+					"days.",
+				window,
+			))
+		}
+		e.WindowMs = &ms
+	}
+
+	if (e.PerWindow == nil) != (e.WindowMs == nil) {
+		return errors.Trace(errors.NewUserErrorf(nil,
+			400, "window_invalid",
+			"The window limit must be specified with both the "+
+				"`per_window` and `window_ms` parameters.",
+		))
+	}
+
+	return nil
+}
+
+// Execute executes the endpoint.
+func (e *AdminSetSpendingPolicy) Execute(
+	ctx context.Context,
+) (*int, *svc.Resp, error) {
+	ctx = db.Begin(ctx, "mint")
+	defer db.LoggedRollback(ctx)
+
+	user, err := model.LoadUserByUsername(ctx, e.Username)
+	if err != nil {
+		return nil, nil, errors.Trace(err) // 500
+	} else if user == nil {
+		return nil, nil, errors.Trace(errors.NewUserErrorf(nil,
+			404, "user_not_found",
+			"The user you are trying to update does not exist: %s.",
+			e.Username,
+		))
+	}
+
+	policy, err := model.CreateOrUpdateSpendingPolicy(ctx,
+		user.Token,
+		e.Asset,
+		e.PerTransaction,
+		e.PerDay,
+		e.PerWindow,
+		e.WindowMs,
+	)
+	if err != nil {
+		return nil, nil, errors.Trace(err) // 500
+	}
+
+	daySpent, windowSpent := policy.Usage(time.Now())
+
+	db.Commit(ctx)
+
+	return ptr.Int(http.StatusOK), &svc.Resp{
+		"spending_policy": format.JSONPtr(model.NewSpendingPolicyResource(ctx,
+			policy, daySpent, windowSpent,
+		)),
+	}, nil
+}
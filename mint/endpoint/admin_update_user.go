@@ -0,0 +1,150 @@
+package endpoint
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/format"
+	"github.com/spolu/settle/lib/ptr"
+	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/lib/authentication"
+	"github.com/spolu/settle/mint/model"
+	"goji.io/pat"
+)
+
+const (
+	// EndPtAdminDisableUser disables a user.
+	EndPtAdminDisableUser EndPtName = "AdminDisableUser"
+	// EndPtAdminEnableUser enables a user.
+	EndPtAdminEnableUser EndPtName = "AdminEnableUser"
+	// EndPtAdminResetPassword resets the password of a user.
+	EndPtAdminResetPassword EndPtName = "AdminResetPassword"
+)
+
+func init() {
+	registrar[EndPtAdminDisableUser] = NewAdminDisableUser
+	registrar[EndPtAdminEnableUser] = NewAdminEnableUser
+	registrar[EndPtAdminResetPassword] = NewAdminResetPassword
+}
+
+// AdminUpdateUser updates the status or password of a user.
+type AdminUpdateUser struct {
+	Admin    *model.User
+	Username string
+
+	Status   *mint.UsStatus
+	Password *string
+}
+
+// NewAdminDisableUser constructs and initialiezes the endpoint.
+func NewAdminDisableUser(
+	r *http.Request,
+) (Endpoint, error) {
+	status := mint.UsStDisabled
+	return &AdminUpdateUser{
+		Status: &status,
+	}, nil
+}
+
+// NewAdminEnableUser constructs and initialiezes the endpoint.
+func NewAdminEnableUser(
+	r *http.Request,
+) (Endpoint, error) {
+	status := mint.UsStActive
+	return &AdminUpdateUser{
+		Status: &status,
+	}, nil
+}
+
+// NewAdminResetPassword constructs and initialiezes the endpoint.
+func NewAdminResetPassword(
+	r *http.Request,
+) (Endpoint, error) {
+	return &AdminUpdateUser{}, nil
+}
+
+// Validate validates the input parameters.
+func (e *AdminUpdateUser) Validate(
+	r *http.Request,
+) error {
+	ctx := r.Context()
+
+	params, err := svc.ParseParams(r)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	e.Admin = authentication.Get(ctx).User
+
+	username, err := ValidateUsername(ctx, pat.Param(r, "user"))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.Username = *username
+
+	if e.Status == nil {
+		e.Password, err = ValidatePassword(ctx, params.Get("password"))
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	// Prevent admins from locking themselves out.
+	if e.Status != nil && *e.Status == mint.UsStDisabled &&
+		e.Username == e.Admin.Username {
+		return errors.Trace(errors.NewUserErrorf(nil,
+			400, "user_invalid",
+			"You cannot disable the user you are currently authenticated "+
+				"with: %s.",
+			e.Username,
+		))
+	}
+
+	return nil
+}
+
+// Execute executes the endpoint.
+func (e *AdminUpdateUser) Execute(
+	ctx context.Context,
+) (*int, *svc.Resp, error) {
+	ctx = db.Begin(ctx, "mint")
+	defer db.LoggedRollback(ctx)
+
+	user, err := model.LoadUserByUsername(ctx, e.Username)
+	if err != nil {
+		return nil, nil, errors.Trace(err) // 500
+	} else if user == nil {
+		return nil, nil, errors.Trace(errors.NewUserErrorf(nil,
+			404, "user_not_found",
+			"The user you are trying to update does not exist: %s.",
+			e.Username,
+		))
+	}
+
+	if e.Status != nil {
+		user.Status = *e.Status
+	}
+	if e.Password != nil {
+		err = user.UpdatePassword(ctx, *e.Password)
+		if err != nil {
+			return nil, nil, errors.Trace(err) // 500
+		}
+	}
+
+	err = user.Save(ctx)
+	if err != nil {
+		return nil, nil, errors.Trace(err) // 500
+	}
+
+	db.Commit(ctx)
+
+	mint.Info(ctx, "Updated user",
+		"admin", e.Admin.Username, "user", user.Username, "status", user.Status)
+
+	return ptr.Int(http.StatusOK), &svc.Resp{
+		"user": format.JSONPtr(model.NewUserResource(ctx, user)),
+	}, nil
+}
@@ -12,11 +12,13 @@ import (
 	"github.com/spolu/settle/lib/format"
 	"github.com/spolu/settle/lib/ptr"
 	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/lib/trace"
 	"github.com/spolu/settle/mint"
 	"github.com/spolu/settle/mint/async"
 	"github.com/spolu/settle/mint/async/task"
 	"github.com/spolu/settle/mint/lib/authentication"
 	"github.com/spolu/settle/mint/lib/plan"
+	"github.com/spolu/settle/mint/lib/signature"
 	"github.com/spolu/settle/mint/model"
 	"goji.io/pat"
 )
@@ -67,10 +69,15 @@ func (e *CancelTransaction) Validate(
 ) error {
 	ctx := r.Context()
 
+	params, err := svc.ParseParams(r)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
 	switch authentication.Get(ctx).Status {
 	case authentication.AutStSkipped:
 		// Validate hop.
-		hop, err := ValidateHop(ctx, r.PostFormValue("hop"))
+		hop, err := ValidateHop(ctx, params.Get("hop"))
 		if err != nil {
 			return errors.Trace(err)
 		}
@@ -93,15 +100,31 @@ func (e *CancelTransaction) Validate(
 func (e *CancelTransaction) Execute(
 	ctx context.Context,
 ) (*int, *svc.Resp, error) {
+	ctx, span := trace.Start(ctx, "transaction.cancel")
+
+	var code *int
+	var resp *svc.Resp
+	var err error
 	switch authentication.Get(ctx).Status {
 	case authentication.AutStSkipped:
-		return e.ExecutePropagated(ctx)
+		code, resp, err = e.ExecutePropagated(ctx)
 	case authentication.AutStSucceeded:
-		return e.ExecuteAuthenticated(ctx)
+		code, resp, err = e.ExecuteAuthenticated(ctx)
+	default:
+		err = errors.Trace(errors.Newf(
+			"Authentication status not expected: %s",
+			authentication.Get(ctx).Status))
 	}
-	return nil, nil, errors.Trace(errors.Newf(
-		"Authentication status not expected: %s",
-		authentication.Get(ctx).Status))
+
+	// The trace is linked to the transaction so that its spans are returned
+	// by RetrieveTransactionTrace.
+	if e.ID != "" {
+		span.Set("transaction", e.ID, "hop", e.Hop)
+		trace.Link(ctx, e.ID)
+	}
+	span.Finish(err)
+
+	return code, resp, err
 }
 
 // ExecuteAuthenticated executes the authenticated cancellation of a
@@ -221,9 +244,8 @@ func (e *CancelTransaction) ExecuteAuthenticated(
 		// If cancellation propagation failed we log it and trigger an
 		// asyncrhonous one. In any case the node before us will check on us
 		// before attempting to settle as well.
-		mint.Logf(ctx,
-			"Cancellation propagation failed: transaction=%s hop=%d error=%s",
-			e.ID, e.Hop, err.Error())
+		mint.Warn(ctx, "Cancellation propagation failed",
+			"transaction", e.ID, "hop", e.Hop, "error", err)
 		err = async.Queue(ctx,
 			task.NewPropagateCancellation(ctx,
 				time.Now(), fmt.Sprintf("%s|%d", e.ID, e.Hop)))
@@ -295,6 +317,16 @@ func (e *CancelTransaction) ExecutePropagated(
 		))
 	}
 
+	// Cancellations are propagated hop by hop starting from the canonical
+	// mint (the first hop), check that the request emanates from the next hop
+	// unless this mint cancels its own hops (when the transaction expires or
+	// should be canceled instead of settled).
+	if signature.Get(ctx).Host != mint.GetHost(ctx) {
+		if err := signature.CheckPeer(ctx, e.Plan.Emitter(e.Hop)); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+	}
+
 	// Check cancelation can be performed (either we're the last node, or the
 	// node after us has already canceled the transaction, or the node after us
 	// does not know about the transaction).
@@ -347,9 +379,8 @@ func (e *CancelTransaction) ExecutePropagated(
 		// If cancellation propagation failed we log it and trigger an
 		// asyncrhonous one. In any case the node before us will check on us
 		// before attempting to settle as well.
-		mint.Logf(ctx,
-			"Cancellation propagation failed: transaction=%s hop=%d error=%s",
-			e.ID, e.Hop, err.Error())
+		mint.Warn(ctx, "Cancellation propagation failed",
+			"transaction", e.ID, "hop", e.Hop, "error", err)
 		err = async.Queue(ctx,
 			task.NewPropagateCancellation(ctx,
 				time.Now(), fmt.Sprintf("%s|%d", e.ID, e.Hop)))
@@ -377,8 +408,8 @@ func (e *CancelTransaction) Cancel(
 	}
 
 	h := e.Plan.Hops[e.Hop]
-	mint.Logf(ctx,
-		"Executing cancellation plan: transaction=%s hop=%d", e.ID, e.Hop)
+	mint.Info(ctx, "Executing cancellation plan",
+		"transaction", e.ID, "hop", e.Hop)
 
 	// Cancel the OpAction (should always be defined)
 	if h.OpAction != nil {
@@ -393,13 +424,12 @@ func (e *CancelTransaction) Cancel(
 		}
 
 		if op.Status == mint.TxStCanceled {
-			mint.Logf(ctx,
-				"Skipped operation: id=%s[%s] created=%q propagation=%s "+
-					"asset=%s source=%s destination=%s amount=%s "+
-					"status=%s transaction=%s",
-				op.Owner, op.Token, op.Created, op.Propagation, op.Asset,
-				op.Source, op.Destination, (*big.Int)(&op.Amount).String(),
-				op.Status, *op.Transaction)
+			mint.Info(ctx, "Skipped operation",
+				"id", op.ID(),
+				"created", op.Created, "propagation", op.Propagation,
+				"asset", op.Asset, "source", op.Source,
+				"destination", op.Destination, "amount", (*big.Int)(&op.Amount),
+				"status", op.Status, "transaction", *op.Transaction)
 
 		} else {
 			a := h.OpAction
@@ -455,13 +485,12 @@ func (e *CancelTransaction) Cancel(
 				return errors.Trace(err)
 			}
 
-			mint.Logf(ctx,
-				"Canceled operation: id=%s[%s] created=%q propagation=%s "+
-					"asset=%s source=%s destination=%s amount=%s "+
-					"status=%s transaction=%s",
-				op.Owner, op.Token, op.Created, op.Propagation, op.Asset,
-				op.Source, op.Destination, (*big.Int)(&op.Amount).String(),
-				op.Status, *op.Transaction)
+			mint.Info(ctx, "Canceled operation",
+				"id", op.ID(),
+				"created", op.Created, "propagation", op.Propagation,
+				"asset", op.Asset, "source", op.Source,
+				"destination", op.Destination, "amount", (*big.Int)(&op.Amount),
+				"status", op.Status, "transaction", *op.Transaction)
 		}
 	}
 
@@ -477,11 +506,11 @@ func (e *CancelTransaction) Cancel(
 		}
 
 		if cr.Status == mint.TxStSettled {
-			mint.Logf(ctx,
-				"Skipped crossing: id=%s[%s] created=%q offer=%s amount=%s "+
-					"status=%s transaction=%s",
-				cr.Owner, cr.Token, cr.Created, cr.Offer,
-				(*big.Int)(&cr.Amount).String(), cr.Status, cr.Transaction)
+			mint.Info(ctx, "Skipped crossing",
+				"id", cr.ID(),
+				"created", cr.Created, "offer", cr.Offer,
+				"amount", (*big.Int)(&cr.Amount), "status", cr.Status,
+				"transaction", cr.Transaction)
 		} else {
 			a := h.CrAction
 
@@ -526,11 +555,11 @@ func (e *CancelTransaction) Cancel(
 				return errors.Trace(err)
 			}
 
-			mint.Logf(ctx,
-				"Canceled crossing: id=%s[%s] created=%q offer=%s amount=%s "+
-					"status=%s transaction=%s",
-				cr.Owner, cr.Token, cr.Created, cr.Offer,
-				(*big.Int)(&cr.Amount).String(), cr.Status, cr.Transaction)
+			mint.Info(ctx, "Canceled crossing",
+				"id", cr.ID(),
+				"created", cr.Created, "offer", cr.Offer,
+				"amount", (*big.Int)(&cr.Amount), "status", cr.Status,
+				"transaction", cr.Transaction)
 		}
 	}
 
@@ -545,11 +574,13 @@ func (e *CancelTransaction) Propagate(
 	if int(e.Hop)-1 >= 0 {
 		m := e.Plan.Hops[e.Hop-1].Mint
 
-		mint.Logf(ctx,
-			"Propagating cancellation: transaction=%s hop=%d mint=%s",
-			e.ID, e.Hop, m)
+		mint.Info(ctx, "Propagating cancellation",
+			"transaction", e.ID, "hop", e.Hop, "mint", m)
 
+		ctx, span := trace.Start(ctx, "transaction.propagate_cancellation",
+			"transaction", e.ID, "hop", e.Hop-1, "peer", m)
 		_, err := e.Client.CancelTransaction(ctx, e.ID, e.Hop-1, m)
+		span.Finish(err)
 		if err != nil {
 			return errors.Trace(err)
 		}
@@ -0,0 +1,153 @@
+package endpoint
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/format"
+	"github.com/spolu/settle/lib/ptr"
+	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/lib/authentication"
+	"github.com/spolu/settle/mint/model"
+)
+
+const (
+	// EndPtCreateAPIKey creates a new API key.
+	EndPtCreateAPIKey EndPtName = "CreateAPIKey"
+)
+
+func init() {
+	registrar[EndPtCreateAPIKey] = NewCreateAPIKey
+}
+
+// CreateAPIKey controls the creation of new API keys.
+type CreateAPIKey struct {
+	User     *model.User
+	Name     string
+	Scopes   []mint.KyScope
+	PayAsset *string
+	PayLimit *big.Int
+	Expires  *time.Time
+}
+
+// NewCreateAPIKey constructs and initialiezes the endpoint.
+func NewCreateAPIKey(
+	r *http.Request,
+) (Endpoint, error) {
+	return &CreateAPIKey{}, nil
+}
+
+// Validate validates the input parameters.
+func (e *CreateAPIKey) Validate(
+	r *http.Request,
+) error {
+	ctx := r.Context()
+
+	params, err := svc.ParseParams(r)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	e.User = authentication.Get(ctx).User
+
+	e.Name = strings.TrimSpace(params.Get("name"))
+	if e.Name == "" {
+		return errors.Trace(errors.NewUserErrorf(nil,
+			400, "name_invalid",
+			"You must provide a name for the API key.",
+		))
+	}
+
+	// Validate scopes.
+	for _, s := range params.List("scopes") {
+		switch mint.KyScope(s) {
+		case mint.KyScRead, mint.KyScCreateOffers, mint.KyScPay:
+			e.Scopes = append(e.Scopes, mint.KyScope(s))
+		default:
+			return errors.Trace(errors.NewUserErrorf(nil,
+				400, "scope_invalid",
+				"The API key scope you provided is invalid: %s. Scopes must "+
+					"be one of `%s`, `%s` or `%s`.",
+				s, mint.KyScRead, mint.KyScCreateOffers, mint.KyScPay,
+			))
+		}
+	}
+	if len(e.Scopes) == 0 {
+		return errors.Trace(errors.NewUserErrorf(nil,
+			400, "scope_invalid",
+			"You must provide at least one scope for the API key.",
+		))
+	}
+
+	asset := params.Get("pay_asset")
+	limit := params.Get("pay_limit")
+	if (asset == "") != (limit == "") {
+		return errors.Trace(errors.NewUserErrorf(nil,
+			400, "pay_limit_invalid",
+			"The pay limit of an API key must be specified with both the "+
+				"`pay_asset` and `pay_limit` parameters.",
+		))
+	}
+	if asset != "" {
+		a, err := ValidateAsset(ctx, asset)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		e.PayAsset = ptr.Str(a.Name)
+
+		e.PayLimit, err = ValidateAmount(ctx, limit)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	if expires := params.Get("expires"); expires != "" {
+		ms, err := strconv.ParseInt(expires, 10, 64)
+		if err != nil || ms*mint.TimeResolutionNs < time.Now().UnixNano() {
+			return errors.Trace(errors.NewUserErrorf(err,
+				400, "expires_invalid",
+				"The expiry date you provided is invalid: %s. Expiry dates "+
+					"are expressed in ms since epoch and must be in the "+
+					"future.",
+				expires,
+			))
+		}
+		t := time.Unix(0, ms*mint.TimeResolutionNs).UTC()
+		e.Expires = &t
+	}
+
+	return nil
+}
+
+// Execute executes the endpoint.
+func (e *CreateAPIKey) Execute(
+	ctx context.Context,
+) (*int, *svc.Resp, error) {
+	ctx = db.Begin(ctx, "mint")
+	defer db.LoggedRollback(ctx)
+
+	key, full, err := model.CreateAPIKey(ctx,
+		e.User,
+		e.Name,
+		e.Scopes,
+		e.PayAsset,
+		e.PayLimit,
+		e.Expires,
+	)
+	if err != nil {
+		return nil, nil, errors.Trace(err) // 500
+	}
+
+	db.Commit(ctx)
+
+	return ptr.Int(http.StatusCreated), &svc.Resp{
+		"api_key": format.JSONPtr(model.NewAPIKeyResource(ctx, key, &full)),
+	}, nil
+}
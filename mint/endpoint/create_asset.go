@@ -45,10 +45,15 @@ func (e *CreateAsset) Validate(
 ) error {
 	ctx := r.Context()
 
+	params, err := svc.ParseParams(r)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
 	e.Owner = fmt.Sprintf("%s@%s",
 		authentication.Get(ctx).User.Username, mint.GetHost(ctx))
 
-	code := r.PostFormValue("code")
+	code := params.Get("code")
 	if !model.AssetCodeRegexp.MatchString(code) {
 		return errors.Trace(errors.NewUserErrorf(nil,
 			400, "code_invalid",
@@ -59,7 +64,7 @@ func (e *CreateAsset) Validate(
 	}
 	e.Code = code
 
-	scale, err := strconv.ParseInt(r.PostFormValue("scale"), 10, 8)
+	scale, err := strconv.ParseInt(params.Get("scale"), 10, 8)
 	if err != nil ||
 		(int8(scale) < model.AssetMinScale ||
 			int8(scale) > model.AssetMaxScale) {
@@ -67,7 +72,7 @@ func (e *CreateAsset) Validate(
 			400, "scale_invalid",
 			"The asset scale provided is invalid: %s. Asset scales must be "+
 				"integers between %d and %d.",
-			r.PostFormValue("scale"), model.AssetMinScale, model.AssetMaxScale,
+			params.Get("scale"), model.AssetMinScale, model.AssetMaxScale,
 		))
 	}
 	e.Scale = int8(scale)
@@ -66,11 +66,16 @@ func (e *CreateOffer) Validate(
 ) error {
 	ctx := r.Context()
 
+	params, err := svc.ParseParams(r)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
 	e.Owner = fmt.Sprintf("%s@%s",
 		authentication.Get(ctx).User.Username, mint.GetHost(ctx))
 
 	// Validate asset pair.
-	pair, err := ValidateAssetPair(ctx, r.PostFormValue("pair"))
+	pair, err := ValidateAssetPair(ctx, params.Get("pair"))
 	if err != nil {
 		return errors.Trace(err) // 400
 	}
@@ -95,7 +100,7 @@ func (e *CreateOffer) Validate(
 	}
 
 	// Validate price.
-	basePrice, quotePrice, err := ValidatePrice(ctx, r.PostFormValue("price"))
+	basePrice, quotePrice, err := ValidatePrice(ctx, params.Get("price"))
 	if err != nil {
 		return errors.Trace(err) // 400
 	}
@@ -103,7 +108,7 @@ func (e *CreateOffer) Validate(
 	e.QuotePrice = *quotePrice
 
 	// Validate amount.
-	amount, err := ValidateAmount(ctx, r.PostFormValue("amount"))
+	amount, err := ValidateAmount(ctx, params.Get("amount"))
 	if err != nil {
 		return errors.Trace(err) // 400
 	}
@@ -147,15 +152,13 @@ func (e *CreateOffer) Execute(
 		return nil, nil, errors.Trace(err) // 500
 	}
 
-	mint.Logf(ctx,
-		"Created offer: id=%s[%s] created=%q propagation=%s "+
-			"base_asset=%s quote_asset=%s base_price=%s quote_price=%s "+
-			"amount=%s status=%s remainder=%s",
-		of.Owner, of.Token, of.Created, of.Propagation, of.BaseAsset,
-		of.QuoteAsset,
-		(*big.Int)(&of.BasePrice).String(), (*big.Int)(&of.QuotePrice),
-		(*big.Int)(&of.Amount).String(), of.Status,
-		(*big.Int)(&of.Remainder).String())
+	mint.Info(ctx, "Created offer",
+		"id", of.ID(), "created", of.Created,
+		"propagation", of.Propagation, "base_asset", of.BaseAsset,
+		"quote_asset", of.QuoteAsset, "base_price", (*big.Int)(&of.BasePrice),
+		"quote_price", (*big.Int)(&of.QuotePrice),
+		"amount", (*big.Int)(&of.Amount), "status", of.Status,
+		"remainder", (*big.Int)(&of.Remainder))
 
 	err = async.Queue(ctx, task.NewPropagateOffer(ctx, time.Now(), of.ID()))
 	if err != nil {
@@ -14,11 +14,13 @@ import (
 	"github.com/spolu/settle/lib/format"
 	"github.com/spolu/settle/lib/ptr"
 	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/lib/trace"
 	"github.com/spolu/settle/mint"
 	"github.com/spolu/settle/mint/async"
 	"github.com/spolu/settle/mint/async/task"
 	"github.com/spolu/settle/mint/lib/authentication"
 	"github.com/spolu/settle/mint/lib/plan"
+	"github.com/spolu/settle/mint/lib/signature"
 	"github.com/spolu/settle/mint/model"
 )
 
@@ -72,6 +74,11 @@ func (e *CreateTransaction) Validate(
 ) error {
 	ctx := r.Context()
 
+	params, err := svc.ParseParams(r)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
 	switch authentication.Get(ctx).Status {
 	case authentication.AutStSkipped:
 		// Validate id.
@@ -83,7 +90,7 @@ func (e *CreateTransaction) Validate(
 		e.Owner = *owner
 
 		// Validate hop.
-		hop, err := ValidateHop(ctx, r.PostFormValue("hop"))
+		hop, err := ValidateHop(ctx, params.Get("hop"))
 		if err != nil {
 			return errors.Trace(err)
 		}
@@ -96,7 +103,7 @@ func (e *CreateTransaction) Validate(
 		e.Hop = int8(0)
 
 		// Validate asset pair.
-		pair, err := ValidateAssetPair(ctx, r.PostFormValue("pair"))
+		pair, err := ValidateAssetPair(ctx, params.Get("pair"))
 		if err != nil {
 			return errors.Trace(err) // 400
 		}
@@ -104,14 +111,14 @@ func (e *CreateTransaction) Validate(
 		e.QuoteAsset = pair[1].Name
 
 		// Validate amount.
-		amount, err := ValidateAmount(ctx, r.PostFormValue("amount"))
+		amount, err := ValidateAmount(ctx, params.Get("amount"))
 		if err != nil {
 			return errors.Trace(err)
 		}
 		e.Amount = *amount
 
 		// Validate destination.
-		dstAddress, err := mint.NormalizedAddress(ctx, r.PostFormValue("destination"))
+		dstAddress, err := mint.NormalizedAddress(ctx, params.Get("destination"))
 		if err != nil {
 			return errors.Trace(errors.NewUserErrorf(err,
 				400, "destination_invalid",
@@ -122,13 +129,7 @@ func (e *CreateTransaction) Validate(
 		e.Destination = dstAddress
 
 		// Validate path.
-		if r.PostForm == nil {
-			err := r.ParseMultipartForm(defaultMaxMemory)
-			if err != nil {
-				return errors.Trace(err) // 500
-			}
-		}
-		path, err := ValidatePath(ctx, r.PostForm["path[]"])
+		path, err := ValidatePath(ctx, params.List("path"))
 		if err != nil {
 			return errors.Trace(err)
 		}
@@ -142,15 +143,31 @@ func (e *CreateTransaction) Validate(
 func (e *CreateTransaction) Execute(
 	ctx context.Context,
 ) (*int, *svc.Resp, error) {
+	ctx, span := trace.Start(ctx, "transaction.create")
+
+	var code *int
+	var resp *svc.Resp
+	var err error
 	switch authentication.Get(ctx).Status {
 	case authentication.AutStSkipped:
-		return e.ExecutePropagated(ctx)
+		code, resp, err = e.ExecutePropagated(ctx)
 	case authentication.AutStSucceeded:
-		return e.ExecuteCanonical(ctx)
+		code, resp, err = e.ExecuteCanonical(ctx)
+	default:
+		err = errors.Trace(errors.Newf(
+			"Authentication status not expected: %s",
+			authentication.Get(ctx).Status))
+	}
+
+	// The trace is linked to the transaction so that its spans are returned
+	// by RetrieveTransactionTrace.
+	if e.ID != "" {
+		span.Set("transaction", e.ID, "hop", e.Hop)
+		trace.Link(ctx, e.ID)
 	}
-	return nil, nil, errors.Trace(errors.Newf(
-		"Authentication status not expected: %s",
-		authentication.Get(ctx).Status))
+	span.Finish(err)
+
+	return code, resp, err
 }
 
 // ExecuteCanonical executes the creation of a canonical transaction (owner
@@ -181,6 +198,13 @@ func (e *CreateTransaction) ExecuteCanonical(
 
 	pl, err := plan.Compute(ctx, e.Client, e.Tx, false)
 	if err != nil {
+		if pe, ok := errors.Cause(err).(mint.ErrPeerNotAllowed); ok {
+			return nil, nil, errors.Trace(errors.NewUserErrorf(err,
+				403, "peer_not_allowed",
+				"The transaction path crosses offers of a mint this mint "+
+					"does not federate with: %s", pe.Host,
+			))
+		}
 		return nil, nil, errors.Trace(errors.NewUserErrorf(err,
 			402, "transaction_failed",
 			"The plan computation for the transaction failed: %s", e.ID,
@@ -188,6 +212,83 @@ func (e *CreateTransaction) ExecuteCanonical(
 	}
 	e.Plan = pl
 
+	// The amount paid by the transaction owner is the amount of the first
+	// operation of the plan, of which it is the source.
+	var paid *plan.TxAction
+	for _, h := range e.Plan.Hops {
+		if h.OpAction != nil && *h.OpAction.OperationSource == e.Owner {
+			paid = h.OpAction
+			break
+		}
+	}
+	if paid == nil {
+		return nil, nil, errors.Trace(errors.Newf(
+			"Owner operation not found in plan: %s", e.ID)) // 500
+	}
+
+	// Record the payment against the spending policy of the user for the
+	// asset paid if any.
+	policy, err := model.LoadSpendingPolicy(ctx,
+		authentication.Get(ctx).User.Token, *paid.OperationAsset)
+	if err != nil {
+		return nil, nil, errors.Trace(err) // 500
+	} else if policy != nil {
+		err = policy.Spend(ctx, e.ID, paid.Amount)
+		if err != nil {
+			switch err := errors.Cause(err).(type) {
+			case model.ErrSpendingLimitExceeded:
+				return nil, nil, errors.Trace(errors.NewUserErrorf(err,
+					403, "spending_limit_exceeded",
+					"The transaction exceeds the %s spending limit of %s "+
+						"for asset %s.",
+					err.Limit, e.Owner, *paid.OperationAsset,
+				))
+			case model.ErrConcurrentModification:
+				return nil, nil, errors.Trace(errors.NewUserErrorf(err,
+					409, "spending_conflict",
+					"Another transaction spending %s was created "+
+						"concurrently, please retry: %s.",
+					*paid.OperationAsset, e.ID,
+				))
+			default:
+				return nil, nil, errors.Trace(err) // 500
+			}
+		}
+	}
+
+	// Record the payment against the daily pay limit of the API key used to
+	// authenticate if any. The key is reloaded within the DB transaction.
+	if k := authentication.Get(ctx).Key; k != nil && k.PayLimit != nil {
+		key, err := model.LoadAPIKeyByToken(ctx, k.Token)
+		if err != nil {
+			return nil, nil, errors.Trace(err) // 500
+		} else if key == nil {
+			return nil, nil, errors.Trace(errors.Newf(
+				"API key not found: %s", k.Token)) // 500
+		}
+		err = key.Spend(ctx, *paid.OperationAsset, paid.Amount)
+		if err != nil {
+			switch err := errors.Cause(err).(type) {
+			case model.ErrPayLimitExceeded:
+				return nil, nil, errors.Trace(errors.NewUserErrorf(err,
+					403, "api_key_pay_limit_exceeded",
+					"The transaction exceeds the daily pay limit of the API "+
+						"key you are authenticated with (%s): %s",
+					err.Reason, e.ID,
+				))
+			case model.ErrConcurrentModification:
+				return nil, nil, errors.Trace(errors.NewUserErrorf(err,
+					409, "spending_conflict",
+					"Another transaction spending with the API key you are "+
+						"authenticated with was created concurrently, please "+
+						"retry: %s.", e.ID,
+				))
+			default:
+				return nil, nil, errors.Trace(err) // 500
+			}
+		}
+	}
+
 	// Commit the transaction in pending state.
 	db.Commit(ctx)
 
@@ -335,6 +436,13 @@ func (e *CreateTransaction) ExecutePropagated(
 
 	pl, err := plan.Compute(ctx, e.Client, e.Tx, false)
 	if err != nil {
+		if pe, ok := errors.Cause(err).(mint.ErrPeerNotAllowed); ok {
+			return nil, nil, errors.Trace(errors.NewUserErrorf(err,
+				403, "peer_not_allowed",
+				"The transaction path crosses offers of a mint this mint "+
+					"does not federate with: %s", pe.Host,
+			))
+		}
 		return nil, nil, errors.Trace(errors.NewUserErrorf(err,
 			402, "transaction_failed",
 			"The plan computation for the transaction failed: %s", e.ID,
@@ -351,6 +459,12 @@ func (e *CreateTransaction) ExecutePropagated(
 		))
 	}
 
+	// Transactions are propagated hop by hop starting from the canonical mint
+	// (the first hop), check that the request emanates from the next hop.
+	if err := signature.CheckPeer(ctx, e.Plan.Emitter(e.Hop)); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+
 	// Commit the transaction as pending if it was created.
 	db.Commit(ctx)
 
@@ -448,8 +562,8 @@ func (e *CreateTransaction) ExecutePlan(
 	}
 
 	h := e.Plan.Hops[e.Hop]
-	mint.Logf(ctx,
-		"Executing transaction plan: transaction=%s hop=%d", e.ID, e.Hop)
+	mint.Info(ctx, "Executing transaction plan",
+		"transaction", e.ID, "hop", e.Hop)
 
 	// Execute the OpAction (should always be defined)
 	if h.OpAction != nil {
@@ -459,13 +573,12 @@ func (e *CreateTransaction) ExecutePlan(
 			return errors.Trace(err)
 		}
 		if op != nil {
-			mint.Logf(ctx,
-				"Skipped operation: id=%s[%s] created=%q propagation=%s "+
-					"asset=%s source=%s destination=%s amount=%s "+
-					"status=%s transaction=%s",
-				op.Owner, op.Token, op.Created, op.Propagation, op.Asset,
-				op.Source, op.Destination, (*big.Int)(&op.Amount).String(),
-				op.Status, *op.Transaction)
+			mint.Info(ctx, "Skipped operation",
+				"id", op.ID(),
+				"created", op.Created, "propagation", op.Propagation,
+				"asset", op.Asset, "source", op.Source,
+				"destination", op.Destination, "amount", (*big.Int)(&op.Amount),
+				"status", op.Status, "transaction", *op.Transaction)
 		} else {
 			a := h.OpAction
 
@@ -564,13 +677,12 @@ func (e *CreateTransaction) ExecutePlan(
 				}
 			}
 
-			mint.Logf(ctx,
-				"Reserved operation: id=%s[%s] created=%q propagation=%s "+
-					"asset=%s source=%s destination=%s amount=%s "+
-					"status=%s transaction=%s",
-				op.Owner, op.Token, op.Created, op.Propagation, op.Asset,
-				op.Source, op.Destination, (*big.Int)(&op.Amount).String(),
-				op.Status, *op.Transaction)
+			mint.Info(ctx, "Reserved operation",
+				"id", op.ID(),
+				"created", op.Created, "propagation", op.Propagation,
+				"asset", op.Asset, "source", op.Source,
+				"destination", op.Destination, "amount", (*big.Int)(&op.Amount),
+				"status", op.Status, "transaction", *op.Transaction)
 		}
 	}
 
@@ -581,11 +693,11 @@ func (e *CreateTransaction) ExecutePlan(
 			return errors.Trace(err)
 		}
 		if cr != nil {
-			mint.Logf(ctx,
-				"Skipped crossing: id=%s[%s] created=%q offer=%s amount=%s "+
-					"status=%s transaction=%s",
-				cr.Owner, cr.Token, cr.Created, cr.Offer,
-				(*big.Int)(&cr.Amount).String(), cr.Status, cr.Transaction)
+			mint.Info(ctx, "Skipped crossing",
+				"id", cr.ID(),
+				"created", cr.Created, "offer", cr.Offer,
+				"amount", (*big.Int)(&cr.Amount), "status", cr.Status,
+				"transaction", cr.Transaction)
 		} else {
 			a := h.CrAction
 
@@ -641,11 +753,11 @@ func (e *CreateTransaction) ExecutePlan(
 				return errors.Trace(err)
 			}
 
-			mint.Logf(ctx,
-				"Reserved crossing: id=%s[%s] created=%q offer=%s amount=%s "+
-					"status=%s transaction=%s",
-				cr.Owner, cr.Token, cr.Created, cr.Offer,
-				(*big.Int)(&cr.Amount).String(), cr.Status, cr.Transaction)
+			mint.Info(ctx, "Reserved crossing",
+				"id", cr.ID(),
+				"created", cr.Created, "offer", cr.Offer,
+				"amount", (*big.Int)(&cr.Amount), "status", cr.Status,
+				"transaction", cr.Transaction)
 		}
 	}
 
@@ -660,11 +772,13 @@ func (e *CreateTransaction) Propagate(
 	if int(e.Hop)-1 >= 0 {
 		m := e.Plan.Hops[e.Hop-1].Mint
 
-		mint.Logf(ctx,
-			"Propagating transaction: transaction=%s hop=%d mint=%s",
-			e.ID, e.Hop-1, m)
+		mint.Info(ctx, "Propagating transaction",
+			"transaction", e.ID, "hop", e.Hop-1, "mint", m)
 
+		ctx, span := trace.Start(ctx, "transaction.propagate",
+			"transaction", e.ID, "hop", e.Hop-1, "peer", m)
 		txn, err := e.Client.PropagateTransaction(ctx, e.ID, e.Hop-1, m)
+		span.Finish(err)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
@@ -3,14 +3,16 @@ package endpoint
 import (
 	"context"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/metrics"
 	"github.com/spolu/settle/lib/respond"
 	"github.com/spolu/settle/lib/svc"
-)
-
-const (
-	defaultMaxMemory = 32 << 20 // 32 MB
+	"github.com/spolu/settle/mint"
+	"github.com/zenazn/goji/web/mutil"
 )
 
 // EndPtName reprensents an endpoint name.
@@ -19,6 +21,9 @@ type EndPtName string
 // registrar is used to register endpoints within the module.
 var registrar = map[EndPtName](func(*http.Request) (Endpoint, error)){}
 
+// streamRegistrar is used to register streaming endpoints within the module.
+var streamRegistrar = map[EndPtName](func(*http.Request) (Streamer, error)){}
+
 // Endpoint is the interface that endpoints need to implement.
 type Endpoint interface {
 	Validate(
@@ -49,13 +54,51 @@ func HandlerFor(
 			return
 		}
 
-		err = endpt.Validate(r)
+		if _, err := svc.ParseParams(r); err != nil {
+			respond.Error(ctx, w, errors.Trace(err))
+			return
+		}
+
+		key, status, resp, err := reserveIdempotencyKey(r)
 		if err != nil {
 			respond.Error(ctx, w, errors.Trace(err))
 			return
+		} else if status != nil {
+			mint.Info(ctx, "Idempotency key replayed",
+				"key", r.Header.Get(mint.HeaderIdempotencyKey))
+			respond.Respond(ctx, w, *status, http.Header{
+				"Idempotent-Replayed": []string{"true"},
+			}, *resp)
+			return
+		}
+
+		if err := endpt.Validate(r); err != nil {
+			if key != nil {
+				if err := releaseIdempotencyKey(ctx, key); err != nil {
+					mint.Warn(ctx, "Failed to release idempotency key",
+						"key", key.Key, "error", err)
+				}
+			}
+			respond.Error(ctx, w, errors.Trace(err))
+			return
+		}
+
+		status, resp, err = endpt.Execute(r.Context())
+
+		if key != nil {
+			var s int
+			var rp svc.Resp
+			if err != nil {
+				s, rp = respond.ErrorResponse(ctx, err)
+			} else {
+				s, rp = *status, *resp
+			}
+			if err := completeIdempotencyKey(ctx, key, s, rp); err != nil {
+				mint.Warn(ctx, "Failed to complete idempotency key",
+					"key", key.Key, "error", err)
+			}
 		}
 
-		status, resp, err := endpt.Execute(r.Context())
 		if err != nil {
 			respond.Error(ctx, w, errors.Trace(err))
 			return
@@ -63,3 +106,98 @@ func HandlerFor(
 		respond.Respond(ctx, w, *status, nil, *resp)
 	}
 }
+
+// Streamer is the interface that streaming endpoints need to implement. Once
+// validated, streaming endpoints write directly to the response writer.
+type Streamer interface {
+	Validate(
+		r *http.Request,
+	) error
+
+	Stream(
+		ctx context.Context,
+		w http.ResponseWriter,
+	)
+}
+
+// StreamHandlerFor returns an handler for the given streaming endpoint name.
+func StreamHandlerFor(
+	name EndPtName,
+) func(
+	http.ResponseWriter,
+	*http.Request,
+) {
+	return func(
+		w http.ResponseWriter,
+		r *http.Request,
+	) {
+		ctx := r.Context()
+
+		endpt, err := streamRegistrar[name](r)
+		if err != nil {
+			respond.Error(ctx, w, errors.Trace(err))
+			return
+		}
+
+		if err := endpt.Validate(r); err != nil {
+			respond.Error(ctx, w, errors.Trace(err))
+			return
+		}
+		endpt.Stream(ctx, w)
+	}
+}
+
+// Instrument is a middleware recording the status and latency of requests by
+// endpoint. It is mounted before the middlewares that may reject requests so
+// that their rejections are recorded as well.
+func Instrument(
+	h http.Handler,
+) http.Handler {
+	return http.HandlerFunc(func(
+		w http.ResponseWriter,
+		r *http.Request,
+	) {
+		start := time.Now()
+		wp := mutil.WrapWriter(w)
+
+		h.ServeHTTP(wp, r)
+
+		name := endpointFor(r)
+		metrics.Inc(r.Context(), mint.MtRequests,
+			name, strconv.Itoa(wp.Status()))
+		metrics.Observe(r.Context(), mint.MtRequestDuration,
+			time.Now().Sub(start).Seconds(), name)
+	})
+}
+
+// endpointFor returns the name of the endpoint a request is routed to based on
+// the routes of the API spec, "unknown" if none.
+func endpointFor(
+	r *http.Request,
+) string {
+	segments := strings.Split(r.URL.Path, "/")
+	for _, route := range openAPISpec.Routes {
+		if route.Method != r.Method {
+			continue
+		}
+		pattern := strings.Split(route.Path, "/")
+		if len(pattern) != len(segments) {
+			continue
+		}
+		match := true
+		for i, p := range pattern {
+			if strings.HasPrefix(p, ":") {
+				match = segments[i] != ""
+			} else {
+				match = p == segments[i]
+			}
+			if !match {
+				break
+			}
+		}
+		if match {
+			return route.Endpoint
+		}
+	}
+	return "unknown"
+}
@@ -0,0 +1,141 @@
+package endpoint
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/lib/authentication"
+	"github.com/spolu/settle/mint/model"
+)
+
+// maxIdempotencyKeyLength is the maximum length of the idempotency keys
+// accepted.
+const maxIdempotencyKeyLength = 256
+
+// fingerprint computes the fingerprint of a request from its method, path and
+// parameters.
+func fingerprint(
+	r *http.Request,
+) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s", r.Method, r.URL.Path, r.PostForm.Encode())
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// reserveIdempotencyKey reserves the idempotency key provided with a request
+// by an authenticated user, if any. It returns the reserved key, or the
+// response to replay if the key was already used for the same request.
+func reserveIdempotencyKey(
+	r *http.Request,
+) (*model.IdempotencyKey, *int, *svc.Resp, error) {
+	ctx := r.Context()
+
+	key := r.Header.Get(mint.HeaderIdempotencyKey)
+	if key == "" || r.Method != "POST" ||
+		authentication.Get(ctx).Status != authentication.AutStSucceeded {
+		return nil, nil, nil, nil
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return nil, nil, nil, errors.Trace(errors.NewUserErrorf(nil,
+			400, "idempotency_key_invalid",
+			"The idempotency key you provided is invalid. Idempotency keys "+
+				"must be at most %d characters long.",
+			maxIdempotencyKeyLength,
+		))
+	}
+
+	if _, err := svc.ParseParams(r); err != nil {
+		return nil, nil, nil, errors.Trace(err)
+	}
+	fp := fingerprint(r)
+	user := authentication.Get(ctx).User
+
+	for {
+		k, err := model.CreateIdempotencyKey(ctx, user.Token, key, fp)
+		if err == nil {
+			return k, nil, nil, nil
+		}
+		if _, ok := errors.Cause(err).(model.ErrUniqueConstraintViolation); !ok {
+			return nil, nil, nil, errors.Trace(err) // 500
+		}
+
+		k, err = model.LoadIdempotencyKey(ctx, user.Token, key)
+		if err != nil {
+			return nil, nil, nil, errors.Trace(err) // 500
+		} else if k == nil {
+			// The key was released concurrently.
+			continue
+		}
+
+		expiry := time.Duration(mint.IdempotencyKeyExpiryMs) * time.Millisecond
+		if time.Now().Sub(k.Created) > expiry {
+			if err := k.Delete(ctx); err != nil {
+				return nil, nil, nil, errors.Trace(err) // 500
+			}
+			continue
+		}
+
+		if k.Fingerprint != fp {
+			return nil, nil, nil, errors.Trace(errors.NewUserErrorf(nil,
+				422, "idempotency_key_reused",
+				"The idempotency key you provided was already used for a "+
+					"different request: %s.",
+				key,
+			))
+		}
+		if k.Status == nil || k.Response == nil {
+			return nil, nil, nil, errors.Trace(errors.NewUserErrorf(nil,
+				409, "idempotency_key_in_progress",
+				"A request with the idempotency key you provided is still in "+
+					"progress: %s.",
+				key,
+			))
+		}
+
+		var resp svc.Resp
+		if err := json.Unmarshal([]byte(*k.Response), &resp); err != nil {
+			return nil, nil, nil, errors.Trace(err) // 500
+		}
+		return nil, k.Status, &resp, nil
+	}
+}
+
+// releaseIdempotencyKey releases a reserved idempotency key so that the
+// request can be retried. It is only called for requests that failed to
+// validate, as no side effect was committed for them.
+func releaseIdempotencyKey(
+	ctx context.Context,
+	k *model.IdempotencyKey,
+) error {
+	return errors.Trace(k.Delete(ctx))
+}
+
+// completeIdempotencyKey stores the response served for a reserved
+// idempotency key, including error responses: once executed, a request may
+// have committed side effects (a transaction created before its propagation
+// failed) and retrying it must replay its response instead of executing it
+// again.
+func completeIdempotencyKey(
+	ctx context.Context,
+	k *model.IdempotencyKey,
+	status int,
+	resp svc.Resp,
+) error {
+	raw, err := json.Marshal(resp)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	response := string(raw)
+
+	k.Status = &status
+	k.Response = &response
+
+	return errors.Trace(k.Save(ctx))
+}
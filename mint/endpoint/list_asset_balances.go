@@ -9,7 +9,6 @@ import (
 
 	"github.com/spolu/settle/lib/db"
 	"github.com/spolu/settle/lib/errors"
-	"github.com/spolu/settle/lib/format"
 	"github.com/spolu/settle/lib/ptr"
 	"github.com/spolu/settle/lib/svc"
 	"github.com/spolu/settle/mint"
@@ -29,8 +28,9 @@ func init() {
 // ListAssetBalances returns a list of balances.
 type ListAssetBalances struct {
 	ListEndpoint
-	Owner string
-	Asset mint.AssetResource
+	Filter model.BalanceFilter
+	Owner  string
+	Asset  mint.AssetResource
 }
 
 // NewListAssetBalances constructs and initialiezes the endpoint.
@@ -38,7 +38,9 @@ func NewListAssetBalances(
 	r *http.Request,
 ) (Endpoint, error) {
 	return &ListAssetBalances{
-		ListEndpoint: ListEndpoint{},
+		ListEndpoint: ListEndpoint{
+			Sorts: []string{model.SrKeyValue},
+		},
 	}, nil
 }
 
@@ -69,6 +71,12 @@ func (e *ListAssetBalances) Validate(
 		))
 	}
 
+	filter, err := ValidateBalanceFilter(ctx, r.URL.Query())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.Filter = *filter
+
 	return e.ListEndpoint.Validate(r)
 }
 
@@ -79,9 +87,9 @@ func (e *ListAssetBalances) Execute(
 	ctx = db.Begin(ctx, "mint")
 	defer db.LoggedRollback(ctx)
 
-	balances, err := model.LoadBalanceListByAsset(ctx,
-		e.ListEndpoint.CreatedBefore,
-		e.ListEndpoint.Limit,
+	balances, more, err := model.LoadBalanceListByAsset(ctx,
+		e.ListEndpoint.Page(),
+		e.Filter,
 		e.Asset.Name,
 	)
 	if err != nil {
@@ -91,12 +99,14 @@ func (e *ListAssetBalances) Execute(
 	db.Commit(ctx)
 
 	l := []mint.BalanceResource{}
+	cursors := []model.Cursor{}
 	for _, b := range balances {
 		b := b
 		l = append(l, model.NewBalanceResource(ctx, &b))
+		cursors = append(cursors, b.Cursor(e.Sort))
 	}
 
-	return ptr.Int(http.StatusOK), &svc.Resp{
-		"balances": format.JSONPtr(l),
-	}, nil
+	return ptr.Int(http.StatusOK), e.ListEndpoint.Resp(
+		"balances", l, cursors, more,
+	), nil
 }
@@ -8,7 +8,6 @@ import (
 
 	"github.com/spolu/settle/lib/db"
 	"github.com/spolu/settle/lib/errors"
-	"github.com/spolu/settle/lib/format"
 	"github.com/spolu/settle/lib/ptr"
 	"github.com/spolu/settle/lib/svc"
 	"github.com/spolu/settle/mint"
@@ -27,6 +26,7 @@ func init() {
 // ListAssetOffers returns a list of offers.
 type ListAssetOffers struct {
 	ListEndpoint
+	Filter      model.OfferFilter
 	Asset       mint.AssetResource
 	Propagation mint.PgType
 }
@@ -36,7 +36,9 @@ func NewListAssetOffers(
 	r *http.Request,
 ) (Endpoint, error) {
 	return &ListAssetOffers{
-		ListEndpoint: ListEndpoint{},
+		ListEndpoint: ListEndpoint{
+			Sorts: []string{model.SrKeyPrice, model.SrKeyRemainder},
+		},
 	}, nil
 }
 
@@ -61,6 +63,12 @@ func (e *ListAssetOffers) Validate(
 	}
 	e.Propagation = *propagation
 
+	filter, err := ValidateOfferFilter(ctx, r.URL.Query())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.Filter = *filter
+
 	return e.ListEndpoint.Validate(r)
 }
 
@@ -72,22 +80,23 @@ func (e *ListAssetOffers) Execute(
 	defer db.LoggedRollback(ctx)
 
 	var offers []model.Offer
+	var more bool
 	var err error
 
 	switch e.Propagation {
 	case mint.PgTpCanonical:
-		offers, err = model.LoadOfferListByBaseAsset(ctx,
-			e.ListEndpoint.CreatedBefore,
-			e.ListEndpoint.Limit,
+		offers, more, err = model.LoadOfferListByBaseAsset(ctx,
+			e.ListEndpoint.Page(),
+			e.Filter,
 			e.Asset.Name,
 		)
 		if err != nil {
 			return nil, nil, errors.Trace(err) // 500
 		}
 	case mint.PgTpPropagated:
-		offers, err = model.LoadOfferListByQuoteAsset(ctx,
-			e.ListEndpoint.CreatedBefore,
-			e.ListEndpoint.Limit,
+		offers, more, err = model.LoadOfferListByQuoteAsset(ctx,
+			e.ListEndpoint.Page(),
+			e.Filter,
 			e.Asset.Name,
 		)
 		if err != nil {
@@ -98,12 +107,14 @@ func (e *ListAssetOffers) Execute(
 	db.Commit(ctx)
 
 	l := []mint.OfferResource{}
+	cursors := []model.Cursor{}
 	for _, o := range offers {
 		o := o
 		l = append(l, model.NewOfferResource(ctx, &o))
+		cursors = append(cursors, o.Cursor(e.Sort))
 	}
 
-	return ptr.Int(http.StatusOK), &svc.Resp{
-		"offers": format.JSONPtr(l),
-	}, nil
+	return ptr.Int(http.StatusOK), e.ListEndpoint.Resp(
+		"offers", l, cursors, more,
+	), nil
 }
@@ -7,7 +7,6 @@ import (
 
 	"github.com/spolu/settle/lib/db"
 	"github.com/spolu/settle/lib/errors"
-	"github.com/spolu/settle/lib/format"
 	"github.com/spolu/settle/lib/ptr"
 	"github.com/spolu/settle/lib/svc"
 	"github.com/spolu/settle/mint"
@@ -27,7 +26,8 @@ func init() {
 // ListAssets returns a list of assets.
 type ListAssets struct {
 	ListEndpoint
-	Owner string
+	Filter model.AssetFilter
+	Owner  string
 }
 
 // NewListAssets constructs and initialiezes the endpoint.
@@ -48,6 +48,12 @@ func (e *ListAssets) Validate(
 	e.Owner = fmt.Sprintf("%s@%s",
 		authentication.Get(ctx).User.Username, mint.GetHost(ctx))
 
+	filter, err := ValidateAssetFilter(ctx, r.URL.Query())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.Filter = *filter
+
 	return e.ListEndpoint.Validate(r)
 }
 
@@ -58,9 +64,9 @@ func (e *ListAssets) Execute(
 	ctx = db.Begin(ctx, "mint")
 	defer db.LoggedRollback(ctx)
 
-	assets, err := model.LoadAssetListByOwner(ctx,
-		e.ListEndpoint.CreatedBefore,
-		e.ListEndpoint.Limit,
+	assets, more, err := model.LoadAssetListByOwner(ctx,
+		e.ListEndpoint.Page(),
+		e.Filter,
 		e.Owner,
 	)
 	if err != nil {
@@ -70,12 +76,14 @@ func (e *ListAssets) Execute(
 	db.Commit(ctx)
 
 	l := []mint.AssetResource{}
+	cursors := []model.Cursor{}
 	for _, a := range assets {
 		a := a
 		l = append(l, model.NewAssetResource(ctx, &a))
+		cursors = append(cursors, a.Cursor())
 	}
 
-	return ptr.Int(http.StatusOK), &svc.Resp{
-		"assets": format.JSONPtr(l),
-	}, nil
+	return ptr.Int(http.StatusOK), e.ListEndpoint.Resp(
+		"assets", l, cursors, more,
+	), nil
 }
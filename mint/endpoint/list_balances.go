@@ -7,7 +7,6 @@ import (
 
 	"github.com/spolu/settle/lib/db"
 	"github.com/spolu/settle/lib/errors"
-	"github.com/spolu/settle/lib/format"
 	"github.com/spolu/settle/lib/ptr"
 	"github.com/spolu/settle/lib/svc"
 	"github.com/spolu/settle/mint"
@@ -27,6 +26,7 @@ func init() {
 // ListBalances returns a list of balances.
 type ListBalances struct {
 	ListEndpoint
+	Filter model.BalanceFilter
 	Holder string
 }
 
@@ -35,7 +35,9 @@ func NewListBalances(
 	r *http.Request,
 ) (Endpoint, error) {
 	return &ListBalances{
-		ListEndpoint: ListEndpoint{},
+		ListEndpoint: ListEndpoint{
+			Sorts: []string{model.SrKeyValue},
+		},
 	}, nil
 }
 
@@ -48,6 +50,12 @@ func (e *ListBalances) Validate(
 	e.Holder = fmt.Sprintf("%s@%s",
 		authentication.Get(ctx).User.Username, mint.GetHost(ctx))
 
+	filter, err := ValidateBalanceFilter(ctx, r.URL.Query())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.Filter = *filter
+
 	return e.ListEndpoint.Validate(r)
 }
 
@@ -58,9 +66,9 @@ func (e *ListBalances) Execute(
 	ctx = db.Begin(ctx, "mint")
 	defer db.LoggedRollback(ctx)
 
-	balances, err := model.LoadBalanceListByHolder(ctx,
-		e.ListEndpoint.CreatedBefore,
-		e.ListEndpoint.Limit,
+	balances, more, err := model.LoadBalanceListByHolder(ctx,
+		e.ListEndpoint.Page(),
+		e.Filter,
 		e.Holder,
 	)
 	if err != nil {
@@ -70,12 +78,14 @@ func (e *ListBalances) Execute(
 	db.Commit(ctx)
 
 	l := []mint.BalanceResource{}
+	cursors := []model.Cursor{}
 	for _, b := range balances {
 		b := b
 		l = append(l, model.NewBalanceResource(ctx, &b))
+		cursors = append(cursors, b.Cursor(e.Sort))
 	}
 
-	return ptr.Int(http.StatusOK), &svc.Resp{
-		"balances": format.JSONPtr(l),
-	}, nil
+	return ptr.Int(http.StatusOK), e.ListEndpoint.Resp(
+		"balances", l, cursors, more,
+	), nil
 }
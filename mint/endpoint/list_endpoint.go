@@ -2,15 +2,24 @@ package endpoint
 
 import (
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/format"
+	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/mint/model"
 )
 
-// ListEndpoint is an helper object to implement list endpoints.
+// ListEndpoint is an helper object to implement list endpoints. Sorts lists
+// the sort keys supported by the endpoint in addition to the creation date.
 type ListEndpoint struct {
 	CreatedBefore time.Time
+	StartingAfter *model.Cursor
+	EndingBefore  *model.Cursor
 	Limit         uint
+	Sort          model.Sort
+	Sorts         []string
 }
 
 // Validate validates the input parameters.
@@ -34,5 +43,108 @@ func (e *ListEndpoint) Validate(
 	}
 	e.CreatedBefore = *createdBefore
 
+	// Validate starting_after and ending_before.
+	startingAfter := r.URL.Query().Get("starting_after")
+	endingBefore := r.URL.Query().Get("ending_before")
+	if startingAfter != "" && endingBefore != "" {
+		return errors.Trace(errors.NewUserErrorf(nil,
+			400, "cursor_invalid",
+			"The paging starting_after and ending_before parameters are "+
+				"mutually exclusive.",
+		))
+	}
+	if startingAfter != "" {
+		e.StartingAfter, err = ValidateCursor(ctx, startingAfter)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if endingBefore != "" {
+		e.EndingBefore, err = ValidateCursor(ctx, endingBefore)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	// Validate sort and order.
+	e.Sort.Key = model.SrKeyCreated
+	if sort := r.URL.Query().Get("sort"); sort != "" {
+		valid := sort == model.SrKeyCreated
+		for _, k := range e.Sorts {
+			valid = valid || sort == k
+		}
+		if !valid {
+			return errors.Trace(errors.NewUserErrorf(nil,
+				400, "sort_invalid",
+				"The sort key you provided is invalid: %s. It can be one "+
+					"of: %s.",
+				sort, strings.Join(append([]string{model.SrKeyCreated},
+					e.Sorts...), ", "),
+			))
+		}
+		e.Sort.Key = sort
+	}
+	switch order := r.URL.Query().Get("order"); order {
+	case "", "desc":
+	case "asc":
+		e.Sort.Asc = true
+	default:
+		return errors.Trace(errors.NewUserErrorf(nil,
+			400, "order_invalid",
+			"The order you provided is invalid: %s. It can be either asc "+
+				"or desc.",
+			order,
+		))
+	}
+
+	// Validate that cursors were taken from a list with the same sort.
+	if err := e.Page().Validate(); err != nil {
+		return errors.Trace(errors.NewUserErrorf(err,
+			400, "cursor_invalid",
+			"The paging cursor provided was not taken from a list sorted by "+
+				"%s.",
+			e.Sort.Key,
+		))
+	}
+
 	return nil
 }
+
+// Page returns the page of objects to load.
+func (e *ListEndpoint) Page() model.Page {
+	return model.Page{
+		CreatedBefore: e.CreatedBefore,
+		StartingAfter: e.StartingAfter,
+		EndingBefore:  e.EndingBefore,
+		Limit:         e.Limit,
+		Sort:          e.Sort,
+	}
+}
+
+// Resp constructs the response of the list endpoint from the list of
+// resources returned under name, the cursors of the objects of the page and
+// whether more objects exist beyond it. The next cursor is meant to be passed
+// with the same paging parameter as the current request (`starting_after`
+// unless `ending_before` was used).
+func (e *ListEndpoint) Resp(
+	name string,
+	list interface{},
+	cursors []model.Cursor,
+	hasMore bool,
+) *svc.Resp {
+	var next *string
+	if hasMore && len(cursors) > 0 {
+		c := cursors[len(cursors)-1]
+		if e.EndingBefore != nil {
+			c = cursors[0]
+		}
+		s := c.String()
+		next = &s
+	}
+
+	return &svc.Resp{
+		name:          format.JSONPtr(list),
+		"has_more":    format.JSONPtr(hasMore),
+		"next_cursor": format.JSONPtr(next),
+	}
+}
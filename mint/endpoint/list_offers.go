@@ -0,0 +1,92 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/ptr"
+	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/lib/authentication"
+	"github.com/spolu/settle/mint/model"
+)
+
+const (
+	// EndPtListOffers lists the offers of the authenticated user.
+	EndPtListOffers EndPtName = "ListOffers"
+)
+
+func init() {
+	registrar[EndPtListOffers] = NewListOffers
+}
+
+// ListOffers returns a list of the canonical offers owned by the
+// authenticated user.
+type ListOffers struct {
+	ListEndpoint
+	Owner  string
+	Filter model.OfferFilter
+}
+
+// NewListOffers constructs and initialiezes the endpoint.
+func NewListOffers(
+	r *http.Request,
+) (Endpoint, error) {
+	return &ListOffers{
+		ListEndpoint: ListEndpoint{
+			Sorts: []string{model.SrKeyPrice, model.SrKeyRemainder},
+		},
+	}, nil
+}
+
+// Validate validates the input parameters.
+func (e *ListOffers) Validate(
+	r *http.Request,
+) error {
+	ctx := r.Context()
+
+	e.Owner = fmt.Sprintf("%s@%s",
+		authentication.Get(ctx).User.Username, mint.GetHost(ctx))
+
+	filter, err := ValidateOfferFilter(ctx, r.URL.Query())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.Filter = *filter
+
+	return e.ListEndpoint.Validate(r)
+}
+
+// Execute executes the endpoint.
+func (e *ListOffers) Execute(
+	ctx context.Context,
+) (*int, *svc.Resp, error) {
+	ctx = db.Begin(ctx, "mint")
+	defer db.LoggedRollback(ctx)
+
+	offers, more, err := model.LoadCanonicalOfferListByOwner(ctx,
+		e.ListEndpoint.Page(),
+		e.Filter,
+		e.Owner,
+	)
+	if err != nil {
+		return nil, nil, errors.Trace(err) // 500
+	}
+
+	db.Commit(ctx)
+
+	l := []mint.OfferResource{}
+	cursors := []model.Cursor{}
+	for _, o := range offers {
+		o := o
+		l = append(l, model.NewOfferResource(ctx, &o))
+		cursors = append(cursors, o.Cursor(e.Sort))
+	}
+
+	return ptr.Int(http.StatusOK), e.ListEndpoint.Resp(
+		"offers", l, cursors, more,
+	), nil
+}
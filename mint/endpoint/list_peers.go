@@ -0,0 +1,87 @@
+package endpoint
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/ptr"
+	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/async/task"
+	"github.com/spolu/settle/mint/model"
+)
+
+const (
+	// EndPtListPeers lists the peers of the mint.
+	EndPtListPeers EndPtName = "ListPeers"
+)
+
+func init() {
+	registrar[EndPtListPeers] = NewListPeers
+}
+
+// ListPeers returns a list of the peer mints this mint interacted with along
+// with their health.
+type ListPeers struct {
+	ListEndpoint
+	Latest bool
+}
+
+// NewListPeers constructs and initialiezes the endpoint.
+func NewListPeers(
+	r *http.Request,
+) (Endpoint, error) {
+	return &ListPeers{
+		ListEndpoint: ListEndpoint{},
+	}, nil
+}
+
+// Validate validates the input parameters.
+func (e *ListPeers) Validate(
+	r *http.Request,
+) error {
+	e.Latest = r.URL.Query().Get("created_before") == ""
+
+	return e.ListEndpoint.Validate(r)
+}
+
+// Execute executes the endpoint.
+func (e *ListPeers) Execute(
+	ctx context.Context,
+) (*int, *svc.Resp, error) {
+	// Record the pending peer contacts so that the list is up to date.
+	_, err := task.RecordPeerContacts(ctx)
+	if err != nil {
+		return nil, nil, errors.Trace(err) // 500
+	}
+	if e.Latest {
+		e.ListEndpoint.CreatedBefore = time.Now()
+	}
+
+	ctx = db.Begin(ctx, "mint")
+	defer db.LoggedRollback(ctx)
+
+	peers, more, err := model.LoadPeerList(ctx,
+		e.ListEndpoint.Page(),
+	)
+	if err != nil {
+		return nil, nil, errors.Trace(err) // 500
+	}
+
+	db.Commit(ctx)
+
+	l := []mint.PeerResource{}
+	cursors := []model.Cursor{}
+	for _, p := range peers {
+		p := p
+		l = append(l, model.NewPeerResource(ctx, &p))
+		cursors = append(cursors, p.Cursor())
+	}
+
+	return ptr.Int(http.StatusOK), e.ListEndpoint.Resp(
+		"peers", l, cursors, more,
+	), nil
+}
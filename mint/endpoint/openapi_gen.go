@@ -0,0 +1,868 @@
+// Code generated by openapi-gen. DO NOT EDIT.
+
+package endpoint
+
+import (
+	"github.com/spolu/settle/lib/openapi"
+	"github.com/spolu/settle/mint"
+)
+
+// openAPISpec is the spec of the API extracted from the routes and endpoints.
+var openAPISpec = openapi.Spec{
+	Routes: []openapi.Route{
+		{Method: "POST", Path: "/assets", Endpoint: "CreateAsset"},
+		{Method: "POST", Path: "/offers", Endpoint: "CreateOffer"},
+		{Method: "POST", Path: "/transactions", Endpoint: "CreateTransaction"},
+		{Method: "POST", Path: "/offers/:offer/close", Endpoint: "CloseOffer"},
+		{Method: "POST", Path: "/keys", Endpoint: "CreateAPIKey"},
+		{Method: "POST", Path: "/keys/:key/revoke", Endpoint: "RevokeAPIKey"},
+		{Method: "GET", Path: "/assets", Endpoint: "ListAssets"},
+		{Method: "GET", Path: "/balances", Endpoint: "ListBalances"},
+		{Method: "GET", Path: "/offers", Endpoint: "ListOffers"},
+		{Method: "GET", Path: "/assets/:asset/balances", Endpoint: "ListAssetBalances"},
+		{Method: "GET", Path: "/peers", Endpoint: "ListPeers"},
+		{Method: "GET", Path: "/keys", Endpoint: "ListAPIKeys"},
+		{Method: "GET", Path: "/usage", Endpoint: "RetrieveUsage"},
+		{Method: "GET", Path: "/events", Endpoint: "StreamEvents"},
+		{Method: "GET", Path: "/admin/users", Endpoint: "AdminListUsers"},
+		{Method: "POST", Path: "/admin/users", Endpoint: "AdminCreateUser"},
+		{Method: "POST", Path: "/admin/users/:user/disable", Endpoint: "AdminDisableUser"},
+		{Method: "POST", Path: "/admin/users/:user/enable", Endpoint: "AdminEnableUser"},
+		{Method: "POST", Path: "/admin/users/:user/password", Endpoint: "AdminResetPassword"},
+		{Method: "GET", Path: "/admin/users/:user/assets", Endpoint: "AdminListUserAssets"},
+		{Method: "GET", Path: "/admin/users/:user/offers", Endpoint: "AdminListUserOffers"},
+		{Method: "GET", Path: "/admin/users/:user/usage", Endpoint: "AdminRetrieveUserUsage"},
+		{Method: "POST", Path: "/admin/users/:user/policies", Endpoint: "AdminSetSpendingPolicy"},
+		{Method: "POST", Path: "/transactions/:transaction/settle", Endpoint: "SettleTransaction"},
+		{Method: "POST", Path: "/transactions/:transaction/cancel", Endpoint: "CancelTransaction"},
+		{Method: "GET", Path: "/offers/:offer", Endpoint: "RetrieveOffer"},
+		{Method: "GET", Path: "/operations/:operation", Endpoint: "RetrieveOperation"},
+		{Method: "GET", Path: "/transactions/:transaction", Endpoint: "RetrieveTransaction"},
+		{Method: "GET", Path: "/transactions/:transaction/trace", Endpoint: "RetrieveTransactionTrace"},
+		{Method: "GET", Path: "/balances/:balance", Endpoint: "RetrieveBalance"},
+		{Method: "GET", Path: "/key", Endpoint: "RetrieveKey"},
+		{Method: "GET", Path: "/.well-known/settle-mint", Endpoint: "RetrieveMint"},
+		{Method: "GET", Path: "/openapi.json", Endpoint: "RetrieveOpenAPI"},
+		{Method: "GET", Path: "/healthz", Endpoint: "RetrieveHealth"},
+		{Method: "GET", Path: "/readyz", Endpoint: "RetrieveReadiness"},
+		{Method: "POST", Path: "/transactions/:transaction", Endpoint: "CreateTransaction"},
+		{Method: "POST", Path: "/operations/:operation", Endpoint: "PropagateOperation"},
+		{Method: "POST", Path: "/offers/:offer", Endpoint: "PropagateOffer"},
+		{Method: "POST", Path: "/balances/:balance", Endpoint: "PropagateBalance"},
+		{Method: "GET", Path: "/assets/:asset", Endpoint: "RetrieveAsset"},
+		{Method: "GET", Path: "/assets/:asset/offers", Endpoint: "ListAssetOffers"},
+		{Method: "GET", Path: "/assets/:asset/book", Endpoint: "RetrieveOrderBook"},
+	},
+	Endpoints: map[string]openapi.Endpoint{
+		"AdminCreateUser": openapi.Endpoint{
+			Name:        "AdminCreateUser",
+			Description: "AdminCreateUser controls the creation of new users by admins.",
+			Status:      201,
+			Params: []openapi.Param{
+				{Name: "username", In: "form"},
+				{Name: "password", In: "form"},
+				{Name: "role", In: "form"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "password_invalid"},
+				{Status: 400, Code: "role_invalid"},
+				{Status: 400, Code: "user_already_exists"},
+				{Status: 400, Code: "username_invalid"},
+			},
+			Fields: []openapi.Field{
+				{Key: "user", Resource: "UserResource"},
+			},
+		},
+		"AdminDisableUser": openapi.Endpoint{
+			Name:        "AdminDisableUser",
+			Description: "AdminUpdateUser updates the status or password of a user.",
+			Status:      200,
+			Params: []openapi.Param{
+				{Name: "user", In: "path"},
+				{Name: "password", In: "form"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "password_invalid"},
+				{Status: 400, Code: "user_invalid"},
+				{Status: 400, Code: "username_invalid"},
+				{Status: 404, Code: "user_not_found"},
+			},
+			Fields: []openapi.Field{
+				{Key: "user", Resource: "UserResource"},
+			},
+		},
+		"AdminEnableUser": openapi.Endpoint{
+			Name:        "AdminEnableUser",
+			Description: "AdminUpdateUser updates the status or password of a user.",
+			Status:      200,
+			Params: []openapi.Param{
+				{Name: "user", In: "path"},
+				{Name: "password", In: "form"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "password_invalid"},
+				{Status: 400, Code: "user_invalid"},
+				{Status: 400, Code: "username_invalid"},
+				{Status: 404, Code: "user_not_found"},
+			},
+			Fields: []openapi.Field{
+				{Key: "user", Resource: "UserResource"},
+			},
+		},
+		"AdminListUserAssets": openapi.Endpoint{
+			Name:        "AdminListUserAssets",
+			Description: "AdminListUserObjects returns a list of assets or offers owned by a user.",
+			Status:      200,
+			Params: []openapi.Param{
+				{Name: "user", In: "path"},
+				{Name: "limit", In: "query"},
+				{Name: "created_before", In: "query"},
+				{Name: "starting_after", In: "query"},
+				{Name: "ending_before", In: "query"},
+				{Name: "sort", In: "query"},
+				{Name: "order", In: "query"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "created_before_invalid"},
+				{Status: 400, Code: "cursor_invalid"},
+				{Status: 400, Code: "order_invalid"},
+				{Status: 400, Code: "sort_invalid"},
+				{Status: 400, Code: "username_invalid"},
+				{Status: 404, Code: "user_not_found"},
+			},
+			Fields: []openapi.Field{
+				{Key: "assets", Resource: "AssetResource", List: true},
+				{Key: "has_more", Type: "boolean"},
+				{Key: "next_cursor", Type: "string"},
+				{Key: "offers", Resource: "OfferResource", List: true},
+			},
+		},
+		"AdminListUserOffers": openapi.Endpoint{
+			Name:        "AdminListUserOffers",
+			Description: "AdminListUserObjects returns a list of assets or offers owned by a user.",
+			Status:      200,
+			Params: []openapi.Param{
+				{Name: "user", In: "path"},
+				{Name: "limit", In: "query"},
+				{Name: "created_before", In: "query"},
+				{Name: "starting_after", In: "query"},
+				{Name: "ending_before", In: "query"},
+				{Name: "sort", In: "query"},
+				{Name: "order", In: "query"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "created_before_invalid"},
+				{Status: 400, Code: "cursor_invalid"},
+				{Status: 400, Code: "order_invalid"},
+				{Status: 400, Code: "sort_invalid"},
+				{Status: 400, Code: "username_invalid"},
+				{Status: 404, Code: "user_not_found"},
+			},
+			Fields: []openapi.Field{
+				{Key: "assets", Resource: "AssetResource", List: true},
+				{Key: "has_more", Type: "boolean"},
+				{Key: "next_cursor", Type: "string"},
+				{Key: "offers", Resource: "OfferResource", List: true},
+			},
+		},
+		"AdminListUsers": openapi.Endpoint{
+			Name:        "AdminListUsers",
+			Description: "AdminListUsers returns a list of users.",
+			Status:      200,
+			Params: []openapi.Param{
+				{Name: "limit", In: "query"},
+				{Name: "created_before", In: "query"},
+				{Name: "starting_after", In: "query"},
+				{Name: "ending_before", In: "query"},
+				{Name: "sort", In: "query"},
+				{Name: "order", In: "query"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "created_before_invalid"},
+				{Status: 400, Code: "cursor_invalid"},
+				{Status: 400, Code: "order_invalid"},
+				{Status: 400, Code: "sort_invalid"},
+			},
+			Fields: []openapi.Field{
+				{Key: "has_more", Type: "boolean"},
+				{Key: "next_cursor", Type: "string"},
+				{Key: "users", Resource: "UserResource", List: true},
+			},
+		},
+		"AdminResetPassword": openapi.Endpoint{
+			Name:        "AdminResetPassword",
+			Description: "AdminUpdateUser updates the status or password of a user.",
+			Status:      200,
+			Params: []openapi.Param{
+				{Name: "user", In: "path"},
+				{Name: "password", In: "form"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "password_invalid"},
+				{Status: 400, Code: "user_invalid"},
+				{Status: 400, Code: "username_invalid"},
+				{Status: 404, Code: "user_not_found"},
+			},
+			Fields: []openapi.Field{
+				{Key: "user", Resource: "UserResource"},
+			},
+		},
+		"AdminRetrieveUserUsage": openapi.Endpoint{
+			Name:        "AdminRetrieveUserUsage",
+			Description: "RetrieveUsage returns the spending policies of a user along with the amounts spent under each of them.",
+			Status:      200,
+			Params: []openapi.Param{
+				{Name: "user", In: "path"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "username_invalid"},
+				{Status: 404, Code: "user_not_found"},
+			},
+			Fields: []openapi.Field{
+				{Key: "spending_policies", Resource: "SpendingPolicyResource", List: true},
+			},
+		},
+		"AdminSetSpendingPolicy": openapi.Endpoint{
+			Name:        "AdminSetSpendingPolicy",
+			Description: "AdminSetSpendingPolicy sets the spending policy of a user for an asset. Omitted limits are not enforced.",
+			Status:      200,
+			Params: []openapi.Param{
+				{Name: "user", In: "path"},
+				{Name: "asset", In: "form"},
+				{Name: "window_ms", In: "form"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "amount_invalid"},
+				{Status: 400, Code: "asset_invalid"},
+				{Status: 400, Code: "username_invalid"},
+				{Status: 400, Code: "window_invalid"},
+				{Status: 404, Code: "user_not_found"},
+			},
+			Fields: []openapi.Field{
+				{Key: "spending_policy", Resource: "SpendingPolicyResource"},
+			},
+		},
+		"CancelTransaction": openapi.Endpoint{
+			Name:        "CancelTransaction",
+			Description: "CancelTransaction creates a new transaction.",
+			Status:      200,
+			Params: []openapi.Param{
+				{Name: "hop", In: "form"},
+				{Name: "transaction", In: "path"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "hop_invalid"},
+				{Status: 400, Code: "id_invalid"},
+				{Status: 402, Code: "cancellation_failed"},
+				{Status: 402, Code: "cancellation_not_authorized"},
+				{Status: 404, Code: "transaction_not_found"},
+			},
+			Fields: []openapi.Field{
+				{Key: "transaction", Resource: "TransactionResource"},
+			},
+		},
+		"CloseOffer": openapi.Endpoint{
+			Name:        "CloseOffer",
+			Description: "CloseOffer closes an offer, making it unusable by transactions",
+			Status:      200,
+			Params: []openapi.Param{
+				{Name: "offer", In: "path"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "id_invalid"},
+				{Status: 400, Code: "not_authorized"},
+				{Status: 404, Code: "offer_not_found"},
+			},
+			Fields: []openapi.Field{
+				{Key: "offer", Resource: "OfferResource"},
+			},
+		},
+		"CreateAPIKey": openapi.Endpoint{
+			Name:        "CreateAPIKey",
+			Description: "CreateAPIKey controls the creation of new API keys.",
+			Status:      201,
+			Params: []openapi.Param{
+				{Name: "name", In: "form"},
+				{Name: "scopes[]", In: "form"},
+				{Name: "pay_asset", In: "form"},
+				{Name: "pay_limit", In: "form"},
+				{Name: "expires", In: "form"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "amount_invalid"},
+				{Status: 400, Code: "asset_invalid"},
+				{Status: 400, Code: "expires_invalid"},
+				{Status: 400, Code: "name_invalid"},
+				{Status: 400, Code: "pay_limit_invalid"},
+				{Status: 400, Code: "scope_invalid"},
+			},
+			Fields: []openapi.Field{
+				{Key: "api_key", Resource: "APIKeyResource"},
+			},
+		},
+		"CreateAsset": openapi.Endpoint{
+			Name:        "CreateAsset",
+			Description: "CreateAsset controls the creation of new assets.",
+			Status:      201,
+			Params: []openapi.Param{
+				{Name: "code", In: "form"},
+				{Name: "scale", In: "form"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "asset_already_exists"},
+				{Status: 400, Code: "code_invalid"},
+				{Status: 400, Code: "scale_invalid"},
+			},
+			Fields: []openapi.Field{
+				{Key: "asset", Resource: "AssetResource"},
+			},
+		},
+		"CreateOffer": openapi.Endpoint{
+			Name:        "CreateOffer",
+			Description: "CreateOffer creates a new canonical offer and triggers its propagation to all the mints involved. Offer are represented as asks: base asset (left) is offered in exchange for quote asset (right) for specified amount (of quote asset) at specified price.",
+			Status:      201,
+			Params: []openapi.Param{
+				{Name: "pair", In: "form"},
+				{Name: "price", In: "form"},
+				{Name: "amount", In: "form"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "amount_invalid"},
+				{Status: 400, Code: "asset_not_found"},
+				{Status: 400, Code: "not_authorized"},
+				{Status: 400, Code: "pair_invalid"},
+				{Status: 400, Code: "price_invalid"},
+			},
+			Fields: []openapi.Field{
+				{Key: "offer", Resource: "OfferResource"},
+			},
+		},
+		"CreateTransaction": openapi.Endpoint{
+			Name:        "CreateTransaction",
+			Description: "CreateTransaction creates a new transaction.",
+			Status:      201,
+			Params: []openapi.Param{
+				{Name: "transaction", In: "path"},
+				{Name: "hop", In: "form"},
+				{Name: "pair", In: "form"},
+				{Name: "amount", In: "form"},
+				{Name: "destination", In: "form"},
+				{Name: "path[]", In: "form"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "amount_invalid"},
+				{Status: 400, Code: "destination_invalid"},
+				{Status: 400, Code: "hop_invalid"},
+				{Status: 400, Code: "id_invalid"},
+				{Status: 400, Code: "pair_invalid"},
+				{Status: 400, Code: "path_invalid"},
+				{Status: 402, Code: "transaction_failed"},
+				{Status: 403, Code: "api_key_pay_limit_exceeded"},
+				{Status: 403, Code: "peer_not_allowed"},
+				{Status: 403, Code: "spending_limit_exceeded"},
+				{Status: 409, Code: "spending_conflict"},
+			},
+			Fields: []openapi.Field{
+				{Key: "transaction", Resource: "TransactionResource"},
+			},
+		},
+		"ListAPIKeys": openapi.Endpoint{
+			Name:        "ListAPIKeys",
+			Description: "ListAPIKeys returns a list of API keys.",
+			Status:      200,
+			Params: []openapi.Param{
+				{Name: "limit", In: "query"},
+				{Name: "created_before", In: "query"},
+				{Name: "starting_after", In: "query"},
+				{Name: "ending_before", In: "query"},
+				{Name: "sort", In: "query"},
+				{Name: "order", In: "query"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "created_before_invalid"},
+				{Status: 400, Code: "cursor_invalid"},
+				{Status: 400, Code: "order_invalid"},
+				{Status: 400, Code: "sort_invalid"},
+			},
+			Fields: []openapi.Field{
+				{Key: "api_keys", Resource: "APIKeyResource", List: true},
+				{Key: "has_more", Type: "boolean"},
+				{Key: "next_cursor", Type: "string"},
+			},
+		},
+		"ListAssetBalances": openapi.Endpoint{
+			Name:        "ListAssetBalances",
+			Description: "ListAssetBalances returns a list of balances.",
+			Status:      200,
+			Params: []openapi.Param{
+				{Name: "asset", In: "path"},
+				{Name: "owner", In: "query"},
+				{Name: "holder", In: "query"},
+				{Name: "asset_code", In: "query"},
+				{Name: "min_value", In: "query"},
+				{Name: "max_value", In: "query"},
+				{Name: "limit", In: "query"},
+				{Name: "created_before", In: "query"},
+				{Name: "starting_after", In: "query"},
+				{Name: "ending_before", In: "query"},
+				{Name: "sort", In: "query"},
+				{Name: "order", In: "query"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "address_invalid"},
+				{Status: 400, Code: "amount_invalid"},
+				{Status: 400, Code: "asset_code_invalid"},
+				{Status: 400, Code: "asset_invalid"},
+				{Status: 400, Code: "created_before_invalid"},
+				{Status: 400, Code: "cursor_invalid"},
+				{Status: 400, Code: "not_authorized"},
+				{Status: 400, Code: "order_invalid"},
+				{Status: 400, Code: "sort_invalid"},
+			},
+			Fields: []openapi.Field{
+				{Key: "balances", Resource: "BalanceResource", List: true},
+				{Key: "has_more", Type: "boolean"},
+				{Key: "next_cursor", Type: "string"},
+			},
+		},
+		"ListAssetOffers": openapi.Endpoint{
+			Name:        "ListAssetOffers",
+			Description: "ListAssetOffers returns a list of offers.",
+			Status:      200,
+			Params: []openapi.Param{
+				{Name: "asset", In: "path"},
+				{Name: "propagation", In: "query"},
+				{Name: "owner", In: "query"},
+				{Name: "status", In: "query"},
+				{Name: "asset_code", In: "query"},
+				{Name: "min_remainder", In: "query"},
+				{Name: "max_remainder", In: "query"},
+				{Name: "limit", In: "query"},
+				{Name: "created_before", In: "query"},
+				{Name: "starting_after", In: "query"},
+				{Name: "ending_before", In: "query"},
+				{Name: "sort", In: "query"},
+				{Name: "order", In: "query"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "address_invalid"},
+				{Status: 400, Code: "amount_invalid"},
+				{Status: 400, Code: "asset_code_invalid"},
+				{Status: 400, Code: "asset_invalid"},
+				{Status: 400, Code: "created_before_invalid"},
+				{Status: 400, Code: "cursor_invalid"},
+				{Status: 400, Code: "order_invalid"},
+				{Status: 400, Code: "propagation_invalid"},
+				{Status: 400, Code: "sort_invalid"},
+				{Status: 400, Code: "status_invalid"},
+			},
+			Fields: []openapi.Field{
+				{Key: "has_more", Type: "boolean"},
+				{Key: "next_cursor", Type: "string"},
+				{Key: "offers", Resource: "OfferResource", List: true},
+			},
+		},
+		"ListAssets": openapi.Endpoint{
+			Name:        "ListAssets",
+			Description: "ListAssets returns a list of assets.",
+			Status:      200,
+			Params: []openapi.Param{
+				{Name: "code", In: "query"},
+				{Name: "limit", In: "query"},
+				{Name: "created_before", In: "query"},
+				{Name: "starting_after", In: "query"},
+				{Name: "ending_before", In: "query"},
+				{Name: "sort", In: "query"},
+				{Name: "order", In: "query"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "asset_code_invalid"},
+				{Status: 400, Code: "created_before_invalid"},
+				{Status: 400, Code: "cursor_invalid"},
+				{Status: 400, Code: "order_invalid"},
+				{Status: 400, Code: "sort_invalid"},
+			},
+			Fields: []openapi.Field{
+				{Key: "assets", Resource: "AssetResource", List: true},
+				{Key: "has_more", Type: "boolean"},
+				{Key: "next_cursor", Type: "string"},
+			},
+		},
+		"ListBalances": openapi.Endpoint{
+			Name:        "ListBalances",
+			Description: "ListBalances returns a list of balances.",
+			Status:      200,
+			Params: []openapi.Param{
+				{Name: "owner", In: "query"},
+				{Name: "holder", In: "query"},
+				{Name: "asset_code", In: "query"},
+				{Name: "min_value", In: "query"},
+				{Name: "max_value", In: "query"},
+				{Name: "limit", In: "query"},
+				{Name: "created_before", In: "query"},
+				{Name: "starting_after", In: "query"},
+				{Name: "ending_before", In: "query"},
+				{Name: "sort", In: "query"},
+				{Name: "order", In: "query"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "address_invalid"},
+				{Status: 400, Code: "amount_invalid"},
+				{Status: 400, Code: "asset_code_invalid"},
+				{Status: 400, Code: "created_before_invalid"},
+				{Status: 400, Code: "cursor_invalid"},
+				{Status: 400, Code: "order_invalid"},
+				{Status: 400, Code: "sort_invalid"},
+			},
+			Fields: []openapi.Field{
+				{Key: "balances", Resource: "BalanceResource", List: true},
+				{Key: "has_more", Type: "boolean"},
+				{Key: "next_cursor", Type: "string"},
+			},
+		},
+		"ListOffers": openapi.Endpoint{
+			Name:        "ListOffers",
+			Description: "ListOffers returns a list of the canonical offers owned by the authenticated user.",
+			Status:      200,
+			Params: []openapi.Param{
+				{Name: "owner", In: "query"},
+				{Name: "status", In: "query"},
+				{Name: "asset_code", In: "query"},
+				{Name: "min_remainder", In: "query"},
+				{Name: "max_remainder", In: "query"},
+				{Name: "limit", In: "query"},
+				{Name: "created_before", In: "query"},
+				{Name: "starting_after", In: "query"},
+				{Name: "ending_before", In: "query"},
+				{Name: "sort", In: "query"},
+				{Name: "order", In: "query"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "address_invalid"},
+				{Status: 400, Code: "amount_invalid"},
+				{Status: 400, Code: "asset_code_invalid"},
+				{Status: 400, Code: "created_before_invalid"},
+				{Status: 400, Code: "cursor_invalid"},
+				{Status: 400, Code: "order_invalid"},
+				{Status: 400, Code: "sort_invalid"},
+				{Status: 400, Code: "status_invalid"},
+			},
+			Fields: []openapi.Field{
+				{Key: "has_more", Type: "boolean"},
+				{Key: "next_cursor", Type: "string"},
+				{Key: "offers", Resource: "OfferResource", List: true},
+			},
+		},
+		"ListPeers": openapi.Endpoint{
+			Name:        "ListPeers",
+			Description: "ListPeers returns a list of the peer mints this mint interacted with along with their health.",
+			Status:      200,
+			Params: []openapi.Param{
+				{Name: "created_before", In: "query"},
+				{Name: "limit", In: "query"},
+				{Name: "starting_after", In: "query"},
+				{Name: "ending_before", In: "query"},
+				{Name: "sort", In: "query"},
+				{Name: "order", In: "query"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "created_before_invalid"},
+				{Status: 400, Code: "cursor_invalid"},
+				{Status: 400, Code: "order_invalid"},
+				{Status: 400, Code: "sort_invalid"},
+			},
+			Fields: []openapi.Field{
+				{Key: "has_more", Type: "boolean"},
+				{Key: "next_cursor", Type: "string"},
+				{Key: "peers", Resource: "PeerResource", List: true},
+			},
+		},
+		"PropagateBalance": openapi.Endpoint{
+			Name:        "PropagateBalance",
+			Description: "PropagateBalance fetches the balance propagated and creates a local propagated copy of it.",
+			Status:      201,
+			Params: []openapi.Param{
+				{Name: "balance", In: "path"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "amount_invalid"},
+				{Status: 400, Code: "id_invalid"},
+				{Status: 402, Code: "propagation_failed"},
+			},
+			Fields: []openapi.Field{
+				{Key: "balance", Resource: "BalanceResource"},
+			},
+		},
+		"PropagateOffer": openapi.Endpoint{
+			Name:        "PropagateOffer",
+			Description: "PropagateOffer retrieves a canonical offer and creates a local propagated copy of it.",
+			Status:      201,
+			Params: []openapi.Param{
+				{Name: "offer", In: "path"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "amount_invalid"},
+				{Status: 400, Code: "id_invalid"},
+				{Status: 400, Code: "price_invalid"},
+				{Status: 402, Code: "propagation_failed"},
+			},
+			Fields: []openapi.Field{
+				{Key: "offer", Resource: "OfferResource"},
+			},
+		},
+		"PropagateOperation": openapi.Endpoint{
+			Name:        "PropagateOperation",
+			Description: "PropagateOperation retrieves a canonical operation and creates a local propagated copy of it.",
+			Status:      201,
+			Params: []openapi.Param{
+				{Name: "operation", In: "path"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "id_invalid"},
+				{Status: 402, Code: "propagation_failed"},
+			},
+			Fields: []openapi.Field{
+				{Key: "operation", Resource: "OperationResource"},
+			},
+		},
+		"RetrieveAsset": openapi.Endpoint{
+			Name:        "RetrieveAsset",
+			Description: "RetrieveAsset retrieves an asset based on its name. It is not authenticated and is used to verify the existence of an asset.",
+			Status:      200,
+			Params: []openapi.Param{
+				{Name: "asset", In: "path"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "asset_invalid"},
+				{Status: 404, Code: "asset_not_found"},
+			},
+			Fields: []openapi.Field{
+				{Key: "asset", Resource: "AssetResource"},
+			},
+		},
+		"RetrieveBalance": openapi.Endpoint{
+			Name:        "RetrieveBalance",
+			Description: "RetrieveBalance retrieves an balance based on its id. It is not authenticated and is used to verify balances when they get propagated.",
+			Status:      200,
+			Params: []openapi.Param{
+				{Name: "balance", In: "path"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "id_invalid"},
+				{Status: 404, Code: "balance_not_found"},
+			},
+			Fields: []openapi.Field{
+				{Key: "balance", Resource: "BalanceResource"},
+			},
+		},
+		"RetrieveHealth": openapi.Endpoint{
+			Name:        "RetrieveHealth",
+			Description: "RetrieveHealth checks whether the mint is alive: its database is reachable and its async worker is running. It responds with a 503 if any check fails.",
+			Fields: []openapi.Field{
+				{Key: "health", Resource: "HealthResource"},
+			},
+		},
+		"RetrieveKey": openapi.Endpoint{
+			Name:        "RetrieveKey",
+			Description: "RetrieveKey retrieves the public identity key of the mint. It is not authenticated and is used by other mints to verify the signature of the requests emitted by this mint. From protocol version 1, all the identity keys of the mint are listed as well so that requests signed with a previous key can still be verified.",
+			Status:      200,
+			Errors: []openapi.Error{
+				{Status: 404, Code: "key_not_found"},
+			},
+			Fields: []openapi.Field{
+				{Key: "key", Resource: "KeyResource"},
+			},
+		},
+		"RetrieveMint": openapi.Endpoint{
+			Name:        "RetrieveMint",
+			Description: "RetrieveMint retrieves the discovery document of the mint. It is not authenticated and is used by other mints and clients to learn how to contact this mint and which capabilities it supports.",
+			Status:      200,
+			Fields: []openapi.Field{
+				{Key: "mint", Resource: "MintResource"},
+			},
+		},
+		"RetrieveOffer": openapi.Endpoint{
+			Name:        "RetrieveOffer",
+			Description: "RetrieveOffer retrieves an offer based on its id. It is not authenticated and is used to verify offers when they get propagated.",
+			Status:      200,
+			Params: []openapi.Param{
+				{Name: "offer", In: "path"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "id_invalid"},
+				{Status: 404, Code: "offer_not_found"},
+			},
+			Fields: []openapi.Field{
+				{Key: "offer", Resource: "OfferResource"},
+			},
+		},
+		"RetrieveOpenAPI": openapi.Endpoint{
+			Name:        "RetrieveOpenAPI",
+			Description: "RetrieveOpenAPI retrieves the OpenAPI document describing the API of the mint. The document is built from the spec generated from the routes and endpoints (see `go generate`).",
+			Status:      200,
+			Fields: []openapi.Field{
+				{Key: "components"},
+				{Key: "info"},
+				{Key: "openapi"},
+				{Key: "paths"},
+				{Key: "servers"},
+			},
+		},
+		"RetrieveOperation": openapi.Endpoint{
+			Name:        "RetrieveOperation",
+			Description: "RetrieveOperation retrieves an operation based on its id. It is not authenticated and is used to verify operations when they get propagated.",
+			Status:      200,
+			Params: []openapi.Param{
+				{Name: "operation", In: "path"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "id_invalid"},
+				{Status: 404, Code: "operation_not_found"},
+			},
+			Fields: []openapi.Field{
+				{Key: "operation", Resource: "OperationResource"},
+			},
+		},
+		"RetrieveOrderBook": openapi.Endpoint{
+			Name:        "RetrieveOrderBook",
+			Description: "RetrieveOrderBook returns the order book of an asset, aggregating its active offers by counter asset and price level.",
+			Status:      200,
+			Params: []openapi.Param{
+				{Name: "asset", In: "path"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "asset_invalid"},
+			},
+			Fields: []openapi.Field{
+				{Key: "book", Resource: "OrderBookResource"},
+			},
+		},
+		"RetrieveReadiness": openapi.Endpoint{
+			Name:        "RetrieveReadiness",
+			Description: "RetrieveReadiness checks whether the mint is ready to serve requests: on top of the health checks, the pending and failed tasks must be under their thresholds and, in production, the certificate file must not be about to expire. It responds with a 503 if any check fails.",
+			Fields: []openapi.Field{
+				{Key: "health", Resource: "HealthResource"},
+			},
+		},
+		"RetrieveTransaction": openapi.Endpoint{
+			Name:        "RetrieveTransaction",
+			Description: "RetrieveTransaction retrieves a transaction based on its id. It is not authenticated and is used to propagate transactions. If `wait_for` is specified, the request blocks until the transaction reaches that status or the timeout passes, returning the transaction in its current state.",
+			Status:      200,
+			Params: []openapi.Param{
+				{Name: "transaction", In: "path"},
+				{Name: "wait_for", In: "query"},
+				{Name: "timeout", In: "query"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "id_invalid"},
+				{Status: 400, Code: "timeout_invalid"},
+				{Status: 400, Code: "wait_for_invalid"},
+				{Status: 404, Code: "transaction_not_found"},
+			},
+			Fields: []openapi.Field{
+				{Key: "transaction", Resource: "TransactionResource"},
+			},
+		},
+		"RetrieveTransactionTrace": openapi.Endpoint{
+			Name:        "RetrieveTransactionTrace",
+			Description: "RetrieveTransactionTrace retrieves the spans recorded by this mint for a transaction. It is restricted to the transaction owner or destination, in which case the spans recorded by the other mints involved in the transaction are retrieved and merged as well, and to the other mints involved (with signed requests) which use it to stitch the trace together.",
+			Status:      200,
+			Params: []openapi.Param{
+				{Name: "transaction", In: "path"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "id_invalid"},
+				{Status: 403, Code: "trace_forbidden"},
+				{Status: 404, Code: "transaction_not_found"},
+			},
+			Fields: []openapi.Field{
+				{Key: "trace", Resource: "TraceResource"},
+			},
+		},
+		"RetrieveUsage": openapi.Endpoint{
+			Name:        "RetrieveUsage",
+			Description: "RetrieveUsage returns the spending policies of a user along with the amounts spent under each of them.",
+			Status:      200,
+			Params: []openapi.Param{
+				{Name: "user", In: "path"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "username_invalid"},
+				{Status: 404, Code: "user_not_found"},
+			},
+			Fields: []openapi.Field{
+				{Key: "spending_policies", Resource: "SpendingPolicyResource", List: true},
+			},
+		},
+		"RevokeAPIKey": openapi.Endpoint{
+			Name:        "RevokeAPIKey",
+			Description: "RevokeAPIKey revokes an API key, making it unusable to authenticate.",
+			Status:      200,
+			Params: []openapi.Param{
+				{Name: "key", In: "path"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "id_invalid"},
+				{Status: 400, Code: "not_authorized"},
+				{Status: 404, Code: "api_key_not_found"},
+			},
+			Fields: []openapi.Field{
+				{Key: "api_key", Resource: "APIKeyResource"},
+			},
+		},
+		"SettleTransaction": openapi.Endpoint{
+			Name:        "SettleTransaction",
+			Description: "SettleTransaction creates a new transaction.",
+			Status:      200,
+			Params: []openapi.Param{
+				{Name: "hop", In: "form"},
+				{Name: "secret", In: "form"},
+				{Name: "transaction", In: "path"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "hop_invalid"},
+				{Status: 400, Code: "id_invalid"},
+				{Status: 400, Code: "secret_invalid"},
+				{Status: 402, Code: "settlement_failed"},
+				{Status: 402, Code: "settlement_not_authorized"},
+				{Status: 404, Code: "transaction_not_found"},
+			},
+			Fields: []openapi.Field{
+				{Key: "transaction", Resource: "TransactionResource"},
+			},
+		},
+		"StreamEvents": openapi.Endpoint{
+			Name:        "StreamEvents",
+			Description: "StreamEvents streams the events of the authenticated user as server-sent events. Streams are woken up by the hub when events are committed and are closed after mint.EventsStreamDurationMs, clients being expected to reconnect with the `Last-Event-ID` header to resume the stream.",
+			Stream:      true,
+			Params: []openapi.Param{
+				{Name: "Last-Event-ID", In: "header"},
+				{Name: "last_event_id", In: "query"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "last_event_id_invalid"},
+			},
+		},
+	},
+	Resources: []interface{}{
+		mint.AssetResource{},
+		mint.BalanceResource{},
+		mint.OperationResource{},
+		mint.OfferResource{},
+		mint.CrossingResource{},
+		mint.TransactionResource{},
+		mint.KeyResource{},
+		mint.MintResource{},
+		mint.PeerResource{},
+		mint.APIKeyResource{},
+		mint.UserResource{},
+		mint.SpendingPolicyResource{},
+		mint.EventResource{},
+		mint.SpanResource{},
+		mint.TraceResource{},
+		mint.HealthResource{},
+		mint.OrderBookResource{},
+		mint.OrderBookPairResource{},
+		mint.PriceLevelResource{},
+	},
+}
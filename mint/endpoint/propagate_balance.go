@@ -181,11 +181,11 @@ func (e *PropagateBalance) Execute(
 			return nil, nil, errors.Trace(err) // 500
 		}
 
-		mint.Logf(ctx,
-			"Propagated balance: id=%s[%s] created=%q propagation=%s "+
-				"asset=%s holder=%s value=%s",
-			bal.Owner, bal.Token, bal.Created, bal.Propagation, bal.Asset,
-			bal.Holder, (*big.Int)(&bal.Value).String())
+		mint.Info(ctx, "Propagated balance",
+			"id", bal.ID(),
+			"created", bal.Created, "propagation", bal.Propagation,
+			"asset", bal.Asset, "holder", bal.Holder,
+			"value", (*big.Int)(&bal.Value))
 	}
 
 	db.Commit(ctx)
@@ -216,14 +216,13 @@ func (e *PropagateOffer) Execute(
 			return nil, nil, errors.Trace(err) // 500
 		}
 
-		mint.Logf(ctx,
-			"Propagated offer: id=%s[%s] created=%q propagation=%s "+
-				"base_asset=%s quote_asset=%s base_price=%s quote_price=%s "+
-				"amount=%s status=%s remainder=%s",
-			of.Owner, of.Token, of.Created, of.Propagation, of.BaseAsset,
-			of.QuoteAsset, of.BasePrice, of.QuotePrice,
-			(*big.Int)(&of.Amount).String(), of.Status,
-			(*big.Int)(&of.Remainder).String())
+		mint.Info(ctx, "Propagated offer",
+			"id", of.ID(),
+			"created", of.Created, "propagation", of.Propagation,
+			"base_asset", of.BaseAsset, "quote_asset", of.QuoteAsset,
+			"base_price", of.BasePrice, "quote_price", of.QuotePrice,
+			"amount", (*big.Int)(&of.Amount), "status", of.Status,
+			"remainder", (*big.Int)(&of.Remainder))
 	}
 
 	db.Commit(ctx)
@@ -210,13 +210,12 @@ func (e *PropagateOperation) ExecutePropagated(
 			return nil, nil, errors.Trace(err) // 500
 		}
 
-		mint.Logf(ctx,
-			"Propagated operation: id=%s[%s] created=%q propagation=%s "+
-				"asset=%s source=%s destination=%s amount=%s "+
-				"status=%s transaction=%s",
-			op.Owner, op.Token, op.Created, op.Propagation, op.Asset,
-			op.Source, op.Destination, (*big.Int)(&op.Amount).String(),
-			op.Status, *op.Transaction)
+		mint.Info(ctx, "Propagated operation",
+			"id", op.ID(),
+			"created", op.Created, "propagation", op.Propagation,
+			"asset", op.Asset, "source", op.Source,
+			"destination", op.Destination, "amount", (*big.Int)(&op.Amount),
+			"status", op.Status, "transaction", *op.Transaction)
 	}
 
 	db.Commit(ctx)
@@ -0,0 +1,84 @@
+package endpoint
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/format"
+	"github.com/spolu/settle/lib/ptr"
+	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/lib/version"
+	"github.com/spolu/settle/mint/model"
+)
+
+const (
+	// EndPtRetrieveKey retrieves the mint identity key.
+	EndPtRetrieveKey EndPtName = "RetrieveKey"
+)
+
+func init() {
+	registrar[EndPtRetrieveKey] = NewRetrieveKey
+}
+
+// RetrieveKey retrieves the public identity key of the mint. It is not
+// authenticated and is used by other mints to verify the signature of the
+// requests emitted by this mint. From protocol version 1, all the identity keys
+// of the mint are listed as well so that requests signed with a previous key
+// can still be verified.
+type RetrieveKey struct{}
+
+// NewRetrieveKey constructs and initialiezes the endpoint.
+func NewRetrieveKey(
+	r *http.Request,
+) (Endpoint, error) {
+	return &RetrieveKey{}, nil
+}
+
+// Validate validates the input parameters.
+func (e *RetrieveKey) Validate(
+	r *http.Request,
+) error {
+	return nil
+}
+
+// Execute executes the endpoint.
+func (e *RetrieveKey) Execute(
+	ctx context.Context,
+) (*int, *svc.Resp, error) {
+	ctx = db.Begin(ctx, "mint")
+	defer db.LoggedRollback(ctx)
+
+	key, err := model.LoadLatestIdentityKey(ctx)
+	if err != nil {
+		return nil, nil, errors.Trace(err) // 500
+	} else if key == nil {
+		return nil, nil, errors.Trace(errors.NewUserErrorf(nil,
+			404, "key_not_found",
+			"This mint does not have any identity key.",
+		))
+	}
+
+	resp := svc.Resp{
+		"key": format.JSONPtr(model.NewKeyResource(ctx, key)),
+	}
+
+	if version.Get(ctx) != "0" {
+		keys, err := model.LoadIdentityKeyList(ctx)
+		if err != nil {
+			return nil, nil, errors.Trace(err) // 500
+		}
+		l := []mint.KeyResource{}
+		for _, k := range keys {
+			k := k
+			l = append(l, model.NewKeyResource(ctx, &k))
+		}
+		resp["keys"] = format.JSONPtr(l)
+	}
+
+	db.Commit(ctx)
+
+	return ptr.Int(http.StatusOK), &resp, nil
+}
@@ -0,0 +1,72 @@
+package endpoint
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/format"
+	"github.com/spolu/settle/lib/ptr"
+	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/model"
+)
+
+const (
+	// EndPtRetrieveMint retrieves the mint discovery document.
+	EndPtRetrieveMint EndPtName = "RetrieveMint"
+)
+
+func init() {
+	registrar[EndPtRetrieveMint] = NewRetrieveMint
+}
+
+// RetrieveMint retrieves the discovery document of the mint. It is not
+// authenticated and is used by other mints and clients to learn how to
+// contact this mint and which capabilities it supports.
+type RetrieveMint struct{}
+
+// NewRetrieveMint constructs and initialiezes the endpoint.
+func NewRetrieveMint(
+	r *http.Request,
+) (Endpoint, error) {
+	return &RetrieveMint{}, nil
+}
+
+// Validate validates the input parameters.
+func (e *RetrieveMint) Validate(
+	r *http.Request,
+) error {
+	return nil
+}
+
+// Execute executes the endpoint.
+func (e *RetrieveMint) Execute(
+	ctx context.Context,
+) (*int, *svc.Resp, error) {
+	ctx = db.Begin(ctx, "mint")
+	defer db.LoggedRollback(ctx)
+
+	key, err := model.LoadLatestIdentityKey(ctx)
+	if err != nil {
+		return nil, nil, errors.Trace(err) // 500
+	}
+
+	db.Commit(ctx)
+
+	keys := []mint.KeyResource{}
+	if key != nil {
+		keys = append(keys, model.NewKeyResource(ctx, key))
+	}
+
+	return ptr.Int(http.StatusOK), &svc.Resp{
+		"mint": format.JSONPtr(mint.MintResource{
+			Host:             mint.GetHost(ctx),
+			ProtocolVersions: mint.ProtocolVersions,
+			APIBaseURL:       mint.GetAPIBaseURL(ctx),
+			Keys:             keys,
+			Features:         mint.Features,
+		}),
+	}, nil
+}
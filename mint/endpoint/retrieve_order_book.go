@@ -0,0 +1,79 @@
+package endpoint
+
+import (
+	"context"
+	"net/http"
+
+	"goji.io/pat"
+
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/format"
+	"github.com/spolu/settle/lib/ptr"
+	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/lib/book"
+	"github.com/spolu/settle/mint/model"
+)
+
+const (
+	// EndPtRetrieveOrderBook retrieves the order book of an asset.
+	EndPtRetrieveOrderBook EndPtName = "RetrieveOrderBook"
+)
+
+func init() {
+	registrar[EndPtRetrieveOrderBook] = NewRetrieveOrderBook
+}
+
+// RetrieveOrderBook returns the order book of an asset, aggregating its
+// active offers by counter asset and price level.
+type RetrieveOrderBook struct {
+	Asset mint.AssetResource
+}
+
+// NewRetrieveOrderBook constructs and initialiezes the endpoint.
+func NewRetrieveOrderBook(
+	r *http.Request,
+) (Endpoint, error) {
+	return &RetrieveOrderBook{}, nil
+}
+
+// Validate validates the input parameters.
+func (e *RetrieveOrderBook) Validate(
+	r *http.Request,
+) error {
+	ctx := r.Context()
+
+	// Validate asset.
+	asset, err := ValidateAsset(ctx, pat.Param(r, "asset"))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.Asset = *asset
+
+	return nil
+}
+
+// Execute executes the endpoint.
+func (e *RetrieveOrderBook) Execute(
+	ctx context.Context,
+) (*int, *svc.Resp, error) {
+	ctx = db.Begin(ctx, "mint")
+	defer db.LoggedRollback(ctx)
+
+	offers, err := model.LoadActiveOfferListByAsset(ctx, e.Asset.Name)
+	if err != nil {
+		return nil, nil, errors.Trace(err) // 500
+	}
+
+	db.Commit(ctx)
+
+	b, err := book.Compute(ctx, e.Asset, offers)
+	if err != nil {
+		return nil, nil, errors.Trace(err) // 500
+	}
+
+	return ptr.Int(http.StatusOK), &svc.Resp{
+		"book": format.JSONPtr(b),
+	}, nil
+}
@@ -3,12 +3,15 @@ package endpoint
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/spolu/settle/lib/db"
 	"github.com/spolu/settle/lib/errors"
 	"github.com/spolu/settle/lib/format"
 	"github.com/spolu/settle/lib/ptr"
 	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/lib/hub"
 	"github.com/spolu/settle/mint/model"
 	"goji.io/pat"
 )
@@ -23,11 +26,16 @@ func init() {
 }
 
 // RetrieveTransaction retrieves a transaction based on its id. It is not
-// authenticated and is used to propagate transactions.
+// authenticated and is used to propagate transactions. If `wait_for` is
+// specified, the request blocks until the transaction reaches that status or
+// the timeout passes, returning the transaction in its current state.
 type RetrieveTransaction struct {
 	ID    string
 	Token string
 	Owner string
+
+	WaitFor *mint.TxStatus
+	Timeout time.Duration
 }
 
 // NewRetrieveTransaction constructs and initialiezes the endpoint.
@@ -53,6 +61,24 @@ func (e *RetrieveTransaction) Validate(
 	e.Token = *token
 	e.Owner = *owner
 
+	// Validate wait_for and timeout.
+	if r.URL.Query().Get("wait_for") != "" {
+		waitFor, err := ValidateWaitFor(ctx, r.URL.Query().Get("wait_for"))
+		if err != nil {
+			return errors.Trace(err)
+		}
+		e.WaitFor = waitFor
+
+		e.Timeout = time.Duration(mint.TransactionWaitMaxMs) * time.Millisecond
+		if r.URL.Query().Get("timeout") != "" {
+			timeout, err := ValidateTimeout(ctx, r.URL.Query().Get("timeout"))
+			if err != nil {
+				return errors.Trace(err)
+			}
+			e.Timeout = *timeout
+		}
+	}
+
 	return nil
 }
 
@@ -60,6 +86,13 @@ func (e *RetrieveTransaction) Validate(
 func (e *RetrieveTransaction) Execute(
 	ctx context.Context,
 ) (*int, *svc.Resp, error) {
+	if e.WaitFor != nil {
+		err := e.Wait(ctx)
+		if err != nil {
+			return nil, nil, errors.Trace(err) // 500
+		}
+	}
+
 	ctx = db.Begin(ctx, "mint")
 	defer db.LoggedRollback(ctx)
 
@@ -92,3 +125,61 @@ func (e *RetrieveTransaction) Execute(
 		)),
 	}, nil
 }
+
+// Wait waits for the transaction to reach the status it waits for, a final
+// status or the timeout to pass. Waiters are woken up by the hub when the
+// transaction status changes and otherwise reload it periodically.
+func (e *RetrieveTransaction) Wait(
+	ctx context.Context,
+) error {
+	notified, unsubscribe := hub.Get(ctx).Subscribe(e.ID)
+	defer unsubscribe()
+
+	timeout := time.After(e.Timeout)
+	poll := time.NewTicker(
+		time.Duration(mint.TransactionWaitPollMs) * time.Millisecond)
+	defer poll.Stop()
+
+	for {
+		status, err := e.status(ctx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if status == nil ||
+			*status == *e.WaitFor ||
+			*status == mint.TxStSettled ||
+			*status == mint.TxStCanceled {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timeout:
+			return nil
+		case <-notified:
+		case <-poll.C:
+		}
+	}
+}
+
+// status loads the current status of the transaction (nil if it does not
+// exist).
+func (e *RetrieveTransaction) status(
+	ctx context.Context,
+) (*mint.TxStatus, error) {
+	ctx = db.Begin(ctx, "mint")
+	defer db.LoggedRollback(ctx)
+
+	tx, err := model.LoadTransactionByID(ctx, e.ID)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	db.Commit(ctx)
+
+	if tx == nil {
+		return nil, nil
+	}
+	return &tx.Status, nil
+}
@@ -0,0 +1,209 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/format"
+	"github.com/spolu/settle/lib/ptr"
+	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/lib/trace"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/lib/authentication"
+	"github.com/spolu/settle/mint/lib/signature"
+	"github.com/spolu/settle/mint/model"
+	"goji.io/pat"
+)
+
+const (
+	// EndPtRetrieveTransactionTrace retrieves the trace of a transaction.
+	EndPtRetrieveTransactionTrace EndPtName = "RetrieveTransactionTrace"
+)
+
+func init() {
+	registrar[EndPtRetrieveTransactionTrace] = NewRetrieveTransactionTrace
+}
+
+// RetrieveTransactionTrace retrieves the spans recorded by this mint for a
+// transaction. It is restricted to the transaction owner or destination, in
+// which case the spans recorded by the other mints involved in the
+// transaction are retrieved and merged as well, and to the other mints
+// involved (with signed requests) which use it to stitch the trace together.
+type RetrieveTransactionTrace struct {
+	Client *mint.Client
+
+	ID    string
+	Token string
+	Owner string
+}
+
+// NewRetrieveTransactionTrace constructs and initialiezes the endpoint.
+func NewRetrieveTransactionTrace(
+	r *http.Request,
+) (Endpoint, error) {
+	ctx := r.Context()
+
+	client := &mint.Client{}
+	err := client.Init(ctx)
+	if err != nil {
+		return nil, errors.Trace(err) // 500
+	}
+	return &RetrieveTransactionTrace{
+		Client: client,
+	}, nil
+}
+
+// Validate validates the input parameters.
+func (e *RetrieveTransactionTrace) Validate(
+	r *http.Request,
+) error {
+	ctx := r.Context()
+
+	// Validate id.
+	id, owner, token, err := ValidateID(ctx, pat.Param(r, "transaction"))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.ID = *id
+	e.Token = *token
+	e.Owner = *owner
+
+	return nil
+}
+
+// Execute executes the endpoint.
+func (e *RetrieveTransactionTrace) Execute(
+	ctx context.Context,
+) (*int, *svc.Resp, error) {
+	ctx = db.Begin(ctx, "mint")
+	defer db.LoggedRollback(ctx)
+
+	tx, err := model.LoadTransactionByID(ctx, e.ID)
+	if err != nil {
+		return nil, nil, errors.Trace(err) // 500
+	} else if tx == nil {
+		return nil, nil, errors.Trace(errors.NewUserErrorf(nil,
+			404, "transaction_not_found",
+			"The transaction whose trace you are trying to retrieve does "+
+				"not exist: %s.", e.ID,
+		))
+	}
+
+	db.Commit(ctx)
+
+	stitch := false
+	if authentication.Get(ctx).Status == authentication.AutStSucceeded {
+		address := fmt.Sprintf("%s@%s",
+			authentication.Get(ctx).User.Username, mint.GetHost(ctx))
+		stitch = address == tx.Owner || address == tx.Destination
+	}
+	involved := false
+	if host := signature.Get(ctx).Host; host != "" {
+		for _, h := range transactionHosts(ctx, tx) {
+			involved = involved || h == host
+		}
+	}
+	if !stitch && !involved {
+		return nil, nil, errors.Trace(errors.NewUserErrorf(nil,
+			403, "trace_forbidden",
+			"The trace of a transaction can only be retrieved by its owner, "+
+				"its destination or the mints involved in it: %s.", e.ID,
+		))
+	}
+
+	t := mint.TraceResource{
+		Transaction: e.ID,
+		Spans:       []mint.SpanResource{},
+		Unreachable: []string{},
+	}
+	for _, s := range trace.Get(ctx).Spans(e.ID) {
+		t.Spans = append(t.Spans, mint.NewSpanResource(ctx, s))
+	}
+
+	if stitch {
+		e.Stitch(ctx, tx, &t)
+	}
+
+	sort.SliceStable(t.Spans, func(i, j int) bool {
+		return t.Spans[i].Start < t.Spans[j].Start
+	})
+
+	return ptr.Int(http.StatusOK), &svc.Resp{
+		"trace": format.JSONPtr(t),
+	}, nil
+}
+
+// Stitch retrieves concurrently the spans recorded by the other mints
+// involved in the transaction (the mints of its owner, offers and
+// destination) and adds them to the trace. Mints that can't be reached are
+// listed as unreachable.
+func (e *RetrieveTransactionTrace) Stitch(
+	ctx context.Context,
+	tx *model.Transaction,
+	t *mint.TraceResource,
+) {
+	hosts := []string{}
+	for _, host := range transactionHosts(ctx, tx) {
+		if host != mint.GetHost(ctx) {
+			hosts = append(hosts, host)
+		}
+	}
+
+	traces := make([]*mint.TraceResource, len(hosts))
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		go func(i int, host string) {
+			defer wg.Done()
+			pt, err := e.Client.RetrieveTransactionTrace(ctx, e.ID, host)
+			if err != nil {
+				mint.Warn(ctx, "Trace retrieval failed",
+					"transaction", e.ID, "peer", host, "error", err)
+				return
+			}
+			traces[i] = pt
+		}(i, host)
+	}
+	wg.Wait()
+
+	for i, pt := range traces {
+		if pt == nil {
+			t.Unreachable = append(t.Unreachable, hosts[i])
+			continue
+		}
+		t.Spans = append(t.Spans, pt.Spans...)
+	}
+}
+
+// transactionHosts returns the hosts of the mints involved in a transaction
+// (the mints of its owner, offers and destination), in order.
+func transactionHosts(
+	ctx context.Context,
+	tx *model.Transaction,
+) []string {
+	addresses := []string{tx.Owner}
+	for _, id := range tx.Path {
+		owner, _, err := mint.NormalizedOwnerAndTokenFromID(ctx, id)
+		if err == nil {
+			addresses = append(addresses, owner)
+		}
+	}
+	addresses = append(addresses, tx.Destination)
+
+	hosts := []string{}
+	seen := map[string]bool{}
+	for _, address := range addresses {
+		_, host, err := mint.UsernameAndMintHostFromAddress(ctx, address)
+		if err != nil || seen[host] {
+			continue
+		}
+		seen[host] = true
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
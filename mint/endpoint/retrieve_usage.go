@@ -0,0 +1,113 @@
+package endpoint
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/format"
+	"github.com/spolu/settle/lib/ptr"
+	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/lib/authentication"
+	"github.com/spolu/settle/mint/model"
+	"goji.io/pat"
+)
+
+const (
+	// EndPtRetrieveUsage retrieves the spending policies and current usage of
+	// the authenticated user.
+	EndPtRetrieveUsage EndPtName = "RetrieveUsage"
+	// EndPtAdminRetrieveUserUsage retrieves the spending policies and current
+	// usage of a user.
+	EndPtAdminRetrieveUserUsage EndPtName = "AdminRetrieveUserUsage"
+)
+
+func init() {
+	registrar[EndPtRetrieveUsage] = NewRetrieveUsage
+	registrar[EndPtAdminRetrieveUserUsage] = NewAdminRetrieveUserUsage
+}
+
+// RetrieveUsage returns the spending policies of a user along with the
+// amounts spent under each of them.
+type RetrieveUsage struct {
+	Admin    bool
+	Username string
+}
+
+// NewRetrieveUsage constructs and initialiezes the endpoint.
+func NewRetrieveUsage(
+	r *http.Request,
+) (Endpoint, error) {
+	return &RetrieveUsage{}, nil
+}
+
+// NewAdminRetrieveUserUsage constructs and initialiezes the endpoint.
+func NewAdminRetrieveUserUsage(
+	r *http.Request,
+) (Endpoint, error) {
+	return &RetrieveUsage{
+		Admin: true,
+	}, nil
+}
+
+// Validate validates the input parameters.
+func (e *RetrieveUsage) Validate(
+	r *http.Request,
+) error {
+	ctx := r.Context()
+
+	if !e.Admin {
+		e.Username = authentication.Get(ctx).User.Username
+		return nil
+	}
+
+	username, err := ValidateUsername(ctx, pat.Param(r, "user"))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.Username = *username
+
+	return nil
+}
+
+// Execute executes the endpoint.
+func (e *RetrieveUsage) Execute(
+	ctx context.Context,
+) (*int, *svc.Resp, error) {
+	ctx = db.Begin(ctx, "mint")
+	defer db.LoggedRollback(ctx)
+
+	user, err := model.LoadUserByUsername(ctx, e.Username)
+	if err != nil {
+		return nil, nil, errors.Trace(err) // 500
+	} else if user == nil {
+		return nil, nil, errors.Trace(errors.NewUserErrorf(nil,
+			404, "user_not_found",
+			"The user you are trying to retrieve does not exist: %s.",
+			e.Username,
+		))
+	}
+
+	policies, err := model.LoadSpendingPolicyListByUser(ctx, user.Token)
+	if err != nil {
+		return nil, nil, errors.Trace(err) // 500
+	}
+
+	now := time.Now()
+	l := []mint.SpendingPolicyResource{}
+	for _, p := range policies {
+		p := p
+		daySpent, windowSpent := p.Usage(now)
+		l = append(l, model.NewSpendingPolicyResource(ctx,
+			&p, daySpent, windowSpent))
+	}
+
+	db.Commit(ctx)
+
+	return ptr.Int(http.StatusOK), &svc.Resp{
+		"spending_policies": format.JSONPtr(l),
+	}, nil
+}
@@ -0,0 +1,103 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/format"
+	"github.com/spolu/settle/lib/ptr"
+	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/lib/authentication"
+	"github.com/spolu/settle/mint/model"
+	"goji.io/pat"
+)
+
+const (
+	// EndPtRevokeAPIKey revokes an API key.
+	EndPtRevokeAPIKey EndPtName = "RevokeAPIKey"
+)
+
+func init() {
+	registrar[EndPtRevokeAPIKey] = NewRevokeAPIKey
+}
+
+// RevokeAPIKey revokes an API key, making it unusable to authenticate.
+type RevokeAPIKey struct {
+	ID        string
+	UserToken string
+	Token     string
+}
+
+// NewRevokeAPIKey constructs and initialiezes the endpoint.
+func NewRevokeAPIKey(
+	r *http.Request,
+) (Endpoint, error) {
+	return &RevokeAPIKey{}, nil
+}
+
+// Validate validates the input parameters.
+func (e *RevokeAPIKey) Validate(
+	r *http.Request,
+) error {
+	ctx := r.Context()
+
+	user := authentication.Get(ctx).User
+	e.UserToken = user.Token
+
+	// Validate id.
+	id, owner, token, err := ValidateID(ctx, pat.Param(r, "key"))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.ID = *id
+	e.Token = *token
+
+	// Validate that the authenticated user owns the key.
+	address := fmt.Sprintf("%s@%s", user.Username, mint.GetHost(ctx))
+	if address != *owner {
+		return errors.Trace(errors.NewUserErrorf(nil,
+			400, "not_authorized",
+			"You can only revoke an API key that is owned by the account "+
+				"you are currently authenticated with: %s. The requested API "+
+				"key is owned by: %s.",
+			address, *owner,
+		))
+	}
+
+	return nil
+}
+
+// Execute executes the endpoint.
+func (e *RevokeAPIKey) Execute(
+	ctx context.Context,
+) (*int, *svc.Resp, error) {
+	ctx = db.Begin(ctx, "mint")
+	defer db.LoggedRollback(ctx)
+
+	key, err := model.LoadAPIKeyByToken(ctx, e.Token)
+	if err != nil {
+		return nil, nil, errors.Trace(err) // 500
+	} else if key == nil || key.UserToken != e.UserToken {
+		return nil, nil, errors.Trace(errors.NewUserErrorf(nil,
+			404, "api_key_not_found",
+			"The API key you are trying to revoke does not exist: %s.",
+			e.ID,
+		))
+	}
+
+	err = key.Revoke(ctx, time.Now().UTC())
+	if err != nil {
+		return nil, nil, errors.Trace(err) // 500
+	}
+
+	db.Commit(ctx)
+
+	return ptr.Int(http.StatusOK), &svc.Resp{
+		"api_key": format.JSONPtr(model.NewAPIKeyResource(ctx, key, nil)),
+	}, nil
+}
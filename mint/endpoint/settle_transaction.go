@@ -15,11 +15,13 @@ import (
 	"github.com/spolu/settle/lib/format"
 	"github.com/spolu/settle/lib/ptr"
 	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/lib/trace"
 	"github.com/spolu/settle/mint"
 	"github.com/spolu/settle/mint/async"
 	"github.com/spolu/settle/mint/async/task"
 	"github.com/spolu/settle/mint/lib/authentication"
 	"github.com/spolu/settle/mint/lib/plan"
+	"github.com/spolu/settle/mint/lib/signature"
 	"github.com/spolu/settle/mint/model"
 	"goji.io/pat"
 )
@@ -71,17 +73,22 @@ func (e *SettleTransaction) Validate(
 ) error {
 	ctx := r.Context()
 
+	params, err := svc.ParseParams(r)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
 	switch authentication.Get(ctx).Status {
 	case authentication.AutStSkipped:
 		// Validate hop.
-		hop, err := ValidateHop(ctx, r.PostFormValue("hop"))
+		hop, err := ValidateHop(ctx, params.Get("hop"))
 		if err != nil {
 			return errors.Trace(err)
 		}
 		e.Hop = *hop
 
 		// Validate secret.
-		secret, err := ValidateSecret(ctx, r.PostFormValue("secret"))
+		secret, err := ValidateSecret(ctx, params.Get("secret"))
 		if err != nil {
 			return errors.Trace(err)
 		}
@@ -104,15 +111,31 @@ func (e *SettleTransaction) Validate(
 func (e *SettleTransaction) Execute(
 	ctx context.Context,
 ) (*int, *svc.Resp, error) {
+	ctx, span := trace.Start(ctx, "transaction.settle")
+
+	var code *int
+	var resp *svc.Resp
+	var err error
 	switch authentication.Get(ctx).Status {
 	case authentication.AutStSkipped:
-		return e.ExecutePropagated(ctx)
+		code, resp, err = e.ExecutePropagated(ctx)
 	case authentication.AutStSucceeded:
-		return e.ExecuteCanonical(ctx)
+		code, resp, err = e.ExecuteCanonical(ctx)
+	default:
+		err = errors.Trace(errors.Newf(
+			"Authentication status not expected: %s",
+			authentication.Get(ctx).Status))
 	}
-	return nil, nil, errors.Trace(errors.Newf(
-		"Authentication status not expected: %s",
-		authentication.Get(ctx).Status))
+
+	// The trace is linked to the transaction so that its spans are returned
+	// by RetrieveTransactionTrace.
+	if e.ID != "" {
+		span.Set("transaction", e.ID, "hop", e.Hop)
+		trace.Link(ctx, e.ID)
+	}
+	span.Finish(err)
+
+	return code, resp, err
 }
 
 // ExecuteCanonical executes the canonical settlement of a transaction (owner
@@ -194,9 +217,8 @@ func (e *SettleTransaction) ExecuteCanonical(
 	err = e.Propagate(ctx)
 	if err != nil {
 		// If propagation failed we log it and trigger an asyncrhonous one.
-		mint.Logf(ctx,
-			"Settlement propagation failed: transaction=%s hop=%d error=%s",
-			e.ID, e.Hop, err.Error())
+		mint.Warn(ctx, "Settlement propagation failed",
+			"transaction", e.ID, "hop", e.Hop, "error", err)
 		err = async.Queue(ctx,
 			task.NewPropagateSettlement(ctx,
 				time.Now(), fmt.Sprintf("%s|%d", e.ID, e.Hop)))
@@ -282,6 +304,12 @@ func (e *SettleTransaction) ExecutePropagated(
 		))
 	}
 
+	// Settlements are propagated hop by hop starting from the canonical mint
+	// (the first hop), check that the request emanates from the next hop.
+	if err := signature.CheckPeer(ctx, pl.Emitter(e.Hop)); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+
 	// Check for potential opportunity to cancel before settling.
 	if pl.CheckShouldCancel(ctx, e.Client, e.Hop) {
 		// Commit the transaction while we cancel.
@@ -292,9 +320,8 @@ func (e *SettleTransaction) ExecutePropagated(
 		_, err := e.Client.CancelTransaction(ctx,
 			e.ID, e.Hop, mint.GetHost(ctx))
 		if err != nil {
-			mint.Logf(ctx,
-				"Opportunistic cancellation failed: transaction=%s hop=%d error=%s",
-				e.ID, e.Hop, err.Error())
+			mint.Warn(ctx, "Opportunistic cancellation failed",
+				"transaction", e.ID, "hop", e.Hop, "error", err)
 		}
 
 		// Reopen a DB transaction and reload the transaction, hopefully
@@ -383,9 +410,8 @@ func (e *SettleTransaction) ExecutePropagated(
 	err = e.Propagate(ctx)
 	if err != nil {
 		// If propagation failed we log it and trigger an asyncrhonous one.
-		mint.Logf(ctx,
-			"Settlement propagation failed: transaction=%s hop=%d error=%s",
-			e.ID, e.Hop, err.Error())
+		mint.Warn(ctx, "Settlement propagation failed",
+			"transaction", e.ID, "hop", e.Hop, "error", err)
 		err = async.Queue(ctx,
 			task.NewPropagateSettlement(ctx,
 				time.Now(), fmt.Sprintf("%s|%d", e.ID, e.Hop)))
@@ -413,8 +439,8 @@ func (e *SettleTransaction) Settle(
 	}
 
 	h := e.Plan.Hops[e.Hop]
-	mint.Logf(ctx,
-		"Executing settlement plan: transaction=%s hop=%d", e.ID, e.Hop)
+	mint.Info(ctx, "Executing settlement plan",
+		"transaction", e.ID, "hop", e.Hop)
 
 	// Settle the OpAction (should always be defined)
 	if h.OpAction != nil {
@@ -429,13 +455,12 @@ func (e *SettleTransaction) Settle(
 		}
 
 		if op.Status == mint.TxStSettled {
-			mint.Logf(ctx,
-				"Skipped operation: id=%s[%s] created=%q propagation=%s "+
-					"asset=%s source=%s destination=%s amount=%s "+
-					"status=%s transaction=%s",
-				op.Owner, op.Token, op.Created, op.Propagation, op.Asset,
-				op.Source, op.Destination, (*big.Int)(&op.Amount).String(),
-				op.Status, *op.Transaction)
+			mint.Info(ctx, "Skipped operation",
+				"id", op.ID(),
+				"created", op.Created, "propagation", op.Propagation,
+				"asset", op.Asset, "source", op.Source,
+				"destination", op.Destination, "amount", (*big.Int)(&op.Amount),
+				"status", op.Status, "transaction", *op.Transaction)
 
 		} else {
 			a := h.OpAction
@@ -488,13 +513,12 @@ func (e *SettleTransaction) Settle(
 				return errors.Trace(err)
 			}
 
-			mint.Logf(ctx,
-				"Settled operation: id=%s[%s] created=%q propagation=%s "+
-					"asset=%s source=%s destination=%s amount=%s "+
-					"status=%s transaction=%s",
-				op.Owner, op.Token, op.Created, op.Propagation, op.Asset,
-				op.Source, op.Destination, (*big.Int)(&op.Amount).String(),
-				op.Status, *op.Transaction)
+			mint.Info(ctx, "Settled operation",
+				"id", op.ID(),
+				"created", op.Created, "propagation", op.Propagation,
+				"asset", op.Asset, "source", op.Source,
+				"destination", op.Destination, "amount", (*big.Int)(&op.Amount),
+				"status", op.Status, "transaction", *op.Transaction)
 
 			opID := op.ID()
 			err = async.Queue(ctx,
@@ -517,11 +541,11 @@ func (e *SettleTransaction) Settle(
 		}
 
 		if cr.Status == mint.TxStSettled {
-			mint.Logf(ctx,
-				"Skipped crossing: id=%s[%s] created=%q offer=%s amount=%s "+
-					"status=%s transaction=%s",
-				cr.Owner, cr.Token, cr.Created, cr.Offer,
-				(*big.Int)(&cr.Amount).String(), cr.Status, cr.Transaction)
+			mint.Info(ctx, "Skipped crossing",
+				"id", cr.ID(),
+				"created", cr.Created, "offer", cr.Offer,
+				"amount", (*big.Int)(&cr.Amount), "status", cr.Status,
+				"transaction", cr.Transaction)
 		} else {
 			cr.Status = mint.TxStSettled
 			err = cr.Save(ctx)
@@ -529,11 +553,11 @@ func (e *SettleTransaction) Settle(
 				return errors.Trace(err)
 			}
 
-			mint.Logf(ctx,
-				"Settled crossing: id=%s[%s] created=%q offer=%s amount=%s "+
-					"status=%s transaction=%s",
-				cr.Owner, cr.Token, cr.Created, cr.Offer,
-				(*big.Int)(&cr.Amount).String(), cr.Status, cr.Transaction)
+			mint.Info(ctx, "Settled crossing",
+				"id", cr.ID(),
+				"created", cr.Created, "offer", cr.Offer,
+				"amount", (*big.Int)(&cr.Amount), "status", cr.Status,
+				"transaction", cr.Transaction)
 		}
 	}
 
@@ -548,12 +572,14 @@ func (e *SettleTransaction) Propagate(
 	if int(e.Hop)-1 >= 0 {
 		m := e.Plan.Hops[e.Hop-1].Mint
 
-		mint.Logf(ctx,
-			"Propagating settlement: transaction=%s hop=%d mint=%s",
-			e.ID, e.Hop, m)
+		mint.Info(ctx, "Propagating settlement",
+			"transaction", e.ID, "hop", e.Hop, "mint", m)
 
 		hop := e.Hop - 1
+		ctx, span := trace.Start(ctx, "transaction.propagate_settlement",
+			"transaction", e.ID, "hop", hop, "peer", m)
 		_, err := e.Client.SettleTransaction(ctx, e.ID, &hop, &e.Secret, &m)
+		span.Finish(err)
 		if err != nil {
 			return errors.Trace(err)
 		}
@@ -0,0 +1,178 @@
+package endpoint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/lib/authentication"
+	"github.com/spolu/settle/mint/lib/hub"
+	"github.com/spolu/settle/mint/model"
+)
+
+const (
+	// EndPtStreamEvents streams the events of the authenticated user.
+	EndPtStreamEvents EndPtName = "StreamEvents"
+
+	// streamEventsBatch is the maximum number of events loaded at once.
+	streamEventsBatch uint = 100
+)
+
+func init() {
+	streamRegistrar[EndPtStreamEvents] = NewStreamEvents
+}
+
+// StreamEvents streams the events of the authenticated user as server-sent
+// events. Streams are woken up by the hub when events are committed and are
+// closed after mint.EventsStreamDurationMs, clients being expected to
+// reconnect with the `Last-Event-ID` header to resume the stream.
+type StreamEvents struct {
+	Address string
+	After   *int64
+}
+
+// NewStreamEvents constructs and initialiezes the endpoint.
+func NewStreamEvents(
+	r *http.Request,
+) (Streamer, error) {
+	return &StreamEvents{}, nil
+}
+
+// Validate validates the input parameters.
+func (e *StreamEvents) Validate(
+	r *http.Request,
+) error {
+	ctx := r.Context()
+
+	e.Address = fmt.Sprintf("%s@%s",
+		authentication.Get(ctx).User.Username, mint.GetHost(ctx))
+
+	// Validate last event id, from the header set by clients when reconnecting
+	// or the query string for clients that can't set headers.
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+	if lastEventID != "" {
+		after, err := model.ParseEventID(lastEventID)
+		if err != nil {
+			return errors.Trace(errors.NewUserErrorf(err,
+				400, "last_event_id_invalid",
+				"The last event id provided is invalid: %s. It must be the "+
+					"id of an event previously received on this stream.",
+				lastEventID,
+			))
+		}
+		e.After = after
+	}
+
+	return nil
+}
+
+// Stream streams the events of the user as they are recorded.
+func (e *StreamEvents) Stream(
+	ctx context.Context,
+	w http.ResponseWriter,
+) {
+	notified, unsubscribe := hub.Get(ctx).Subscribe(e.Address)
+	defer unsubscribe()
+
+	// Streams outlive the write timeout of the server.
+	err := http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	if err != nil {
+		mint.Warn(ctx, "Failed to clear stream write deadline",
+			"address", e.Address, "error", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	flush := func() {
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	fmt.Fprintf(w, "retry: %d\n\n", mint.EventsRetryMs)
+	flush()
+
+	deadline := time.After(
+		time.Duration(mint.EventsStreamDurationMs) * time.Millisecond)
+	keepAlive := time.NewTicker(
+		time.Duration(mint.EventsKeepAliveMs) * time.Millisecond)
+	defer keepAlive.Stop()
+
+	for {
+		events, err := e.load(ctx)
+		if err != nil {
+			mint.Warn(ctx, "Failed to load events",
+				"address", e.Address, "error", err)
+			return
+		}
+
+		for _, ev := range events {
+			data, err := json.Marshal(model.NewEventResource(ctx, &ev))
+			if err != nil {
+				mint.Warn(ctx, "Failed to marshal event",
+					"address", e.Address, "error", err)
+				return
+			}
+			fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n",
+				ev.ID(), ev.Type, data)
+			e.After = ev.Seq
+		}
+		if len(events) > 0 {
+			flush()
+		}
+		if uint(len(events)) == streamEventsBatch {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-deadline:
+			return
+		case <-notified:
+		case <-keepAlive.C:
+			// Comments keep idle connections open through proxies and detect
+			// disconnected clients. Events committed by another process are
+			// picked up as well.
+			if _, err := fmt.Fprintf(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flush()
+		}
+	}
+}
+
+// load sequences the committed events of the user and loads the next batch of
+// them in its own DB transaction.
+func (e *StreamEvents) load(
+	ctx context.Context,
+) ([]model.Event, error) {
+	ctx = db.Begin(ctx, "mint")
+	defer db.LoggedRollback(ctx)
+
+	err := model.SequenceEventsByAddress(ctx, e.Address)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	events, err := model.LoadEventListByAddress(ctx,
+		e.Address, e.After, streamEventsBatch)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	db.Commit(ctx)
+
+	return events, nil
+}
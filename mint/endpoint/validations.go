@@ -3,6 +3,7 @@ package endpoint
 import (
 	"context"
 	"math/big"
+	"net/url"
 	"regexp"
 	"strconv"
 	"time"
@@ -16,6 +17,44 @@ import (
 var PriceRegexp = regexp.MustCompile(
 	"^([0-9]+)\\/([0-9]+)$")
 
+// Possible asset code: HOUR-OF-WORK
+var assetCodeRegexp = regexp.MustCompile("^[A-Z0-9-]{1,64}$")
+
+// Possible username: von.neuman-23_86
+var usernameRegexp = regexp.MustCompile("^([a-zA-Z0-9-_.]{1,256})$")
+
+// ValidateUsername validates a username.
+func ValidateUsername(
+	ctx context.Context,
+	username string,
+) (*string, error) {
+	if !usernameRegexp.MatchString(username) {
+		return nil, errors.Trace(errors.NewUserErrorf(nil,
+			400, "username_invalid",
+			"The username you provided is invalid: %s.",
+			username,
+		))
+	}
+
+	return &username, nil
+}
+
+// ValidatePassword validates a password.
+func ValidatePassword(
+	ctx context.Context,
+	password string,
+) (*string, error) {
+	if len(password) < 8 {
+		return nil, errors.Trace(errors.NewUserErrorf(nil,
+			400, "password_invalid",
+			"The password you provided is invalid. Passwords must be at "+
+				"least 8 characters long.",
+		))
+	}
+
+	return &password, nil
+}
+
 // ValidateAsset vlaidates an asset name.
 func ValidateAsset(
 	ctx context.Context,
@@ -213,6 +252,25 @@ func ValidateCreatedBefore(
 	return &converted, nil
 }
 
+// ValidateCursor validates a paging cursor.
+func ValidateCursor(
+	ctx context.Context,
+	cursor string,
+) (*model.Cursor, error) {
+	c, err := model.ParseCursor(cursor)
+	if err != nil {
+		return nil, errors.Trace(errors.NewUserErrorf(err,
+			400, "cursor_invalid",
+			"The paging cursor provided is invalid: %s. Paging cursors "+
+				"must be taken from the `next_cursor` value of a previous "+
+				"list response.",
+			cursor,
+		))
+	}
+
+	return c, nil
+}
+
 // ValidateLimit validates a paging limit.
 func ValidateLimit(
 	ctx context.Context,
@@ -259,3 +317,187 @@ func ValidatePropagation(
 
 	return &p, nil
 }
+
+// ValidateAddress validates an address, returning its normalized form.
+func ValidateAddress(
+	ctx context.Context,
+	address string,
+) (*string, error) {
+	a, err := mint.NormalizedAddress(ctx, address)
+	if err != nil {
+		return nil, errors.Trace(errors.NewUserErrorf(err,
+			400, "address_invalid",
+			"The address you provided is invalid: %s.",
+			address,
+		))
+	}
+
+	return &a, nil
+}
+
+// ValidateAssetCode validates an asset code.
+func ValidateAssetCode(
+	ctx context.Context,
+	code string,
+) (*string, error) {
+	if !assetCodeRegexp.MatchString(code) {
+		return nil, errors.Trace(errors.NewUserErrorf(nil,
+			400, "asset_code_invalid",
+			"The asset code you provided is invalid: %s. Asset codes are "+
+				"composed of up to 64 uppercase letters, digits and dashes.",
+			code,
+		))
+	}
+
+	return &code, nil
+}
+
+// ValidateOfferStatus validates an offer status.
+func ValidateOfferStatus(
+	ctx context.Context,
+	status string,
+) (*mint.OfStatus, error) {
+	s := mint.OfStatus(status)
+	switch s {
+	case mint.OfStActive, mint.OfStClosed, mint.OfStConsumed:
+	default:
+		return nil, errors.Trace(errors.NewUserErrorf(nil,
+			400, "status_invalid",
+			"The offer status you provided is invalid: %s. It can be one "+
+				"of active, closed or consumed.",
+			status,
+		))
+	}
+
+	return &s, nil
+}
+
+// ValidateWaitFor validates the transaction status a request waits for.
+func ValidateWaitFor(
+	ctx context.Context,
+	status string,
+) (*mint.TxStatus, error) {
+	s := mint.TxStatus(status)
+	switch s {
+	case mint.TxStSettled, mint.TxStCanceled:
+	default:
+		return nil, errors.Trace(errors.NewUserErrorf(nil,
+			400, "wait_for_invalid",
+			"The status you provided to wait for is invalid: %s. It can be "+
+				"one of settled or canceled.",
+			status,
+		))
+	}
+
+	return &s, nil
+}
+
+// ValidateTimeout validates a wait timeout expressed as a duration (`30s`).
+func ValidateTimeout(
+	ctx context.Context,
+	timeout string,
+) (*time.Duration, error) {
+	max := time.Duration(mint.TransactionWaitMaxMs) * time.Millisecond
+	d, err := time.ParseDuration(timeout)
+	if err != nil || d <= 0 || d > max {
+		return nil, errors.Trace(errors.NewUserErrorf(err,
+			400, "timeout_invalid",
+			"The timeout you provided is invalid: %s. It must be a "+
+				"positive duration (such as `30s`) of at most %s.",
+			timeout, max,
+		))
+	}
+
+	return &d, nil
+}
+
+// ValidateAssetFilter validates the filters of an asset list.
+func ValidateAssetFilter(
+	ctx context.Context,
+	query url.Values,
+) (*model.AssetFilter, error) {
+	f := model.AssetFilter{}
+	var err error
+
+	if code := query.Get("code"); code != "" {
+		if f.Code, err = ValidateAssetCode(ctx, code); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+
+	return &f, nil
+}
+
+// ValidateBalanceFilter validates the filters of a balance list.
+func ValidateBalanceFilter(
+	ctx context.Context,
+	query url.Values,
+) (*model.BalanceFilter, error) {
+	f := model.BalanceFilter{}
+	var err error
+
+	if owner := query.Get("owner"); owner != "" {
+		if f.Owner, err = ValidateAddress(ctx, owner); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if holder := query.Get("holder"); holder != "" {
+		if f.Holder, err = ValidateAddress(ctx, holder); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if code := query.Get("asset_code"); code != "" {
+		if f.AssetCode, err = ValidateAssetCode(ctx, code); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if min := query.Get("min_value"); min != "" {
+		if f.MinValue, err = ValidateAmount(ctx, min); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if max := query.Get("max_value"); max != "" {
+		if f.MaxValue, err = ValidateAmount(ctx, max); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+
+	return &f, nil
+}
+
+// ValidateOfferFilter validates the filters of an offer list.
+func ValidateOfferFilter(
+	ctx context.Context,
+	query url.Values,
+) (*model.OfferFilter, error) {
+	f := model.OfferFilter{}
+	var err error
+
+	if owner := query.Get("owner"); owner != "" {
+		if f.Owner, err = ValidateAddress(ctx, owner); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if status := query.Get("status"); status != "" {
+		if f.Status, err = ValidateOfferStatus(ctx, status); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if code := query.Get("asset_code"); code != "" {
+		if f.AssetCode, err = ValidateAssetCode(ctx, code); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if min := query.Get("min_remainder"); min != "" {
+		if f.MinRemainder, err = ValidateAmount(ctx, min); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if max := query.Get("max_remainder"); max != "" {
+		if f.MaxRemainder, err = ValidateAmount(ctx, max); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+
+	return &f, nil
+}
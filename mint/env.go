@@ -2,10 +2,15 @@ package mint
 
 import (
 	"context"
-	"fmt"
+	"crypto/ed25519"
+	"encoding/base64"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/spolu/settle/lib/env"
 	"github.com/spolu/settle/lib/logging"
+	"github.com/spolu/settle/lib/svc"
 )
 
 const (
@@ -17,6 +22,55 @@ const (
 	EnvCfgKeyFile env.ConfigKey = "key_file"
 	// EnvCfgCrtFile is the production certificate file.
 	EnvCfgCrtFile env.ConfigKey = "crt_file"
+	// EnvCfgOfferSuspectFailures is the number of consecutive failures to
+	// refresh the offers propagated from a mint after which the mint is marked
+	// as suspect.
+	EnvCfgOfferSuspectFailures env.ConfigKey = "offer_suspect_failures"
+	// EnvCfgIdentityKey is the base64 encoded private identity key of the
+	// mint, used to sign requests to other mints.
+	EnvCfgIdentityKey env.ConfigKey = "identity_key"
+	// EnvCfgAPIBaseURL is the externally accessible base URL of the mint API
+	// advertised in its discovery document.
+	EnvCfgAPIBaseURL env.ConfigKey = "api_base_url"
+	// EnvCfgClientTimeoutMs is the deadline of requests to other mints.
+	EnvCfgClientTimeoutMs env.ConfigKey = "client_timeout_ms"
+	// EnvCfgClientRetries is the number of retries of idempotent requests to
+	// other mints.
+	EnvCfgClientRetries env.ConfigKey = "client_retries"
+	// EnvCfgClientEncoding is the encoding of the bodies of requests to other
+	// mints (`form` or `json`).
+	EnvCfgClientEncoding env.ConfigKey = "client_encoding"
+	// EnvCfgBreakerFailures is the number of consecutive failures after which
+	// the circuit breaker of a mint opens.
+	EnvCfgBreakerFailures env.ConfigKey = "breaker_failures"
+	// EnvCfgBreakerProbeMs is the time after which an open circuit breaker
+	// probes its mint.
+	EnvCfgBreakerProbeMs env.ConfigKey = "breaker_probe_ms"
+	// EnvCfgPeerAllowList is the comma separated list of rules matching the
+	// mints this mint federates with.
+	EnvCfgPeerAllowList env.ConfigKey = "peer_allow_list"
+	// EnvCfgPeerDenyList is the comma separated list of rules matching the
+	// mints this mint refuses to federate with.
+	EnvCfgPeerDenyList env.ConfigKey = "peer_deny_list"
+	// EnvCfgAPIHostAllowList is the comma separated list of rules matching the
+	// hosts other mints may advertise in their API base URL besides their
+	// own.
+	EnvCfgAPIHostAllowList env.ConfigKey = "api_host_allow_list"
+	// EnvCfgRateLimits is the comma separated list of rate limits per route
+	// group (`group=rate:burst`).
+	EnvCfgRateLimits env.ConfigKey = "rate_limits"
+	// EnvCfgTrustedProxies is the comma separated list of IPs or CIDRs of the
+	// proxies whose X-Forwarded-For header is trusted.
+	EnvCfgTrustedProxies env.ConfigKey = "trusted_proxies"
+	// EnvCfgMetricsAddr is the address on which metrics are served, separately
+	// from the API.
+	EnvCfgMetricsAddr env.ConfigKey = "metrics_addr"
+	// EnvCfgHealthMaxPendingTasks is the number of pending tasks above which
+	// the mint is not ready.
+	EnvCfgHealthMaxPendingTasks env.ConfigKey = "health_max_pending_tasks"
+	// EnvCfgHealthMaxFailedTasks is the number of recently failed tasks above
+	// which the mint is not ready.
+	EnvCfgHealthMaxFailedTasks env.ConfigKey = "health_max_failed_tasks"
 )
 
 // GetHost retrieves the current mint host from the given contest.
@@ -33,11 +87,207 @@ func GetPort(
 	return env.Get(ctx).Config[EnvCfgPort]
 }
 
-// Logf shells out to logging.Logf adding the mint host as prefix.
-func Logf(
+// GetOfferSuspectFailures retrieves the number of consecutive failures after
+// which a remote mint is marked as suspect from the given context, defaulting
+// to OfferSuspectFailures.
+func GetOfferSuspectFailures(
 	ctx context.Context,
-	format string,
-	v ...interface{},
-) {
-	logging.Logf(ctx, fmt.Sprintf("[%s] ", GetHost(ctx))+format, v...)
+) uint {
+	failures, err := strconv.ParseUint(
+		env.Get(ctx).Config[EnvCfgOfferSuspectFailures], 10, 32)
+	if err != nil || failures == 0 {
+		return OfferSuspectFailures
+	}
+	return uint(failures)
+}
+
+// GetIdentityKey retrieves the mint private identity key from the given
+// context. It returns nil if no valid key is configured.
+func GetIdentityKey(
+	ctx context.Context,
+) ed25519.PrivateKey {
+	key, err := base64.StdEncoding.DecodeString(
+		env.Get(ctx).Config[EnvCfgIdentityKey])
+	if err != nil || len(key) != ed25519.PrivateKeySize {
+		return nil
+	}
+	return ed25519.PrivateKey(key)
+}
+
+// GetAPIBaseURL retrieves the mint API base URL from the given context,
+// defaulting to the environment default scheme and port for the mint host.
+func GetAPIBaseURL(
+	ctx context.Context,
+) string {
+	if base := env.Get(ctx).Config[EnvCfgAPIBaseURL]; base != "" {
+		return base
+	}
+	return DefaultMintURL(ctx, GetHost(ctx), "", url.Values{}).String()
+}
+
+// GetClientTimeout retrieves the deadline of requests to other mints from the
+// given context, defaulting to ClientTimeoutMs.
+func GetClientTimeout(
+	ctx context.Context,
+) time.Duration {
+	ms, err := strconv.ParseInt(
+		env.Get(ctx).Config[EnvCfgClientTimeoutMs], 10, 64)
+	if err != nil || ms <= 0 {
+		ms = ClientTimeoutMs
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// GetClientRetries retrieves the number of retries of idempotent requests to
+// other mints from the given context, defaulting to ClientRetries.
+func GetClientRetries(
+	ctx context.Context,
+) uint {
+	retries, err := strconv.ParseUint(
+		env.Get(ctx).Config[EnvCfgClientRetries], 10, 32)
+	if err != nil {
+		return ClientRetries
+	}
+	return uint(retries)
+}
+
+// GetHealthMaxPendingTasks retrieves the number of pending tasks above which
+// the mint is not ready from the given context, defaulting to
+// HealthMaxPendingTasks.
+func GetHealthMaxPendingTasks(
+	ctx context.Context,
+) int {
+	max, err := strconv.Atoi(
+		env.Get(ctx).Config[EnvCfgHealthMaxPendingTasks])
+	if err != nil || max < 0 {
+		return HealthMaxPendingTasks
+	}
+	return max
+}
+
+// GetHealthMaxFailedTasks retrieves the number of failed tasks created within
+// HealthFailedTasksWindowMs above which the mint is not ready from the given
+// context, defaulting to HealthMaxFailedTasks.
+func GetHealthMaxFailedTasks(
+	ctx context.Context,
+) int {
+	max, err := strconv.Atoi(
+		env.Get(ctx).Config[EnvCfgHealthMaxFailedTasks])
+	if err != nil || max < 0 {
+		return HealthMaxFailedTasks
+	}
+	return max
+}
+
+// GetClientContentType retrieves the content type of the bodies of requests
+// to other mints from the given context, defaulting to form encoded bodies.
+func GetClientContentType(
+	ctx context.Context,
+) string {
+	if env.Get(ctx).Config[EnvCfgClientEncoding] == "json" {
+		return svc.ContentTypeJSON
+	}
+	return svc.ContentTypeForm
+}
+
+// GetBreakerFailures retrieves the number of consecutive failures after which
+// the circuit breaker of a mint opens from the given context, defaulting to
+// BreakerFailures.
+func GetBreakerFailures(
+	ctx context.Context,
+) uint {
+	failures, err := strconv.ParseUint(
+		env.Get(ctx).Config[EnvCfgBreakerFailures], 10, 32)
+	if err != nil || failures == 0 {
+		return BreakerFailures
+	}
+	return uint(failures)
+}
+
+// GetBreakerProbe retrieves the time after which an open circuit breaker
+// probes its mint from the given context, defaulting to BreakerProbeMs.
+func GetBreakerProbe(
+	ctx context.Context,
+) time.Duration {
+	ms, err := strconv.ParseInt(
+		env.Get(ctx).Config[EnvCfgBreakerProbeMs], 10, 64)
+	if err != nil || ms <= 0 {
+		ms = BreakerProbeMs
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// GetPeerAllowList retrieves the peer allow list rules from the given
+// context. An empty list allows all mints.
+func GetPeerAllowList(
+	ctx context.Context,
+) []string {
+	return peerRules(env.Get(ctx).Config[EnvCfgPeerAllowList])
+}
+
+// GetAPIHostAllowList retrieves the rules matching the hosts other mints may
+// advertise in their API base URL besides their own from the given context.
+func GetAPIHostAllowList(
+	ctx context.Context,
+) []string {
+	return peerRules(env.Get(ctx).Config[EnvCfgAPIHostAllowList])
+}
+
+// GetPeerDenyList retrieves the peer deny list rules from the given context.
+func GetPeerDenyList(
+	ctx context.Context,
+) []string {
+	return peerRules(env.Get(ctx).Config[EnvCfgPeerDenyList])
+}
+
+// GetRateLimits retrieves the rate limits specification from the given
+// context.
+func GetRateLimits(
+	ctx context.Context,
+) string {
+	return env.Get(ctx).Config[EnvCfgRateLimits]
+}
+
+// GetTrustedProxies retrieves the trusted proxies specification from the
+// given context.
+func GetTrustedProxies(
+	ctx context.Context,
+) string {
+	return env.Get(ctx).Config[EnvCfgTrustedProxies]
+}
+
+// GetMetricsAddr retrieves the address on which metrics are served from the
+// given context, empty if they are served on the API.
+func GetMetricsAddr(
+	ctx context.Context,
+) string {
+	return env.Get(ctx).Config[EnvCfgMetricsAddr]
+}
+
+// hostFields prepends the mint host to the fields of a log entry.
+func hostFields(
+	ctx context.Context,
+	kv []interface{},
+) []interface{} {
+	return append([]interface{}{"host", GetHost(ctx)}, kv...)
+}
+
+// Debug shells out to logging.Debug adding the mint host as field.
+func Debug(ctx context.Context, msg string, kv ...interface{}) {
+	logging.Debug(ctx, msg, hostFields(ctx, kv)...)
+}
+
+// Info shells out to logging.Info adding the mint host as field.
+func Info(ctx context.Context, msg string, kv ...interface{}) {
+	logging.Info(ctx, msg, hostFields(ctx, kv)...)
+}
+
+// Warn shells out to logging.Warn adding the mint host as field.
+func Warn(ctx context.Context, msg string, kv ...interface{}) {
+	logging.Warn(ctx, msg, hostFields(ctx, kv)...)
+}
+
+// Error shells out to logging.Error adding the mint host as field.
+func Error(ctx context.Context, msg string, kv ...interface{}) {
+	logging.Error(ctx, msg, hostFields(ctx, kv)...)
 }
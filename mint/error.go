@@ -14,3 +14,25 @@ func (e ErrMintClient) Error() string {
 	return fmt.Sprintf(
 		"[%d] (%s) %s", e.StatusCode, e.ErrCode, e.ErrMessage)
 }
+
+// ErrCircuitOpen is returned by the client when a request is not attempted
+// because the circuit breaker of the mint it targets is open.
+type ErrCircuitOpen struct {
+	Host string
+}
+
+func (e ErrCircuitOpen) Error() string {
+	return fmt.Sprintf(
+		"Circuit breaker open for mint %s", e.Host)
+}
+
+// ErrPeerNotAllowed is returned when an interaction with a mint is refused as
+// it is not allowed by the configured peer allow and deny lists.
+type ErrPeerNotAllowed struct {
+	Host string
+}
+
+func (e ErrPeerNotAllowed) Error() string {
+	return fmt.Sprintf(
+		"Mint not allowed: %s", e.Host)
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"net/http"
 	"regexp"
+	"strings"
+	"time"
 
 	"github.com/spolu/settle/lib/errors"
 	"github.com/spolu/settle/lib/respond"
 	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/lib/signature"
 	"github.com/spolu/settle/mint/model"
 )
 
@@ -33,10 +36,12 @@ const (
 )
 
 // Status stores the authentication information, the status and authenticated
-// user if applicable.
+// user if applicable. Key is the API key used to authenticate, nil if the user
+// authenticated with their password.
 type Status struct {
 	Status AutStatus
 	User   *model.User
+	Key    *model.APIKey
 }
 
 // With stores the authentication information in a new context.
@@ -70,6 +75,12 @@ var SkipList = []*SkipRule{
 	&SkipRule{"GET", regexp.MustCompile("^/operations/[a-zA-Z0-9_\\+:@\\.\\[\\]]+$")},
 	&SkipRule{"GET", regexp.MustCompile("^/transactions/[a-zA-Z0-9_\\+:@\\.\\[\\]]+$")},
 	&SkipRule{"GET", regexp.MustCompile("^/balances/[a-zA-Z0-9_\\+:@\\.\\[\\]]+$")},
+	&SkipRule{"GET", regexp.MustCompile("^/key$")},
+	&SkipRule{"GET", regexp.MustCompile("^/\\.well-known/settle-mint$")},
+	&SkipRule{"GET", regexp.MustCompile("^/openapi\\.json$")},
+	&SkipRule{"GET", regexp.MustCompile("^/healthz$")},
+	&SkipRule{"GET", regexp.MustCompile("^/readyz$")},
+	&SkipRule{"GET", regexp.MustCompile("^/metrics$")},
 
 	&SkipRule{"POST", regexp.MustCompile("^/offers/[a-zA-Z0-9_\\+:@\\.\\[\\]]+$")},
 	&SkipRule{"POST", regexp.MustCompile("^/operations/[a-zA-Z0-9_\\+:@\\.\\[\\]]+$")},
@@ -81,6 +92,152 @@ var SkipList = []*SkipRule{
 
 	&SkipRule{"GET", regexp.MustCompile("^/assets/[a-zA-Z0-9_\\+:@\\.\\[\\]]+$")},
 	&SkipRule{"GET", regexp.MustCompile("^/assets/[a-zA-Z0-9_\\+:@\\.\\[\\]]+/offers$")},
+	&SkipRule{"GET", regexp.MustCompile("^/assets/[a-zA-Z0-9_\\+:@\\.\\[\\]]+/book$")},
+}
+
+// ScopeRule defines the scope required by an API key to access an endpoint.
+type ScopeRule struct {
+	Method  string
+	Pattern *regexp.Regexp
+	Scope   mint.KyScope
+}
+
+// ScopeList is the list of endpoints accessible with API keys along with the
+// scope they require. Endpoints that are not listed (such as API keys
+// management) require the user password.
+var ScopeList = []*ScopeRule{
+	&ScopeRule{"GET", regexp.MustCompile("^/assets(/.*)?$"), mint.KyScRead},
+	&ScopeRule{"GET", regexp.MustCompile("^/balances(/.*)?$"), mint.KyScRead},
+	&ScopeRule{"GET", regexp.MustCompile("^/offers(/.*)?$"), mint.KyScRead},
+	&ScopeRule{"GET", regexp.MustCompile("^/operations(/.*)?$"), mint.KyScRead},
+	&ScopeRule{"GET", regexp.MustCompile("^/transactions(/.*)?$"), mint.KyScRead},
+	&ScopeRule{"GET", regexp.MustCompile("^/peers$"), mint.KyScRead},
+	&ScopeRule{"GET", regexp.MustCompile("^/usage$"), mint.KyScRead},
+	&ScopeRule{"GET", regexp.MustCompile("^/events$"), mint.KyScRead},
+
+	&ScopeRule{"POST", regexp.MustCompile("^/offers$"), mint.KyScCreateOffers},
+	&ScopeRule{"POST", regexp.MustCompile("^/offers/[a-zA-Z0-9_\\+:@\\.\\[\\]]+/close$"), mint.KyScCreateOffers},
+
+	&ScopeRule{"POST", regexp.MustCompile("^/transactions$"), mint.KyScPay},
+	&ScopeRule{"POST", regexp.MustCompile("^/transactions/[a-zA-Z0-9_\\+:@\\.\\[\\]]+/settle$"), mint.KyScPay},
+	&ScopeRule{"POST", regexp.MustCompile("^/transactions/[a-zA-Z0-9_\\+:@\\.\\[\\]]+/cancel$"), mint.KyScPay},
+}
+
+// AdminRule defines an endpoint restricted to admin users.
+type AdminRule struct {
+	Method  string
+	Pattern *regexp.Regexp
+}
+
+// AdminList is the list of endpoints restricted to admin users.
+var AdminList = []*AdminRule{
+	&AdminRule{"GET", regexp.MustCompile("^/admin/.*$")},
+	&AdminRule{"POST", regexp.MustCompile("^/admin/.*$")},
+}
+
+// checkUser checks that an authenticated user is active and allowed to access
+// the endpoint requested.
+func checkUser(
+	r *http.Request,
+	user *model.User,
+) error {
+	if user.Status == mint.UsStDisabled {
+		return errors.Trace(errors.NewUserErrorf(nil,
+			403, "user_disabled",
+			"The user you are trying to authenticate with is disabled: %s.",
+			user.Username,
+		))
+	}
+
+	for _, a := range AdminList {
+		if a.Method == r.Method && a.Pattern.MatchString(r.URL.Path) &&
+			user.Role != mint.UsRlAdmin {
+			return errors.Trace(errors.NewUserErrorf(nil,
+				403, "admin_required",
+				"This endpoint is restricted to admin users.",
+			))
+		}
+	}
+
+	return nil
+}
+
+// bearerKey extracts the API key token and secret from the bearer token of
+// the request if any.
+func bearerKey(
+	r *http.Request,
+) (string, string, bool) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return "", "", false
+	}
+	ss := strings.SplitN(strings.TrimPrefix(auth, "Bearer "), ".", 2)
+	if len(ss) != 2 {
+		return "", "", true
+	}
+	return ss[0], ss[1], true
+}
+
+// authenticateKey authenticates a request with an API key, checking that the
+// key is granted the scope required by the request and recording its use.
+func authenticateKey(
+	ctx context.Context,
+	r *http.Request,
+	token string,
+	secret string,
+) (*model.User, *model.APIKey, error) {
+	invalid := errors.NewUserErrorf(nil,
+		400, "api_key_invalid",
+		"The API key you are trying to authenticate with is invalid.",
+	)
+
+	key, err := model.LoadAPIKeyByToken(ctx, token)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	} else if key == nil || key.Revoked != nil {
+		return nil, nil, errors.Trace(invalid)
+	}
+	if err := key.CheckSecret(ctx, secret); err != nil {
+		return nil, nil, errors.Trace(invalid)
+	}
+	if key.Expires != nil && key.Expires.Before(time.Now()) {
+		return nil, nil, errors.Trace(errors.NewUserErrorf(nil,
+			400, "api_key_expired",
+			"The API key you are trying to authenticate with has expired.",
+		))
+	}
+
+	var scope *mint.KyScope
+	for _, s := range ScopeList {
+		if s.Method == r.Method && s.Pattern.MatchString(r.URL.Path) {
+			scope = &s.Scope
+			break
+		}
+	}
+	if scope == nil || !key.HasScope(*scope) {
+		return nil, nil, errors.Trace(errors.NewUserErrorf(nil,
+			403, "api_key_scope_insufficient",
+			"The API key you are authenticating with is not granted the "+
+				"scope required by this request.",
+		))
+	}
+
+	user, err := model.LoadUserByToken(ctx, key.UserToken)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	} else if user == nil {
+		return nil, nil, errors.Trace(invalid)
+	}
+
+	resolution := time.Duration(mint.APIKeyLastUsedResolutionMs) *
+		time.Millisecond
+	if key.LastUsed == nil || time.Now().Sub(*key.LastUsed) > resolution {
+		if err := key.Touch(ctx, time.Now().UTC()); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+	}
+
+	return user, key, nil
 }
 
 // ServeHTTP handles incoming HTTP requests and attempt to authenticate them.
@@ -89,7 +246,7 @@ func (m middleware) ServeHTTP(
 	r *http.Request,
 ) {
 	ctx := r.Context()
-	withStatus := With(ctx, Status{AutStFailed, nil})
+	withStatus := With(ctx, Status{AutStFailed, nil, nil})
 
 	username, password, _ := r.BasicAuth()
 	skip := false
@@ -98,25 +255,53 @@ func (m middleware) ServeHTTP(
 			skip = true
 		}
 	}
+	// Requests whose signature was verified are authenticated as emitted by
+	// the signing mint and don't require a user.
+	if signature.Get(ctx).Host != "" {
+		skip = true
+	}
 
 	// Helper closure to fallback to the skiplist or log and return an
-	// authentication error.
+	// authentication error. Forbidden requests (403) are reported even if the
+	// endpoint does not require authentication.
 	failedAuth := func(err error) {
+		if e := errors.ExtractUserError(err); e != nil && e.Status() == 403 {
+			skip = false
+		}
 		if skip {
-			withStatus = With(ctx, Status{AutStSkipped, nil})
-			mint.Logf(ctx,
-				"Authentication: status=%q username=%q",
-				Get(withStatus).Status, username)
+			withStatus = With(ctx, Status{AutStSkipped, nil, nil})
+			mint.Info(ctx, "Authentication",
+				"status", Get(withStatus).Status, "username", username)
 			m.Handler.ServeHTTP(w, r.WithContext(withStatus))
 		} else {
-			withStatus = With(ctx, Status{AutStFailed, nil})
-			mint.Logf(ctx,
-				"Authentication: status=%q username=%q",
-				Get(withStatus).Status, username)
+			withStatus = With(ctx, Status{AutStFailed, nil, nil})
+			mint.Info(ctx, "Authentication",
+				"status", Get(withStatus).Status, "username", username)
 			respond.Error(withStatus, w, errors.Trace(err))
 		}
 	}
 
+	if token, secret, ok := bearerKey(r); ok {
+		user, key, err := authenticateKey(ctx, r, token, secret)
+		if err != nil {
+			failedAuth(errors.Trace(err))
+			return
+		}
+
+		if err := checkUser(r, user); err != nil {
+			failedAuth(errors.Trace(err))
+			return
+		}
+
+		withStatus = With(ctx, Status{AutStSucceeded, user, key})
+		mint.Info(ctx, "Authentication",
+			"status", Get(withStatus).Status,
+			"user", Get(withStatus).User.Token, "key", key.Token)
+
+		m.Handler.ServeHTTP(w, r.WithContext(withStatus))
+		return
+	}
+
 	user, err := model.LoadUserByUsername(ctx, username)
 	if err != nil {
 		failedAuth(errors.Trace(err))
@@ -137,11 +322,15 @@ func (m middleware) ServeHTTP(
 		return
 	}
 
-	withStatus = With(ctx, Status{AutStSucceeded, user})
-	mint.Logf(ctx,
-		"Authentication: status=%q user=%q username=%q",
-		Get(withStatus).Status, Get(withStatus).User.Token,
-		username)
+	if err := checkUser(r, user); err != nil {
+		failedAuth(errors.Trace(err))
+		return
+	}
+
+	withStatus = With(ctx, Status{AutStSucceeded, user, nil})
+	mint.Info(ctx, "Authentication",
+		"status", Get(withStatus).Status, "user", Get(withStatus).User.Token,
+		"username", username)
 
 	m.Handler.ServeHTTP(w, r.WithContext(withStatus))
 }
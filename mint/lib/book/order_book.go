@@ -0,0 +1,163 @@
+package book
+
+import (
+	"context"
+	"math/big"
+	"sort"
+
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/model"
+)
+
+// RateDecimals is the number of decimals of the rates of price levels.
+const RateDecimals = 12
+
+// level is a price level being aggregated.
+type level struct {
+	rate   *big.Rat
+	amount *big.Int
+	offers int
+}
+
+// pair is the order book of an asset against a counter asset being
+// aggregated.
+type pair struct {
+	counter *mint.AssetResource
+	asks    []*level
+	bids    []*level
+}
+
+// scaleFactor returns 10^scale.
+func scaleFactor(
+	scale int8,
+) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)
+}
+
+// add adds liquidity at the specified rate to a list of levels.
+func add(
+	levels []*level,
+	rate *big.Rat,
+	amount *big.Int,
+) []*level {
+	for _, l := range levels {
+		if l.rate.Cmp(rate) == 0 {
+			l.amount.Add(l.amount, amount)
+			l.offers++
+			return levels
+		}
+	}
+	return append(levels, &level{
+		rate:   rate,
+		amount: new(big.Int).Set(amount),
+		offers: 1,
+	})
+}
+
+// resources sorts levels by rate (increasing if asc) and computes their
+// cumulative depth.
+func resources(
+	levels []*level,
+	asc bool,
+) []mint.PriceLevelResource {
+	sort.Slice(levels, func(i, j int) bool {
+		if asc {
+			return levels[i].rate.Cmp(levels[j].rate) < 0
+		}
+		return levels[i].rate.Cmp(levels[j].rate) > 0
+	})
+
+	depth := new(big.Int)
+	l := []mint.PriceLevelResource{}
+	for _, lv := range levels {
+		depth = new(big.Int).Add(depth, lv.amount)
+		l = append(l, mint.PriceLevelResource{
+			Rate:   lv.rate.FloatString(RateDecimals),
+			Amount: lv.amount,
+			Depth:  depth,
+			Offers: lv.offers,
+		})
+	}
+	return l
+}
+
+// Compute aggregates the active offers involving an asset into its order book.
+// An offer on pair A/B at price pB/pQ exchanges pB units of A for pQ units of
+// B, its remainder being expressed in B. Offers whose base asset is the book
+// asset are asks, offers whose quote asset is the book asset are bids.
+func Compute(
+	ctx context.Context,
+	asset mint.AssetResource,
+	offers []model.Offer,
+) (*mint.OrderBookResource, error) {
+	pairs := map[string]*pair{}
+	names := []string{}
+
+	for _, o := range offers {
+		if o.Status != mint.OfStActive {
+			continue
+		}
+		basePrice := (*big.Int)(&o.BasePrice)
+		quotePrice := (*big.Int)(&o.QuotePrice)
+		if basePrice.Sign() == 0 || quotePrice.Sign() == 0 {
+			continue
+		}
+
+		ask := o.BaseAsset == asset.Name
+		counterName := o.BaseAsset
+		if ask {
+			counterName = o.QuoteAsset
+		} else if o.QuoteAsset != asset.Name {
+			continue
+		}
+
+		p, ok := pairs[counterName]
+		if !ok {
+			counter, err := mint.AssetResourceFromName(ctx, counterName)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			p = &pair{counter: counter}
+			pairs[counterName] = p
+			names = append(names, counterName)
+		}
+
+		// The rate is expressed in units of counter asset per unit of asset:
+		// (pCounter / 10^sCounter) / (pAsset / 10^sAsset).
+		assetPrice, counterPrice := basePrice, quotePrice
+		if !ask {
+			assetPrice, counterPrice = quotePrice, basePrice
+		}
+		rate := new(big.Rat).SetFrac(
+			new(big.Int).Mul(counterPrice, scaleFactor(asset.Scale)),
+			new(big.Int).Mul(assetPrice, scaleFactor(p.counter.Scale)),
+		)
+
+		remainder := (*big.Int)(&o.Remainder)
+		if ask {
+			// The remainder is expressed in counter asset, converted to the
+			// amount of asset the offer can still sell.
+			amount := new(big.Int).Quo(
+				new(big.Int).Mul(remainder, basePrice), quotePrice)
+			p.asks = add(p.asks, rate, amount)
+		} else {
+			p.bids = add(p.bids, rate, remainder)
+		}
+	}
+
+	sort.Strings(names)
+	book := mint.OrderBookResource{
+		Asset: asset.Name,
+		Pairs: []mint.OrderBookPairResource{},
+	}
+	for _, n := range names {
+		book.Pairs = append(book.Pairs, mint.OrderBookPairResource{
+			CounterAsset: n,
+			Asks:         resources(pairs[n].asks, true),
+			Bids:         resources(pairs[n].bids, false),
+		})
+	}
+
+	return &book, nil
+}
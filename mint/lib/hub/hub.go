@@ -0,0 +1,119 @@
+package hub
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// Hub lets requests wait for changes to objects committed by other requests or
+// tasks of the same mint process. Waiters subscribe to an object ID and are
+// signaled when it is notified.
+type Hub struct {
+	mutex   *sync.Mutex
+	waiters map[string]map[chan struct{}]bool
+}
+
+// New constructs a new notification hub.
+func New() *Hub {
+	return &Hub{
+		mutex:   &sync.Mutex{},
+		waiters: map[string]map[chan struct{}]bool{},
+	}
+}
+
+// Subscribe registers a waiter for the provided object ID. The returned channel
+// receives a signal each time the object is notified (signals are coalesced
+// if the waiter is not ready to receive them). The returned function must be
+// called to unregister the waiter. A nil hub never signals its waiters.
+func (h *Hub) Subscribe(
+	id string,
+) (<-chan struct{}, func()) {
+	if h == nil {
+		return nil, func() {}
+	}
+	ch := make(chan struct{}, 1)
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if _, ok := h.waiters[id]; !ok {
+		h.waiters[id] = map[chan struct{}]bool{}
+	}
+	h.waiters[id][ch] = true
+
+	return ch, func() {
+		h.mutex.Lock()
+		defer h.mutex.Unlock()
+		delete(h.waiters[id], ch)
+		if len(h.waiters[id]) == 0 {
+			delete(h.waiters, id)
+		}
+	}
+}
+
+// Notify signals the waiters of the provided object ID. It is a no-op on a nil
+// hub.
+func (h *Hub) Notify(
+	id string,
+) {
+	if h == nil {
+		return
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	for ch := range h.waiters[id] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// ContextKey is the type of the key used with context to carry contextual
+// hub.
+type ContextKey string
+
+const (
+	// hubKey the context.Context key to store the hub.
+	hubKey ContextKey = "hub.hub"
+)
+
+// With stores the hub in the provided context.
+func With(
+	ctx context.Context,
+	hub *Hub,
+) context.Context {
+	return context.WithValue(ctx, hubKey, hub)
+}
+
+// Get returns the hub currently stored in the context (nil if none).
+func Get(
+	ctx context.Context,
+) *Hub {
+	hub, _ := ctx.Value(hubKey).(*Hub)
+	return hub
+}
+
+type middleware struct {
+	http.Handler
+	*Hub
+}
+
+// ServeHTTP handles incoming HTTP requests and injects the hub in their
+// context.
+func (m middleware) ServeHTTP(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	m.Handler.ServeHTTP(w, r.WithContext(With(r.Context(), m.Hub)))
+}
+
+// Middleware returns a middleware that injects the specified hub in requests.
+func Middleware(
+	hub *Hub,
+) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return middleware{h, hub}
+	}
+}
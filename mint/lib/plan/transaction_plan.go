@@ -2,11 +2,11 @@ package plan
 
 import (
 	"context"
-	"fmt"
 	"math/big"
 	"regexp"
 
 	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/trace"
 	"github.com/spolu/settle/mint"
 	"github.com/spolu/settle/mint/model"
 	"golang.org/x/sync/errgroup"
@@ -55,11 +55,34 @@ type TxPlan struct {
 }
 
 // Compute retrieves the offers of the path and compute the transaction plan.
+// Pending transactions are rejected with ErrPeerNotAllowed if the path crosses
+// offers of mints not allowed by the peer allow and deny lists. Transactions
+// already reserved are not checked so that they can always be settled or
+// canceled. The computation is recorded as a span.
 func Compute(
 	ctx context.Context,
 	client *mint.Client,
 	tx *model.Transaction,
 	shallow bool,
+) (*TxPlan, error) {
+	ctx, span := trace.Start(ctx, "plan.compute",
+		"transaction", tx.ID(), "shallow", shallow)
+
+	pl, err := compute(ctx, client, tx, shallow)
+	if pl != nil {
+		span.Set("hops", len(pl.Hops))
+	}
+	span.Finish(err)
+
+	return pl, err
+}
+
+// compute computes the transaction plan (see Compute).
+func compute(
+	ctx context.Context,
+	client *mint.Client,
+	tx *model.Transaction,
+	shallow bool,
 ) (*TxPlan, error) {
 	g, ctx := errgroup.WithContext(ctx)
 	offers := make([]mint.OfferResource, len(tx.Path))
@@ -67,6 +90,19 @@ func Compute(
 	for i, id := range tx.Path {
 		i, id := i, id
 		g.Go(func() error {
+			if tx.Status == mint.TxStPending {
+				owner, _, err := mint.NormalizedOwnerAndTokenFromID(ctx, id)
+				if err != nil {
+					return errors.Trace(err)
+				}
+				_, host, err := mint.UsernameAndMintHostFromAddress(ctx, owner)
+				if err != nil {
+					return errors.Trace(err)
+				}
+				if !mint.PeerAllowed(ctx, host) {
+					return errors.Trace(mint.ErrPeerNotAllowed{Host: host})
+				}
+			}
 			if !shallow {
 				offer, err := client.RetrieveOffer(ctx, id)
 				if err != nil {
@@ -258,25 +294,28 @@ func Compute(
 		plan.Hops[hop-1].OpAction.Amount = amount
 	}
 
-	logLine := fmt.Sprintf("Transaction plan for %s:", plan.Transaction)
+	mint.Info(ctx, "Transaction plan",
+		"transaction", plan.Transaction, "hops", len(plan.Hops))
 	for i, h := range plan.Hops {
-		logLine += fmt.Sprintf("\n  [%d] mint=%s", i, h.Mint)
+		kv := []interface{}{
+			"transaction", plan.Transaction, "plan_hop", i, "mint", h.Mint,
+		}
 		if h.OpAction != nil {
 			a := h.OpAction
-			logLine += fmt.Sprintf("\n    [%s] amount=%s asset=%s "+
-				"source=%s destination=%s ",
-				a.Type, a.Amount.String(), *a.OperationAsset,
-				*a.OperationSource, *a.OperationDestination)
+			kv = append(kv,
+				"operation", a.Type, "operation_amount", a.Amount,
+				"asset", *a.OperationAsset, "source", *a.OperationSource,
+				"destination", *a.OperationDestination)
 		}
 		if h.CrAction != nil {
 			a := h.CrAction
-			logLine += fmt.Sprintf(
-				"\n    [%s ] amount=%s offer=%s pair=%s price=%s",
-				a.Type, a.Amount.String(), *a.CrossingOffer,
-				offers[i-offset-1].Pair, offers[i-offset-1].Price)
+			kv = append(kv,
+				"crossing", a.Type, "crossing_amount", a.Amount,
+				"offer", *a.CrossingOffer, "pair", offers[i-offset-1].Pair,
+				"price", offers[i-offset-1].Price)
 		}
+		mint.Debug(ctx, "Transaction plan hop", kv...)
 	}
-	mint.Logf(ctx, logLine)
 
 	return &plan, nil
 }
@@ -386,6 +425,19 @@ func (p *TxPlan) MinMaxHop(
 	return &min, &max, nil
 }
 
+// Emitter returns the mint expected to propagate the transaction (its
+// creation, settlement or cancellation) to the specified hop. Propagations go
+// hop by hop from the last hop down to the first one, the last hop receiving
+// them from the canonical mint (the first hop). Works on a shallow plan.
+func (p *TxPlan) Emitter(
+	hop int8,
+) string {
+	if int(hop)+1 < len(p.Hops) {
+		return p.Hops[hop+1].Mint
+	}
+	return p.Hops[0].Mint
+}
+
 // CheckShouldCancel checks whether the next node on the offer path has
 // canceled. If so, no need to settle, we can cancel instead. Works on a
 // shallow plan.
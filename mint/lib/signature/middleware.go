@@ -0,0 +1,474 @@
+package signature
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/respond"
+	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/lib/version"
+)
+
+// ContextKey is the type of the key used with context to carry contextual
+// signature status.
+type ContextKey string
+
+const (
+	// statusKey the context.Context key to store the signature status.
+	statusKey ContextKey = "signature.status"
+)
+
+// Status stores the signature information of a request. Host is the host of
+// the mint that signed the request, empty if the request was not signed.
+type Status struct {
+	Host string
+}
+
+// With stores the signature information in a new context.
+func With(
+	ctx context.Context,
+	status Status,
+) context.Context {
+	return context.WithValue(ctx, statusKey, status)
+}
+
+// Get retrieves the signature information from the context.
+func Get(
+	ctx context.Context,
+) Status {
+	return ctx.Value(statusKey).(Status)
+}
+
+// Rule defines an endpoint by its method and path pattern.
+type Rule struct {
+	Method  string
+	Pattern *regexp.Regexp
+}
+
+// OwnerList is the list of propagation endpoints whose requests are expected
+// to be emitted by the mint of the owner of the object identified in the path.
+var OwnerList = []*Rule{
+	&Rule{"POST", regexp.MustCompile("^/offers/([a-zA-Z0-9_\\+:@\\.\\[\\]]+)$")},
+	&Rule{"POST", regexp.MustCompile("^/operations/([a-zA-Z0-9_\\+:@\\.\\[\\]]+)$")},
+	&Rule{"POST", regexp.MustCompile("^/balances/([a-zA-Z0-9_\\+:@\\.\\[\\]]+)$")},
+}
+
+// PropagationList is the list of propagation endpoints whose requests have
+// their signature verified. Signatures of requests to other endpoints (except
+// the ones of PeerList) are ignored so that anonymous requests can't make this
+// mint contact arbitrary hosts to retrieve their keys.
+var PropagationList = append([]*Rule{
+	&Rule{"POST", regexp.MustCompile("^/transactions/([a-zA-Z0-9_\\+:@\\.\\[\\]]+)$")},
+	&Rule{"POST", regexp.MustCompile("^/transactions/([a-zA-Z0-9_\\+:@\\.\\[\\]]+)/settle$")},
+	&Rule{"POST", regexp.MustCompile("^/transactions/([a-zA-Z0-9_\\+:@\\.\\[\\]]+)/cancel$")},
+}, OwnerList...)
+
+// PeerList is the list of endpoints other than propagations that mints call
+// on each other, whose requests have their signature verified as well.
+var PeerList = []*Rule{
+	&Rule{"GET", regexp.MustCompile("^/transactions/([a-zA-Z0-9_\\+:@\\.\\[\\]]+)/trace$")},
+}
+
+// Match returns the submatches of the pattern of the rule in the path of the
+// request, nil if the request does not match the rule.
+func (rl *Rule) Match(
+	r *http.Request,
+) []string {
+	if rl.Method != r.Method {
+		return nil
+	}
+	return rl.Pattern.FindStringSubmatch(r.URL.Path)
+}
+
+// Signed returns whether the request is signed on an endpoint whose requests
+// have their signature verified. Such requests are rejected by the middleware
+// if their signature is invalid.
+func Signed(
+	r *http.Request,
+) bool {
+	if r.Header.Get(mint.HeaderSignature) == "" {
+		return false
+	}
+	for _, l := range [][]*Rule{PropagationList, PeerList} {
+		for _, p := range l {
+			if p.Match(r) != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// peerKey is a cached peer identity key. A nil key indicates that the peer
+// does not advertise signing support. A non nil err indicates that the
+// retrieval of the key failed.
+type peerKey struct {
+	key     ed25519.PublicKey
+	err     error
+	fetched time.Time
+}
+
+var peerKeys = map[string]peerKey{}
+var peerKeysMutex = &sync.Mutex{}
+
+// seenNonce is a nonce seen by this mint (prefixed by the signing host).
+type seenNonce struct {
+	key  string
+	seen time.Time
+}
+
+// nonces stores the nonces seen recently (per host) to detect replays. The
+// nonces are also queued in the order they were seen so that expired ones are
+// pruned from the front of the queue without scanning the ones still valid.
+var nonces = map[string]time.Time{}
+var noncesQueue = []seenNonce{}
+var noncesMutex = &sync.Mutex{}
+
+// recordNonce records the nonce of a verified request, pruning the expired
+// ones. It returns false if the nonce was already seen.
+func recordNonce(
+	key string,
+) bool {
+	noncesMutex.Lock()
+	defer noncesMutex.Unlock()
+
+	now := time.Now()
+	window := 2 * time.Duration(mint.SignatureSkewMs) * time.Millisecond
+	for len(noncesQueue) > 0 && now.Sub(noncesQueue[0].seen) > window {
+		delete(nonces, noncesQueue[0].key)
+		noncesQueue = noncesQueue[1:]
+	}
+
+	if _, ok := nonces[key]; ok {
+		return false
+	}
+	nonces[key] = now
+	noncesQueue = append(noncesQueue, seenNonce{key: key, seen: now})
+
+	return true
+}
+
+// PeerKey returns the identity key of the specified mint, retrieving it if it
+// is not cached or if refresh is true. Failed retrievals are cached as well and
+// keys are refreshed at most once per PeerKeyRefreshMs. It returns nil if the
+// mint does not advertise any identity key.
+func PeerKey(
+	ctx context.Context,
+	host string,
+	refresh bool,
+) (ed25519.PublicKey, error) {
+	peerKeysMutex.Lock()
+	cached, ok := peerKeys[host]
+	peerKeysMutex.Unlock()
+
+	if ok {
+		expiry := time.Duration(mint.PeerKeyCacheMs) * time.Millisecond
+		if cached.err != nil {
+			expiry = time.Duration(mint.PeerKeyFailureCacheMs) *
+				time.Millisecond
+		} else if refresh {
+			expiry = time.Duration(mint.PeerKeyRefreshMs) * time.Millisecond
+		}
+		if time.Now().Sub(cached.fetched) < expiry {
+			return cached.key, cached.err
+		}
+	}
+
+	key, err := retrievePeerKey(ctx, host)
+
+	peerKeysMutex.Lock()
+	peerKeys[host] = peerKey{key, err, time.Now()}
+	peerKeysMutex.Unlock()
+
+	return key, err
+}
+
+// retrievePeerKey retrieves the identity key of the specified mint.
+func retrievePeerKey(
+	ctx context.Context,
+	host string,
+) (ed25519.PublicKey, error) {
+
+	client := &mint.Client{}
+	err := client.Init(ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	// Only retrieve the key of mints advertising signing support.
+	supported, err := client.Supports(ctx, host, mint.FtSignedRequests)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	var k *mint.KeyResource
+	if supported {
+		k, err = client.RetrieveKey(ctx, host)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+
+	var key ed25519.PublicKey
+	if k != nil {
+		if k.Algorithm != mint.KeyAlgorithm {
+			return nil, errors.Trace(errors.Newf(
+				"Unsupported key algorithm for mint %s: %s", host, k.Algorithm))
+		}
+		raw, err := base64.StdEncoding.DecodeString(k.PublicKey)
+		if err != nil || len(raw) != ed25519.PublicKeySize {
+			return nil, errors.Trace(errors.Newf(
+				"Invalid key for mint %s: %s", host, k.PublicKey))
+		}
+		key = ed25519.PublicKey(raw)
+	}
+
+	return key, nil
+}
+
+// CheckPeer checks that the request being served was emitted by the specified
+// mint and that this mint federates with it. Signed requests must have been
+// signed by that mint. Unsigned requests are rejected if that mint advertises
+// signing support or if its key can't be retrieved.
+func CheckPeer(
+	ctx context.Context,
+	host string,
+) error {
+	if !mint.PeerAllowed(ctx, host) {
+		return errors.Trace(errors.NewUserErrorf(nil,
+			403, "peer_not_allowed",
+			"This mint does not accept propagations from %s.", host,
+		))
+	}
+
+	status := Get(ctx)
+	if status.Host != "" {
+		if status.Host != host {
+			return errors.Trace(errors.NewUserErrorf(nil,
+				400, "signature_invalid",
+				"The request was signed by %s but was expected from %s.",
+				status.Host, host,
+			))
+		}
+		return nil
+	}
+
+	key, err := PeerKey(ctx, host, false)
+	if err != nil {
+		// We can't determine if the peer signs its requests, so the request
+		// is denied rather than trusted unsigned.
+		mint.Warn(ctx, "Failed to retrieve peer key",
+			"peer", host, "error", err)
+		return errors.Trace(errors.NewUserErrorf(err,
+			503, "peer_key_unavailable",
+			"The identity key of %s could not be retrieved to check the "+
+				"origin of the request.", host,
+		))
+	}
+	if key != nil {
+		return errors.Trace(errors.NewUserErrorf(nil,
+			400, "signature_required",
+			"Requests emitted by %s must be signed.", host,
+		))
+	}
+
+	return nil
+}
+
+// verify verifies the signature of the request, returning the host of the
+// signing mint.
+func verify(
+	ctx context.Context,
+	r *http.Request,
+	body []byte,
+) (string, error) {
+	host := r.Header.Get(mint.HeaderSignatureHost)
+	date := r.Header.Get(mint.HeaderSignatureDate)
+	nonce := r.Header.Get(mint.HeaderSignatureNonce)
+
+	invalid := func(err error, format string, v ...interface{}) error {
+		return errors.Trace(errors.NewUserErrorf(err,
+			400, "signature_invalid", format, v...))
+	}
+
+	if host == "" || nonce == "" {
+		return "", invalid(nil,
+			"The request signature is missing its host or nonce.")
+	}
+	if !mint.PeerAllowed(ctx, host) {
+		return "", errors.Trace(errors.NewUserErrorf(nil,
+			403, "peer_not_allowed",
+			"This mint does not accept propagations from %s.", host,
+		))
+	}
+
+	d, err := strconv.ParseInt(date, 10, 64)
+	if err != nil {
+		return "", invalid(err,
+			"The request signature date is invalid: %s.", date)
+	}
+	skew := time.Now().UnixNano()/mint.TimeResolutionNs - d
+	if skew > mint.SignatureSkewMs || skew < -mint.SignatureSkewMs {
+		return "", invalid(nil,
+			"The request signature date is too far from the current time: %s.",
+			date)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(
+		r.Header.Get(mint.HeaderSignature))
+	if err != nil {
+		return "", invalid(err, "The request signature is not valid base64.")
+	}
+
+	// The request may have been routed to this mint with the path of its API
+	// base URL stripped, in which case we restore it as signed by the client.
+	uri := r.RequestURI
+	if base, err := url.Parse(mint.GetAPIBaseURL(ctx)); err == nil {
+		prefix := strings.TrimRight(base.Path, "/")
+		if prefix != "" && !strings.HasPrefix(uri, prefix+"/") {
+			uri = prefix + uri
+		}
+	}
+
+	target := ""
+	if mint.SignsTarget(version.Get(ctx)) {
+		target = mint.GetHost(ctx)
+	}
+
+	payload := mint.SignaturePayload(
+		r.Method, uri, body, date, nonce, host, target)
+
+	// The cached peer key may be outdated (rotated or newly advertised), in
+	// which case we retry once with a freshly retrieved key (if it was not
+	// already refreshed recently).
+	verified := false
+	for _, refresh := range []bool{false, true} {
+		key, err := PeerKey(ctx, host, refresh)
+		if err != nil {
+			return "", invalid(err,
+				"Failed to retrieve the identity key of %s.", host)
+		}
+		if key != nil && ed25519.Verify(key, payload, signature) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return "", invalid(nil,
+			"The request signature does not match the key of %s.", host)
+	}
+
+	// Nonces are recorded only once the signature is verified so that they
+	// can't be exhausted by unsigned requests.
+	if !recordNonce(host + " " + nonce) {
+		return "", errors.Trace(errors.NewUserErrorf(nil,
+			400, "signature_replayed",
+			"The request signature nonce was already used: %s.", nonce,
+		))
+	}
+
+	return host, nil
+}
+
+type middleware struct {
+	http.Handler
+}
+
+// ServeHTTP handles incoming HTTP requests, verifies their signature if
+// present on propagation and peer endpoints and checks the emitting mint of
+// propagation requests.
+func (m middleware) ServeHTTP(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	ctx := r.Context()
+	status := Status{}
+
+	if Signed(r) {
+		body := []byte{}
+		if r.Body != nil {
+			b, err := ioutil.ReadAll(io.LimitReader(r.Body, svc.MaxBodySize+1))
+			if err != nil {
+				respond.Error(ctx, w, errors.Trace(err))
+				return
+			}
+			if len(b) > svc.MaxBodySize {
+				respond.Error(ctx, w, errors.Trace(errors.NewUserErrorf(nil,
+					413, "body_too_large",
+					"The body of your request is too large, the maximum "+
+						"size is %d bytes.",
+					svc.MaxBodySize,
+				)))
+				return
+			}
+			body = b
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		host, err := verify(ctx, r, body)
+		if err != nil {
+			mint.Warn(ctx, "Signature",
+				"status", "failed",
+				"peer", r.Header.Get(mint.HeaderSignatureHost))
+			respond.Error(ctx, w, errors.Trace(err))
+			return
+		}
+		status.Host = host
+
+		mint.Info(ctx, "Signature", "status", "verified", "peer", host)
+	}
+
+	ctx = With(ctx, status)
+
+	for _, o := range OwnerList {
+		match := o.Match(r)
+		if match == nil {
+			continue
+		}
+		// Invalid ids are left to the endpoint validation.
+		owner, _, err := mint.NormalizedOwnerAndTokenFromID(ctx, match[1])
+		if err != nil {
+			break
+		}
+		_, host, err := mint.UsernameAndMintHostFromAddress(ctx, owner)
+		if err != nil {
+			break
+		}
+		if err := CheckPeer(ctx, host); err != nil {
+			respond.Error(ctx, w, errors.Trace(err))
+			return
+		}
+		break
+	}
+
+	// Record the contact with the emitting mint in the peer registry. Only
+	// mints whose signature was verified are recorded so that anonymous
+	// requests can't insert arbitrary hosts in the registry.
+	if peer := status.Host; peer != "" && peer != mint.GetHost(ctx) {
+		mint.RecordPeerContact(ctx, mint.PeerContact{
+			Host:            peer,
+			Time:            time.Now(),
+			ProtocolVersion: mint.AdvertisedProtocolVersion(peer),
+		})
+	}
+
+	m.Handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// Middleware that verifies mint-to-mint request signatures.
+func Middleware(h http.Handler) http.Handler {
+	return middleware{h}
+}
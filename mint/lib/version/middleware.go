@@ -0,0 +1,109 @@
+package version
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/format"
+	"github.com/spolu/settle/lib/logging"
+	"github.com/spolu/settle/lib/respond"
+	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/mint"
+)
+
+// ContextKey is the type of the key used with context to carry the
+// negotiated protocol version.
+type ContextKey string
+
+const (
+	// versionKey the context.Context key to store the negotiated protocol
+	// version.
+	versionKey ContextKey = "version.version"
+)
+
+// With stores the negotiated protocol version in a new context.
+func With(
+	ctx context.Context,
+	version string,
+) context.Context {
+	return context.WithValue(ctx, versionKey, version)
+}
+
+// Get retrieves the protocol version negotiated for the request being served
+// from the context, defaulting to mint.ProtocolVersion. Endpoints can branch
+// on it to evolve their payloads while supporting older mints.
+func Get(
+	ctx context.Context,
+) string {
+	if v, ok := ctx.Value(versionKey).(string); ok {
+		return v
+	}
+	return mint.ProtocolVersion
+}
+
+// Range returns the range of protocol versions supported by this mint.
+func Range() mint.ProtocolVersionRange {
+	return mint.ProtocolVersionRange{
+		Min:       mint.MinProtocolVersion,
+		Max:       mint.ProtocolVersion,
+		Supported: mint.ProtocolVersions,
+	}
+}
+
+type middleware struct {
+	http.Handler
+}
+
+// ServeHTTP handles incoming HTTP requests, rejecting the ones requesting an
+// unsupported protocol version. Requests that do not specify any version are
+// served with the current protocol version. The discovery document is always
+// served so that peers can learn the supported versions.
+func (m middleware) ServeHTTP(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	ctx := r.Context()
+
+	requested := strings.TrimSpace(r.Header.Get("Mint-Protocol-Version"))
+
+	version := mint.ProtocolVersion
+	if requested != "" && r.URL.Path != mint.DiscoveryPath {
+		supported := false
+		for _, v := range mint.ProtocolVersions {
+			if v == requested {
+				supported = true
+			}
+		}
+		if !supported {
+			rng := Range()
+			e := errors.NewUserErrorf(nil,
+				400, "protocol_version_unsupported",
+				"The protocol version you requested is not supported: %s. "+
+					"Supported versions range from %s to %s.",
+				requested, rng.Min, rng.Max,
+			)
+			logging.Info(ctx, "UserError",
+				"status", e.Status(), "code", e.Code(), "message", e.Message())
+
+			respond.Respond(ctx, w, e.Status(), http.Header{
+				"Mint-Protocol-Versions": {strings.Join(rng.Supported, ",")},
+			}, svc.Resp{
+				"error":             format.JSONPtr(errors.Build(e)),
+				"protocol_versions": format.JSONPtr(rng),
+			})
+			return
+		}
+		version = requested
+	}
+
+	w.Header().Set("Mint-Protocol-Version", version)
+
+	m.Handler.ServeHTTP(w, r.WithContext(With(ctx, version)))
+}
+
+// Middleware that negotiates the protocol version of API requests.
+func Middleware(h http.Handler) http.Handler {
+	return middleware{h}
+}
@@ -0,0 +1,66 @@
+package mint
+
+import "github.com/spolu/settle/lib/metrics"
+
+const (
+	// MtPeerOther is the peer label of the mints that are not recorded in the
+	// peer registry, so that hosts named by remote requests can't create an
+	// unbounded number of series.
+	MtPeerOther string = "other"
+)
+
+var (
+	// MtRequests counts the requests handled by endpoint and status.
+	MtRequests = metrics.NewCounter(
+		"mint_requests_total",
+		"Requests handled by endpoint and status.",
+		"endpoint", "status",
+	)
+	// MtRequestDuration is the latency of requests by endpoint.
+	MtRequestDuration = metrics.NewHistogram(
+		"mint_request_duration_seconds",
+		"Latency of requests by endpoint.",
+		nil, "endpoint",
+	)
+	// MtTransactions counts the transactions reaching a status by status and
+	// role (canonical or propagated).
+	MtTransactions = metrics.NewCounter(
+		"mint_transactions_total",
+		"Transactions reaching a status by status and role.",
+		"status", "role",
+	)
+	// MtAsyncPending is the number of tasks pending in the async queue by
+	// task name.
+	MtAsyncPending = metrics.NewGauge(
+		"mint_async_pending_tasks",
+		"Tasks pending in the async queue by task.",
+		"task",
+	)
+	// MtAsyncFailed is the number of tasks created within
+	// HealthFailedTasksWindowMs that failed out of retries.
+	MtAsyncFailed = metrics.NewGauge(
+		"mint_async_failed_tasks",
+		"Tasks created within the failure window that failed out of retries.",
+	)
+	// MtAsyncRuns counts the executions of tasks by task name and result
+	// (succeeded, retried or failed once out of retries).
+	MtAsyncRuns = metrics.NewCounter(
+		"mint_async_task_runs_total",
+		"Executions of tasks by task and result.",
+		"task", "result",
+	)
+	// MtClientDuration is the latency of the requests sent to other mints by
+	// peer (see PeerLabel).
+	MtClientDuration = metrics.NewHistogram(
+		"mint_client_request_duration_seconds",
+		"Latency of requests sent to other mints by peer.",
+		nil, "peer",
+	)
+	// MtClientErrors counts the failed requests sent to other mints by peer
+	// (see PeerLabel).
+	MtClientErrors = metrics.NewCounter(
+		"mint_client_errors_total",
+		"Failed requests sent to other mints by peer.",
+		"peer",
+	)
+)
@@ -0,0 +1,348 @@
+package model
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	sqlite3 "github.com/mattn/go-sqlite3"
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/ptr"
+	"github.com/spolu/settle/lib/token"
+	"github.com/spolu/settle/mint"
+)
+
+// APIKey represents a scoped API key granting access to the account of a
+// user. Only the hash of the key secret is stored. The full key is
+// `token.secret`.
+type APIKey struct {
+	Token     string
+	Created   time.Time
+	UserToken string `db:"user_token"`
+	Owner     string
+
+	Name       string
+	SecretHash string `db:"secret_hash"`
+	Scopes     KyScopes
+
+	PayAsset  *string   `db:"pay_asset"`
+	PayLimit  *Amount   `db:"pay_limit"`
+	PaySpent  Amount    `db:"pay_spent"`
+	PayWindow time.Time `db:"pay_window"`
+	// PayVersion is incremented on each payment.
+	PayVersion int64 `db:"pay_version"`
+
+	Expires  *time.Time
+	LastUsed *time.Time `db:"last_used"`
+	Revoked  *time.Time
+}
+
+// ID returns the ID of the object.
+func (k *APIKey) ID() string {
+	return fmt.Sprintf("%s[%s]", k.Owner, k.Token)
+}
+
+// Cursor returns the paging cursor of the API key.
+func (k *APIKey) Cursor() Cursor {
+	return Cursor{Created: k.Created, Key: k.Token}
+}
+
+// NewAPIKeyResource generates a new resource. The full key is only set if
+// provided (upon creation).
+func NewAPIKeyResource(
+	ctx context.Context,
+	key *APIKey,
+	full *string,
+) mint.APIKeyResource {
+	k := mint.APIKeyResource{
+		ID:      key.ID(),
+		Created: key.Created.UnixNano() / mint.TimeResolutionNs,
+		Owner:   key.Owner,
+
+		Name:     key.Name,
+		Scopes:   []mint.KyScope(key.Scopes),
+		PayAsset: key.PayAsset,
+
+		Key: full,
+	}
+	if key.PayLimit != nil {
+		k.PayLimit = (*big.Int)(key.PayLimit)
+	}
+	if key.Expires != nil {
+		k.Expires = ptr.Int64(key.Expires.UnixNano() / mint.TimeResolutionNs)
+	}
+	if key.LastUsed != nil {
+		k.LastUsed = ptr.Int64(key.LastUsed.UnixNano() / mint.TimeResolutionNs)
+	}
+	if key.Revoked != nil {
+		k.Revoked = ptr.Int64(key.Revoked.UnixNano() / mint.TimeResolutionNs)
+	}
+	return k
+}
+
+// hashAPIKeySecret hashes an API key secret. Secrets are random and long
+// enough that a plain SHA-256 is used (contrary to passwords).
+func hashAPIKeySecret(
+	secret string,
+) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(secret)))
+}
+
+// CreateAPIKey creates and stores a new APIKey for the provided user. It
+// returns the key along with its full value, which is not stored.
+func CreateAPIKey(
+	ctx context.Context,
+	user *User,
+	name string,
+	scopes []mint.KyScope,
+	payAsset *string,
+	payLimit *big.Int,
+	expires *time.Time,
+) (*APIKey, string, error) {
+	secret := token.RandStr() + token.RandStr()
+
+	key := APIKey{
+		Token:     token.New("apikey"),
+		Created:   time.Now().UTC(),
+		UserToken: user.Token,
+		Owner:     fmt.Sprintf("%s@%s", user.Username, mint.GetHost(ctx)),
+
+		Name:       name,
+		SecretHash: hashAPIKeySecret(secret),
+		Scopes:     KyScopes(scopes),
+
+		PayAsset:  payAsset,
+		PaySpent:  Amount(*big.NewInt(0)),
+		PayWindow: time.Now().UTC().Truncate(24 * time.Hour),
+
+		Expires: expires,
+	}
+	if payLimit != nil {
+		key.PayLimit = (*Amount)(payLimit)
+	}
+
+	ext := db.Ext(ctx, "mint")
+	if _, err := sqlx.NamedExec(ext, `
+INSERT INTO api_keys
+  (token, created, user_token, owner, name, secret_hash, scopes,
+   pay_asset, pay_limit, pay_spent, pay_window, pay_version, expires,
+   last_used, revoked)
+VALUES
+  (:token, :created, :user_token, :owner, :name, :secret_hash, :scopes,
+   :pay_asset, :pay_limit, :pay_spent, :pay_window, :pay_version, :expires,
+   :last_used, :revoked)
+`, key); err != nil {
+		switch err := err.(type) {
+		case *pq.Error:
+			if err.Code.Name() == "unique_violation" {
+				return nil, "", errors.Trace(ErrUniqueConstraintViolation{err})
+			}
+		case sqlite3.Error:
+			if err.ExtendedCode == sqlite3.ErrConstraintUnique {
+				return nil, "", errors.Trace(ErrUniqueConstraintViolation{err})
+			}
+		}
+		return nil, "", errors.Trace(err)
+	}
+
+	return &key, fmt.Sprintf("%s.%s", key.Token, secret), nil
+}
+
+// Touch records the use of the key at the provided time. Only the last use
+// is updated so that concurrent spendings or revocations are not overwritten.
+func (k *APIKey) Touch(
+	ctx context.Context,
+	now time.Time,
+) error {
+	k.LastUsed = &now
+
+	ext := db.Ext(ctx, "mint")
+	_, err := sqlx.NamedExec(ext, `
+UPDATE api_keys
+SET last_used = :last_used
+WHERE token = :token
+`, k)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	return nil
+}
+
+// Revoke revokes the key at the provided time (a no-op if it is already
+// revoked).
+func (k *APIKey) Revoke(
+	ctx context.Context,
+	now time.Time,
+) error {
+	if k.Revoked != nil {
+		return nil
+	}
+	k.Revoked = &now
+
+	ext := db.Ext(ctx, "mint")
+	_, err := sqlx.NamedExec(ext, `
+UPDATE api_keys
+SET revoked = :revoked
+WHERE token = :token
+  AND revoked IS NULL
+`, k)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	return nil
+}
+
+// LoadAPIKeyByToken attempts to load an API key with the given token.
+func LoadAPIKeyByToken(
+	ctx context.Context,
+	token string,
+) (*APIKey, error) {
+	key := APIKey{
+		Token: token,
+	}
+
+	ext := db.Ext(ctx, "mint")
+	if rows, err := sqlx.NamedQuery(ext, `
+SELECT *
+FROM api_keys
+WHERE token = :token
+`, key); err != nil {
+		return nil, errors.Trace(err)
+	} else if !rows.Next() {
+		return nil, nil
+	} else if err := rows.StructScan(&key); err != nil {
+		defer rows.Close()
+		return nil, errors.Trace(err)
+	} else if err := rows.Close(); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	return &key, nil
+}
+
+// LoadAPIKeyListByUser loads a list of API keys for the given user.
+func LoadAPIKeyListByUser(
+	ctx context.Context,
+	page Page,
+	userToken string,
+) ([]APIKey, bool, error) {
+	query := page.Params(map[string]interface{}{
+		"user_token": userToken,
+	})
+
+	ext := db.Ext(ctx, "mint")
+	rows, err := sqlx.NamedQuery(ext, page.Query("api_keys", "token",
+		[]string{"user_token = :user_token"}), query)
+	if err != nil {
+		return nil, false, errors.Trace(err)
+	}
+
+	keys := []APIKey{}
+
+	defer rows.Close()
+	for rows.Next() {
+		k := APIKey{}
+		err := rows.StructScan(&k)
+		if err != nil {
+			return nil, false, errors.Trace(err)
+		}
+		keys = append(keys, k)
+	}
+
+	i, j, more := page.Bounds(len(keys))
+	return keys[i:j], more, nil
+}
+
+// CheckSecret checks if the provided secret matches the secret hash of the
+// key.
+func (k *APIKey) CheckSecret(
+	ctx context.Context,
+	secret string,
+) error {
+	if subtle.ConstantTimeCompare(
+		[]byte(k.SecretHash), []byte(hashAPIKeySecret(secret))) != 1 {
+		return errors.Newf("Secret mismatch")
+	}
+	return nil
+}
+
+// HasScope returns whether the key was granted the provided scope.
+func (k *APIKey) HasScope(
+	scope mint.KyScope,
+) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Spend records a payment of the provided amount of asset made with the key,
+// resetting the spent amount every day. It errors with ErrPayLimitExceeded if
+// the payment exceeds the daily pay limit of the key and with
+// ErrConcurrentModification if another payment was recorded concurrently
+// since the key was loaded.
+func (k *APIKey) Spend(
+	ctx context.Context,
+	asset string,
+	amount *big.Int,
+) error {
+	if k.PayLimit == nil {
+		return nil
+	}
+	if k.PayAsset == nil || *k.PayAsset != asset {
+		return errors.Trace(ErrPayLimitExceeded{
+			fmt.Sprintf("not allowed to pay with asset %s", asset)})
+	}
+
+	window := time.Now().UTC().Truncate(24 * time.Hour)
+	spent := new(big.Int).Set((*big.Int)(&k.PaySpent))
+	if k.PayWindow.Before(window) {
+		spent = big.NewInt(0)
+	}
+
+	spent.Add(spent, amount)
+	if spent.Cmp((*big.Int)(k.PayLimit)) > 0 {
+		return errors.Trace(ErrPayLimitExceeded{
+			fmt.Sprintf("daily pay limit would be exceeded: %s > %s",
+				spent.String(), (*big.Int)(k.PayLimit).String())})
+	}
+
+	// The spent amount and window are updated only if no payment was made
+	// since the key was loaded, serializing concurrent payments.
+	ext := db.Ext(ctx, "mint")
+	res, err := sqlx.NamedExec(ext, `
+UPDATE api_keys
+SET pay_spent = :pay_spent, pay_window = :pay_window,
+    pay_version = pay_version + 1
+WHERE token = :token
+  AND pay_version = :pay_version
+`, map[string]interface{}{
+		"token":       k.Token,
+		"pay_spent":   Amount(*spent),
+		"pay_window":  window,
+		"pay_version": k.PayVersion,
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return errors.Trace(err)
+	} else if n != 1 {
+		return errors.Trace(ErrConcurrentModification{"api_keys"})
+	}
+	k.PaySpent = Amount(*spent)
+	k.PayWindow = window
+	k.PayVersion++
+
+	return nil
+}
@@ -36,6 +36,11 @@ type Asset struct {
 	Scale int8   // Asset scale.
 }
 
+// Cursor returns the paging cursor of the asset.
+func (a *Asset) Cursor() Cursor {
+	return Cursor{Created: a.Created, Key: a.Token}
+}
+
 // NewAssetResource generates a new resource.
 func NewAssetResource(
 	ctx context.Context,
@@ -147,30 +152,41 @@ func LoadCanonicalAssetByName(
 		r.Owner, r.Code, r.Scale)
 }
 
+// AssetFilter restricts the assets of an asset list.
+type AssetFilter struct {
+	Code *string
+}
+
+// conditions returns the SQL conditions of the filter and adds their
+// parameters to the query parameters.
+func (f AssetFilter) conditions(
+	query map[string]interface{},
+) []string {
+	c := []string{}
+	if f.Code != nil {
+		c = append(c, "code = :filter_code")
+		query["filter_code"] = *f.Code
+	}
+	return c
+}
+
 // LoadAssetListByOwner loads an asset list by owner.
 func LoadAssetListByOwner(
 	ctx context.Context,
-	createdBefore time.Time,
-	limit uint,
+	page Page,
+	filter AssetFilter,
 	owner string,
-) ([]Asset, error) {
-	query := map[string]interface{}{
-		"owner":          owner,
-		"created_before": createdBefore.UTC(),
-		"limit":          limit,
-	}
+) ([]Asset, bool, error) {
+	query := page.Params(map[string]interface{}{
+		"owner": owner,
+	})
 
 	ext := db.Ext(ctx, "mint")
-	rows, err := sqlx.NamedQuery(ext, `
-SELECT *
-FROM assets
-WHERE owner = :owner
-AND created < :created_before
-ORDER BY created DESC
-LIMIT :limit
-`, query)
+	rows, err := sqlx.NamedQuery(ext, page.Query("assets", "token",
+		append([]string{"owner = :owner"}, filter.conditions(query)...)),
+		query)
 	if err != nil {
-		return nil, errors.Trace(err)
+		return nil, false, errors.Trace(err)
 	}
 
 	assets := []Asset{}
@@ -180,10 +196,11 @@ LIMIT :limit
 		a := Asset{}
 		err := rows.StructScan(&a)
 		if err != nil {
-			return nil, errors.Trace(err)
+			return nil, false, errors.Trace(err)
 		}
 		assets = append(assets, a)
 	}
 
-	return assets, nil
+	i, j, more := page.Bounds(len(assets))
+	return assets[i:j], more, nil
 }
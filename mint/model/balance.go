@@ -32,6 +32,22 @@ type Balance struct {
 	Value  Amount
 }
 
+// Cursor returns the paging cursor of the balance in a list with the provided
+// sort.
+func (b *Balance) Cursor(
+	sort Sort,
+) Cursor {
+	c := Cursor{Created: b.Created, Key: b.Token}
+	if sort.Key != SrKeyCreated {
+		c.SortKey = sort.Key
+	}
+	switch sort.Key {
+	case SrKeyValue:
+		c.Value = (*big.Int)(&b.Value).String()
+	}
+	return c
+}
+
 // NewBalanceResource generates a new resource.
 func NewBalanceResource(
 	ctx context.Context,
@@ -89,6 +105,11 @@ VALUES
 		return nil, errors.Trace(err)
 	}
 
+	if err := recordEvent(ctx, mint.EvTpBalanceUpdated, balance.ID(),
+		NewBalanceResource(ctx, &balance), balance.Holder); err != nil {
+		return nil, errors.Trace(err)
+	}
+
 	return &balance, nil
 }
 
@@ -133,6 +154,11 @@ VALUES
 		return nil, errors.Trace(err)
 	}
 
+	if err := recordEvent(ctx, mint.EvTpBalanceUpdated, balance.ID(),
+		NewBalanceResource(ctx, &balance), balance.Holder); err != nil {
+		return nil, errors.Trace(err)
+	}
+
 	return &balance, nil
 }
 
@@ -142,20 +168,31 @@ func (b *Balance) ID() string {
 }
 
 // Save updates the object database representation with the in-memory values.
+// An event is recorded if the value of the balance changed.
 func (b *Balance) Save(
 	ctx context.Context,
 ) error {
 	ext := db.Ext(ctx, "mint")
-	_, err := sqlx.NamedExec(ext, `
+	res, err := sqlx.NamedExec(ext, `
 UPDATE balances
 SET value = :value
 WHERE owner = :owner
   AND token = :token
+  AND value <> :value
 `, b)
 	if err != nil {
 		return errors.Trace(err)
 	}
 
+	if n, err := res.RowsAffected(); err != nil {
+		return errors.Trace(err)
+	} else if n > 0 {
+		if err := recordEvent(ctx, mint.EvTpBalanceUpdated, b.ID(),
+			NewBalanceResource(ctx, b), b.Holder); err != nil {
+			return errors.Trace(err)
+		}
+	}
+
 	return nil
 }
 
@@ -295,30 +332,61 @@ WHERE owner = :owner
 	return &balance, nil
 }
 
-// LoadBalanceListByHolder loads a balance list by holder.
-func LoadBalanceListByHolder(
-	ctx context.Context,
-	createdBefore time.Time,
-	limit uint,
-	holder string,
-) ([]Balance, error) {
-	query := map[string]interface{}{
-		"holder":         holder,
-		"created_before": createdBefore.UTC(),
-		"limit":          limit,
+// BalanceFilter restricts the balances of a balance list.
+type BalanceFilter struct {
+	Owner     *string
+	Holder    *string
+	AssetCode *string
+	MinValue  *big.Int
+	MaxValue  *big.Int
+}
+
+// conditions returns the SQL conditions of the filter and adds their
+// parameters to the query parameters.
+func (f BalanceFilter) conditions(
+	query map[string]interface{},
+) []string {
+	c := []string{}
+	if f.Owner != nil {
+		c = append(c, "owner = :filter_owner")
+		query["filter_owner"] = *f.Owner
+	}
+	if f.Holder != nil {
+		c = append(c, "holder = :filter_holder")
+		query["filter_holder"] = *f.Holder
 	}
+	if f.AssetCode != nil {
+		c = append(c, assetCodeCondition("asset", "filter_asset"))
+		query["filter_asset"] = assetCodePattern(*f.AssetCode)
+	}
+	if f.MinValue != nil {
+		c = append(c, amountCondition("value", ">", "filter_min_value"))
+		query["filter_min_value"] = f.MinValue.String()
+	}
+	if f.MaxValue != nil {
+		c = append(c, amountCondition("value", "<", "filter_max_value"))
+		query["filter_max_value"] = f.MaxValue.String()
+	}
+	return c
+}
+
+// loadBalanceList loads a filtered and sorted page of balances satisfying
+// the provided conditions.
+func loadBalanceList(
+	ctx context.Context,
+	page Page,
+	filter BalanceFilter,
+	query map[string]interface{},
+	conditions ...string,
+) ([]Balance, bool, error) {
+	query = page.Params(query)
+	conditions = append(conditions, filter.conditions(query)...)
 
 	ext := db.Ext(ctx, "mint")
-	rows, err := sqlx.NamedQuery(ext, `
-SELECT *
-FROM balances
-WHERE holder = :holder
-AND created < :created_before
-ORDER BY created DESC
-LIMIT :limit
-`, query)
+	rows, err := sqlx.NamedQuery(ext,
+		page.Query("balances", "token", conditions), query)
 	if err != nil {
-		return nil, errors.Trace(err)
+		return nil, false, errors.Trace(err)
 	}
 
 	balances := []Balance{}
@@ -328,25 +396,53 @@ LIMIT :limit
 		b := Balance{}
 		err := rows.StructScan(&b)
 		if err != nil {
-			return nil, errors.Trace(err)
+			return nil, false, errors.Trace(err)
 		}
 
 		balances = append(balances, b)
 	}
 
-	return balances, nil
+	i, j, more := page.Bounds(len(balances))
+	return balances[i:j], more, nil
+}
+
+// LoadBalanceListByHolder loads a balance list by holder.
+func LoadBalanceListByHolder(
+	ctx context.Context,
+	page Page,
+	filter BalanceFilter,
+	holder string,
+) ([]Balance, bool, error) {
+	return loadBalanceList(ctx, page, filter, map[string]interface{}{
+		"holder": holder,
+	}, "holder = :holder")
 }
 
 // LoadBalanceListByAsset loads a balance list by asset.
 func LoadBalanceListByAsset(
+	ctx context.Context,
+	page Page,
+	filter BalanceFilter,
+	asset string,
+) ([]Balance, bool, error) {
+	return loadBalanceList(ctx, page, filter, map[string]interface{}{
+		"asset": asset,
+	}, "asset = :asset")
+}
+
+// LoadPropagatedBalanceList loads a list of propagated balances ordered by
+// decreasing (created, token), starting strictly after the provided created
+// and token pair. It is used to walk all propagated balances in batches.
+func LoadPropagatedBalanceList(
 	ctx context.Context,
 	createdBefore time.Time,
+	tokenBefore string,
 	limit uint,
-	asset string,
 ) ([]Balance, error) {
 	query := map[string]interface{}{
-		"asset":          asset,
+		"propagation":    mint.PgTpPropagated,
 		"created_before": createdBefore.UTC(),
+		"token_before":   tokenBefore,
 		"limit":          limit,
 	}
 
@@ -354,9 +450,10 @@ func LoadBalanceListByAsset(
 	rows, err := sqlx.NamedQuery(ext, `
 SELECT *
 FROM balances
-WHERE asset = :asset
-AND created < :created_before
-ORDER BY created DESC
+WHERE propagation = :propagation
+  AND (created < :created_before
+    OR (created = :created_before AND token < :token_before))
+ORDER BY created DESC, token DESC
 LIMIT :limit
 `, query)
 	if err != nil {
@@ -0,0 +1,111 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	sqlite3 "github.com/mattn/go-sqlite3"
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/token"
+	"github.com/spolu/settle/mint"
+)
+
+// BalanceDiscrepancy records a divergence found between a propagated balance
+// and its canonical version during reconciliation. Discrepancies are only
+// stored on the mint holding the propagated copy.
+type BalanceDiscrepancy struct {
+	Token   string
+	Created time.Time
+
+	Balance string // Propagated balance ID.
+	Asset   string // Asset name.
+	Holder  string // Holder address.
+
+	Type           mint.DsType
+	LocalValue     Amount  `db:"local_value"`
+	CanonicalValue *Amount `db:"canonical_value"`
+}
+
+// CreateBalanceDiscrepancy creates and stores a new BalanceDiscrepancy.
+func CreateBalanceDiscrepancy(
+	ctx context.Context,
+	balance *Balance,
+	typ mint.DsType,
+	canonicalValue *Amount,
+) (*BalanceDiscrepancy, error) {
+	discrepancy := BalanceDiscrepancy{
+		Token:   token.New("discrepancy"),
+		Created: time.Now().UTC(),
+
+		Balance: balance.ID(),
+		Asset:   balance.Asset,
+		Holder:  balance.Holder,
+
+		Type:           typ,
+		LocalValue:     balance.Value,
+		CanonicalValue: canonicalValue,
+	}
+
+	ext := db.Ext(ctx, "mint")
+	if _, err := sqlx.NamedExec(ext, `
+INSERT INTO balance_discrepancies
+  (token, created, balance, asset, holder, type, local_value,
+   canonical_value)
+VALUES
+  (:token, :created, :balance, :asset, :holder, :type, :local_value,
+   :canonical_value)
+`, discrepancy); err != nil {
+		switch err := err.(type) {
+		case *pq.Error:
+			if err.Code.Name() == "unique_violation" {
+				return nil, errors.Trace(ErrUniqueConstraintViolation{err})
+			}
+		case sqlite3.Error:
+			if err.ExtendedCode == sqlite3.ErrConstraintUnique {
+				return nil, errors.Trace(ErrUniqueConstraintViolation{err})
+			}
+		}
+		return nil, errors.Trace(err)
+	}
+
+	return &discrepancy, nil
+}
+
+// LoadBalanceDiscrepanciesByBalance loads all the discrepancies recorded for
+// the given propagated balance id.
+func LoadBalanceDiscrepanciesByBalance(
+	ctx context.Context,
+	balance string,
+) ([]*BalanceDiscrepancy, error) {
+	query := map[string]interface{}{
+		"balance": balance,
+	}
+
+	ext := db.Ext(ctx, "mint")
+	rows, err := sqlx.NamedQuery(ext, `
+SELECT *
+FROM balance_discrepancies
+WHERE balance = :balance
+ORDER BY created ASC
+`, query)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	discrepancies := []*BalanceDiscrepancy{}
+
+	defer rows.Close()
+	for rows.Next() {
+		d := BalanceDiscrepancy{}
+		err := rows.StructScan(&d)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		discrepancies = append(discrepancies, &d)
+	}
+
+	return discrepancies, nil
+}
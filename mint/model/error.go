@@ -12,3 +12,36 @@ func (e ErrUniqueConstraintViolation) Error() string {
 	return fmt.Sprintf(
 		"Unique constraint violation in %s", e.Err.Error())
 }
+
+// ErrSpendingLimitExceeded is returned when a spending exceeds one of the
+// limits of a spending policy.
+type ErrSpendingLimitExceeded struct {
+	Limit string
+}
+
+func (e ErrSpendingLimitExceeded) Error() string {
+	return fmt.Sprintf(
+		"Spending limit exceeded: %s", e.Limit)
+}
+
+// ErrPayLimitExceeded is returned when a payment made with an API key exceeds
+// its daily pay limit or is made with an asset the key can't pay with.
+type ErrPayLimitExceeded struct {
+	Reason string
+}
+
+func (e ErrPayLimitExceeded) Error() string {
+	return fmt.Sprintf(
+		"Pay limit exceeded: %s", e.Reason)
+}
+
+// ErrConcurrentModification is returned when an object was modified by a
+// concurrent DB transaction since it was loaded.
+type ErrConcurrentModification struct {
+	Table string
+}
+
+func (e ErrConcurrentModification) Error() string {
+	return fmt.Sprintf(
+		"Concurrent modification in %s", e.Table)
+}
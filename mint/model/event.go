@@ -0,0 +1,314 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/token"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/lib/hub"
+)
+
+// Event represents a change to an object of a user, recorded in the same DB
+// transaction as the change so that it is visible once committed. Events are
+// only recorded for users of this mint.
+//
+// Events are positioned in the event log by their sequence number, assigned
+// once they are committed (see SequenceEventsByAddress) so that events
+// committed late by long transactions are not skipped by streams that already
+// moved past their creation time.
+type Event struct {
+	Token   string
+	Created time.Time
+	Seq     *int64
+
+	Address string
+	Type    mint.EvType
+	Object  string
+	Payload string
+}
+
+// ID returns the position of the event in the event log, used as event ID.
+func (e *Event) ID() string {
+	if e.Seq == nil {
+		return ""
+	}
+	return strconv.FormatInt(*e.Seq, 10)
+}
+
+// ParseEventID parses an event ID into the sequence number it represents.
+func ParseEventID(
+	id string,
+) (*int64, error) {
+	seq, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if seq <= 0 {
+		return nil, errors.Newf("Invalid event id: %s", id)
+	}
+	return &seq, nil
+}
+
+// NewEventResource generates a new resource.
+func NewEventResource(
+	ctx context.Context,
+	event *Event,
+) mint.EventResource {
+	data := json.RawMessage(event.Payload)
+	return mint.EventResource{
+		ID:      event.ID(),
+		Created: event.Created.UnixNano() / mint.TimeResolutionNs,
+		Type:    event.Type,
+		Object:  event.Object,
+		Data:    &data,
+	}
+}
+
+// recordEvent records an event for each of the addresses specified that
+// belong to this mint. Their streams are notified once committed.
+func recordEvent(
+	ctx context.Context,
+	typ mint.EvType,
+	object string,
+	resource interface{},
+	addresses ...string,
+) error {
+	payload, err := json.Marshal(resource)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	recorded := map[string]bool{}
+	for _, address := range addresses {
+		_, host, err := mint.UsernameAndMintHostFromAddress(ctx, address)
+		if err != nil || host != mint.GetHost(ctx) || recorded[address] {
+			continue
+		}
+		recorded[address] = true
+
+		event := Event{
+			Token:   token.New("event"),
+			Created: time.Now().UTC(),
+
+			Address: address,
+			Type:    typ,
+			Object:  object,
+			Payload: string(payload),
+		}
+
+		ext := db.Ext(ctx, "mint")
+		if _, err := sqlx.NamedExec(ext, `
+INSERT INTO events
+  (token, created, address, type, object, payload)
+VALUES
+  (:token, :created, :address, :type, :object, :payload)
+`, event); err != nil {
+			return errors.Trace(err)
+		}
+
+		db.OnCommit(ctx, func() {
+			hub.Get(ctx).Notify(address)
+		})
+	}
+
+	return nil
+}
+
+// SequenceEventsByAddress assigns sequence numbers to the committed events of
+// an address that don't have one yet, in order of creation. Events committed
+// later receive greater sequence numbers than the ones already assigned, so
+// that the sequence follows the order in which events become visible.
+//
+// The sequence is locked until the current transaction completes so that
+// concurrent assignments (including from other processes sharing the DB) never
+// draw the same numbers.
+func SequenceEventsByAddress(
+	ctx context.Context,
+	address string,
+) error {
+	tokens, err := loadUnsequencedEventTokens(ctx, address)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	seq, err := lockEventsSequence(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	// The events are loaded again once the sequence is locked as they may
+	// have been sequenced concurrently in the meantime.
+	tokens, err = loadUnsequencedEventTokens(ctx, address)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	ext := db.Ext(ctx, "mint")
+	for _, token := range tokens {
+		seq++
+		if _, err := sqlx.NamedExec(ext, `
+UPDATE events
+SET seq = :seq
+WHERE token = :token
+`, map[string]interface{}{
+			"seq":   seq,
+			"token": token,
+		}); err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	if _, err := sqlx.NamedExec(ext, `
+UPDATE sequences
+SET value = :value
+WHERE name = :name
+`, map[string]interface{}{
+		"name":  eventsSequence,
+		"value": seq,
+	}); err != nil {
+		return errors.Trace(err)
+	}
+
+	return nil
+}
+
+// eventsSequence is the name of the sequence events are numbered from.
+const eventsSequence = "events"
+
+// lockEventsSequence locks the events sequence until the current transaction
+// completes and returns the last sequence number assigned. The sequence is
+// created on first use, following the events already sequenced.
+func lockEventsSequence(
+	ctx context.Context,
+) (int64, error) {
+	ext := db.Ext(ctx, "mint")
+	query := map[string]interface{}{
+		"name": eventsSequence,
+	}
+
+	res, err := sqlx.NamedExec(ext, `
+UPDATE sequences
+SET value = value
+WHERE name = :name
+`, query)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return 0, errors.Trace(err)
+	} else if n == 0 {
+		if _, err := sqlx.NamedExec(ext, `
+INSERT INTO sequences
+  (name, value)
+VALUES
+  (:name, (SELECT COALESCE(MAX(seq), 0) FROM events))
+`, query); err != nil {
+			return 0, errors.Trace(err)
+		}
+	}
+
+	rows, err := sqlx.NamedQuery(ext, `
+SELECT value
+FROM sequences
+WHERE name = :name
+`, query)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	defer rows.Close()
+
+	value := int64(0)
+	if rows.Next() {
+		if err := rows.Scan(&value); err != nil {
+			return 0, errors.Trace(err)
+		}
+	}
+
+	return value, nil
+}
+
+// loadUnsequencedEventTokens loads the tokens of the committed events of an
+// address that don't have a sequence number yet, in order of creation.
+func loadUnsequencedEventTokens(
+	ctx context.Context,
+	address string,
+) ([]string, error) {
+	rows, err := sqlx.NamedQuery(db.Ext(ctx, "mint"), `
+SELECT token
+FROM events
+WHERE address = :address
+  AND seq IS NULL
+ORDER BY created ASC, token ASC
+`, map[string]interface{}{
+		"address": address,
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer rows.Close()
+
+	tokens := []string{}
+	for rows.Next() {
+		token := ""
+		if err := rows.Scan(&token); err != nil {
+			return nil, errors.Trace(err)
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, nil
+}
+
+// LoadEventListByAddress loads the sequenced events of an address following
+// the specified sequence number (if any) in increasing order.
+func LoadEventListByAddress(
+	ctx context.Context,
+	address string,
+	after *int64,
+	limit uint,
+) ([]Event, error) {
+	query := map[string]interface{}{
+		"address": address,
+		"limit":   limit,
+		"after":   int64(0),
+	}
+	if after != nil {
+		query["after"] = *after
+	}
+
+	ext := db.Ext(ctx, "mint")
+	rows, err := sqlx.NamedQuery(ext, `
+SELECT *
+FROM events
+WHERE address = :address
+  AND seq > :after
+ORDER BY seq ASC
+LIMIT :limit
+`, query)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	events := []Event{}
+
+	defer rows.Close()
+	for rows.Next() {
+		e := Event{}
+		err := rows.StructScan(&e)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		events = append(events, e)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,132 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	sqlite3 "github.com/mattn/go-sqlite3"
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+)
+
+// IdempotencyKey represents a key provided by a user to make a request
+// idempotent, along with the fingerprint of the request and the response that
+// was served for it once completed.
+type IdempotencyKey struct {
+	UserToken string `db:"user_token"`
+	Key       string `db:"idempotency_key"`
+	Created   time.Time
+
+	Fingerprint string
+	Status      *int
+	Response    *string
+}
+
+// CreateIdempotencyKey creates and stores a new in-progress IdempotencyKey. It
+// errors with ErrUniqueConstraintViolation if the key was already used by the
+// user.
+func CreateIdempotencyKey(
+	ctx context.Context,
+	userToken string,
+	key string,
+	fingerprint string,
+) (*IdempotencyKey, error) {
+	k := IdempotencyKey{
+		UserToken: userToken,
+		Key:       key,
+		Created:   time.Now().UTC(),
+
+		Fingerprint: fingerprint,
+	}
+
+	ext := db.Ext(ctx, "mint")
+	if _, err := sqlx.NamedExec(ext, `
+INSERT INTO idempotency_keys
+  (user_token, idempotency_key, created, fingerprint, status, response)
+VALUES
+  (:user_token, :idempotency_key, :created, :fingerprint, :status, :response)
+`, k); err != nil {
+		switch err := err.(type) {
+		case *pq.Error:
+			if err.Code.Name() == "unique_violation" {
+				return nil, errors.Trace(ErrUniqueConstraintViolation{err})
+			}
+		case sqlite3.Error:
+			if err.ExtendedCode == sqlite3.ErrConstraintUnique ||
+				err.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+				return nil, errors.Trace(ErrUniqueConstraintViolation{err})
+			}
+		}
+		return nil, errors.Trace(err)
+	}
+
+	return &k, nil
+}
+
+// Save updates the object database representation with the in-memory values.
+func (k *IdempotencyKey) Save(
+	ctx context.Context,
+) error {
+	ext := db.Ext(ctx, "mint")
+	_, err := sqlx.NamedExec(ext, `
+UPDATE idempotency_keys
+SET status = :status, response = :response
+WHERE user_token = :user_token
+  AND idempotency_key = :idempotency_key
+`, k)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	return nil
+}
+
+// Delete deletes the object database representation, releasing the key.
+func (k *IdempotencyKey) Delete(
+	ctx context.Context,
+) error {
+	ext := db.Ext(ctx, "mint")
+	_, err := sqlx.NamedExec(ext, `
+DELETE FROM idempotency_keys
+WHERE user_token = :user_token
+  AND idempotency_key = :idempotency_key
+`, k)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	return nil
+}
+
+// LoadIdempotencyKey attempts to load an idempotency key used by a user.
+func LoadIdempotencyKey(
+	ctx context.Context,
+	userToken string,
+	key string,
+) (*IdempotencyKey, error) {
+	k := IdempotencyKey{
+		UserToken: userToken,
+		Key:       key,
+	}
+
+	ext := db.Ext(ctx, "mint")
+	if rows, err := sqlx.NamedQuery(ext, `
+SELECT *
+FROM idempotency_keys
+WHERE user_token = :user_token
+  AND idempotency_key = :idempotency_key
+`, k); err != nil {
+		return nil, errors.Trace(err)
+	} else if !rows.Next() {
+		return nil, nil
+	} else if err := rows.StructScan(&k); err != nil {
+		defer rows.Close()
+		return nil, errors.Trace(err)
+	} else if err := rows.Close(); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	return &k, nil
+}
@@ -0,0 +1,157 @@
+package model
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	sqlite3 "github.com/mattn/go-sqlite3"
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/token"
+	"github.com/spolu/settle/mint"
+)
+
+// IdentityKey represents an Ed25519 key pair used by this mint to sign its
+// requests to other mints. The most recently created key is the one in use.
+type IdentityKey struct {
+	Token   string
+	Created time.Time
+
+	PublicKey  string `db:"public_key"`  // Base64 encoded public key.
+	PrivateKey string `db:"private_key"` // Base64 encoded private key.
+}
+
+// NewKeyResource generates a new resource.
+func NewKeyResource(
+	ctx context.Context,
+	key *IdentityKey,
+) mint.KeyResource {
+	return mint.KeyResource{
+		ID: fmt.Sprintf(
+			"%s[%s]", mint.GetHost(ctx), key.Token),
+		Created:   key.Created.UnixNano() / mint.TimeResolutionNs,
+		Host:      mint.GetHost(ctx),
+		Algorithm: mint.KeyAlgorithm,
+		PublicKey: key.PublicKey,
+	}
+}
+
+// CreateIdentityKey generates, stores and returns a new IdentityKey.
+func CreateIdentityKey(
+	ctx context.Context,
+) (*IdentityKey, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	key := IdentityKey{
+		Token:   token.New("key"),
+		Created: time.Now().UTC(),
+
+		PublicKey:  base64.StdEncoding.EncodeToString(pub),
+		PrivateKey: base64.StdEncoding.EncodeToString(priv),
+	}
+
+	ext := db.Ext(ctx, "mint")
+	if _, err := sqlx.NamedExec(ext, `
+INSERT INTO identity_keys
+  (token, created, public_key, private_key)
+VALUES
+  (:token, :created, :public_key, :private_key)
+`, key); err != nil {
+		switch err := err.(type) {
+		case *pq.Error:
+			if err.Code.Name() == "unique_violation" {
+				return nil, errors.Trace(ErrUniqueConstraintViolation{err})
+			}
+		case sqlite3.Error:
+			if err.ExtendedCode == sqlite3.ErrConstraintUnique {
+				return nil, errors.Trace(ErrUniqueConstraintViolation{err})
+			}
+		}
+		return nil, errors.Trace(err)
+	}
+
+	return &key, nil
+}
+
+// LoadLatestIdentityKey attempts to load the most recently created identity
+// key.
+func LoadLatestIdentityKey(
+	ctx context.Context,
+) (*IdentityKey, error) {
+	key := IdentityKey{}
+
+	ext := db.Ext(ctx, "mint")
+	if rows, err := sqlx.NamedQuery(ext, `
+SELECT *
+FROM identity_keys
+ORDER BY created DESC
+LIMIT 1
+`, key); err != nil {
+		return nil, errors.Trace(err)
+	} else if !rows.Next() {
+		return nil, nil
+	} else if err := rows.StructScan(&key); err != nil {
+		defer rows.Close()
+		return nil, errors.Trace(err)
+	} else if err := rows.Close(); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	return &key, nil
+}
+
+// LoadIdentityKeyList loads all the identity keys of the mint, most recent
+// first.
+func LoadIdentityKeyList(
+	ctx context.Context,
+) ([]IdentityKey, error) {
+	ext := db.Ext(ctx, "mint")
+	rows, err := sqlx.NamedQuery(ext, `
+SELECT *
+FROM identity_keys
+ORDER BY created DESC
+`, map[string]interface{}{})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	keys := []IdentityKey{}
+
+	defer rows.Close()
+	for rows.Next() {
+		k := IdentityKey{}
+		err := rows.StructScan(&k)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		keys = append(keys, k)
+	}
+
+	return keys, nil
+}
+
+// LoadOrCreateIdentityKey loads the latest identity key or creates one if none
+// exists yet.
+func LoadOrCreateIdentityKey(
+	ctx context.Context,
+) (*IdentityKey, error) {
+	key, err := LoadLatestIdentityKey(ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	} else if key == nil {
+		key, err = CreateIdentityKey(ctx)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return key, nil
+}
@@ -0,0 +1,128 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	sqlite3 "github.com/mattn/go-sqlite3"
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/mint"
+)
+
+// MintSync tracks the synchronization state of the propagated offers stored on
+// this mint with the remote mint they were propagated from. The offers of a
+// suspect mint are not advertised.
+type MintSync struct {
+	Host    string
+	Created time.Time
+	Updated time.Time
+
+	Failures uint
+	Status   mint.SyStatus
+}
+
+// CreateMintSync creates and stores a new healthy MintSync for the given
+// remote mint.
+func CreateMintSync(
+	ctx context.Context,
+	host string,
+) (*MintSync, error) {
+	sync := MintSync{
+		Host:    host,
+		Created: time.Now().UTC(),
+		Updated: time.Now().UTC(),
+
+		Failures: 0,
+		Status:   mint.SyStHealthy,
+	}
+
+	ext := db.Ext(ctx, "mint")
+	if _, err := sqlx.NamedExec(ext, `
+INSERT INTO mint_syncs
+  (host, created, updated, failures, status)
+VALUES
+  (:host, :created, :updated, :failures, :status)
+`, sync); err != nil {
+		switch err := err.(type) {
+		case *pq.Error:
+			if err.Code.Name() == "unique_violation" {
+				return nil, errors.Trace(ErrUniqueConstraintViolation{err})
+			}
+		case sqlite3.Error:
+			if err.ExtendedCode == sqlite3.ErrConstraintUnique {
+				return nil, errors.Trace(ErrUniqueConstraintViolation{err})
+			}
+		}
+		return nil, errors.Trace(err)
+	}
+
+	return &sync, nil
+}
+
+// Save updates the object database representation with the in-memory values.
+func (s *MintSync) Save(
+	ctx context.Context,
+) error {
+	s.Updated = time.Now().UTC()
+
+	ext := db.Ext(ctx, "mint")
+	_, err := sqlx.NamedExec(ext, `
+UPDATE mint_syncs
+SET updated = :updated, failures = :failures, status = :status
+WHERE host = :host
+`, s)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	return nil
+}
+
+// LoadMintSyncByHost attempts to load the mint sync for the given remote mint.
+func LoadMintSyncByHost(
+	ctx context.Context,
+	host string,
+) (*MintSync, error) {
+	sync := MintSync{
+		Host: host,
+	}
+
+	ext := db.Ext(ctx, "mint")
+	if rows, err := sqlx.NamedQuery(ext, `
+SELECT *
+FROM mint_syncs
+WHERE host = :host
+`, sync); err != nil {
+		return nil, errors.Trace(err)
+	} else if !rows.Next() {
+		return nil, nil
+	} else if err := rows.StructScan(&sync); err != nil {
+		defer rows.Close()
+		return nil, errors.Trace(err)
+	} else if err := rows.Close(); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	return &sync, nil
+}
+
+// LoadOrCreateMintSyncByHost loads the existing mint sync for the given remote
+// mint or creates a healthy one if it does not exist.
+func LoadOrCreateMintSyncByHost(
+	ctx context.Context,
+	host string,
+) (*MintSync, error) {
+	sync, err := LoadMintSyncByHost(ctx, host)
+	if err != nil {
+		return nil, errors.Trace(err)
+	} else if sync == nil {
+		sync, err = CreateMintSync(ctx, host)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return sync, nil
+}
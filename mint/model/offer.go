@@ -37,6 +37,27 @@ type Offer struct {
 
 	Status    mint.OfStatus
 	Remainder Amount
+
+	// SortPrice is stored to sort offers by price (see SortPrice).
+	SortPrice string `db:"sort_price"`
+}
+
+// Cursor returns the paging cursor of the offer in a list with the provided
+// sort.
+func (o *Offer) Cursor(
+	sort Sort,
+) Cursor {
+	c := Cursor{Created: o.Created, Key: o.Token}
+	if sort.Key != SrKeyCreated {
+		c.SortKey = sort.Key
+	}
+	switch sort.Key {
+	case SrKeyPrice:
+		c.Value = o.SortPrice
+	case SrKeyRemainder:
+		c.Value = (*big.Int)(&o.Remainder).String()
+	}
+	return c
 }
 
 // NewOfferResource generates a new resource.
@@ -87,16 +108,18 @@ func CreateCanonicalOffer(
 
 		Status:    status,
 		Remainder: remainder,
+
+		SortPrice: SortPrice((*big.Int)(&basePrice), (*big.Int)(&quotePrice)),
 	}
 
 	ext := db.Ext(ctx, "mint")
 	if _, err := sqlx.NamedExec(ext, `
 INSERT INTO offers
   (owner, token, created, propagation, base_asset, quote_asset,
-   base_price, quote_price, amount, status, remainder)
+   base_price, quote_price, amount, status, remainder, sort_price)
 VALUES
   (:owner, :token, :created, :propagation, :base_asset, :quote_asset,
-   :base_price, :quote_price, :amount, :status, :remainder)
+   :base_price, :quote_price, :amount, :status, :remainder, :sort_price)
 `, offer); err != nil {
 		switch err := err.(type) {
 		case *pq.Error:
@@ -111,6 +134,11 @@ VALUES
 		return nil, errors.Trace(err)
 	}
 
+	if err := recordEvent(ctx, mint.EvType("offer."+string(offer.Status)),
+		offer.ID(), NewOfferResource(ctx, &offer), offer.Owner); err != nil {
+		return nil, errors.Trace(err)
+	}
+
 	return &offer, nil
 }
 
@@ -142,16 +170,18 @@ func CreatePropagatedOffer(
 
 		Status:    status,
 		Remainder: remainder,
+
+		SortPrice: SortPrice((*big.Int)(&basePrice), (*big.Int)(&quotePrice)),
 	}
 
 	ext := db.Ext(ctx, "mint")
 	if _, err := sqlx.NamedExec(ext, `
 INSERT INTO offers
   (owner, token, created, propagation, base_asset, quote_asset,
-   base_price, quote_price, amount, status, remainder)
+   base_price, quote_price, amount, status, remainder, sort_price)
 VALUES
   (:owner, :token, :created, :propagation, :base_asset, :quote_asset,
-   :base_price, :quote_price, :amount, :status, :remainder)
+   :base_price, :quote_price, :amount, :status, :remainder, :sort_price)
 `, offer); err != nil {
 		switch err := err.(type) {
 		case *pq.Error:
@@ -166,6 +196,11 @@ VALUES
 		return nil, errors.Trace(err)
 	}
 
+	if err := recordEvent(ctx, mint.EvType("offer."+string(offer.Status)),
+		offer.ID(), NewOfferResource(ctx, &offer), offer.Owner); err != nil {
+		return nil, errors.Trace(err)
+	}
+
 	return &offer, nil
 }
 
@@ -175,13 +210,35 @@ func (o *Offer) ID() string {
 }
 
 // Save updates the object database representation with the in-memory values.
+// An event is recorded if the status of the offer changed.
 func (o *Offer) Save(
 	ctx context.Context,
 ) error {
 	ext := db.Ext(ctx, "mint")
-	_, err := sqlx.NamedExec(ext, `
+	res, err := sqlx.NamedExec(ext, `
 UPDATE offers
 SET status = :status, remainder = :remainder
+WHERE owner = :owner
+  AND token = :token
+  AND status <> :status
+`, o)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return errors.Trace(err)
+	} else if n > 0 {
+		if err := recordEvent(ctx, mint.EvType("offer."+string(o.Status)),
+			o.ID(), NewOfferResource(ctx, o), o.Owner); err != nil {
+			return errors.Trace(err)
+		}
+		return nil
+	}
+
+	_, err = sqlx.NamedExec(ext, `
+UPDATE offers
+SET remainder = :remainder
 WHERE owner = :owner
   AND token = :token
 `, o)
@@ -286,27 +343,156 @@ func LoadPropagatedOfferByID(
 	return LoadPropagatedOfferByOwnerToken(ctx, owner, token)
 }
 
-// LoadOfferListByBaseAsset loads a balance list by base asset.
+// OfferFilter restricts the offers of an offer list.
+type OfferFilter struct {
+	Owner        *string
+	Status       *mint.OfStatus
+	AssetCode    *string
+	MinRemainder *big.Int
+	MaxRemainder *big.Int
+}
+
+// conditions returns the SQL conditions of the filter and adds their
+// parameters to the query parameters.
+func (f OfferFilter) conditions(
+	query map[string]interface{},
+) []string {
+	c := []string{}
+	if f.Owner != nil {
+		c = append(c, "owner = :filter_owner")
+		query["filter_owner"] = *f.Owner
+	}
+	if f.Status != nil {
+		c = append(c, "status = :filter_status")
+		query["filter_status"] = string(*f.Status)
+	}
+	if f.AssetCode != nil {
+		c = append(c, "("+assetCodeCondition("base_asset", "filter_asset")+
+			" OR "+assetCodeCondition("quote_asset", "filter_asset")+")")
+		query["filter_asset"] = assetCodePattern(*f.AssetCode)
+	}
+	if f.MinRemainder != nil {
+		c = append(c,
+			amountCondition("remainder", ">", "filter_min_remainder"))
+		query["filter_min_remainder"] = f.MinRemainder.String()
+	}
+	if f.MaxRemainder != nil {
+		c = append(c,
+			amountCondition("remainder", "<", "filter_max_remainder"))
+		query["filter_max_remainder"] = f.MaxRemainder.String()
+	}
+	return c
+}
+
+// notSuspectCondition is the SQL condition excluding the offers propagated
+// from suspect mints (the suspect parameter being mint.SyStSuspect).
+const notSuspectCondition = `NOT EXISTS (
+    SELECT 1
+    FROM mint_syncs
+    WHERE mint_syncs.status = :suspect
+      AND substr(offers.owner,
+        length(offers.owner) - length(mint_syncs.host)) =
+        '@' || mint_syncs.host
+  )`
+
+// loadOfferList loads a filtered and sorted page of offers satisfying the
+// provided conditions.
+func loadOfferList(
+	ctx context.Context,
+	page Page,
+	filter OfferFilter,
+	query map[string]interface{},
+	conditions ...string,
+) ([]Offer, bool, error) {
+	query = page.Params(query)
+	conditions = append(conditions, filter.conditions(query)...)
+
+	ext := db.Ext(ctx, "mint")
+	rows, err := sqlx.NamedQuery(ext,
+		page.Query("offers", "token", conditions), query)
+	if err != nil {
+		return nil, false, errors.Trace(err)
+	}
+
+	offers := []Offer{}
+
+	defer rows.Close()
+	for rows.Next() {
+		o := Offer{}
+		err := rows.StructScan(&o)
+		if err != nil {
+			return nil, false, errors.Trace(err)
+		}
+
+		offers = append(offers, o)
+	}
+
+	i, j, more := page.Bounds(len(offers))
+	return offers[i:j], more, nil
+}
+
+// LoadCanonicalOfferListByOwner loads a list of canonical offers by owner.
+func LoadCanonicalOfferListByOwner(
+	ctx context.Context,
+	page Page,
+	filter OfferFilter,
+	owner string,
+) ([]Offer, bool, error) {
+	return loadOfferList(ctx, page, filter, map[string]interface{}{
+		"owner":       owner,
+		"propagation": mint.PgTpCanonical,
+	}, "owner = :owner", "propagation = :propagation")
+}
+
+// LoadOfferListByBaseAsset loads a balance list by base asset. The offers
+// propagated from suspect mints are excluded.
 func LoadOfferListByBaseAsset(
 	ctx context.Context,
-	createdBefore time.Time,
-	limit uint,
+	page Page,
+	filter OfferFilter,
+	asset string,
+) ([]Offer, bool, error) {
+	return loadOfferList(ctx, page, filter, map[string]interface{}{
+		"base_asset": asset,
+		"suspect":    mint.SyStSuspect,
+	}, "base_asset = :base_asset", notSuspectCondition)
+}
+
+// LoadOfferListByQuoteAsset loads a balance list by quote asset. The offers
+// propagated from suspect mints are excluded.
+func LoadOfferListByQuoteAsset(
+	ctx context.Context,
+	page Page,
+	filter OfferFilter,
+	asset string,
+) ([]Offer, bool, error) {
+	return loadOfferList(ctx, page, filter, map[string]interface{}{
+		"quote_asset": asset,
+		"suspect":     mint.SyStSuspect,
+	}, "quote_asset = :quote_asset", notSuspectCondition)
+}
+
+// LoadActiveOfferListByAsset loads the list of active offers whose base or
+// quote asset is the specified asset. The offers propagated from suspect mints
+// are excluded.
+func LoadActiveOfferListByAsset(
+	ctx context.Context,
 	asset string,
 ) ([]Offer, error) {
 	query := map[string]interface{}{
-		"base_asset":     asset,
-		"created_before": createdBefore.UTC(),
-		"limit":          limit,
+		"asset":   asset,
+		"active":  mint.OfStActive,
+		"suspect": mint.SyStSuspect,
 	}
 
 	ext := db.Ext(ctx, "mint")
 	rows, err := sqlx.NamedQuery(ext, `
 SELECT *
 FROM offers
-WHERE base_asset = :base_asset
-  AND created < :created_before
-ORDER BY created DESC
-LIMIT :limit
+WHERE (base_asset = :asset OR quote_asset = :asset)
+  AND status = :active
+  AND `+notSuspectCondition+`
+ORDER BY created ASC, token ASC
 `, query)
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -328,16 +514,20 @@ LIMIT :limit
 	return offers, nil
 }
 
-// LoadOfferListByQuoteAsset loads a balance list by quote asset.
-func LoadOfferListByQuoteAsset(
+// LoadActivePropagatedOfferList loads a list of active propagated offers
+// ordered by decreasing (created, token), starting strictly after the provided
+// created and token pair. It is used to walk all propagated offers in batches.
+func LoadActivePropagatedOfferList(
 	ctx context.Context,
 	createdBefore time.Time,
+	tokenBefore string,
 	limit uint,
-	asset string,
 ) ([]Offer, error) {
 	query := map[string]interface{}{
-		"quote_asset":    asset,
+		"propagation":    mint.PgTpPropagated,
+		"status":         mint.OfStActive,
 		"created_before": createdBefore.UTC(),
+		"token_before":   tokenBefore,
 		"limit":          limit,
 	}
 
@@ -345,9 +535,11 @@ func LoadOfferListByQuoteAsset(
 	rows, err := sqlx.NamedQuery(ext, `
 SELECT *
 FROM offers
-WHERE quote_asset = :quote_asset
-  AND created < :created_before
-ORDER BY created DESC
+WHERE propagation = :propagation
+  AND status = :status
+  AND (created < :created_before
+    OR (created = :created_before AND token < :token_before))
+ORDER BY created DESC, token DESC
 LIMIT :limit
 `, query)
 	if err != nil {
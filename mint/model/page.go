@@ -0,0 +1,370 @@
+package model
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spolu/settle/lib/errors"
+)
+
+// Cursor identifies the position of an object in a list ordered by decreasing
+// creation date and key (generally the object token), so that objects created
+// at the same time are neither skipped nor duplicated when paging. Cursors of
+// lists sorted by another sort key also carry the sort key and its value for
+// the object, so that paging seeks on it even if the object left the list.
+type Cursor struct {
+	Created time.Time
+	Key     string
+	SortKey string
+	Value   string
+}
+
+// String returns the opaque representation of the cursor.
+func (c Cursor) String() string {
+	if c.SortKey != "" {
+		return base64.RawURLEncoding.EncodeToString([]byte(
+			fmt.Sprintf("%d;%s=%s:%s",
+				c.Created.UnixNano(), c.SortKey, c.Value, c.Key)))
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(
+		fmt.Sprintf("%d:%s", c.Created.UnixNano(), c.Key)))
+}
+
+// ParseCursor parses the opaque representation of a cursor.
+func ParseCursor(
+	cursor string,
+) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ss := strings.SplitN(string(raw), ":", 2)
+	if len(ss) != 2 || ss[1] == "" {
+		return nil, errors.Newf("Invalid cursor: %s", cursor)
+	}
+	hs := strings.SplitN(ss[0], ";", 2)
+	ns, err := strconv.ParseInt(hs[0], 10, 64)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	c := Cursor{
+		Created: time.Unix(0, ns).UTC(),
+		Key:     ss[1],
+	}
+	if len(hs) == 2 {
+		vs := strings.SplitN(hs[1], "=", 2)
+		if len(vs) != 2 || vs[0] == "" || vs[1] == "" {
+			return nil, errors.Newf("Invalid cursor: %s", cursor)
+		}
+		c.SortKey = vs[0]
+		c.Value = vs[1]
+	}
+	return &c, nil
+}
+
+// Sort represents the order of a list of objects. Lists are sorted by the
+// value of Key (in increasing order if Asc is true), objects with the same
+// value being ordered by (created, key) in the same direction.
+type Sort struct {
+	Key string
+	Asc bool
+}
+
+const (
+	// SrKeyCreated is the default sort key, sorting objects by creation date.
+	SrKeyCreated = "created"
+	// SrKeyValue sorts balances by value.
+	SrKeyValue = "value"
+	// SrKeyPrice sorts offers by price.
+	SrKeyPrice = "price"
+	// SrKeyRemainder sorts offers by remainder.
+	SrKeyRemainder = "remainder"
+)
+
+const (
+	// priceIntegerDigits is the number of digits of the integer part of sort
+	// prices (asset prices being lower than 2^128 < 10^39).
+	priceIntegerDigits = 39
+	// priceFractionDigits is the number of digits of the fractional part of
+	// sort prices. Two distinct prices differ by more than 2^-256 > 10^-78
+	// (their quote prices being lower than 2^128) so truncating them to 78
+	// digits preserves their order.
+	priceFractionDigits = 78
+)
+
+// columns returns the SQL expressions ordering a list by the sort key, before
+// the creation date and key. Amounts are stored as the decimal representation
+// of non-negative integers, so they are ordered by length and then lexically.
+func (s Sort) columns() []string {
+	switch s.Key {
+	case SrKeyValue:
+		return []string{"LENGTH(value)", "value"}
+	case SrKeyRemainder:
+		return []string{"LENGTH(remainder)", "remainder"}
+	case SrKeyPrice:
+		return []string{"sort_price"}
+	}
+	return []string{}
+}
+
+// values returns the values of the sort columns at the position of the
+// cursor, from the sort key value it carries.
+func (s Sort) values(
+	c *Cursor,
+) ([]interface{}, error) {
+	key := s.Key
+	if key == SrKeyCreated {
+		key = ""
+	}
+	if c.SortKey != key {
+		return nil, errors.Newf("Unexpected cursor sort key: %s", c.SortKey)
+	}
+
+	switch s.Key {
+	case SrKeyValue, SrKeyRemainder:
+		var a big.Int
+		if _, ok := a.SetString(c.Value, 10); !ok || a.Sign() < 0 {
+			return nil, errors.Newf(
+				"Invalid cursor %s value: %s", s.Key, c.Value)
+		}
+		return []interface{}{len(c.Value), c.Value}, nil
+	case SrKeyPrice:
+		if !isSortPrice(c.Value) {
+			return nil, errors.Newf(
+				"Invalid cursor %s value: %s", s.Key, c.Value)
+		}
+		return []interface{}{c.Value}, nil
+	}
+	return []interface{}{}, nil
+}
+
+// SortPrice returns the sort price of an offer, stored along with it to sort
+// offers by price: the ratio of its base and quote prices truncated to
+// priceFractionDigits and padded to a fixed width so that sort prices compare
+// lexically. Offers whose quote price is zero sort after all the others.
+func SortPrice(
+	basePrice *big.Int,
+	quotePrice *big.Int,
+) string {
+	if quotePrice.Sign() == 0 {
+		return strings.Repeat("9", priceIntegerDigits) + "." +
+			strings.Repeat("9", priceFractionDigits)
+	}
+	price := new(big.Int).Mul(basePrice, new(big.Int).Exp(
+		big.NewInt(10), big.NewInt(priceFractionDigits), nil))
+	price.Quo(price, quotePrice)
+
+	digits := price.String()
+	if len(digits) < priceIntegerDigits+priceFractionDigits {
+		digits = strings.Repeat("0",
+			priceIntegerDigits+priceFractionDigits-len(digits)) + digits
+	}
+	return digits[:len(digits)-priceFractionDigits] + "." +
+		digits[len(digits)-priceFractionDigits:]
+}
+
+// isSortPrice returns whether the value is a well formed sort price.
+func isSortPrice(
+	value string,
+) bool {
+	if len(value) != priceIntegerDigits+1+priceFractionDigits ||
+		value[priceIntegerDigits] != '.' {
+		return false
+	}
+	for i, c := range value {
+		if i != priceIntegerDigits && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// Page represents the page of a list of objects to load. Lists are ordered by
+// decreasing (created, key) unless sorted otherwise. StartingAfter and
+// EndingBefore are mutually exclusive.
+type Page struct {
+	CreatedBefore time.Time
+	StartingAfter *Cursor
+	EndingBefore  *Cursor
+	Limit         uint
+	Sort          Sort
+}
+
+// cursor returns the cursor of the page, if any.
+func (p Page) cursor() *Cursor {
+	if p.StartingAfter != nil {
+		return p.StartingAfter
+	}
+	return p.EndingBefore
+}
+
+// Validate checks that the cursor of the page (if any) carries a value for
+// the sort key of the page.
+func (p Page) Validate() error {
+	if c := p.cursor(); c != nil {
+		if _, err := p.Sort.values(c); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// Query constructs the query loading the page of the objects of the table
+// that satisfy all the conditions (SQL conditions whose parameters are added
+// to the query parameters by the caller), along with the page conditions,
+// ordering and limit. The key column is the column used to order objects
+// created at the same time.
+func (p Page) Query(
+	table string,
+	key string,
+	conditions []string,
+) string {
+	conditions = append(conditions, "created < :created_before")
+
+	columns := append(p.Sort.columns(), "created", key)
+	params := []string{}
+	for i := range p.Sort.columns() {
+		params = append(params, fmt.Sprintf(":cursor_sort_%d", i))
+	}
+	params = append(params, ":cursor_created", ":cursor_key")
+
+	// Pages ending before a cursor are selected in reverse order from the
+	// cursor and reordered.
+	asc := p.Sort.Asc
+	if p.EndingBefore != nil {
+		asc = !asc
+	}
+
+	if p.cursor() != nil {
+		op := "<"
+		if asc {
+			op = ">"
+		}
+		seek := []string{}
+		for i := range columns {
+			c := ""
+			for j := 0; j < i; j++ {
+				c += fmt.Sprintf("%s = %s AND ", columns[j], params[j])
+			}
+			seek = append(seek, fmt.Sprintf("(%s%s %s %s)",
+				c, columns[i], op, params[i]))
+		}
+		conditions = append(conditions, fmt.Sprintf("(%s)",
+			strings.Join(seek, `
+    OR `)))
+	}
+
+	query := fmt.Sprintf(`
+SELECT *
+FROM %s
+WHERE %s
+ORDER BY %s
+LIMIT :limit
+`, table, strings.Join(conditions, `
+  AND `), orderBy(columns, asc))
+
+	if p.EndingBefore != nil {
+		return fmt.Sprintf(`
+SELECT *
+FROM (%s) AS page
+ORDER BY %s
+`, query, orderBy(columns, p.Sort.Asc))
+	}
+	return query
+}
+
+// orderBy returns the ORDER BY clause ordering a list by the columns in the
+// specified direction.
+func orderBy(
+	columns []string,
+	asc bool,
+) string {
+	direction := "DESC"
+	if asc {
+		direction = "ASC"
+	}
+	order := []string{}
+	for _, c := range columns {
+		order = append(order, c+" "+direction)
+	}
+	return strings.Join(order, ", ")
+}
+
+// Params adds the page parameters to the parameters of a list query. One
+// more object than the page limit is loaded to determine if more objects
+// exist.
+func (p Page) Params(
+	query map[string]interface{},
+) map[string]interface{} {
+	query["created_before"] = p.CreatedBefore.UTC()
+	query["limit"] = p.Limit + 1
+
+	if cursor := p.cursor(); cursor != nil {
+		query["cursor_created"] = cursor.Created.UTC()
+		query["cursor_key"] = cursor.Key
+
+		// The cursor value is checked by Validate.
+		values, _ := p.Sort.values(cursor)
+		for i, v := range values {
+			query[fmt.Sprintf("cursor_sort_%d", i)] = v
+		}
+	}
+
+	return query
+}
+
+// Bounds returns the bounds of the page within the n objects loaded by a
+// list query and whether more objects exist beyond the page (in the paging
+// direction).
+func (p Page) Bounds(
+	n int,
+) (int, int, bool) {
+	if uint(n) <= p.Limit {
+		return 0, n, false
+	}
+	if p.EndingBefore != nil {
+		return n - int(p.Limit), n, true
+	}
+	return 0, int(p.Limit), true
+}
+
+// amountCondition returns an SQL condition comparing an amount column to the
+// named parameter. Amounts are stored as the decimal representation of
+// non-negative integers, so they compare by length and then lexically. Op
+// must be either "<" or ">", the condition including equality.
+func amountCondition(
+	column string,
+	op string,
+	param string,
+) string {
+	return fmt.Sprintf(`(LENGTH(%[1]s) %[2]s LENGTH(:%[3]s)
+    OR (LENGTH(%[1]s) = LENGTH(:%[3]s) AND %[1]s %[2]s= :%[3]s))`,
+		column, op, param)
+}
+
+// assetCodeCondition returns an SQL condition matching the asset names of a
+// column against the named parameter, set to the pattern returned by
+// assetCodePattern.
+func assetCodeCondition(
+	column string,
+	param string,
+) string {
+	return fmt.Sprintf(`%s LIKE :%s ESCAPE '\'`, column, param)
+}
+
+// likeEscaper escapes the LIKE wildcards (and brackets, special for some
+// databases) with the escape character of assetCodeCondition.
+var likeEscaper = strings.NewReplacer(
+	`\`, `\\`, `%`, `\%`, `_`, `\_`, `[`, `\[`)
+
+// assetCodePattern returns the LIKE pattern matching the names of assets with
+// the specified code (see assetCodeCondition).
+func assetCodePattern(
+	code string,
+) string {
+	return `%\[` + likeEscaper.Replace(code) + `.%`
+}
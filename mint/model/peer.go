@@ -0,0 +1,255 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	sqlite3 "github.com/mattn/go-sqlite3"
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/ptr"
+	"github.com/spolu/settle/mint"
+)
+
+// Peer tracks the health of a mint this mint interacted with.
+type Peer struct {
+	Host         string
+	Created      time.Time
+	FirstContact time.Time `db:"first_contact"`
+	LastContact  time.Time `db:"last_contact"`
+
+	Failures        uint
+	Latencies       string  // Comma separated most recent latencies (ms).
+	ProtocolVersion *string `db:"protocol_version"`
+}
+
+// Cursor returns the paging cursor of the peer.
+func (p *Peer) Cursor() Cursor {
+	return Cursor{Created: p.Created, Key: p.Host}
+}
+
+// NewPeerResource generates a new resource.
+func NewPeerResource(
+	ctx context.Context,
+	peer *Peer,
+) mint.PeerResource {
+	return mint.PeerResource{
+		Host: peer.Host,
+		FirstContact: peer.FirstContact.UnixNano() /
+			mint.TimeResolutionNs,
+		LastContact: peer.LastContact.UnixNano() /
+			mint.TimeResolutionNs,
+		Failures: peer.Failures,
+		Latency: mint.PeerLatency{
+			P50: peer.LatencyPercentile(50),
+			P90: peer.LatencyPercentile(90),
+			P99: peer.LatencyPercentile(99),
+		},
+		ProtocolVersion: peer.ProtocolVersion,
+		Breaker:         mint.BreakerState(peer.Host),
+	}
+}
+
+// LatencyPercentile computes the p-th percentile of the most recent latencies
+// of requests to the peer (nearest-rank method). It returns nil if no
+// latency was recorded.
+func (p *Peer) LatencyPercentile(
+	percentile int,
+) *int64 {
+	latencies := []int64{}
+	for _, l := range strings.Split(p.Latencies, ",") {
+		if v, err := strconv.ParseInt(l, 10, 64); err == nil {
+			latencies = append(latencies, v)
+		}
+	}
+	if len(latencies) == 0 {
+		return nil
+	}
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i] < latencies[j]
+	})
+
+	rank := (percentile*len(latencies) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return ptr.Int64(latencies[rank-1])
+}
+
+// Record updates the in-memory peer with the provided contact.
+func (p *Peer) Record(
+	contact mint.PeerContact,
+) {
+	if contact.Time.Before(p.FirstContact) {
+		p.FirstContact = contact.Time.UTC()
+	}
+	if contact.Time.After(p.LastContact) {
+		p.LastContact = contact.Time.UTC()
+	}
+	if contact.ProtocolVersion != "" {
+		p.ProtocolVersion = ptr.Str(contact.ProtocolVersion)
+	}
+
+	// Received requests carry no information on the peer failures.
+	if contact.Latency == nil {
+		return
+	}
+
+	if contact.Failed {
+		p.Failures++
+	} else {
+		p.Failures = 0
+	}
+
+	latencies := []string{}
+	if p.Latencies != "" {
+		latencies = strings.Split(p.Latencies, ",")
+	}
+	latencies = append(latencies,
+		fmt.Sprintf("%d", *contact.Latency/time.Millisecond))
+	if len(latencies) > mint.PeerLatencySamples {
+		latencies = latencies[len(latencies)-mint.PeerLatencySamples:]
+	}
+	p.Latencies = strings.Join(latencies, ",")
+}
+
+// CreatePeer creates and stores a new Peer from its first contact.
+func CreatePeer(
+	ctx context.Context,
+	contact mint.PeerContact,
+) (*Peer, error) {
+	peer := Peer{
+		Host:         contact.Host,
+		Created:      time.Now().UTC(),
+		FirstContact: contact.Time.UTC(),
+		LastContact:  contact.Time.UTC(),
+
+		Failures:  0,
+		Latencies: "",
+	}
+	peer.Record(contact)
+
+	ext := db.Ext(ctx, "mint")
+	if _, err := sqlx.NamedExec(ext, `
+INSERT INTO peers
+  (host, created, first_contact, last_contact, failures, latencies,
+   protocol_version)
+VALUES
+  (:host, :created, :first_contact, :last_contact, :failures, :latencies,
+   :protocol_version)
+`, peer); err != nil {
+		switch err := err.(type) {
+		case *pq.Error:
+			if err.Code.Name() == "unique_violation" {
+				return nil, errors.Trace(ErrUniqueConstraintViolation{err})
+			}
+		case sqlite3.Error:
+			if err.ExtendedCode == sqlite3.ErrConstraintUnique {
+				return nil, errors.Trace(ErrUniqueConstraintViolation{err})
+			}
+		}
+		return nil, errors.Trace(err)
+	}
+
+	return &peer, nil
+}
+
+// Save updates the object database representation with the in-memory values.
+func (p *Peer) Save(
+	ctx context.Context,
+) error {
+	ext := db.Ext(ctx, "mint")
+	_, err := sqlx.NamedExec(ext, `
+UPDATE peers
+SET first_contact = :first_contact, last_contact = :last_contact,
+    failures = :failures, latencies = :latencies,
+    protocol_version = :protocol_version
+WHERE host = :host
+`, p)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	return nil
+}
+
+// LoadPeerByHost attempts to load the peer for the given host.
+func LoadPeerByHost(
+	ctx context.Context,
+	host string,
+) (*Peer, error) {
+	peer := Peer{
+		Host: host,
+	}
+
+	ext := db.Ext(ctx, "mint")
+	if rows, err := sqlx.NamedQuery(ext, `
+SELECT *
+FROM peers
+WHERE host = :host
+`, peer); err != nil {
+		return nil, errors.Trace(err)
+	} else if !rows.Next() {
+		return nil, nil
+	} else if err := rows.StructScan(&peer); err != nil {
+		defer rows.Close()
+		return nil, errors.Trace(err)
+	} else if err := rows.Close(); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	return &peer, nil
+}
+
+// LoadPeerHosts loads the hosts of all the peers.
+func LoadPeerHosts(
+	ctx context.Context,
+) ([]string, error) {
+	hosts := []string{}
+
+	ext := db.Ext(ctx, "mint")
+	if err := sqlx.Select(ext, &hosts, `
+SELECT host
+FROM peers
+`); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	return hosts, nil
+}
+
+// LoadPeerList loads a list of peers.
+func LoadPeerList(
+	ctx context.Context,
+	page Page,
+) ([]Peer, bool, error) {
+	query := page.Params(map[string]interface{}{})
+
+	ext := db.Ext(ctx, "mint")
+	rows, err := sqlx.NamedQuery(ext,
+		page.Query("peers", "host", []string{}), query)
+	if err != nil {
+		return nil, false, errors.Trace(err)
+	}
+
+	peers := []Peer{}
+
+	defer rows.Close()
+	for rows.Next() {
+		p := Peer{}
+		err := rows.StructScan(&p)
+		if err != nil {
+			return nil, false, errors.Trace(err)
+		}
+		peers = append(peers, p)
+	}
+
+	i, j, more := page.Bounds(len(peers))
+	return peers[i:j], more, nil
+}
@@ -10,6 +10,8 @@ CREATE TABLE IF NOT EXISTS users(
 
   username VARCHAR(256) NOT NULL,
   password_hash VARCHAR(256) NOT NULL,
+  role VARCHAR(32) NOT NULL DEFAULT 'user',      -- role (user, admin)
+  status VARCHAR(32) NOT NULL DEFAULT 'active',  -- status (active, disabled)
 
   PRIMARY KEY(token),
   CONSTRAINT users_username_u UNIQUE (username)
@@ -23,4 +25,16 @@ func init() {
 		"users",
 		usersSQL,
 	)
+	db.RegisterColumn(
+		"mint",
+		"users",
+		"role",
+		"VARCHAR(32) NOT NULL DEFAULT 'user'",
+	)
+	db.RegisterColumn(
+		"mint",
+		"users",
+		"status",
+		"VARCHAR(32) NOT NULL DEFAULT 'active'",
+	)
 }
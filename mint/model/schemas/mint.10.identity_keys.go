@@ -0,0 +1,25 @@
+package schemas
+
+import "github.com/spolu/settle/lib/db"
+
+const (
+	identityKeysSQL = `
+CREATE TABLE IF NOT EXISTS identity_keys(
+  token VARCHAR(256) NOT NULL,       -- token
+  created TIMESTAMP NOT NULL,
+
+  public_key VARCHAR(256) NOT NULL,  -- base64 encoded ed25519 public key
+  private_key VARCHAR(256) NOT NULL, -- base64 encoded ed25519 private key
+
+  PRIMARY KEY(token)
+);
+`
+)
+
+func init() {
+	db.RegisterSchema(
+		"mint",
+		"identity_keys",
+		identityKeysSQL,
+	)
+}
@@ -0,0 +1,28 @@
+package schemas
+
+import "github.com/spolu/settle/lib/db"
+
+const (
+	peersSQL = `
+CREATE TABLE IF NOT EXISTS peers(
+  host VARCHAR(256) NOT NULL,        -- peer mint host
+  created TIMESTAMP NOT NULL,
+  first_contact TIMESTAMP NOT NULL,
+  last_contact TIMESTAMP NOT NULL,
+
+  failures INTEGER NOT NULL,         -- consecutive request failures
+  latencies TEXT NOT NULL,           -- most recent request latencies (ms)
+  protocol_version VARCHAR(32),      -- advertised protocol version
+
+  PRIMARY KEY(host)
+);
+`
+)
+
+func init() {
+	db.RegisterSchema(
+		"mint",
+		"peers",
+		peersSQL,
+	)
+}
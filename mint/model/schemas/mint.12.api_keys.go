@@ -0,0 +1,44 @@
+package schemas
+
+import "github.com/spolu/settle/lib/db"
+
+const (
+	apiKeysSQL = `
+CREATE TABLE IF NOT EXISTS api_keys(
+  token VARCHAR(256) NOT NULL,       -- token
+  created TIMESTAMP NOT NULL,
+  user_token VARCHAR(256) NOT NULL,  -- user token
+  owner VARCHAR(256) NOT NULL,       -- user address
+
+  name VARCHAR(256) NOT NULL,        -- name chosen by the user
+  secret_hash VARCHAR(256) NOT NULL, -- hex(sha256(secret))
+  scopes VARCHAR(256) NOT NULL,      -- join of scopes
+
+  pay_asset VARCHAR(256),            -- asset of the daily pay limit
+  pay_limit VARCHAR(64),             -- daily pay limit
+  pay_spent VARCHAR(64) NOT NULL,    -- amount spent during the pay window
+  pay_window TIMESTAMP NOT NULL,     -- start of the current pay window (day)
+  pay_version BIGINT NOT NULL DEFAULT 0, -- incremented on each payment
+
+  expires TIMESTAMP,
+  last_used TIMESTAMP,
+  revoked TIMESTAMP,
+
+  PRIMARY KEY(token)
+);
+`
+)
+
+func init() {
+	db.RegisterSchema(
+		"mint",
+		"api_keys",
+		apiKeysSQL,
+	)
+	db.RegisterColumn(
+		"mint",
+		"api_keys",
+		"pay_version",
+		"BIGINT NOT NULL DEFAULT 0",
+	)
+}
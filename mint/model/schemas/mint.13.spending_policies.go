@@ -0,0 +1,200 @@
+package schemas
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+)
+
+const (
+	spendingPoliciesSQL = `
+CREATE TABLE IF NOT EXISTS spending_policies(
+  user_token VARCHAR(256) NOT NULL,  -- user token
+  asset VARCHAR(256) NOT NULL,       -- asset name
+  created TIMESTAMP NOT NULL,
+
+  per_transaction VARCHAR(64),       -- maximum amount per transaction
+  per_day VARCHAR(64),               -- maximum amount per day (UTC)
+  per_window VARCHAR(64),            -- maximum amount per window
+  window_ms BIGINT,                  -- window duration in ms
+
+  day_start TIMESTAMP,               -- start of the current day (UTC)
+  day_spent VARCHAR(64),             -- amount spent during the current day
+  window_start TIMESTAMP,            -- start of the current window
+  window_spent VARCHAR(64),          -- amount spent during the current window
+
+  version BIGINT NOT NULL,           -- incremented on each spending
+
+  PRIMARY KEY(user_token, asset)
+);
+`
+)
+
+func init() {
+	db.RegisterSchema(
+		"mint",
+		"spending_policies",
+		spendingPoliciesSQL,
+	)
+	db.RegisterColumn(
+		"mint",
+		"spending_policies",
+		"day_start",
+		"TIMESTAMP",
+	)
+	db.RegisterColumn(
+		"mint",
+		"spending_policies",
+		"day_spent",
+		"VARCHAR(64)",
+	)
+	db.RegisterColumn(
+		"mint",
+		"spending_policies",
+		"window_start",
+		"TIMESTAMP",
+	)
+	db.RegisterColumn(
+		"mint",
+		"spending_policies",
+		"window_spent",
+		"VARCHAR(64)",
+	)
+	db.RegisterBackfill(
+		"mint",
+		"spending_policies",
+		"window_spent",
+		backfillSpendingPoliciesTotals,
+	)
+}
+
+// backfillSpendingPoliciesTotals computes the running totals of the existing
+// policies from their spendings of the current day and window, the window
+// starting with the first spending it contains.
+func backfillSpendingPoliciesTotals(
+	ctx context.Context,
+	mintDB *sqlx.DB,
+) error {
+	now := time.Now().UTC()
+	day := now.Truncate(24 * time.Hour)
+
+	type totals struct {
+		userToken   string
+		asset       string
+		windowMs    *int64
+		daySpent    *big.Int
+		windowStart *time.Time
+		windowSpent *big.Int
+	}
+	policies := []*totals{}
+
+	rows, err := mintDB.Queryx(`
+SELECT user_token, asset, window_ms
+FROM spending_policies
+`)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		p := totals{daySpent: new(big.Int)}
+		err := rows.Scan(&p.userToken, &p.asset, &p.windowMs)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		policies = append(policies, &p)
+	}
+	if err := rows.Err(); err != nil {
+		return errors.Trace(err)
+	}
+	rows.Close()
+
+	for _, p := range policies {
+		since := day
+		var window *time.Time
+		if p.windowMs != nil {
+			w := now.Add(-time.Duration(*p.windowMs) * time.Millisecond)
+			window = &w
+			if w.Before(since) {
+				since = w
+			}
+			p.windowSpent = new(big.Int)
+		}
+
+		rows, err := sqlx.NamedQuery(mintDB, `
+SELECT created, amount
+FROM spendings
+WHERE user_token = :user_token
+  AND asset = :asset
+  AND created >= :since
+ORDER BY created
+`, map[string]interface{}{
+			"user_token": p.userToken,
+			"asset":      p.asset,
+			"since":      since,
+		})
+		if err != nil {
+			return errors.Trace(err)
+		}
+
+		for rows.Next() {
+			var created time.Time
+			var amount string
+			if err := rows.Scan(&created, &amount); err != nil {
+				rows.Close()
+				return errors.Trace(err)
+			}
+			a, ok := new(big.Int).SetString(amount, 10)
+			if !ok {
+				rows.Close()
+				return errors.Newf("Invalid amount: %s", amount)
+			}
+			if !created.Before(day) {
+				p.daySpent.Add(p.daySpent, a)
+			}
+			if window != nil && !created.Before(*window) {
+				if p.windowStart == nil {
+					start := created.UTC()
+					p.windowStart = &start
+				}
+				p.windowSpent.Add(p.windowSpent, a)
+			}
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return errors.Trace(err)
+		}
+		rows.Close()
+	}
+
+	for _, p := range policies {
+		u := map[string]interface{}{
+			"user_token":   p.userToken,
+			"asset":        p.asset,
+			"day_start":    day,
+			"day_spent":    p.daySpent.String(),
+			"window_start": p.windowStart,
+			"window_spent": nil,
+		}
+		if p.windowStart != nil {
+			u["window_spent"] = p.windowSpent.String()
+		}
+		_, err := sqlx.NamedExec(mintDB, `
+UPDATE spending_policies
+SET day_start = :day_start, day_spent = :day_spent,
+    window_start = :window_start, window_spent = :window_spent
+WHERE user_token = :user_token
+  AND asset = :asset
+`, u)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	return nil
+}
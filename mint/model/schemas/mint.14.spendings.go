@@ -0,0 +1,27 @@
+package schemas
+
+import "github.com/spolu/settle/lib/db"
+
+const (
+	spendingsSQL = `
+CREATE TABLE IF NOT EXISTS spendings(
+  token VARCHAR(256) NOT NULL,        -- token
+  created TIMESTAMP NOT NULL,
+  user_token VARCHAR(256) NOT NULL,   -- user token
+  asset VARCHAR(256) NOT NULL,        -- asset name
+
+  amount VARCHAR(64) NOT NULL,        -- amount spent
+  txn VARCHAR(256) NOT NULL,          -- transaction id
+
+  PRIMARY KEY(token)
+);
+`
+)
+
+func init() {
+	db.RegisterSchema(
+		"mint",
+		"spendings",
+		spendingsSQL,
+	)
+}
@@ -0,0 +1,27 @@
+package schemas
+
+import "github.com/spolu/settle/lib/db"
+
+const (
+	idempotencyKeysSQL = `
+CREATE TABLE IF NOT EXISTS idempotency_keys(
+  user_token VARCHAR(256) NOT NULL,       -- user token
+  idempotency_key VARCHAR(256) NOT NULL,  -- key provided by the client
+  created TIMESTAMP NOT NULL,
+
+  fingerprint VARCHAR(256) NOT NULL,      -- hex(sha256(method, path, params))
+  status INT,                             -- response status (null if in progress)
+  response TEXT,                          -- response body
+
+  PRIMARY KEY(user_token, idempotency_key)
+);
+`
+)
+
+func init() {
+	db.RegisterSchema(
+		"mint",
+		"idempotency_keys",
+		idempotencyKeysSQL,
+	)
+}
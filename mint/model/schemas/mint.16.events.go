@@ -0,0 +1,41 @@
+package schemas
+
+import "github.com/spolu/settle/lib/db"
+
+const (
+	eventsSQL = `
+CREATE TABLE IF NOT EXISTS events(
+  token VARCHAR(256) NOT NULL,    -- token
+  created TIMESTAMP NOT NULL,
+  seq BIGINT,                     -- position in the event log once committed
+
+  address VARCHAR(256) NOT NULL,  -- address of the user notified
+  type VARCHAR(64) NOT NULL,      -- event type
+  object VARCHAR(256) NOT NULL,   -- id of the object concerned
+  payload TEXT NOT NULL,          -- json representation of the object
+
+  PRIMARY KEY(token)
+);
+`
+)
+
+func init() {
+	db.RegisterSchema(
+		"mint",
+		"events",
+		eventsSQL,
+	)
+	db.RegisterColumn(
+		"mint",
+		"events",
+		"seq",
+		"BIGINT",
+	)
+	db.RegisterIndex(
+		"mint",
+		"events",
+		"events_seq",
+		true,
+		"seq",
+	)
+}
@@ -0,0 +1,22 @@
+package schemas
+
+import "github.com/spolu/settle/lib/db"
+
+const (
+	sequencesSQL = `
+CREATE TABLE IF NOT EXISTS sequences(
+  name VARCHAR(64) NOT NULL,      -- name of the sequence
+  value BIGINT NOT NULL,          -- last value assigned
+
+  PRIMARY KEY(name)
+);
+`
+)
+
+func init() {
+	db.RegisterSchema(
+		"mint",
+		"sequences",
+		sequencesSQL,
+	)
+}
@@ -1,6 +1,14 @@
 package schemas
 
-import "github.com/spolu/settle/lib/db"
+import (
+	"context"
+	"math/big"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/mint/model"
+)
 
 const (
 	offersSQL = `
@@ -20,6 +28,8 @@ CREATE TABLE IF NOT EXISTS offers(
   status VARCHAR(32) NOT NULL,       -- status (active, closed, consumed)
   remainder VARCHAR(64) NOT NULL,    -- remainder amount of quote asset asked
 
+  sort_price VARCHAR(128) NOT NULL DEFAULT '', -- price used to sort offers
+
   PRIMARY KEY(owner, token)
 );
 `
@@ -31,4 +41,73 @@ func init() {
 		"offers",
 		offersSQL,
 	)
+	db.RegisterColumn(
+		"mint",
+		"offers",
+		"sort_price",
+		"VARCHAR(128) NOT NULL DEFAULT ''",
+	)
+	db.RegisterBackfill(
+		"mint",
+		"offers",
+		"sort_price",
+		backfillOffersSortPrice,
+	)
+}
+
+// backfillOffersSortPrice computes the sort price of the existing offers (see
+// model.SortPrice).
+func backfillOffersSortPrice(
+	ctx context.Context,
+	mintDB *sqlx.DB,
+) error {
+	rows, err := mintDB.Queryx(`
+SELECT owner, token, base_price, quote_price
+FROM offers
+`)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	updates := []map[string]interface{}{}
+
+	defer rows.Close()
+	for rows.Next() {
+		var owner, token, basePrice, quotePrice string
+		err := rows.Scan(&owner, &token, &basePrice, &quotePrice)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		b, ok := new(big.Int).SetString(basePrice, 10)
+		if !ok {
+			return errors.Newf("Invalid base price: %s", basePrice)
+		}
+		q, ok := new(big.Int).SetString(quotePrice, 10)
+		if !ok {
+			return errors.Newf("Invalid quote price: %s", quotePrice)
+		}
+		updates = append(updates, map[string]interface{}{
+			"owner":      owner,
+			"token":      token,
+			"sort_price": model.SortPrice(b, q),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return errors.Trace(err)
+	}
+	rows.Close()
+
+	for _, u := range updates {
+		_, err := sqlx.NamedExec(mintDB, `
+UPDATE offers
+SET sort_price = :sort_price
+WHERE owner = :owner
+  AND token = :token
+`, u)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	return nil
 }
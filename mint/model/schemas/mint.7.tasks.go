@@ -13,6 +13,7 @@ CREATE TABLE IF NOT EXISTS tasks(
 
   status VARCHAR(32) NOT NULL,       -- status (pending, succeeded, failed)
   retry INT,                         -- retry count
+  trace VARCHAR(256) NOT NULL DEFAULT '',  -- trace context of the queuer
 
   PRIMARY KEY(token)
 );
@@ -25,4 +26,10 @@ func init() {
 		"tasks",
 		tasksSQL,
 	)
+	db.RegisterColumn(
+		"mint",
+		"tasks",
+		"trace",
+		"VARCHAR(256) NOT NULL DEFAULT ''",
+	)
 }
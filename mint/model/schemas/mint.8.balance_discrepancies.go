@@ -0,0 +1,30 @@
+package schemas
+
+import "github.com/spolu/settle/lib/db"
+
+const (
+	balanceDiscrepanciesSQL = `
+CREATE TABLE IF NOT EXISTS balance_discrepancies(
+  token VARCHAR(256) NOT NULL,       -- token
+  created TIMESTAMP NOT NULL,
+
+  balance VARCHAR(512) NOT NULL,     -- propagated balance id
+  asset VARCHAR(256) NOT NULL,       -- asset name
+  holder VARCHAR(256) NOT NULL,      -- balance holder address
+
+  type VARCHAR(32) NOT NULL,         -- type (value_mismatch, canonical_missing)
+  local_value VARCHAR(64) NOT NULL,  -- propagated value found locally
+  canonical_value VARCHAR(64),       -- canonical value if retrieved
+
+  PRIMARY KEY(token)
+);
+`
+)
+
+func init() {
+	db.RegisterSchema(
+		"mint",
+		"balance_discrepancies",
+		balanceDiscrepanciesSQL,
+	)
+}
@@ -0,0 +1,26 @@
+package schemas
+
+import "github.com/spolu/settle/lib/db"
+
+const (
+	mintSyncsSQL = `
+CREATE TABLE IF NOT EXISTS mint_syncs(
+  host VARCHAR(256) NOT NULL,        -- remote mint host
+  created TIMESTAMP NOT NULL,
+  updated TIMESTAMP NOT NULL,        -- last refresh attempt
+
+  failures INTEGER NOT NULL,         -- consecutive refresh failures
+  status VARCHAR(32) NOT NULL,       -- status (healthy, suspect)
+
+  PRIMARY KEY(host)
+);
+`
+)
+
+func init() {
+	db.RegisterSchema(
+		"mint",
+		"mint_syncs",
+		mintSyncsSQL,
+	)
+}
@@ -0,0 +1,316 @@
+package model
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/token"
+	"github.com/spolu/settle/mint"
+)
+
+// SpendingPolicy represents the limits enforced on the amount of an asset a
+// user can spend through canonical transactions. Spendings are recorded upon
+// the creation of transactions (whether they eventually settle or not) so
+// that a compromised credential can't drain a user trust network quickly.
+type SpendingPolicy struct {
+	UserToken string `db:"user_token"`
+	Asset     string
+	Created   time.Time
+
+	PerTransaction *Amount `db:"per_transaction"`
+	PerDay         *Amount `db:"per_day"`
+	PerWindow      *Amount `db:"per_window"`
+	WindowMs       *int64  `db:"window_ms"`
+
+	// Running totals of the current day and window, reset by the first
+	// spending made once they elapsed.
+	DayStart    *time.Time `db:"day_start"`
+	DaySpent    *Amount    `db:"day_spent"`
+	WindowStart *time.Time `db:"window_start"`
+	WindowSpent *Amount    `db:"window_spent"`
+
+	Version int64
+}
+
+// Spending represents an amount of an asset spent by a user through a
+// canonical transaction.
+type Spending struct {
+	Token     string
+	Created   time.Time
+	UserToken string `db:"user_token"`
+	Asset     string
+
+	Amount      Amount
+	Transaction string `db:"txn"`
+}
+
+// NewSpendingPolicyResource generates a new resource.
+func NewSpendingPolicyResource(
+	ctx context.Context,
+	policy *SpendingPolicy,
+	daySpent *big.Int,
+	windowSpent *big.Int,
+) mint.SpendingPolicyResource {
+	p := mint.SpendingPolicyResource{
+		Asset:    policy.Asset,
+		WindowMs: policy.WindowMs,
+
+		DaySpent:    daySpent,
+		WindowSpent: windowSpent,
+	}
+	if policy.PerTransaction != nil {
+		p.PerTransaction = (*big.Int)(policy.PerTransaction)
+	}
+	if policy.PerDay != nil {
+		p.PerDay = (*big.Int)(policy.PerDay)
+	}
+	if policy.PerWindow != nil {
+		p.PerWindow = (*big.Int)(policy.PerWindow)
+	}
+	return p
+}
+
+// CreateOrUpdateSpendingPolicy stores the spending policy of a user for an
+// asset, replacing the existing one if any.
+func CreateOrUpdateSpendingPolicy(
+	ctx context.Context,
+	userToken string,
+	asset string,
+	perTransaction *big.Int,
+	perDay *big.Int,
+	perWindow *big.Int,
+	windowMs *int64,
+) (*SpendingPolicy, error) {
+	policy, err := LoadSpendingPolicy(ctx, userToken, asset)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	query := `
+UPDATE spending_policies
+SET per_transaction = :per_transaction, per_day = :per_day,
+    per_window = :per_window, window_ms = :window_ms,
+    version = version + 1
+WHERE user_token = :user_token
+  AND asset = :asset
+`
+	if policy == nil {
+		policy = &SpendingPolicy{
+			UserToken: userToken,
+			Asset:     asset,
+			Created:   time.Now().UTC(),
+		}
+		query = `
+INSERT INTO spending_policies
+  (user_token, asset, created, per_transaction, per_day, per_window,
+   window_ms, day_start, day_spent, window_start, window_spent, version)
+VALUES
+  (:user_token, :asset, :created, :per_transaction, :per_day, :per_window,
+   :window_ms, :day_start, :day_spent, :window_start, :window_spent, :version)
+`
+	}
+
+	policy.PerTransaction = (*Amount)(perTransaction)
+	policy.PerDay = (*Amount)(perDay)
+	policy.PerWindow = (*Amount)(perWindow)
+	policy.WindowMs = windowMs
+
+	ext := db.Ext(ctx, "mint")
+	if _, err := sqlx.NamedExec(ext, query, policy); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	return policy, nil
+}
+
+// LoadSpendingPolicy attempts to load the spending policy of a user for an
+// asset.
+func LoadSpendingPolicy(
+	ctx context.Context,
+	userToken string,
+	asset string,
+) (*SpendingPolicy, error) {
+	policy := SpendingPolicy{
+		UserToken: userToken,
+		Asset:     asset,
+	}
+
+	ext := db.Ext(ctx, "mint")
+	if rows, err := sqlx.NamedQuery(ext, `
+SELECT *
+FROM spending_policies
+WHERE user_token = :user_token
+  AND asset = :asset
+`, policy); err != nil {
+		return nil, errors.Trace(err)
+	} else if !rows.Next() {
+		return nil, nil
+	} else if err := rows.StructScan(&policy); err != nil {
+		defer rows.Close()
+		return nil, errors.Trace(err)
+	} else if err := rows.Close(); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	return &policy, nil
+}
+
+// LoadSpendingPolicyListByUser loads the spending policies of a user.
+func LoadSpendingPolicyListByUser(
+	ctx context.Context,
+	userToken string,
+) ([]SpendingPolicy, error) {
+	query := map[string]interface{}{
+		"user_token": userToken,
+	}
+
+	ext := db.Ext(ctx, "mint")
+	rows, err := sqlx.NamedQuery(ext, `
+SELECT *
+FROM spending_policies
+WHERE user_token = :user_token
+ORDER BY asset
+`, query)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	policies := []SpendingPolicy{}
+
+	defer rows.Close()
+	for rows.Next() {
+		p := SpendingPolicy{}
+		err := rows.StructScan(&p)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		policies = append(policies, p)
+	}
+
+	return policies, nil
+}
+
+// Usage returns the amounts spent under the policy during the current day
+// (UTC) and the current window (nil if the policy has no window). A window
+// starts with the first spending made after the previous one elapsed.
+func (p *SpendingPolicy) Usage(
+	now time.Time,
+) (*big.Int, *big.Int) {
+	_, daySpent, _, windowSpent := p.usage(now)
+	return daySpent, windowSpent
+}
+
+// usage returns the start and amount spent of the current day and window as
+// of now, resetting the running totals stored on the policy once their day or
+// window elapsed.
+func (p *SpendingPolicy) usage(
+	now time.Time,
+) (time.Time, *big.Int, *time.Time, *big.Int) {
+	day := now.UTC().Truncate(24 * time.Hour)
+	daySpent := new(big.Int)
+	if p.DayStart != nil && p.DaySpent != nil && !p.DayStart.Before(day) {
+		daySpent.Set((*big.Int)(p.DaySpent))
+	}
+
+	if p.WindowMs == nil {
+		return day, daySpent, nil, nil
+	}
+	window := now.UTC()
+	windowSpent := new(big.Int)
+	if p.WindowStart != nil && p.WindowSpent != nil &&
+		now.Before(p.WindowStart.Add(
+			time.Duration(*p.WindowMs)*time.Millisecond)) {
+		window = *p.WindowStart
+		windowSpent.Set((*big.Int)(p.WindowSpent))
+	}
+
+	return day, daySpent, &window, windowSpent
+}
+
+// Spend records a spending of the provided amount by a transaction after
+// checking it against the policy limits. The running totals of the policy are
+// updated along with its version. It errors with ErrSpendingLimitExceeded if a
+// limit would be exceeded and with ErrConcurrentModification if another
+// spending was recorded concurrently since the policy was loaded.
+func (p *SpendingPolicy) Spend(
+	ctx context.Context,
+	transaction string,
+	amount *big.Int,
+) error {
+	now := time.Now().UTC()
+
+	if p.PerTransaction != nil &&
+		amount.Cmp((*big.Int)(p.PerTransaction)) > 0 {
+		return errors.Trace(ErrSpendingLimitExceeded{"per_transaction"})
+	}
+
+	day, daySpent, window, windowSpent := p.usage(now)
+	daySpent.Add(daySpent, amount)
+	if p.PerDay != nil && daySpent.Cmp((*big.Int)(p.PerDay)) > 0 {
+		return errors.Trace(ErrSpendingLimitExceeded{"per_day"})
+	}
+	if windowSpent != nil {
+		windowSpent.Add(windowSpent, amount)
+		if p.PerWindow != nil && windowSpent.Cmp((*big.Int)(p.PerWindow)) > 0 {
+			return errors.Trace(ErrSpendingLimitExceeded{"per_window"})
+		}
+	}
+
+	// The running totals are updated only if the policy version did not
+	// change since the policy was loaded, serializing concurrent spendings.
+	ext := db.Ext(ctx, "mint")
+	res, err := sqlx.NamedExec(ext, `
+UPDATE spending_policies
+SET day_start = :day_start, day_spent = :day_spent,
+    window_start = :window_start, window_spent = :window_spent,
+    version = version + 1
+WHERE user_token = :user_token
+  AND asset = :asset
+  AND version = :version
+`, map[string]interface{}{
+		"user_token":   p.UserToken,
+		"asset":        p.Asset,
+		"version":      p.Version,
+		"day_start":    day,
+		"day_spent":    (*Amount)(daySpent),
+		"window_start": window,
+		"window_spent": (*Amount)(windowSpent),
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return errors.Trace(err)
+	} else if n != 1 {
+		return errors.Trace(ErrConcurrentModification{"spending_policies"})
+	}
+	p.DayStart = &day
+	p.DaySpent = (*Amount)(daySpent)
+	p.WindowStart = window
+	p.WindowSpent = (*Amount)(windowSpent)
+	p.Version++
+
+	spending := Spending{
+		Token:     token.New("spending"),
+		Created:   now,
+		UserToken: p.UserToken,
+		Asset:     p.Asset,
+
+		Amount:      Amount(*amount),
+		Transaction: transaction,
+	}
+	if _, err := sqlx.NamedExec(ext, `
+INSERT INTO spendings
+  (token, created, user_token, asset, amount, txn)
+VALUES
+  (:token, :created, :user_token, :asset, :amount, :txn)
+`, spending); err != nil {
+		return errors.Trace(err)
+	}
+
+	return nil
+}
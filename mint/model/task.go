@@ -23,6 +23,10 @@ type Task struct {
 
 	Status mint.TkStatus
 	Retry  uint
+
+	// Trace is the context of the span that queued the task (see
+	// trace.SpanContext), empty if none.
+	Trace string
 }
 
 // CreateTask creates and stores a new Task.
@@ -33,6 +37,7 @@ func CreateTask(
 	subject string,
 	status mint.TkStatus,
 	retry uint,
+	trace string,
 ) (*Task, error) {
 	task := Task{
 		Token:   token.New("task"),
@@ -42,14 +47,15 @@ func CreateTask(
 		Subject: subject,
 		Status:  status,
 		Retry:   retry,
+		Trace:   trace,
 	}
 
 	ext := db.Ext(ctx, "mint")
 	if _, err := sqlx.NamedExec(ext, `
 INSERT INTO tasks
-  (token, created, name, subject, status, retry)
+  (token, created, name, subject, status, retry, trace)
 VALUES
-  (:token, :created, :name, :subject, :status, :retry)
+  (:token, :created, :name, :subject, :status, :retry, :trace)
 `, task); err != nil {
 		switch err := err.(type) {
 		case *pq.Error:
@@ -116,3 +122,38 @@ WHERE status = :status
 
 	return tasks, nil
 }
+
+// CountTasks counts the tasks with the provided status created after since.
+func CountTasks(
+	ctx context.Context,
+	status mint.TkStatus,
+	since time.Time,
+) (int, error) {
+	query := map[string]interface{}{
+		"status": status,
+		"since":  since.UTC(),
+	}
+
+	ext := db.Ext(ctx, "mint")
+	rows, err := sqlx.NamedQuery(ext, `
+SELECT COUNT(*)
+FROM tasks
+WHERE status = :status
+  AND created > :since
+`, query)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+
+	count := 0
+
+	defer rows.Close()
+	if rows.Next() {
+		err := rows.Scan(&count)
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+	}
+
+	return count, nil
+}
@@ -14,8 +14,10 @@ import (
 	sqlite3 "github.com/mattn/go-sqlite3"
 	"github.com/spolu/settle/lib/db"
 	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/metrics"
 	"github.com/spolu/settle/lib/token"
 	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/lib/hub"
 )
 
 // Transaction represents a transaction across a chain of offers.
@@ -133,6 +135,11 @@ VALUES
 		return nil, errors.Trace(err)
 	}
 
+	transaction.recordStatus(ctx)
+	if err := transaction.recordEvent(ctx); err != nil {
+		return nil, errors.Trace(err)
+	}
+
 	return &transaction, nil
 }
 
@@ -189,6 +196,11 @@ VALUES
 		return nil, errors.Trace(err)
 	}
 
+	transaction.recordStatus(ctx)
+	if err := transaction.recordEvent(ctx); err != nil {
+		return nil, errors.Trace(err)
+	}
+
 	return &transaction, nil
 }
 
@@ -198,13 +210,37 @@ func (t *Transaction) ID() string {
 }
 
 // Save updates the object database representation with the in-memory values.
+// An event is recorded if the status of the transaction changed and its
+// waiters are notified once committed.
 func (t *Transaction) Save(
 	ctx context.Context,
 ) error {
 	ext := db.Ext(ctx, "mint")
-	_, err := sqlx.NamedExec(ext, `
+	res, err := sqlx.NamedExec(ext, `
 UPDATE transactions
 SET status = :status, secret = :secret
+WHERE owner = :owner
+  AND token = :token
+  AND status <> :status
+`, t)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return errors.Trace(err)
+	} else if n > 0 {
+		id := t.ID()
+		db.OnCommit(ctx, func() {
+			hub.Get(ctx).Notify(id)
+		})
+		t.recordStatus(ctx)
+		return t.recordEvent(ctx)
+	}
+
+	_, err = sqlx.NamedExec(ext, `
+UPDATE transactions
+SET secret = :secret
 WHERE owner = :owner
   AND token = :token
 `, t)
@@ -215,6 +251,29 @@ WHERE owner = :owner
 	return nil
 }
 
+// recordStatus counts the transaction in its current status once committed.
+func (t *Transaction) recordStatus(
+	ctx context.Context,
+) {
+	status, role := string(t.Status), string(t.Propagation)
+	db.OnCommit(ctx, func() {
+		metrics.Inc(ctx, mint.MtTransactions, status, role)
+	})
+}
+
+// recordEvent records an event for the current status of the transaction for
+// its owner and destination. Pending transactions are not reported.
+func (t *Transaction) recordEvent(
+	ctx context.Context,
+) error {
+	if t.Status == mint.TxStPending {
+		return nil
+	}
+	return recordEvent(ctx, mint.EvType("transaction."+string(t.Status)),
+		t.ID(), NewTransactionResource(ctx, t, nil, nil),
+		t.Owner, t.Destination)
+}
+
 // LoadCanonicalTransactionByOwnerToken attempts to load the canonical
 // transaction for the given owner and token.
 func LoadCanonicalTransactionByOwnerToken(
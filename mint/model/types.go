@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/mint"
 )
 
 // Amount extends big.Int to implement sql.Scanner and driver.Valuer.
@@ -64,3 +65,37 @@ func (p *OfPath) Scan(src interface{}) error {
 
 	return nil
 }
+
+// KyScopes is a list of API key scopes and implements sql.Scanner and
+// driver.Valuer for easy serialization.
+type KyScopes []mint.KyScope
+
+// Value implements driver.Valuer.
+func (s KyScopes) Value() (value driver.Value, err error) {
+	l := []string{}
+	for _, sc := range s {
+		l = append(l, string(sc))
+	}
+	return strings.Join(l, ","), nil
+}
+
+// Scan implements sql.Scanner.
+func (s *KyScopes) Scan(src interface{}) error {
+	str := ""
+	switch src := src.(type) {
+	case []byte:
+		str = string(src)
+	case string:
+		str = src
+	default:
+		return errors.Newf("Incompatible type for KyScopes with value: %q", src)
+	}
+	*s = KyScopes{}
+	if len(str) > 0 {
+		for _, sc := range strings.Split(str, ",") {
+			*s = append(*s, mint.KyScope(sc))
+		}
+	}
+
+	return nil
+}
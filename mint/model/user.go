@@ -3,6 +3,7 @@ package model
 import (
 	"context"
 	"encoding/base64"
+	"fmt"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -11,18 +12,41 @@ import (
 	"github.com/spolu/settle/lib/db"
 	"github.com/spolu/settle/lib/errors"
 	"github.com/spolu/settle/lib/token"
+	"github.com/spolu/settle/mint"
 	"golang.org/x/crypto/scrypt"
 )
 
-// User represents a user object. User objects are not managed by the mint and
-// solely accesed in read-only mode, leaving user management to an external
-// system with access to the same underlying mintDB.
+// User represents a user object. User objects are managed by the mint admin
+// API or by an external system with access to the same underlying mintDB.
 type User struct {
 	Token   string
 	Created time.Time
 
 	Username     string
 	PasswordHash string `db:"password_hash"`
+	Role         mint.UsRole
+	Status       mint.UsStatus
+}
+
+// Cursor returns the paging cursor of the user.
+func (u *User) Cursor() Cursor {
+	return Cursor{Created: u.Created, Key: u.Token}
+}
+
+// NewUserResource generates a new resource.
+func NewUserResource(
+	ctx context.Context,
+	user *User,
+) mint.UserResource {
+	return mint.UserResource{
+		ID:      user.Token,
+		Created: user.Created.UnixNano() / mint.TimeResolutionNs,
+
+		Username: user.Username,
+		Address:  fmt.Sprintf("%s@%s", user.Username, mint.GetHost(ctx)),
+		Role:     user.Role,
+		Status:   user.Status,
+	}
 }
 
 // CreateUser creates and stores a new User object.
@@ -30,12 +54,15 @@ func CreateUser(
 	ctx context.Context,
 	username string,
 	password string,
+	role mint.UsRole,
 ) (*User, error) {
 	user := User{
 		Token:   token.New("user"),
 		Created: time.Now().UTC(),
 
 		Username: username,
+		Role:     role,
+		Status:   mint.UsStActive,
 	}
 
 	h, err := scrypt.Key([]byte(password), []byte(user.Token), 16384, 8, 1, 64)
@@ -48,9 +75,9 @@ func CreateUser(
 	ext := db.Ext(ctx, "mint")
 	if _, err := sqlx.NamedExec(ext, `
 INSERT INTO users
-  (token, created, username, password_hash)
+  (token, created, username, password_hash, role, status)
 VALUES
-  (:token, :created, :username, :password_hash)
+  (:token, :created, :username, :password_hash, :role, :status)
 `, user); err != nil {
 		switch err := err.(type) {
 		case *pq.Error:
@@ -75,7 +102,8 @@ func (u *User) Save(
 	ext := db.Ext(ctx, "mint")
 	_, err := sqlx.NamedExec(ext, `
 UPDATE users
-SET username = :username, password_hash = :password_hash
+SET username = :username, password_hash = :password_hash,
+    role = :role, status = :status
 WHERE token = :token
 `, u)
 	if err != nil {
@@ -141,6 +169,36 @@ WHERE username = :username
 	return &user, nil
 }
 
+// LoadUserList loads a list of users.
+func LoadUserList(
+	ctx context.Context,
+	page Page,
+) ([]User, bool, error) {
+	query := page.Params(map[string]interface{}{})
+
+	ext := db.Ext(ctx, "mint")
+	rows, err := sqlx.NamedQuery(ext,
+		page.Query("users", "token", []string{}), query)
+	if err != nil {
+		return nil, false, errors.Trace(err)
+	}
+
+	users := []User{}
+
+	defer rows.Close()
+	for rows.Next() {
+		u := User{}
+		err := rows.StructScan(&u)
+		if err != nil {
+			return nil, false, errors.Trace(err)
+		}
+		users = append(users, u)
+	}
+
+	i, j, more := page.Bounds(len(users))
+	return users[i:j], more, nil
+}
+
 // CheckPassword checks if the provided password matches the password hash
 // associated with that user.
 func (u *User) CheckPassword(
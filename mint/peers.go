@@ -0,0 +1,191 @@
+package mint
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PeerContact is an interaction with a peer mint, either emitted by this mint
+// through the Client or received from the peer (propagation requests).
+type PeerContact struct {
+	Host    string
+	Time    time.Time
+	Latency *time.Duration // Nil for requests received from the peer.
+	Failed  bool
+
+	// ProtocolVersion is the highest protocol version advertised by the peer,
+	// empty if unknown.
+	ProtocolVersion string
+}
+
+// maxPendingContacts bounds the number of contacts kept in memory until they
+// are recorded. Contacts beyond it are dropped.
+const maxPendingContacts = 10000
+
+// pendingContacts stores the pending contacts by local mint host.
+var pendingContacts = map[string][]PeerContact{}
+var pendingContactsMutex = &sync.Mutex{}
+
+// RecordPeerContact stores a peer contact in memory until it is recorded in
+// the peer registry (see DrainPeerContacts). Contacts are buffered so that
+// they can be recorded from within database transactions and without
+// delaying requests.
+func RecordPeerContact(
+	ctx context.Context,
+	contact PeerContact,
+) {
+	pendingContactsMutex.Lock()
+	defer pendingContactsMutex.Unlock()
+
+	host := GetHost(ctx)
+	if len(pendingContacts[host]) < maxPendingContacts {
+		pendingContacts[host] = append(pendingContacts[host], contact)
+	}
+}
+
+// knownPeers stores the hosts of the peers recorded in the peer registry by
+// local mint host.
+var knownPeers = map[string]map[string]bool{}
+var knownPeersMutex = &sync.Mutex{}
+
+// AddKnownPeers marks the specified mints as recorded in the peer registry.
+func AddKnownPeers(
+	ctx context.Context,
+	hosts ...string,
+) {
+	knownPeersMutex.Lock()
+	defer knownPeersMutex.Unlock()
+
+	host := GetHost(ctx)
+	if _, ok := knownPeers[host]; !ok {
+		knownPeers[host] = map[string]bool{}
+	}
+	for _, h := range hosts {
+		knownPeers[host][h] = true
+	}
+}
+
+// PeerLabel returns the label identifying the specified mint in metrics: its
+// host if it is recorded in the peer registry, MtPeerOther otherwise.
+func PeerLabel(
+	ctx context.Context,
+	host string,
+) string {
+	knownPeersMutex.Lock()
+	defer knownPeersMutex.Unlock()
+
+	if knownPeers[GetHost(ctx)][host] {
+		return host
+	}
+	return MtPeerOther
+}
+
+// DrainPeerContacts returns up to max pending peer contacts in the order they
+// were recorded and clears them. It also returns the number of contacts left
+// pending.
+func DrainPeerContacts(
+	ctx context.Context,
+	max int,
+) ([]PeerContact, int) {
+	pendingContactsMutex.Lock()
+	defer pendingContactsMutex.Unlock()
+
+	host := GetHost(ctx)
+	contacts := pendingContacts[host]
+	if len(contacts) <= max {
+		delete(pendingContacts, host)
+		return contacts, 0
+	}
+	pendingContacts[host] = append([]PeerContact{}, contacts[max:]...)
+	return contacts[:max], len(contacts) - max
+}
+
+// AdvertisedProtocolVersion returns the highest protocol version advertised
+// by the specified mint in its cached discovery document, empty if unknown.
+func AdvertisedProtocolVersion(
+	host string,
+) string {
+	m := CachedDiscovery(host)
+	if m == nil {
+		return ""
+	}
+
+	advertised := ""
+	best := int64(-1)
+	for _, v := range m.ProtocolVersions {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err == nil && n > best {
+			advertised = v
+			best = n
+		}
+	}
+	return advertised
+}
+
+// peerRules parses a comma separated list of peer rules.
+func peerRules(
+	list string,
+) []string {
+	rules := []string{}
+	for _, r := range strings.Split(list, ",") {
+		if r = strings.ToLower(strings.TrimSpace(r)); r != "" {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// peerRuleMatches returns whether the peer rule matches the specified mint
+// host. Rules are either exact hosts (`mint.example.com`, also matching any
+// port unless the rule specifies one) or wildcard domains
+// (`*.example.com`, matching any subdomain of example.com).
+func peerRuleMatches(
+	rule string,
+	host string,
+) bool {
+	host = strings.ToLower(host)
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+
+	if strings.HasPrefix(rule, "*.") {
+		return strings.HasSuffix(hostname, rule[1:])
+	}
+	if _, _, err := net.SplitHostPort(rule); err == nil {
+		return rule == host
+	}
+	return rule == hostname
+}
+
+// PeerAllowed returns whether this mint federates with the specified mint
+// according to the peer allow and deny lists: the mint must not match any
+// deny rule and, if the allow list is not empty, must match one of its rules.
+// This mint is always allowed.
+func PeerAllowed(
+	ctx context.Context,
+	host string,
+) bool {
+	if host == GetHost(ctx) {
+		return true
+	}
+	for _, r := range GetPeerDenyList(ctx) {
+		if peerRuleMatches(r, host) {
+			return false
+		}
+	}
+	allow := GetPeerAllowList(ctx)
+	if len(allow) == 0 {
+		return true
+	}
+	for _, r := range allow {
+		if peerRuleMatches(r, host) {
+			return true
+		}
+	}
+	return false
+}
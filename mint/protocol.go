@@ -1,18 +1,150 @@
 package mint
 
-import "math/big"
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/spolu/settle/lib/health"
+)
 
 const (
-	// ProtocolVersion is the current protocol version.
-	ProtocolVersion string = "0"
+	// ProtocolVersion is the current protocol version. Version 1 lists all the
+	// identity keys of the mint when its key is retrieved. Version 2 signs the
+	// host of the target mint of requests.
+	ProtocolVersion string = "2"
+	// MinProtocolVersion is the oldest protocol version still supported.
+	MinProtocolVersion string = "0"
 	// TimeResolutionNs is the resolution of our time variables in nanoseconds
 	// (aka resolution in milliseconds).
 	TimeResolutionNs int64 = 1000 * 1000
 	// TransactionExpiryMs is the time it takes to attempt to cancel a
 	// transaction for this mint. Expressed in ms.
 	TransactionExpiryMs int64 = 1000 * 60 * 60
+	// ReconciliationIntervalMs is the time between two reconciliations of the
+	// propagated objects stored by this mint. Expressed in ms.
+	ReconciliationIntervalMs int64 = 1000 * 60 * 60
+	// OfferSuspectFailures is the default number of consecutive failures to
+	// refresh the offers propagated from a mint after which the mint is marked
+	// as suspect.
+	OfferSuspectFailures uint = 3
+	// SignatureSkewMs is the maximum difference tolerated between the date of
+	// a signed mint-to-mint request and the time of its reception. Expressed
+	// in ms.
+	SignatureSkewMs int64 = 1000 * 60 * 5
+	// PeerKeyCacheMs is the time for which the identity key of a peer mint is
+	// cached after being retrieved. Expressed in ms.
+	PeerKeyCacheMs int64 = 1000 * 60 * 60
+	// PeerKeyFailureCacheMs is the time for which a failure to retrieve the
+	// identity key of a peer mint is cached. Expressed in ms.
+	PeerKeyFailureCacheMs int64 = 1000 * 60
+	// PeerKeyRefreshMs is the minimum interval between two refreshes of the
+	// identity key of a peer mint when a signature fails to verify with the
+	// cached key. Expressed in ms.
+	PeerKeyRefreshMs int64 = 1000 * 60
+	// KeyAlgorithm is the algorithm of the mint identity keys.
+	KeyAlgorithm string = "ed25519"
+	// DiscoveryCacheMs is the time for which the discovery document of a mint
+	// is cached after being retrieved. Expressed in ms.
+	DiscoveryCacheMs int64 = 1000 * 60 * 60
+	// DiscoveryPath is the well-known path at which mints serve their
+	// discovery document.
+	DiscoveryPath string = "/.well-known/settle-mint"
+	// ClientTimeoutMs is the default deadline of a request to another mint.
+	// Expressed in ms.
+	ClientTimeoutMs int64 = 1000 * 10
+	// ClientRetries is the default number of times an idempotent request to
+	// another mint is retried upon failure.
+	ClientRetries uint = 2
+	// ClientRetryBackoffMs is the base backoff between two attempts of a
+	// request to another mint, doubled at each retry and jittered. Expressed
+	// in ms.
+	ClientRetryBackoffMs int64 = 100
+	// BreakerFailures is the default number of consecutive failures to reach
+	// a mint after which its circuit breaker opens.
+	BreakerFailures uint = 5
+	// BreakerProbeMs is the default time after which an open circuit breaker
+	// lets a request through to probe the mint. Expressed in ms.
+	BreakerProbeMs int64 = 1000 * 30
+	// PeerRecordIntervalMs is the time between two recordings of the peer
+	// contacts in the peer registry. Expressed in ms.
+	PeerRecordIntervalMs int64 = 1000 * 60
+	// PeerLatencySamples is the number of most recent latencies kept per peer
+	// to compute latency percentiles.
+	PeerLatencySamples int = 100
+	// APIKeyLastUsedResolutionMs is the resolution at which the last use of
+	// API keys is recorded. Expressed in ms.
+	APIKeyLastUsedResolutionMs int64 = 1000 * 60
+	// HeaderIdempotencyKey carries the key provided by clients to make a
+	// request idempotent.
+	HeaderIdempotencyKey string = "Idempotency-Key"
+	// IdempotencyKeyExpiryMs is the time after which an idempotency key can be
+	// reused for a different request. Expressed in ms.
+	IdempotencyKeyExpiryMs int64 = 1000 * 60 * 60 * 24
+	// EventsKeepAliveMs is the interval at which event streams send keep-alive
+	// comments and check for events committed by another process. Expressed
+	// in ms.
+	EventsKeepAliveMs int64 = 1000 * 15
+	// EventsStreamDurationMs is the maximum duration of an event stream after
+	// which clients are expected to reconnect. Expressed in ms.
+	EventsStreamDurationMs int64 = 1000 * 60 * 10
+	// EventsRetryMs is the reconnection delay advised to event stream
+	// clients. Expressed in ms.
+	EventsRetryMs int64 = 1000 * 3
+	// TransactionWaitMaxMs is the maximum time a request can wait for a
+	// transaction to reach a given status. Expressed in ms.
+	TransactionWaitMaxMs int64 = 1000 * 30
+	// TransactionWaitPollMs is the interval at which waiting requests reload
+	// a transaction in the absence of notification (for changes committed by
+	// another process). Expressed in ms.
+	TransactionWaitPollMs int64 = 1000
+	// TraceMaxTraces is the number of most recent traces whose spans are kept
+	// in memory by a mint.
+	TraceMaxTraces int = 10000
+	// TraceMaxSpans is the number of spans kept in memory per trace by a
+	// mint.
+	TraceMaxSpans int = 1000
+	// HealthAsyncStaleMs is the time after which the async worker is
+	// considered stuck if its loop did not run or its current task did not
+	// complete.
+	HealthAsyncStaleMs int64 = 1000 * 60 * 5
+	// HealthMaxPendingTasks is the default number of pending tasks above
+	// which the mint is not ready.
+	HealthMaxPendingTasks int = 1000
+	// HealthMaxFailedTasks is the default number of failed tasks created
+	// within HealthFailedTasksWindowMs above which the mint is not ready.
+	HealthMaxFailedTasks int = 10
+	// HealthFailedTasksWindowMs is the window over which failed tasks are
+	// counted.
+	HealthFailedTasksWindowMs int64 = 1000 * 60 * 60 * 24
+	// AsyncFailedTasksRefreshMs is the time after which the count of failed
+	// tasks reported in metrics is refreshed. Expressed in ms.
+	AsyncFailedTasksRefreshMs int64 = 1000 * 60
+	// HealthCertificateExpiryMs is the validity left under which the
+	// production certificate makes the mint not ready.
+	HealthCertificateExpiryMs int64 = 1000 * 60 * 60 * 24 * 7
 )
 
+// ProtocolVersions is the list of protocol versions supported by this mint,
+// from MinProtocolVersion to ProtocolVersion.
+var ProtocolVersions = []string{MinProtocolVersion, "1", ProtocolVersion}
+
+// ProtocolVersionRange represents the range of protocol versions supported by
+// a mint.
+type ProtocolVersionRange struct {
+	Min       string   `json:"min"`
+	Max       string   `json:"max"`
+	Supported []string `json:"supported"`
+}
+
+const (
+	// FtSignedRequests indicates that a mint signs its mint-to-mint requests
+	// and verifies the signature of the requests it receives.
+	FtSignedRequests string = "signed_requests"
+)
+
+// Features is the list of optional features supported by this mint.
+var Features = []string{FtSignedRequests}
+
 // PgType is the propagation type of an object.
 type PgType string
 
@@ -53,6 +185,66 @@ const (
 	TxStCanceled TxStatus = "canceled"
 )
 
+// DsType is the type of a discrepancy found during reconciliation.
+type DsType string
+
+const (
+	// DsTpValueMismatch is used when a propagated copy diverged from its
+	// canonical version.
+	DsTpValueMismatch DsType = "value_mismatch"
+	// DsTpCanonicalMissing is used when the canonical version of a propagated
+	// copy does not exist on its owner mint.
+	DsTpCanonicalMissing DsType = "canonical_missing"
+)
+
+// SyStatus is the synchronization status of the offers propagated from a
+// remote mint.
+type SyStatus string
+
+const (
+	// SyStHealthy is used to mark a remote mint whose propagated offers are
+	// successfully refreshed.
+	SyStHealthy SyStatus = "healthy"
+	// SyStSuspect is used to mark a remote mint whose propagated offers
+	// repeatedly failed to be refreshed. The offers of suspect mints are not
+	// advertised.
+	SyStSuspect SyStatus = "suspect"
+)
+
+// KyScope is a scope granted to an API key.
+type KyScope string
+
+const (
+	// KyScRead grants read-only access (GET requests).
+	KyScRead KyScope = "read"
+	// KyScCreateOffers grants the creation and closing of offers.
+	KyScCreateOffers KyScope = "create_offers"
+	// KyScPay grants the creation, settlement and cancellation of
+	// transactions, up to the key daily pay limit if any.
+	KyScPay KyScope = "pay"
+)
+
+// UsRole is the role of a user.
+type UsRole string
+
+const (
+	// UsRlUser is the role of regular users.
+	UsRlUser UsRole = "user"
+	// UsRlAdmin is the role of users allowed to manage the users of the mint.
+	UsRlAdmin UsRole = "admin"
+)
+
+// UsStatus is the status of a user.
+type UsStatus string
+
+const (
+	// UsStActive is used to mark a user as active.
+	UsStActive UsStatus = "active"
+	// UsStDisabled is used to mark a user as disabled. Disabled users can't
+	// authenticate.
+	UsStDisabled UsStatus = "disabled"
+)
+
 // AssetResource is the representation of an asset in the mint API.
 type AssetResource struct {
 	ID          string `json:"id"`
@@ -143,3 +335,176 @@ type TransactionResource struct {
 	Operations []OperationResource `json:"operations"`
 	Crossings  []CrossingResource  `json:"crossings"`
 }
+
+// KeyResource is the representation of a mint identity key in the mint API.
+type KeyResource struct {
+	ID        string `json:"id"`
+	Created   int64  `json:"created"`
+	Host      string `json:"host"`
+	Algorithm string `json:"algorithm"`
+	PublicKey string `json:"public_key"`
+}
+
+// MintResource is the representation of the discovery document of a mint,
+// served at DiscoveryPath.
+type MintResource struct {
+	Host             string        `json:"host"`
+	ProtocolVersions []string      `json:"protocol_versions"`
+	APIBaseURL       string        `json:"api_base_url"`
+	Keys             []KeyResource `json:"keys"`
+	Features         []string      `json:"features"`
+}
+
+// PeerLatency represents the latency percentiles of the requests to a peer
+// mint. Expressed in ms, nil if no request was emitted to the peer.
+type PeerLatency struct {
+	P50 *int64 `json:"p50"`
+	P90 *int64 `json:"p90"`
+	P99 *int64 `json:"p99"`
+}
+
+// PeerResource is the representation of a peer mint in the mint API.
+type PeerResource struct {
+	Host         string `json:"host"`
+	FirstContact int64  `json:"first_contact"`
+	LastContact  int64  `json:"last_contact"`
+
+	Failures        uint        `json:"failures"`
+	Latency         PeerLatency `json:"latency"`
+	ProtocolVersion *string     `json:"protocol_version"`
+	Breaker         BkState     `json:"breaker"`
+}
+
+// APIKeyResource is the representation of an API key in the mint API. The
+// full key (used as bearer token) is only returned upon creation.
+type APIKeyResource struct {
+	ID      string `json:"id"`
+	Created int64  `json:"created"`
+	Owner   string `json:"owner"`
+
+	Name     string    `json:"name"`
+	Scopes   []KyScope `json:"scopes"`
+	PayAsset *string   `json:"pay_asset"`
+	PayLimit *big.Int  `json:"pay_limit"`
+	Expires  *int64    `json:"expires"`
+	LastUsed *int64    `json:"last_used"`
+	Revoked  *int64    `json:"revoked"`
+
+	Key *string `json:"key"`
+}
+
+// UserResource is the representation of a user in the mint admin API.
+type UserResource struct {
+	ID      string `json:"id"`
+	Created int64  `json:"created"`
+
+	Username string   `json:"username"`
+	Address  string   `json:"address"`
+	Role     UsRole   `json:"role"`
+	Status   UsStatus `json:"status"`
+}
+
+// SpendingPolicyResource is the representation of the spending policy of a
+// user for an asset in the mint API, along with its current usage. Limits are
+// nil when not enforced.
+type SpendingPolicyResource struct {
+	Asset string `json:"asset"`
+
+	PerTransaction *big.Int `json:"per_transaction"`
+	PerDay         *big.Int `json:"per_day"`
+	PerWindow      *big.Int `json:"per_window"`
+	WindowMs       *int64   `json:"window_ms"`
+
+	DaySpent    *big.Int `json:"day_spent"`
+	WindowSpent *big.Int `json:"window_spent"`
+}
+
+// EvType is the type of an event.
+type EvType string
+
+const (
+	// EvTpBalanceUpdated is emitted when a balance is created or its value
+	// changes.
+	EvTpBalanceUpdated EvType = "balance.updated"
+	// EvTpOfferActive is emitted when an offer is created.
+	EvTpOfferActive EvType = "offer.active"
+	// EvTpOfferClosed is emitted when an offer is closed.
+	EvTpOfferClosed EvType = "offer.closed"
+	// EvTpOfferConsumed is emitted when an offer is consumed.
+	EvTpOfferConsumed EvType = "offer.consumed"
+	// EvTpTransactionReserved is emitted when a transaction is reserved.
+	EvTpTransactionReserved EvType = "transaction.reserved"
+	// EvTpTransactionSettled is emitted when a transaction is settled.
+	EvTpTransactionSettled EvType = "transaction.settled"
+	// EvTpTransactionCanceled is emitted when a transaction is canceled.
+	EvTpTransactionCanceled EvType = "transaction.canceled"
+)
+
+// EventResource is the representation of an event in the mint API. Data is
+// the representation of the object concerned at the time of the event.
+type EventResource struct {
+	ID      string           `json:"id"`
+	Created int64            `json:"created"`
+	Type    EvType           `json:"type"`
+	Object  string           `json:"object"`
+	Data    *json.RawMessage `json:"data"`
+}
+
+// SpanResource is the representation of a span recorded by a mint in the mint
+// API. Start is expressed in ms and Duration in µs.
+type SpanResource struct {
+	TraceID  string  `json:"trace_id"`
+	ID       string  `json:"id"`
+	ParentID *string `json:"parent_id"`
+	Mint     string  `json:"mint"`
+
+	Name       string            `json:"name"`
+	Start      int64             `json:"start"`
+	Duration   int64             `json:"duration_us"`
+	Error      *string           `json:"error"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+// TraceResource is the representation of the trace of a transaction in the
+// mint API: the spans recorded by the mints involved, ordered by start time.
+// Unreachable lists the mints whose spans could not be retrieved.
+type TraceResource struct {
+	Transaction string         `json:"transaction"`
+	Spans       []SpanResource `json:"spans"`
+	Unreachable []string       `json:"unreachable"`
+}
+
+// HealthResource is the representation of the health of the mint in the mint
+// API: the overall status and the result of each check.
+type HealthResource struct {
+	Status health.Status  `json:"status"`
+	Checks []health.Check `json:"checks"`
+}
+
+// OrderBookResource is the representation of the order book of an asset in
+// the mint API. Active offers are grouped by counter asset.
+type OrderBookResource struct {
+	Asset string                  `json:"asset"`
+	Pairs []OrderBookPairResource `json:"pairs"`
+}
+
+// OrderBookPairResource is the representation of the order book of an asset
+// against a counter asset. Asks are offers selling the asset (by increasing
+// rate) and bids offers buying it (by decreasing rate).
+type OrderBookPairResource struct {
+	CounterAsset string               `json:"counter_asset"`
+	Asks         []PriceLevelResource `json:"asks"`
+	Bids         []PriceLevelResource `json:"bids"`
+}
+
+// PriceLevelResource is the representation of a price level of an order book.
+// Rate is the decimal number of units of counter asset per unit of asset
+// (accounting for both assets scales). Amount is the remaining liquidity at
+// that level and Depth the cumulative liquidity up to that level, both
+// expressed in native units of the asset.
+type PriceLevelResource struct {
+	Rate   string   `json:"rate"`
+	Amount *big.Int `json:"amount"`
+	Depth  *big.Int `json:"depth"`
+	Offers int      `json:"offers"`
+}
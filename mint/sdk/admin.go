@@ -0,0 +1,150 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/mint"
+)
+
+// AdminListUsers lists the users of the mint. It requires an admin user.
+func (c *Client) AdminListUsers(
+	ctx context.Context,
+	params *ListParams,
+) *UserIter {
+	return newUserIter(ctx, c, request{
+		Method: "GET",
+		Path:   "/admin/users",
+		Query:  params.values(),
+	})
+}
+
+// AdminCreateUser creates a user on the mint. It requires an admin user.
+func (c *Client) AdminCreateUser(
+	ctx context.Context,
+	params *AdminCreateUserParams,
+) (*mint.UserResource, error) {
+	var user mint.UserResource
+	err := c.retrieve(ctx, request{
+		Method: "POST",
+		Path:   "/admin/users",
+		Params: params.values(),
+	}, "user", &user)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &user, nil
+}
+
+// AdminDisableUser disables a user of the mint. It requires an admin user.
+func (c *Client) AdminDisableUser(
+	ctx context.Context,
+	username string,
+) (*mint.UserResource, error) {
+	return c.adminUpdateUser(ctx, username, "disable", nil)
+}
+
+// AdminEnableUser enables a user of the mint. It requires an admin user.
+func (c *Client) AdminEnableUser(
+	ctx context.Context,
+	username string,
+) (*mint.UserResource, error) {
+	return c.adminUpdateUser(ctx, username, "enable", nil)
+}
+
+// AdminResetPassword resets the password of a user of the mint. It requires
+// an admin user.
+func (c *Client) AdminResetPassword(
+	ctx context.Context,
+	username string,
+	password string,
+) (*mint.UserResource, error) {
+	return c.adminUpdateUser(ctx, username, "password", url.Values{
+		"password": {password},
+	})
+}
+
+// adminUpdateUser performs one of the admin user update actions.
+func (c *Client) adminUpdateUser(
+	ctx context.Context,
+	username string,
+	action string,
+	params url.Values,
+) (*mint.UserResource, error) {
+	var user mint.UserResource
+	err := c.retrieve(ctx, request{
+		Method: "POST",
+		Path:   fmt.Sprintf("/admin/users/%s/%s", username, action),
+		Params: params,
+	}, "user", &user)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &user, nil
+}
+
+// AdminListUserAssets lists the assets of a user of the mint. It requires an
+// admin user.
+func (c *Client) AdminListUserAssets(
+	ctx context.Context,
+	username string,
+	params *ListParams,
+) *AssetIter {
+	return newAssetIter(ctx, c, request{
+		Method: "GET",
+		Path:   fmt.Sprintf("/admin/users/%s/assets", username),
+		Query:  params.values(),
+	})
+}
+
+// AdminListUserOffers lists the offers of a user of the mint. It requires an
+// admin user.
+func (c *Client) AdminListUserOffers(
+	ctx context.Context,
+	username string,
+	params *ListParams,
+) *OfferIter {
+	return newOfferIter(ctx, c, request{
+		Method: "GET",
+		Path:   fmt.Sprintf("/admin/users/%s/offers", username),
+		Query:  params.values(),
+	})
+}
+
+// AdminRetrieveUserUsage retrieves the spending policies of a user of the mint
+// along with the amounts spent under them. It requires an admin user.
+func (c *Client) AdminRetrieveUserUsage(
+	ctx context.Context,
+	username string,
+) ([]mint.SpendingPolicyResource, error) {
+	policies := []mint.SpendingPolicyResource{}
+	err := c.retrieve(ctx, request{
+		Method: "GET",
+		Path:   fmt.Sprintf("/admin/users/%s/usage", username),
+	}, "spending_policies", &policies)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return policies, nil
+}
+
+// AdminSetSpendingPolicy sets the spending policy of a user of the mint for an
+// asset. It requires an admin user.
+func (c *Client) AdminSetSpendingPolicy(
+	ctx context.Context,
+	username string,
+	params *AdminSetSpendingPolicyParams,
+) (*mint.SpendingPolicyResource, error) {
+	var policy mint.SpendingPolicyResource
+	err := c.retrieve(ctx, request{
+		Method: "POST",
+		Path:   fmt.Sprintf("/admin/users/%s/policies", username),
+		Params: params.values(),
+	}, "spending_policy", &policy)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &policy, nil
+}
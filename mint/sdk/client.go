@@ -0,0 +1,315 @@
+// Package sdk exposes the mint API to Go programs with typed requests and
+// responses. It covers the endpoints used by users, admins and integrations
+// of a mint; the mint-to-mint propagation endpoints are used through
+// mint.Client.
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/spolu/settle/lib/client"
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/lib/token"
+	"github.com/spolu/settle/mint"
+)
+
+// idempotentRetries is the number of times idempotent requests are retried
+// when their response is lost.
+const idempotentRetries = 2
+
+// Credentials are used to authenticate requests to a mint, either with the
+// username and password of a user or with an API key (`token.secret`).
+type Credentials struct {
+	Username string
+	Password string
+	APIKey   string
+}
+
+// authenticate sets the authorization header of a request.
+func (c *Credentials) authenticate(
+	req *http.Request,
+) {
+	if c.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	} else {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+}
+
+// ContextKey is the type of the key used with context to carry contextual
+// credentials.
+type ContextKey string
+
+const (
+	// credentialsKey the context.Context key to store the credentials.
+	credentialsKey ContextKey = "sdk.credentials"
+)
+
+// WithCredentials stores the credentials to use for requests made with the
+// returned context, overriding the credentials of the client.
+func WithCredentials(
+	ctx context.Context,
+	credentials *Credentials,
+) context.Context {
+	return context.WithValue(ctx, credentialsKey, credentials)
+}
+
+// GetCredentials returns the credentials stored in the context (nil if none).
+func GetCredentials(
+	ctx context.Context,
+) *Credentials {
+	credentials, _ := ctx.Value(credentialsKey).(*Credentials)
+	return credentials
+}
+
+// Client exposes the API of the mint at Host. Requests to that mint are
+// authenticated with the credentials stored in the context if any, or the
+// credentials of the client otherwise. Public objects owned by other mints are
+// retrieved from their own mint, without credentials. Errors returned by mints
+// are reported as mint.ErrMintClient. Request bodies are form encoded unless
+// JSON is set.
+type Client struct {
+	Host        string
+	Credentials *Credentials
+	JSON        bool
+
+	httpClient *http.Client
+}
+
+// Init initializes the client.
+func (c *Client) Init(
+	ctx context.Context,
+) error {
+	c.httpClient = client.Default(ctx)
+	return nil
+}
+
+// request describes a request to a mint.
+type request struct {
+	Method string
+	Host   string // Host of the mint, the client's mint if empty.
+	Path   string
+	Query  url.Values
+	Params url.Values
+
+	// Idempotent requests are sent with an idempotency key and retried if
+	// their response is lost.
+	Idempotent bool
+}
+
+// url constructs the URL of the request, attempting to discover its mint
+// first so that its advertised API base URL is used.
+func (c *Client) url(
+	ctx context.Context,
+	host string,
+	path string,
+	query url.Values,
+) *url.URL {
+	m := &mint.Client{}
+	if err := m.Init(ctx); err == nil {
+		// Discovery is best effort, the URL falls back to the defaults.
+		m.Discover(ctx, host)
+	}
+	return mint.FullMintURL(ctx, host, path, query)
+}
+
+// do performs a request and returns its response, converting error responses
+// to mint.ErrMintClient.
+func (c *Client) do(
+	ctx context.Context,
+	r request,
+) (*svc.Resp, error) {
+	key := ""
+	retries := 0
+	if r.Idempotent {
+		key = token.New("idempotency")
+		retries = idempotentRetries
+	}
+
+	var err error
+	for attempt := 0; attempt <= retries; attempt++ {
+		var raw *svc.Resp
+		raw, err = c.send(ctx, r, key)
+		if err == nil {
+			return raw, nil
+		}
+		if _, ok := errors.Cause(err).(mint.ErrMintClient); ok {
+			break
+		}
+	}
+
+	return nil, errors.Trace(err)
+}
+
+// newRequest constructs the HTTP request for a request to a mint with the
+// provided idempotency key if not empty.
+func (c *Client) newRequest(
+	ctx context.Context,
+	r request,
+	key string,
+) (*http.Request, error) {
+	host := r.Host
+	if host == "" {
+		host = c.Host
+	}
+	if r.Query == nil {
+		r.Query = url.Values{}
+	}
+
+	var body io.Reader
+	contentType := svc.ContentTypeForm
+	if c.JSON {
+		contentType = svc.ContentTypeJSON
+	}
+	if r.Method == "POST" {
+		b, err := svc.Params(r.Params).Encode(contentType)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(r.Method,
+		c.url(ctx, host, r.Path, r.Query).String(), body)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	req.Header.Add("Mint-Protocol-Version",
+		mint.NegotiatedProtocolVersion(host))
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if key != "" {
+		req.Header.Set(mint.HeaderIdempotencyKey, key)
+	}
+	if host == c.Host {
+		if credentials := c.credentials(ctx); credentials != nil {
+			credentials.authenticate(req)
+		}
+	}
+
+	return req.WithContext(ctx), nil
+}
+
+// send sends a request once with the provided idempotency key if not empty.
+func (c *Client) send(
+	ctx context.Context,
+	r request,
+	key string,
+) (*svc.Resp, error) {
+	req, err := c.newRequest(ctx, r, key)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	res, err := c.getHTTPClient(ctx).Do(req)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer res.Body.Close()
+
+	var raw svc.Resp
+	if err := json.NewDecoder(res.Body).Decode(&raw); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
+		return nil, errors.Trace(errorFromResp(res.StatusCode, &raw))
+	}
+
+	return &raw, nil
+}
+
+// errorFromResp converts an error response to a mint.ErrMintClient.
+func errorFromResp(
+	status int,
+	raw *svc.Resp,
+) error {
+	var e errors.ConcreteUserError
+	if err := raw.Extract("error", &e); err != nil {
+		return errors.Trace(err)
+	}
+	return mint.ErrMintClient{
+		StatusCode: status,
+		ErrCode:    e.ErrCode,
+		ErrMessage: e.ErrMessage,
+	}
+}
+
+// getHTTPClient returns the HTTP client to use.
+func (c *Client) getHTTPClient(
+	ctx context.Context,
+) *http.Client {
+	if c.httpClient == nil {
+		return client.Default(ctx)
+	}
+	return c.httpClient
+}
+
+// credentials returns the credentials to use for a request.
+func (c *Client) credentials(
+	ctx context.Context,
+) *Credentials {
+	if credentials := GetCredentials(ctx); credentials != nil {
+		return credentials
+	}
+	return c.Credentials
+}
+
+// retrieve performs a request and extracts the object returned under name in
+// the response into object.
+func (c *Client) retrieve(
+	ctx context.Context,
+	r request,
+	name string,
+	object interface{},
+) error {
+	raw, err := c.do(ctx, r)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err := raw.Extract(name, object); err != nil {
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+// hostFromAddress returns the host of the mint of an address.
+func hostFromAddress(
+	ctx context.Context,
+	address string,
+) (string, error) {
+	_, host, err := mint.UsernameAndMintHostFromAddress(ctx, address)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	return host, nil
+}
+
+// hostFromID returns the host of the mint of the owner of an object ID.
+func hostFromID(
+	ctx context.Context,
+	id string,
+) (string, error) {
+	owner, _, err := mint.NormalizedOwnerAndTokenFromID(ctx, id)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	return hostFromAddress(ctx, owner)
+}
+
+// IsNotFound returns whether an error returned by the client indicates that
+// the object requested does not exist.
+func IsNotFound(
+	err error,
+) bool {
+	e, ok := errors.Cause(err).(mint.ErrMintClient)
+	return ok && e.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,181 @@
+package sdk
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/mint"
+)
+
+// EventStream reads the events of the authenticated user from the event
+// stream of the mint of the client. Streams are periodically closed by mints,
+// in which case the event stream reconnects after the delay advised by the
+// mint and resumes after the last event received.
+//
+//	s := client.StreamEvents(ctx, "")
+//	defer s.Close()
+//	for s.Next() {
+//	  event := s.Event()
+//	}
+//	if err := s.Err(); err != nil {
+//	  ...
+//	}
+type EventStream struct {
+	// LastEventID is the ID of the last event received, used to resume the
+	// stream.
+	LastEventID string
+
+	ctx    context.Context
+	client *Client
+
+	body    io.ReadCloser
+	reader  *bufio.Reader
+	retry   time.Duration
+	current mint.EventResource
+	err     error
+}
+
+// StreamEvents streams the events of the authenticated user recorded after
+// lastEventID (from the start of the event log if empty) until the context is
+// done.
+func (c *Client) StreamEvents(
+	ctx context.Context,
+	lastEventID string,
+) *EventStream {
+	return &EventStream{
+		LastEventID: lastEventID,
+		ctx:         ctx,
+		client:      c,
+		retry:       time.Duration(mint.EventsRetryMs) * time.Millisecond,
+	}
+}
+
+// Next blocks until the next event is received, returning false once the
+// context is done or an error occurred.
+func (s *EventStream) Next() bool {
+	for s.err == nil && s.ctx.Err() == nil {
+		if s.body == nil {
+			if s.err = s.connect(); s.err != nil {
+				break
+			}
+		}
+
+		event, err := s.read()
+		if err == io.EOF {
+			// The stream was closed by the mint, reconnect after the advised
+			// delay.
+			s.Close()
+			select {
+			case <-s.ctx.Done():
+			case <-time.After(s.retry):
+			}
+			continue
+		} else if err != nil {
+			if s.ctx.Err() == nil {
+				s.err = errors.Trace(err)
+			}
+			break
+		}
+
+		if event != nil {
+			s.current = *event
+			s.LastEventID = event.ID
+			return true
+		}
+	}
+
+	s.Close()
+	return false
+}
+
+// Event returns the current event.
+func (s *EventStream) Event() mint.EventResource {
+	return s.current
+}
+
+// Err returns the error that stopped the stream, if any.
+func (s *EventStream) Err() error {
+	return s.err
+}
+
+// Close closes the underlying connection to the mint if any.
+func (s *EventStream) Close() {
+	if s.body != nil {
+		s.body.Close()
+		s.body = nil
+		s.reader = nil
+	}
+}
+
+// connect opens the stream, resuming after the last event received.
+func (s *EventStream) connect() error {
+	req, err := s.client.newRequest(s.ctx, request{
+		Method: "GET",
+		Path:   "/events",
+	}, "")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if s.LastEventID != "" {
+		req.Header.Set("Last-Event-ID", s.LastEventID)
+	}
+
+	res, err := s.client.getHTTPClient(s.ctx).Do(req)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		var raw svc.Resp
+		if err := json.NewDecoder(res.Body).Decode(&raw); err != nil {
+			return errors.Trace(err)
+		}
+		return errors.Trace(errorFromResp(res.StatusCode, &raw))
+	}
+
+	s.body = res.Body
+	s.reader = bufio.NewReader(res.Body)
+	return nil
+}
+
+// read reads the next message of the stream, returning nil if it does not
+// carry an event.
+func (s *EventStream) read() (*mint.EventResource, error) {
+	data := ""
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case line == "":
+			if data == "" {
+				return nil, nil
+			}
+			var event mint.EventResource
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				return nil, errors.Trace(err)
+			}
+			return &event, nil
+		case strings.HasPrefix(line, "data: "):
+			data += strings.TrimPrefix(line, "data: ")
+		case strings.HasPrefix(line, "retry: "):
+			ms, err := strconv.ParseInt(strings.TrimPrefix(line, "retry: "), 10, 64)
+			if err == nil && ms >= 0 {
+				s.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
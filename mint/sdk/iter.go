@@ -0,0 +1,282 @@
+package sdk
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/mint"
+)
+
+// Iter iterates over the objects of a list endpoint, fetching the following
+// pages as needed by following the `next_cursor` of each page. Typed iterators
+// embed it and expose the current object.
+//
+//	it := client.ListAssets(ctx, nil)
+//	for it.Next() {
+//	  asset := it.Asset()
+//	}
+//	if err := it.Err(); err != nil {
+//	  ...
+//	}
+type Iter struct {
+	ctx    context.Context
+	client *Client
+	req    request
+	decode func(raw *svc.Resp) ([]interface{}, error)
+
+	page    []interface{}
+	current interface{}
+	fetched bool
+	more    bool
+	err     error
+}
+
+// newIter constructs an iterator over the pages returned by the request, whose
+// objects are decoded with decode.
+func newIter(
+	ctx context.Context,
+	client *Client,
+	req request,
+	decode func(raw *svc.Resp) ([]interface{}, error),
+) *Iter {
+	if req.Query == nil {
+		req.Query = url.Values{}
+	}
+	return &Iter{
+		ctx:    ctx,
+		client: client,
+		req:    req,
+		decode: decode,
+	}
+}
+
+// Next advances the iterator, returning false once all objects have been
+// iterated over or an error occurred.
+func (it *Iter) Next() bool {
+	for len(it.page) == 0 {
+		if it.err != nil || (it.fetched && !it.more) {
+			return false
+		}
+		it.err = it.fetch()
+	}
+	it.current, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Current returns the current object.
+func (it *Iter) Current() interface{} {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *Iter) Err() error {
+	return it.err
+}
+
+// fetch fetches the next page of objects.
+func (it *Iter) fetch() error {
+	raw, err := it.client.do(it.ctx, it.req)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	it.page, err = it.decode(raw)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	it.fetched = true
+
+	// Mints that do not support cursors return a single page.
+	var next *string
+	if err := raw.Extract("has_more", &it.more); err != nil || !it.more {
+		it.more = false
+		return nil
+	}
+	if err := raw.Extract("next_cursor", &next); err != nil || next == nil {
+		it.more = false
+		return nil
+	}
+
+	// The next cursor is passed with the paging parameter of the request.
+	if it.req.Query.Get("ending_before") != "" {
+		it.req.Query.Set("ending_before", *next)
+	} else {
+		it.req.Query.Set("starting_after", *next)
+	}
+	return nil
+}
+
+// AssetIter iterates over a list of assets.
+type AssetIter struct {
+	*Iter
+}
+
+// Asset returns the current asset.
+func (it *AssetIter) Asset() mint.AssetResource {
+	return it.Current().(mint.AssetResource)
+}
+
+func newAssetIter(
+	ctx context.Context,
+	client *Client,
+	req request,
+) *AssetIter {
+	return &AssetIter{newIter(ctx, client, req,
+		func(raw *svc.Resp) ([]interface{}, error) {
+			var l []mint.AssetResource
+			if err := raw.Extract("assets", &l); err != nil {
+				return nil, errors.Trace(err)
+			}
+			page := []interface{}{}
+			for _, o := range l {
+				page = append(page, o)
+			}
+			return page, nil
+		})}
+}
+
+// BalanceIter iterates over a list of balances.
+type BalanceIter struct {
+	*Iter
+}
+
+// Balance returns the current balance.
+func (it *BalanceIter) Balance() mint.BalanceResource {
+	return it.Current().(mint.BalanceResource)
+}
+
+func newBalanceIter(
+	ctx context.Context,
+	client *Client,
+	req request,
+) *BalanceIter {
+	return &BalanceIter{newIter(ctx, client, req,
+		func(raw *svc.Resp) ([]interface{}, error) {
+			var l []mint.BalanceResource
+			if err := raw.Extract("balances", &l); err != nil {
+				return nil, errors.Trace(err)
+			}
+			page := []interface{}{}
+			for _, o := range l {
+				page = append(page, o)
+			}
+			return page, nil
+		})}
+}
+
+// OfferIter iterates over a list of offers.
+type OfferIter struct {
+	*Iter
+}
+
+// Offer returns the current offer.
+func (it *OfferIter) Offer() mint.OfferResource {
+	return it.Current().(mint.OfferResource)
+}
+
+func newOfferIter(
+	ctx context.Context,
+	client *Client,
+	req request,
+) *OfferIter {
+	return &OfferIter{newIter(ctx, client, req,
+		func(raw *svc.Resp) ([]interface{}, error) {
+			var l []mint.OfferResource
+			if err := raw.Extract("offers", &l); err != nil {
+				return nil, errors.Trace(err)
+			}
+			page := []interface{}{}
+			for _, o := range l {
+				page = append(page, o)
+			}
+			return page, nil
+		})}
+}
+
+// PeerIter iterates over a list of peers.
+type PeerIter struct {
+	*Iter
+}
+
+// Peer returns the current peer.
+func (it *PeerIter) Peer() mint.PeerResource {
+	return it.Current().(mint.PeerResource)
+}
+
+func newPeerIter(
+	ctx context.Context,
+	client *Client,
+	req request,
+) *PeerIter {
+	return &PeerIter{newIter(ctx, client, req,
+		func(raw *svc.Resp) ([]interface{}, error) {
+			var l []mint.PeerResource
+			if err := raw.Extract("peers", &l); err != nil {
+				return nil, errors.Trace(err)
+			}
+			page := []interface{}{}
+			for _, o := range l {
+				page = append(page, o)
+			}
+			return page, nil
+		})}
+}
+
+// APIKeyIter iterates over a list of API keys.
+type APIKeyIter struct {
+	*Iter
+}
+
+// APIKey returns the current API key.
+func (it *APIKeyIter) APIKey() mint.APIKeyResource {
+	return it.Current().(mint.APIKeyResource)
+}
+
+func newAPIKeyIter(
+	ctx context.Context,
+	client *Client,
+	req request,
+) *APIKeyIter {
+	return &APIKeyIter{newIter(ctx, client, req,
+		func(raw *svc.Resp) ([]interface{}, error) {
+			var l []mint.APIKeyResource
+			if err := raw.Extract("api_keys", &l); err != nil {
+				return nil, errors.Trace(err)
+			}
+			page := []interface{}{}
+			for _, o := range l {
+				page = append(page, o)
+			}
+			return page, nil
+		})}
+}
+
+// UserIter iterates over a list of users.
+type UserIter struct {
+	*Iter
+}
+
+// User returns the current user.
+func (it *UserIter) User() mint.UserResource {
+	return it.Current().(mint.UserResource)
+}
+
+func newUserIter(
+	ctx context.Context,
+	client *Client,
+	req request,
+) *UserIter {
+	return &UserIter{newIter(ctx, client, req,
+		func(raw *svc.Resp) ([]interface{}, error) {
+			var l []mint.UserResource
+			if err := raw.Extract("users", &l); err != nil {
+				return nil, errors.Trace(err)
+			}
+			page := []interface{}{}
+			for _, o := range l {
+				page = append(page, o)
+			}
+			return page, nil
+		})}
+}
@@ -0,0 +1,283 @@
+package sdk
+
+import (
+	"fmt"
+	"math/big"
+	"net/url"
+	"time"
+
+	"github.com/spolu/settle/mint"
+)
+
+// CreateAssetParams are the parameters to create an asset.
+type CreateAssetParams struct {
+	Code  string
+	Scale int8
+}
+
+func (p *CreateAssetParams) values() url.Values {
+	return url.Values{
+		"code":  {p.Code},
+		"scale": {fmt.Sprintf("%d", p.Scale)},
+	}
+}
+
+// CreateOfferParams are the parameters to create an offer. Price is
+// expressed as `base_price/quote_price`.
+type CreateOfferParams struct {
+	Pair   string
+	Price  string
+	Amount *big.Int
+}
+
+func (p *CreateOfferParams) values() url.Values {
+	return url.Values{
+		"pair":   {p.Pair},
+		"price":  {p.Price},
+		"amount": {p.Amount.String()},
+	}
+}
+
+// CreateTransactionParams are the parameters to create a transaction. Path is
+// the list of offer IDs to cross, if any.
+type CreateTransactionParams struct {
+	Pair        string
+	Amount      *big.Int
+	Destination string
+	Path        []string
+}
+
+func (p *CreateTransactionParams) values() url.Values {
+	return url.Values{
+		"pair":        {p.Pair},
+		"amount":      {p.Amount.String()},
+		"destination": {p.Destination},
+		"path[]":      p.Path,
+	}
+}
+
+// RetrieveTransactionParams are the parameters to retrieve a transaction. If
+// WaitFor is set, the mint waits for the transaction to reach that status (or
+// Timeout to pass) before responding.
+type RetrieveTransactionParams struct {
+	WaitFor mint.TxStatus
+	Timeout time.Duration
+}
+
+func (p *RetrieveTransactionParams) values() url.Values {
+	v := url.Values{}
+	if p == nil {
+		return v
+	}
+	if p.WaitFor != "" {
+		v.Set("wait_for", string(p.WaitFor))
+	}
+	if p.Timeout > 0 {
+		v.Set("timeout", p.Timeout.String())
+	}
+	return v
+}
+
+// CreateAPIKeyParams are the parameters to create an API key. PayAsset and
+// PayLimit are specified together to cap the amounts paid with the key.
+type CreateAPIKeyParams struct {
+	Name     string
+	Scopes   []mint.KyScope
+	PayAsset string
+	PayLimit *big.Int
+	Expires  *time.Time
+}
+
+func (p *CreateAPIKeyParams) values() url.Values {
+	v := url.Values{
+		"name": {p.Name},
+	}
+	for _, s := range p.Scopes {
+		v.Add("scopes[]", string(s))
+	}
+	if p.PayAsset != "" {
+		v.Set("pay_asset", p.PayAsset)
+	}
+	if p.PayLimit != nil {
+		v.Set("pay_limit", p.PayLimit.String())
+	}
+	if p.Expires != nil {
+		v.Set("expires", fmt.Sprintf("%d",
+			p.Expires.UnixNano()/mint.TimeResolutionNs))
+	}
+	return v
+}
+
+// AdminCreateUserParams are the parameters to create a user. Role defaults to
+// a regular user.
+type AdminCreateUserParams struct {
+	Username string
+	Password string
+	Role     mint.UsRole
+}
+
+func (p *AdminCreateUserParams) values() url.Values {
+	v := url.Values{
+		"username": {p.Username},
+		"password": {p.Password},
+	}
+	if p.Role != "" {
+		v.Set("role", string(p.Role))
+	}
+	return v
+}
+
+// AdminSetSpendingPolicyParams are the parameters to set the spending policy
+// of a user for an asset. Limits that are not set are removed.
+type AdminSetSpendingPolicyParams struct {
+	Asset          string
+	PerTransaction *big.Int
+	PerDay         *big.Int
+	PerWindow      *big.Int
+	WindowMs       *int64
+}
+
+func (p *AdminSetSpendingPolicyParams) values() url.Values {
+	v := url.Values{
+		"asset": {p.Asset},
+	}
+	if p.PerTransaction != nil {
+		v.Set("per_transaction", p.PerTransaction.String())
+	}
+	if p.PerDay != nil {
+		v.Set("per_day", p.PerDay.String())
+	}
+	if p.PerWindow != nil {
+		v.Set("per_window", p.PerWindow.String())
+	}
+	if p.WindowMs != nil {
+		v.Set("window_ms", fmt.Sprintf("%d", *p.WindowMs))
+	}
+	return v
+}
+
+// ListParams are the paging and sorting parameters of list endpoints. Limit is
+// the size of the pages fetched by iterators. Order is `asc` or `desc`.
+type ListParams struct {
+	Limit         uint
+	StartingAfter string
+	EndingBefore  string
+	CreatedBefore *time.Time
+	Sort          string
+	Order         string
+}
+
+func (p *ListParams) values() url.Values {
+	v := url.Values{}
+	if p == nil {
+		return v
+	}
+	if p.Limit > 0 {
+		v.Set("limit", fmt.Sprintf("%d", p.Limit))
+	}
+	if p.StartingAfter != "" {
+		v.Set("starting_after", p.StartingAfter)
+	}
+	if p.EndingBefore != "" {
+		v.Set("ending_before", p.EndingBefore)
+	}
+	if p.CreatedBefore != nil {
+		v.Set("created_before", fmt.Sprintf("%d",
+			p.CreatedBefore.UnixNano()/mint.TimeResolutionNs))
+	}
+	if p.Sort != "" {
+		v.Set("sort", p.Sort)
+	}
+	if p.Order != "" {
+		v.Set("order", p.Order)
+	}
+	return v
+}
+
+// AssetListParams are the parameters to list assets.
+type AssetListParams struct {
+	ListParams
+	Code string
+}
+
+func (p *AssetListParams) values() url.Values {
+	if p == nil {
+		return url.Values{}
+	}
+	v := p.ListParams.values()
+	if p.Code != "" {
+		v.Set("code", p.Code)
+	}
+	return v
+}
+
+// BalanceListParams are the parameters to list balances.
+type BalanceListParams struct {
+	ListParams
+	Owner     string
+	Holder    string
+	AssetCode string
+	MinValue  *big.Int
+	MaxValue  *big.Int
+}
+
+func (p *BalanceListParams) values() url.Values {
+	if p == nil {
+		return url.Values{}
+	}
+	v := p.ListParams.values()
+	if p.Owner != "" {
+		v.Set("owner", p.Owner)
+	}
+	if p.Holder != "" {
+		v.Set("holder", p.Holder)
+	}
+	if p.AssetCode != "" {
+		v.Set("asset_code", p.AssetCode)
+	}
+	if p.MinValue != nil {
+		v.Set("min_value", p.MinValue.String())
+	}
+	if p.MaxValue != nil {
+		v.Set("max_value", p.MaxValue.String())
+	}
+	return v
+}
+
+// OfferListParams are the parameters to list offers. Propagation only applies
+// to the offers of an asset.
+type OfferListParams struct {
+	ListParams
+	Owner        string
+	Status       mint.OfStatus
+	AssetCode    string
+	MinRemainder *big.Int
+	MaxRemainder *big.Int
+	Propagation  mint.PgType
+}
+
+func (p *OfferListParams) values() url.Values {
+	if p == nil {
+		return url.Values{}
+	}
+	v := p.ListParams.values()
+	if p.Owner != "" {
+		v.Set("owner", p.Owner)
+	}
+	if p.Status != "" {
+		v.Set("status", string(p.Status))
+	}
+	if p.AssetCode != "" {
+		v.Set("asset_code", p.AssetCode)
+	}
+	if p.MinRemainder != nil {
+		v.Set("min_remainder", p.MinRemainder.String())
+	}
+	if p.MaxRemainder != nil {
+		v.Set("max_remainder", p.MaxRemainder.String())
+	}
+	if p.Propagation != "" {
+		v.Set("propagation", string(p.Propagation))
+	}
+	return v
+}
@@ -0,0 +1,213 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/mint"
+)
+
+// RetrieveMint retrieves the description of a mint (protocol versions,
+// features and API base URL).
+func (c *Client) RetrieveMint(
+	ctx context.Context,
+	host string,
+) (*mint.MintResource, error) {
+	var m mint.MintResource
+	err := c.retrieve(ctx, request{
+		Method: "GET",
+		Host:   host,
+		Path:   "/.well-known/settle-mint",
+	}, "mint", &m)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &m, nil
+}
+
+// RetrieveKey retrieves the identity key of a mint.
+func (c *Client) RetrieveKey(
+	ctx context.Context,
+	host string,
+) (*mint.KeyResource, error) {
+	var key mint.KeyResource
+	err := c.retrieve(ctx, request{
+		Method: "GET",
+		Host:   host,
+		Path:   "/key",
+	}, "key", &key)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &key, nil
+}
+
+// RetrieveAsset retrieves an asset from the mint of its owner.
+func (c *Client) RetrieveAsset(
+	ctx context.Context,
+	name string,
+) (*mint.AssetResource, error) {
+	a, err := mint.AssetResourceFromName(ctx, name)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	host, err := hostFromAddress(ctx, a.Owner)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	var asset mint.AssetResource
+	err = c.retrieve(ctx, request{
+		Method: "GET",
+		Host:   host,
+		Path:   fmt.Sprintf("/assets/%s", name),
+	}, "asset", &asset)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &asset, nil
+}
+
+// ListAssetOffers lists the offers of an asset from the mint of its owner.
+func (c *Client) ListAssetOffers(
+	ctx context.Context,
+	asset string,
+	params *OfferListParams,
+) *OfferIter {
+	host := ""
+	a, err := mint.AssetResourceFromName(ctx, asset)
+	if err == nil {
+		host, err = hostFromAddress(ctx, a.Owner)
+	}
+
+	it := newOfferIter(ctx, c, request{
+		Method: "GET",
+		Host:   host,
+		Path:   fmt.Sprintf("/assets/%s/offers", asset),
+		Query:  params.values(),
+	})
+	if err != nil {
+		it.err = errors.Trace(err)
+	}
+	return it
+}
+
+// RetrieveOrderBook retrieves the order book of an asset from the mint of its
+// owner.
+func (c *Client) RetrieveOrderBook(
+	ctx context.Context,
+	asset string,
+) (*mint.OrderBookResource, error) {
+	a, err := mint.AssetResourceFromName(ctx, asset)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	host, err := hostFromAddress(ctx, a.Owner)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	var book mint.OrderBookResource
+	err = c.retrieve(ctx, request{
+		Method: "GET",
+		Host:   host,
+		Path:   fmt.Sprintf("/assets/%s/book", asset),
+	}, "book", &book)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &book, nil
+}
+
+// RetrieveOffer retrieves an offer from the mint of its owner.
+func (c *Client) RetrieveOffer(
+	ctx context.Context,
+	id string,
+) (*mint.OfferResource, error) {
+	host, err := hostFromID(ctx, id)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	var offer mint.OfferResource
+	err = c.retrieve(ctx, request{
+		Method: "GET",
+		Host:   host,
+		Path:   fmt.Sprintf("/offers/%s", id),
+	}, "offer", &offer)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &offer, nil
+}
+
+// RetrieveOperation retrieves an operation from the mint of its owner.
+func (c *Client) RetrieveOperation(
+	ctx context.Context,
+	id string,
+) (*mint.OperationResource, error) {
+	host, err := hostFromID(ctx, id)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	var operation mint.OperationResource
+	err = c.retrieve(ctx, request{
+		Method: "GET",
+		Host:   host,
+		Path:   fmt.Sprintf("/operations/%s", id),
+	}, "operation", &operation)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &operation, nil
+}
+
+// RetrieveBalance retrieves a balance from the mint of its owner.
+func (c *Client) RetrieveBalance(
+	ctx context.Context,
+	id string,
+) (*mint.BalanceResource, error) {
+	host, err := hostFromID(ctx, id)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	var balance mint.BalanceResource
+	err = c.retrieve(ctx, request{
+		Method: "GET",
+		Host:   host,
+		Path:   fmt.Sprintf("/balances/%s", id),
+	}, "balance", &balance)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &balance, nil
+}
+
+// RetrieveTransaction retrieves a transaction from the mint of its owner,
+// optionally waiting for it to reach a status (see
+// RetrieveTransactionParams).
+func (c *Client) RetrieveTransaction(
+	ctx context.Context,
+	id string,
+	params *RetrieveTransactionParams,
+) (*mint.TransactionResource, error) {
+	host, err := hostFromID(ctx, id)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	var transaction mint.TransactionResource
+	err = c.retrieve(ctx, request{
+		Method: "GET",
+		Host:   host,
+		Path:   fmt.Sprintf("/transactions/%s", id),
+		Query:  params.values(),
+	}, "transaction", &transaction)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &transaction, nil
+}
@@ -0,0 +1,254 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/mint"
+)
+
+// CreateAsset creates an asset for the authenticated user.
+func (c *Client) CreateAsset(
+	ctx context.Context,
+	params *CreateAssetParams,
+) (*mint.AssetResource, error) {
+	var asset mint.AssetResource
+	err := c.retrieve(ctx, request{
+		Method: "POST",
+		Path:   "/assets",
+		Params: params.values(),
+	}, "asset", &asset)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &asset, nil
+}
+
+// CreateOffer creates an offer for the authenticated user. The request is
+// idempotent and retried if its response is lost.
+func (c *Client) CreateOffer(
+	ctx context.Context,
+	params *CreateOfferParams,
+) (*mint.OfferResource, error) {
+	var offer mint.OfferResource
+	err := c.retrieve(ctx, request{
+		Method:     "POST",
+		Path:       "/offers",
+		Params:     params.values(),
+		Idempotent: true,
+	}, "offer", &offer)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &offer, nil
+}
+
+// CloseOffer closes an offer of the authenticated user.
+func (c *Client) CloseOffer(
+	ctx context.Context,
+	id string,
+) (*mint.OfferResource, error) {
+	var offer mint.OfferResource
+	err := c.retrieve(ctx, request{
+		Method: "POST",
+		Path:   fmt.Sprintf("/offers/%s/close", id),
+	}, "offer", &offer)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &offer, nil
+}
+
+// CreateTransaction creates a transaction for the authenticated user. The
+// request is idempotent and retried if its response is lost.
+func (c *Client) CreateTransaction(
+	ctx context.Context,
+	params *CreateTransactionParams,
+) (*mint.TransactionResource, error) {
+	var transaction mint.TransactionResource
+	err := c.retrieve(ctx, request{
+		Method:     "POST",
+		Path:       "/transactions",
+		Params:     params.values(),
+		Idempotent: true,
+	}, "transaction", &transaction)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &transaction, nil
+}
+
+// SettleTransaction settles a transaction of the authenticated user.
+func (c *Client) SettleTransaction(
+	ctx context.Context,
+	id string,
+) (*mint.TransactionResource, error) {
+	var transaction mint.TransactionResource
+	err := c.retrieve(ctx, request{
+		Method: "POST",
+		Path:   fmt.Sprintf("/transactions/%s/settle", id),
+	}, "transaction", &transaction)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &transaction, nil
+}
+
+// CancelTransaction cancels a transaction at the mint of the client.
+func (c *Client) CancelTransaction(
+	ctx context.Context,
+	id string,
+) (*mint.TransactionResource, error) {
+	var transaction mint.TransactionResource
+	err := c.retrieve(ctx, request{
+		Method: "POST",
+		Path:   fmt.Sprintf("/transactions/%s/cancel", id),
+	}, "transaction", &transaction)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &transaction, nil
+}
+
+// RetrieveTransactionTrace retrieves the trace of a transaction of the
+// authenticated user, stitched together by the mint of the client from the
+// spans recorded by each mint involved.
+func (c *Client) RetrieveTransactionTrace(
+	ctx context.Context,
+	id string,
+) (*mint.TraceResource, error) {
+	var t mint.TraceResource
+	err := c.retrieve(ctx, request{
+		Method: "GET",
+		Path:   fmt.Sprintf("/transactions/%s/trace", id),
+	}, "trace", &t)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &t, nil
+}
+
+// ListAssets lists the assets of the authenticated user.
+func (c *Client) ListAssets(
+	ctx context.Context,
+	params *AssetListParams,
+) *AssetIter {
+	return newAssetIter(ctx, c, request{
+		Method: "GET",
+		Path:   "/assets",
+		Query:  params.values(),
+	})
+}
+
+// ListBalances lists the balances held by the authenticated user.
+func (c *Client) ListBalances(
+	ctx context.Context,
+	params *BalanceListParams,
+) *BalanceIter {
+	return newBalanceIter(ctx, c, request{
+		Method: "GET",
+		Path:   "/balances",
+		Query:  params.values(),
+	})
+}
+
+// ListOffers lists the offers of the authenticated user.
+func (c *Client) ListOffers(
+	ctx context.Context,
+	params *OfferListParams,
+) *OfferIter {
+	return newOfferIter(ctx, c, request{
+		Method: "GET",
+		Path:   "/offers",
+		Query:  params.values(),
+	})
+}
+
+// ListAssetBalances lists the balances of an asset of the authenticated user.
+func (c *Client) ListAssetBalances(
+	ctx context.Context,
+	asset string,
+	params *BalanceListParams,
+) *BalanceIter {
+	return newBalanceIter(ctx, c, request{
+		Method: "GET",
+		Path:   fmt.Sprintf("/assets/%s/balances", asset),
+		Query:  params.values(),
+	})
+}
+
+// ListPeers lists the peer mints the mint of the client has been in contact
+// with.
+func (c *Client) ListPeers(
+	ctx context.Context,
+	params *ListParams,
+) *PeerIter {
+	return newPeerIter(ctx, c, request{
+		Method: "GET",
+		Path:   "/peers",
+		Query:  params.values(),
+	})
+}
+
+// CreateAPIKey creates an API key for the authenticated user. The secret of
+// the key is only returned upon creation.
+func (c *Client) CreateAPIKey(
+	ctx context.Context,
+	params *CreateAPIKeyParams,
+) (*mint.APIKeyResource, error) {
+	var key mint.APIKeyResource
+	err := c.retrieve(ctx, request{
+		Method: "POST",
+		Path:   "/keys",
+		Params: params.values(),
+	}, "api_key", &key)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &key, nil
+}
+
+// RevokeAPIKey revokes an API key of the authenticated user.
+func (c *Client) RevokeAPIKey(
+	ctx context.Context,
+	id string,
+) (*mint.APIKeyResource, error) {
+	var key mint.APIKeyResource
+	err := c.retrieve(ctx, request{
+		Method: "POST",
+		Path:   fmt.Sprintf("/keys/%s/revoke", id),
+	}, "api_key", &key)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &key, nil
+}
+
+// ListAPIKeys lists the API keys of the authenticated user.
+func (c *Client) ListAPIKeys(
+	ctx context.Context,
+	params *ListParams,
+) *APIKeyIter {
+	return newAPIKeyIter(ctx, c, request{
+		Method: "GET",
+		Path:   "/keys",
+		Query:  params.values(),
+	})
+}
+
+// RetrieveUsage retrieves the spending policies of the authenticated user
+// along with the amounts spent under them.
+func (c *Client) RetrieveUsage(
+	ctx context.Context,
+) ([]mint.SpendingPolicyResource, error) {
+	policies := []mint.SpendingPolicyResource{}
+	err := c.retrieve(ctx, request{
+		Method: "GET",
+		Path:   "/usage",
+	}, "spending_policies", &policies)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return policies, nil
+}
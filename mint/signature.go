@@ -0,0 +1,111 @@
+package mint
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/token"
+)
+
+const (
+	// HeaderSignature carries the base64 encoded signature of a mint-to-mint
+	// request.
+	HeaderSignature string = "Mint-Signature"
+	// HeaderSignatureHost carries the host of the mint that signed the
+	// request.
+	HeaderSignatureHost string = "Mint-Signature-Host"
+	// HeaderSignatureDate carries the date of the signature (unix time in ms).
+	HeaderSignatureDate string = "Mint-Signature-Date"
+	// HeaderSignatureNonce carries the nonce of the signature, used to prevent
+	// replays.
+	HeaderSignatureNonce string = "Mint-Signature-Nonce"
+)
+
+// SignaturePayload computes the payload signed for a mint-to-mint request.
+// It covers the method, request URI, body digest, date, nonce, signing host
+// and, if not empty, the host of the target mint so that a request can't be
+// replayed to another mint.
+func SignaturePayload(
+	method string,
+	uri string,
+	body []byte,
+	date string,
+	nonce string,
+	host string,
+	target string,
+) []byte {
+	payload := fmt.Sprintf(
+		"%s\n%s\n%x\n%s\n%s\n%s",
+		method, uri, sha256.Sum256(body), date, nonce, host)
+	if target != "" {
+		payload += "\n" + target
+	}
+	return []byte(payload)
+}
+
+// SignsTarget returns whether the signatures of requests made with the
+// specified protocol version cover the host of the target mint (from version
+// 2). Requests that don't specify any version use the current one.
+func SignsTarget(
+	version string,
+) bool {
+	if version == "" {
+		version = ProtocolVersion
+	}
+	v, err := strconv.ParseInt(version, 10, 64)
+	return err == nil && v >= 2
+}
+
+// SignRequest signs the provided request to the target mint with the mint
+// identity key stored in the context. It is a no-op if no identity key is
+// configured. The request body is read through GetBody and left untouched.
+// The protocol version of the request must be set beforehand as it determines
+// whether the target is signed.
+func SignRequest(
+	ctx context.Context,
+	req *http.Request,
+	target string,
+) error {
+	key := GetIdentityKey(ctx)
+	if key == nil {
+		return nil
+	}
+
+	body := []byte{}
+	if req.Body != nil && req.GetBody != nil {
+		r, err := req.GetBody()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		body, err = ioutil.ReadAll(r)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	date := fmt.Sprintf("%d", time.Now().UnixNano()/TimeResolutionNs)
+	nonce := token.RandStr()
+	host := GetHost(ctx)
+	if !SignsTarget(req.Header.Get("Mint-Protocol-Version")) {
+		target = ""
+	}
+
+	signature := ed25519.Sign(key, SignaturePayload(
+		req.Method, req.URL.RequestURI(), body, date, nonce, host, target))
+
+	req.Header.Set(HeaderSignatureHost, host)
+	req.Header.Set(HeaderSignatureDate, date)
+	req.Header.Set(HeaderSignatureNonce, nonce)
+	req.Header.Set(HeaderSignature,
+		base64.StdEncoding.EncodeToString(signature))
+
+	return nil
+}
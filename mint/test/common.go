@@ -18,14 +18,21 @@ import (
 	"github.com/spolu/settle/lib/db"
 	"github.com/spolu/settle/lib/env"
 	"github.com/spolu/settle/lib/logging"
+	"github.com/spolu/settle/lib/metrics"
+	"github.com/spolu/settle/lib/ratelimit"
 	"github.com/spolu/settle/lib/recoverer"
 	"github.com/spolu/settle/lib/requestlogger"
 	"github.com/spolu/settle/lib/svc"
 	"github.com/spolu/settle/lib/token"
+	"github.com/spolu/settle/lib/trace"
 	"github.com/spolu/settle/mint"
 	"github.com/spolu/settle/mint/app"
 	"github.com/spolu/settle/mint/async"
+	"github.com/spolu/settle/mint/endpoint"
 	"github.com/spolu/settle/mint/lib/authentication"
+	"github.com/spolu/settle/mint/lib/hub"
+	"github.com/spolu/settle/mint/lib/signature"
+	"github.com/spolu/settle/mint/lib/version"
 	"github.com/spolu/settle/mint/model"
 	goji "goji.io"
 )
@@ -55,12 +62,15 @@ func init() {
 
 // Mint represents a test mint.
 type Mint struct {
-	Server  *httptest.Server
-	Mux     *goji.Mux
-	Env     *env.Env
-	DB      *sqlx.DB
-	Ctx     context.Context
-	TmpFile string
+	Server        *httptest.Server
+	MetricsServer *httptest.Server
+	Mux           *goji.Mux
+	Env           *env.Env
+	DB            *sqlx.DB
+	Ctx           context.Context
+	Limiter       *ratelimit.Limiter
+	IPLimiter     *ratelimit.Limiter
+	TmpFile       string
 }
 
 // CreateMint creates a new test mint with an in-memory DB and returns
@@ -90,19 +100,47 @@ func CreateMint(
 	}
 	ctx = db.WithDB(ctx, "mint", mintDB)
 
+	key, err := model.LoadOrCreateIdentityKey(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mintEnv.Config[mint.EnvCfgIdentityKey] = key.PrivateKey
+
+	ctx = hub.With(ctx, hub.New())
+	ctx = metrics.With(ctx, metrics.NewRegistry())
+	ctx = trace.With(ctx, trace.New(mint.TraceMaxTraces, mint.TraceMaxSpans))
+
 	a, err := async.NewAsync(ctx)
 	if err != nil {
 		t.Fatal(err)
 	}
 	ctx = async.With(ctx, a)
 
+	clientIP, err := app.NewClientIPMiddleware(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ipLimiter, limiter, err := app.NewRateLimiters(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	mux := goji.NewMux()
 	mux.Use(requestlogger.Middleware)
+	mux.Use(trace.Middleware(trace.Get(ctx), signature.Signed))
+	mux.Use(metrics.Middleware(metrics.Get(ctx)))
+	mux.Use(endpoint.Instrument)
 	mux.Use(recoverer.Middleware)
+	mux.Use(clientIP)
+	mux.Use(ratelimit.Middleware(ipLimiter))
 	mux.Use(db.Middleware(db.GetDBMap(ctx)))
 	mux.Use(env.Middleware(env.Get(ctx)))
 	mux.Use(async.Middleware(async.Get(ctx)))
+	mux.Use(hub.Middleware(hub.Get(ctx)))
+	mux.Use(version.Middleware)
+	mux.Use(signature.Middleware)
 	mux.Use(authentication.Middleware)
+	mux.Use(ratelimit.Middleware(limiter))
 
 	(&app.Controller{}).Bind(mux)
 
@@ -110,17 +148,21 @@ func CreateMint(
 	// tasks when needed instead.
 
 	m := Mint{
-		Server:  httptest.NewServer(mux),
-		Mux:     mux,
-		Env:     &mintEnv,
-		DB:      mintDB,
-		Ctx:     ctx,
-		TmpFile: tmpFile,
+		Server: httptest.NewServer(mux),
+		MetricsServer: httptest.NewServer(
+			metrics.Handler(metrics.Get(ctx))),
+		Mux:       mux,
+		Env:       &mintEnv,
+		DB:        mintDB,
+		Ctx:       ctx,
+		Limiter:   limiter,
+		IPLimiter: ipLimiter,
+		TmpFile:   tmpFile,
 	}
 	m.Env.Config[mint.EnvCfgHost] = m.Server.URL[7:]
 
-	logging.Logf(ctx, "Creating test mint: mint_host=%s",
-		m.Env.Config[mint.EnvCfgHost])
+	logging.Info(ctx, "Creating test mint",
+		"mint_host", m.Env.Config[mint.EnvCfgHost])
 
 	return &m
 }
@@ -128,6 +170,7 @@ func CreateMint(
 // Close closes the mint after usage.
 func (m *Mint) Close() {
 	defer os.Remove(m.TmpFile)
+	m.MetricsServer.Close()
 	m.DB.Close()
 }
 
@@ -147,19 +190,35 @@ var userFirstnames = []string{
 // CreateUser creates a user and generates an associated MintUser
 func (m *Mint) CreateUser(
 	t *testing.T,
+) *MintUser {
+	return m.createUser(t, mint.UsRlUser)
+}
+
+// CreateAdmin creates an admin user and generates an associated MintUser
+func (m *Mint) CreateAdmin(
+	t *testing.T,
+) *MintUser {
+	return m.createUser(t, mint.UsRlAdmin)
+}
+
+// createUser creates a user with the provided role and generates an
+// associated MintUser
+func (m *Mint) createUser(
+	t *testing.T,
+	role mint.UsRole,
 ) *MintUser {
 	userIdx++
 	username := token.New(userFirstnames[userIdx%len(userFirstnames)])
 	password := token.New("password")
 
-	_, err := model.CreateUser(m.Ctx, username, password)
+	_, err := model.CreateUser(m.Ctx, username, password, role)
 	if err != nil {
 		t.Fatal(err)
 	}
 	m.Env.Config[mint.EnvCfgHost] = m.Server.URL[7:]
 
-	logging.Logf(m.Ctx, "Creating test mint: minst_host=%s",
-		m.Env.Config[mint.EnvCfgHost])
+	logging.Info(m.Ctx, "Creating test mint",
+		"mint_host", m.Env.Config[mint.EnvCfgHost])
 
 	return &MintUser{
 		m, username, password,
@@ -199,6 +258,38 @@ func (m *Mint) Post(
 	return r.StatusCode, raw
 }
 
+// PostJSON posts a raw JSON body to a specified endpoint on the mint.
+func (m *Mint) PostJSON(
+	t *testing.T,
+	user *MintUser,
+	path string,
+	body string,
+) (int, svc.Resp) {
+	req, err := http.NewRequest("POST",
+		fmt.Sprintf("%s%s", m.Server.URL, path),
+		strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if user != nil {
+		req.SetBasicAuth(user.Username, user.Password)
+	}
+
+	r, err := getDefaultHTTPClient().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Body.Close()
+
+	var raw svc.Resp
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+		t.Fatal(err)
+	}
+
+	return r.StatusCode, raw
+}
+
 // Post posts to a specified endpoint on the mint.
 func (u *MintUser) Post(
 	t *testing.T,
@@ -208,6 +299,39 @@ func (u *MintUser) Post(
 	return u.Mint.Post(t, u, path, params)
 }
 
+// PostFrom posts to a specified endpoint on the mint a request signed by the
+// provided peer mint, as peer mints would do when propagating objects.
+func (m *Mint) PostFrom(
+	t *testing.T,
+	peer *Mint,
+	path string,
+	params url.Values,
+) (int, svc.Resp) {
+	req, err := http.NewRequest("POST",
+		fmt.Sprintf("%s%s", m.Server.URL, path),
+		strings.NewReader(params.Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if err := mint.SignRequest(peer.Ctx, req, mint.GetHost(m.Ctx)); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := getDefaultHTTPClient().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Body.Close()
+
+	var raw svc.Resp
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+		t.Fatal(err)
+	}
+
+	return r.StatusCode, raw
+}
+
 // Get gets a specified endpoint on the mint.
 func (m *Mint) Get(
 	t *testing.T,
@@ -0,0 +1,160 @@
+package functional
+
+import (
+	"fmt"
+	"math/big"
+	"net/url"
+	"testing"
+
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminUsersRequiresAdmin(
+	t *testing.T,
+) {
+	t.Parallel()
+	m := test.CreateMint(t)
+	defer m.Close()
+	u := m.CreateUser(t)
+
+	status, raw := u.Get(t, "/admin/users")
+	assert.Equal(t, 403, status)
+	assert.Equal(t, "admin_required", errorCode(t, raw))
+}
+
+func TestAdminUsersCreateAndList(
+	t *testing.T,
+) {
+	t.Parallel()
+	m := test.CreateMint(t)
+	defer m.Close()
+	admin := m.CreateAdmin(t)
+
+	status, raw := admin.Post(t, "/admin/users", url.Values{
+		"username": {"kurt"},
+		"password": {"incompleteness"},
+	})
+	assert.Equal(t, 201, status)
+	var user mint.UserResource
+	err := raw.Extract("user", &user)
+	assert.Nil(t, err)
+	assert.Equal(t, "kurt", user.Username)
+	assert.Equal(t, fmt.Sprintf("kurt@%s", m.Env.Config[mint.EnvCfgHost]),
+		user.Address)
+	assert.Equal(t, mint.UsRlUser, user.Role)
+	assert.Equal(t, mint.UsStActive, user.Status)
+
+	status, raw = admin.Post(t, "/admin/users", url.Values{
+		"username": {"kurt"},
+		"password": {"incompleteness"},
+	})
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "user_already_exists", errorCode(t, raw))
+
+	status, raw = admin.Get(t, "/admin/users")
+	assert.Equal(t, 200, status)
+	var users []mint.UserResource
+	err = raw.Extract("users", &users)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(users))
+	assert.Equal(t, user.ID, users[0].ID)
+	assert.Equal(t, admin.Username, users[1].Username)
+	assert.Equal(t, mint.UsRlAdmin, users[1].Role)
+
+	// The created user can authenticate.
+	kurt := &test.MintUser{
+		Mint:     m,
+		Username: "kurt",
+		Password: "incompleteness",
+		Address:  user.Address,
+	}
+	status, _ = kurt.Get(t, "/assets")
+	assert.Equal(t, 200, status)
+}
+
+func TestAdminUsersDisableAndResetPassword(
+	t *testing.T,
+) {
+	t.Parallel()
+	m := test.CreateMint(t)
+	defer m.Close()
+	admin := m.CreateAdmin(t)
+	u := m.CreateUser(t)
+
+	status, raw := admin.Post(t,
+		fmt.Sprintf("/admin/users/%s/disable", u.Username), nil)
+	assert.Equal(t, 200, status)
+	var user mint.UserResource
+	err := raw.Extract("user", &user)
+	assert.Nil(t, err)
+	assert.Equal(t, mint.UsStDisabled, user.Status)
+
+	status, raw = u.Get(t, "/assets")
+	assert.Equal(t, 403, status)
+	assert.Equal(t, "user_disabled", errorCode(t, raw))
+
+	status, _ = admin.Post(t,
+		fmt.Sprintf("/admin/users/%s/enable", u.Username), nil)
+	assert.Equal(t, 200, status)
+
+	status, _ = u.Get(t, "/assets")
+	assert.Equal(t, 200, status)
+
+	status, _ = admin.Post(t,
+		fmt.Sprintf("/admin/users/%s/password", u.Username),
+		url.Values{"password": {"newpassword"}})
+	assert.Equal(t, 200, status)
+
+	status, raw = u.Get(t, "/assets")
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "password_invalid", errorCode(t, raw))
+
+	u.Password = "newpassword"
+	status, _ = u.Get(t, "/assets")
+	assert.Equal(t, 200, status)
+
+	status, raw = admin.Post(t,
+		fmt.Sprintf("/admin/users/%s/disable", admin.Username), nil)
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "user_invalid", errorCode(t, raw))
+
+	status, raw = admin.Post(t, "/admin/users/unknown/disable", nil)
+	assert.Equal(t, 404, status)
+	assert.Equal(t, "user_not_found", errorCode(t, raw))
+}
+
+func TestAdminUsersAssetsAndOffers(
+	t *testing.T,
+) {
+	t.Parallel()
+	m := test.CreateMint(t)
+	defer m.Close()
+	admin := m.CreateAdmin(t)
+	u := m.CreateUser(t)
+
+	a := u.CreateAsset(t, "USD", 2)
+	b := u.CreateAsset(t, "EUR", 2)
+	o := u.CreateOffer(t,
+		fmt.Sprintf("%s/%s", a.Name, b.Name), "100/100", big.NewInt(10))
+
+	status, raw := admin.Get(t,
+		fmt.Sprintf("/admin/users/%s/assets", u.Username))
+	assert.Equal(t, 200, status)
+	var assets []mint.AssetResource
+	err := raw.Extract("assets", &assets)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(assets))
+	assert.Equal(t, b.ID, assets[0].ID)
+	assert.Equal(t, a.ID, assets[1].ID)
+
+	status, raw = admin.Get(t,
+		fmt.Sprintf("/admin/users/%s/offers", u.Username))
+	assert.Equal(t, 200, status)
+	var offers []mint.OfferResource
+	err = raw.Extract("offers", &offers)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(offers))
+	assert.Equal(t, o.ID, offers[0].ID)
+}
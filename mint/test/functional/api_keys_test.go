@@ -0,0 +1,213 @@
+package functional
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/model"
+	"github.com/spolu/settle/mint/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func createAPIKey(
+	t *testing.T,
+	u *test.MintUser,
+	params url.Values,
+) mint.APIKeyResource {
+	status, raw := u.Post(t, "/keys", params)
+	assert.Equal(t, 201, status)
+
+	var key mint.APIKeyResource
+	if err := raw.Extract("api_key", &key); err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func requestWithKey(
+	t *testing.T,
+	m *test.Mint,
+	key string,
+	method string,
+	path string,
+	params url.Values,
+) (int, svc.Resp) {
+	req, err := http.NewRequest(method,
+		fmt.Sprintf("%s%s", m.Server.URL, path),
+		strings.NewReader(params.Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer "+key)
+
+	r, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Body.Close()
+
+	var raw svc.Resp
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+		t.Fatal(err)
+	}
+
+	return r.StatusCode, raw
+}
+
+func errorCode(
+	t *testing.T,
+	raw svc.Resp,
+) string {
+	var e errors.ConcreteUserError
+	if err := raw.Extract("error", &e); err != nil {
+		t.Fatal(err)
+	}
+	return e.ErrCode
+}
+
+func TestAPIKeysScopes(
+	t *testing.T,
+) {
+	t.Parallel()
+	m := test.CreateMint(t)
+	defer m.Close()
+	u := m.CreateUser(t)
+
+	a := u.CreateAsset(t, "USD", 2)
+
+	key := createAPIKey(t, u, url.Values{
+		"name":     {"reader"},
+		"scopes[]": {string(mint.KyScRead)},
+	})
+	assert.Equal(t, "reader", key.Name)
+	assert.Equal(t, u.Address, key.Owner)
+	assert.Equal(t, []mint.KyScope{mint.KyScRead}, key.Scopes)
+	assert.Nil(t, key.LastUsed)
+	assert.NotNil(t, key.Key)
+
+	status, raw := requestWithKey(t, m, *key.Key, "GET", "/assets", nil)
+	assert.Equal(t, 200, status)
+	var assets []mint.AssetResource
+	err := raw.Extract("assets", &assets)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(assets))
+	assert.Equal(t, a.ID, assets[0].ID)
+
+	status, raw = requestWithKey(t, m, *key.Key, "POST", "/offers",
+		url.Values{
+			"pair":   {fmt.Sprintf("%s/%s", a.Name, a.Name)},
+			"price":  {"100/100"},
+			"amount": {"10"},
+		})
+	assert.Equal(t, 403, status)
+	assert.Equal(t, "api_key_scope_insufficient", errorCode(t, raw))
+
+	// Key management requires the user password.
+	status, raw = requestWithKey(t, m, *key.Key, "GET", "/keys", nil)
+	assert.Equal(t, 403, status)
+	assert.Equal(t, "api_key_scope_insufficient", errorCode(t, raw))
+
+	status, raw = requestWithKey(t, m, *key.Key+"x", "GET", "/assets", nil)
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "api_key_invalid", errorCode(t, raw))
+
+	status, raw = u.Get(t, "/keys")
+	assert.Equal(t, 200, status)
+	var keys []mint.APIKeyResource
+	err = raw.Extract("api_keys", &keys)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(keys))
+	assert.Equal(t, key.ID, keys[0].ID)
+	assert.NotNil(t, keys[0].LastUsed)
+	assert.Nil(t, keys[0].Key)
+
+	status, raw = u.Post(t, fmt.Sprintf("/keys/%s/revoke", key.ID), nil)
+	assert.Equal(t, 200, status)
+	var revoked mint.APIKeyResource
+	err = raw.Extract("api_key", &revoked)
+	assert.Nil(t, err)
+	assert.NotNil(t, revoked.Revoked)
+
+	status, raw = requestWithKey(t, m, *key.Key, "GET", "/assets", nil)
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "api_key_invalid", errorCode(t, raw))
+}
+
+func TestAPIKeysPayLimit(
+	t *testing.T,
+) {
+	t.Parallel()
+	m := test.CreateMint(t)
+	defer m.Close()
+	u := m.CreateUser(t)
+	d := m.CreateUser(t)
+
+	a := u.CreateAsset(t, "USD", 2)
+
+	key := createAPIKey(t, u, url.Values{
+		"name":      {"payer"},
+		"scopes[]":  {string(mint.KyScPay)},
+		"pay_asset": {a.Name},
+		"pay_limit": {"15"},
+	})
+	assert.Equal(t, a.Name, *key.PayAsset)
+	assert.Equal(t, "15", key.PayLimit.String())
+
+	pay := func(amount string) (int, svc.Resp) {
+		return requestWithKey(t, m, *key.Key, "POST", "/transactions",
+			url.Values{
+				"pair":        {fmt.Sprintf("%s/%s", a.Name, a.Name)},
+				"amount":      {amount},
+				"destination": {d.Address},
+			})
+	}
+
+	status, _ := pay("10")
+	assert.Equal(t, 201, status)
+
+	// A payment recorded with a stale copy of the key conflicts instead of
+	// overwriting the amount spent.
+	token := strings.Split(*key.Key, ".")[0]
+	k1, err := model.LoadAPIKeyByToken(m.Ctx, token)
+	assert.Nil(t, err)
+	k2, err := model.LoadAPIKeyByToken(m.Ctx, token)
+	assert.Nil(t, err)
+	assert.Nil(t, k1.Spend(m.Ctx, a.Name, big.NewInt(1)))
+	err = k2.Spend(m.Ctx, a.Name, big.NewInt(1))
+	assert.IsType(t, model.ErrConcurrentModification{}, errors.Cause(err))
+
+	status, raw := pay("10")
+	assert.Equal(t, 403, status)
+	assert.Equal(t, "api_key_pay_limit_exceeded", errorCode(t, raw))
+
+	status, _ = pay("4")
+	assert.Equal(t, 201, status)
+
+	status, _ = pay("1")
+	assert.Equal(t, 403, status)
+}
+
+func TestAPIKeysInvalidScope(
+	t *testing.T,
+) {
+	t.Parallel()
+	m := test.CreateMint(t)
+	defer m.Close()
+	u := m.CreateUser(t)
+
+	status, raw := u.Post(t, "/keys", url.Values{
+		"name":     {"admin"},
+		"scopes[]": {"admin"},
+	})
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "scope_invalid", errorCode(t, raw))
+}
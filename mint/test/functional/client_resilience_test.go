@@ -0,0 +1,189 @@
+package functional
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/test"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupPeer creates a test mint along with a fake peer mint whose offer
+// requests are served by the provided handler and counted. The fake peer does
+// not serve any discovery document.
+func setupPeer(
+	t *testing.T,
+	handler func(w http.ResponseWriter, r *http.Request),
+) (*test.Mint, *httptest.Server, *int32) {
+	m := test.CreateMint(t)
+	m.Env.Config[mint.EnvCfgClientRetries] = "0"
+
+	count := int32(0)
+	peer := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == mint.DiscoveryPath {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			atomic.AddInt32(&count, 1)
+			handler(w, r)
+		}))
+
+	return m, peer, &count
+}
+
+func offerNotFound(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNotFound)
+	fmt.Fprint(w,
+		`{"error":{"code":"offer_not_found","message":"Not found."}}`)
+}
+
+func retrievePeerOffer(
+	t *testing.T,
+	m *test.Mint,
+	peer *httptest.Server,
+) error {
+	client := &mint.Client{}
+	err := client.Init(m.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.RetrieveOffer(m.Ctx,
+		fmt.Sprintf("foo@%s[offer_test]", peer.URL[7:]))
+	return err
+}
+
+func TestClientTimeout(
+	t *testing.T,
+) {
+	t.Parallel()
+	done := make(chan struct{})
+	m, peer, _ := setupPeer(t,
+		func(w http.ResponseWriter, r *http.Request) {
+			<-done
+		})
+	defer m.Close()
+	defer peer.Close()
+	defer close(done)
+
+	m.Env.Config[mint.EnvCfgClientTimeoutMs] = "100"
+
+	start := time.Now()
+	err := retrievePeerOffer(t, m, peer)
+	assert.NotNil(t, err)
+	assert.True(t, time.Now().Sub(start) < 5*time.Second)
+}
+
+func TestClientRetries(
+	t *testing.T,
+) {
+	t.Parallel()
+	attempts := int32(0)
+	m, peer, count := setupPeer(t,
+		func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			offerNotFound(w, r)
+		})
+	defer m.Close()
+	defer peer.Close()
+
+	m.Env.Config[mint.EnvCfgClientRetries] = "2"
+
+	err := retrievePeerOffer(t, m, peer)
+	assert.NotNil(t, err)
+	e, ok := errors.Cause(err).(mint.ErrMintClient)
+	assert.True(t, ok)
+	assert.Equal(t, "offer_not_found", e.ErrCode)
+	assert.Equal(t, int32(2), atomic.LoadInt32(count))
+}
+
+func TestClientRetriesCanceled(
+	t *testing.T,
+) {
+	t.Parallel()
+	var cancel context.CancelFunc
+	m, peer, count := setupPeer(t,
+		func(w http.ResponseWriter, r *http.Request) {
+			cancel()
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+	defer m.Close()
+	defer peer.Close()
+
+	m.Env.Config[mint.EnvCfgClientRetries] = "10"
+
+	ctx, cancel := context.WithCancel(m.Ctx)
+	defer cancel()
+
+	client := &mint.Client{}
+	err := client.Init(m.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The retries stop as soon as the context is canceled instead of waiting
+	// for their backoff.
+	start := time.Now()
+	_, err = client.RetrieveOffer(ctx,
+		fmt.Sprintf("foo@%s[offer_test]", peer.URL[7:]))
+	assert.Equal(t, context.Canceled, errors.Cause(err))
+	assert.Equal(t, int32(1), atomic.LoadInt32(count))
+	assert.True(t, time.Now().Sub(start) < 5*time.Second)
+}
+
+func TestClientCircuitBreaker(
+	t *testing.T,
+) {
+	t.Parallel()
+	healthy := int32(0)
+	m, peer, count := setupPeer(t,
+		func(w http.ResponseWriter, r *http.Request) {
+			if atomic.LoadInt32(&healthy) == 0 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			offerNotFound(w, r)
+		})
+	defer m.Close()
+	defer peer.Close()
+
+	m.Env.Config[mint.EnvCfgBreakerFailures] = "3"
+	m.Env.Config[mint.EnvCfgBreakerProbeMs] = "200"
+
+	for i := 0; i < 3; i++ {
+		err := retrievePeerOffer(t, m, peer)
+		assert.NotNil(t, err)
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(count))
+	assert.Equal(t, mint.BkStOpen, mint.BreakerState(peer.URL[7:]))
+
+	// The breaker fails fast without contacting the peer.
+	err := retrievePeerOffer(t, m, peer)
+	_, ok := errors.Cause(err).(mint.ErrCircuitOpen)
+	assert.True(t, ok)
+	assert.Equal(t, int32(3), atomic.LoadInt32(count))
+
+	// Once the probe interval has elapsed, a successful probe closes it.
+	atomic.StoreInt32(&healthy, 1)
+	time.Sleep(300 * time.Millisecond)
+
+	err = retrievePeerOffer(t, m, peer)
+	_, ok = errors.Cause(err).(mint.ErrMintClient)
+	assert.True(t, ok)
+	assert.Equal(t, int32(4), atomic.LoadInt32(count))
+	assert.Equal(t, mint.BkStClosed, mint.BreakerState(peer.URL[7:]))
+}
@@ -0,0 +1,165 @@
+package functional
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/spolu/settle/lib/env"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/async"
+	"github.com/spolu/settle/mint/model"
+	"github.com/spolu/settle/mint/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupDiscovery(
+	t *testing.T,
+) ([]*test.Mint, []*test.MintUser) {
+	m := []*test.Mint{
+		test.CreateMint(t),
+		test.CreateMint(t),
+	}
+	u := []*test.MintUser{
+		m[0].CreateUser(t),
+		m[1].CreateUser(t),
+	}
+	u[0].CreateAsset(t, "USD", 2)
+	u[1].CreateAsset(t, "USD", 2)
+
+	return m, u
+}
+
+func tearDownDiscovery(
+	t *testing.T,
+	mints []*test.Mint,
+) {
+	for _, m := range mints {
+		m.Close()
+	}
+}
+
+func TestDiscoveryRetrieveMint(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, _ := setupDiscovery(t)
+	defer tearDownDiscovery(t, m)
+
+	status, raw := m[0].Get(t, nil, mint.DiscoveryPath)
+
+	var doc mint.MintResource
+	err := raw.Extract("mint", &doc)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 200, status)
+	assert.Equal(t, mint.GetHost(m[0].Ctx), doc.Host)
+	assert.Equal(t, m[0].Server.URL, doc.APIBaseURL)
+	assert.Equal(t, mint.ProtocolVersions, doc.ProtocolVersions)
+	assert.Contains(t, doc.Features, mint.FtSignedRequests)
+	assert.Equal(t, 1, len(doc.Keys))
+	assert.Equal(t, mint.KeyAlgorithm, doc.Keys[0].Algorithm)
+}
+
+func TestDiscoveryAPIBaseURL(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, u := setupDiscovery(t)
+	defer tearDownDiscovery(t, m)
+
+	// Serve m[1] API under a path prefix (as a reverse proxy would) and
+	// advertise it in its discovery document. POST requests are counted to
+	// check that propagations go through the prefix.
+	count := int32(0)
+	proxy := httptest.NewServer(http.StripPrefix("/api",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "POST" {
+				atomic.AddInt32(&count, 1)
+			}
+			m[1].Mux.ServeHTTP(w, r)
+		})))
+	defer proxy.Close()
+	m[1].Env.Config[mint.EnvCfgAPIBaseURL] = proxy.URL + "/api"
+
+	c := &mint.Client{}
+	err := c.Init(m[0].Ctx)
+	assert.Nil(t, err)
+
+	doc, err := c.Discover(m[0].Ctx, mint.GetHost(m[1].Ctx))
+	assert.Nil(t, err)
+	assert.Equal(t, proxy.URL+"/api", doc.APIBaseURL)
+
+	assert.Equal(t,
+		proxy.URL+"/api/offers",
+		mint.FullMintURL(m[0].Ctx, mint.GetHost(m[1].Ctx),
+			"/offers", nil).String())
+
+	// Propagate (signed) an offer from m[0] to m[1] through the prefix.
+	offer := u[0].CreateOffer(t,
+		fmt.Sprintf("%s[USD.2]/%s[USD.2]", u[0].Address, u[1].Address),
+		"100/100", big.NewInt(100))
+	async.TestRunOne(m[0].Ctx)
+
+	of, err := model.LoadPropagatedOfferByID(m[1].Ctx, offer.ID)
+	assert.Nil(t, err)
+	assert.NotNil(t, of)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&count))
+}
+
+func TestDiscoveryAPIBaseURLForeignHost(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, _ := setupDiscovery(t)
+	defer tearDownDiscovery(t, m)
+
+	m[1].Env.Config[mint.EnvCfgAPIBaseURL] = "http://example.com/api"
+
+	c := &mint.Client{}
+	err := c.Init(m[0].Ctx)
+	assert.Nil(t, err)
+
+	// A mint can't redirect requests to a host that is not its own.
+	_, err = c.Discover(m[0].Ctx, mint.GetHost(m[1].Ctx))
+	assert.NotNil(t, err)
+	assert.Nil(t, mint.CachedDiscovery(mint.GetHost(m[1].Ctx)))
+
+	// Unless the host is explicitly allowed.
+	m[0].Env.Config[mint.EnvCfgAPIHostAllowList] = "*.net,example.com"
+
+	doc, err := c.Discover(m[0].Ctx, mint.GetHost(m[1].Ctx))
+	assert.Nil(t, err)
+	assert.Equal(t, "http://example.com/api", doc.APIBaseURL)
+}
+
+func TestDiscoveryAPIBaseURLScheme(
+	t *testing.T,
+) {
+	t.Parallel()
+	ctx := env.With(context.Background(), &env.Env{
+		Environment: env.Production,
+		Config:      map[env.ConfigKey]string{},
+	})
+
+	// Plain HTTP is only accepted in QA.
+	assert.NotNil(t, mint.CheckAPIBaseURL(ctx,
+		"mint.example.com", "http://mint.example.com/api"))
+	assert.NotNil(t, mint.CheckAPIBaseURL(ctx,
+		"mint.example.com", "ftp://mint.example.com/api"))
+	assert.Nil(t, mint.CheckAPIBaseURL(ctx,
+		"mint.example.com", "https://mint.example.com:8443/api"))
+	assert.NotNil(t, mint.CheckAPIBaseURL(ctx,
+		"mint.example.com", "https://example.com/api"))
+
+	qa := env.With(context.Background(), &env.Env{
+		Environment: env.QA,
+		Config:      map[env.ConfigKey]string{},
+	})
+	assert.Nil(t, mint.CheckAPIBaseURL(qa,
+		"127.0.0.1:2406", "http://127.0.0.1:8080/api"))
+}
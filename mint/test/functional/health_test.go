@@ -0,0 +1,98 @@
+package functional
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spolu/settle/lib/health"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/async"
+	"github.com/spolu/settle/mint/async/task"
+	"github.com/spolu/settle/mint/model"
+	"github.com/spolu/settle/mint/test"
+	"github.com/stretchr/testify/assert"
+)
+
+// getHealth retrieves the health of a mint from the provided path.
+func getHealth(
+	t *testing.T,
+	m *test.Mint,
+	path string,
+) (int, map[string]health.Check) {
+	status, raw := m.Get(t, nil, path)
+
+	var h mint.HealthResource
+	err := raw.Extract("health", &h)
+	assert.Nil(t, err)
+
+	checks := map[string]health.Check{}
+	for _, c := range h.Checks {
+		checks[c.Name] = c
+	}
+	if status == 200 {
+		assert.Equal(t, health.HlStOK, h.Status)
+	} else {
+		assert.Equal(t, health.HlStFailing, h.Status)
+	}
+	return status, checks
+}
+
+func TestHealth(
+	t *testing.T,
+) {
+	t.Parallel()
+	m := test.CreateMint(t)
+	defer m.Close()
+
+	// No worker has run yet.
+	status, checks := getHealth(t, m, "/healthz")
+	assert.Equal(t, 503, status)
+	assert.Equal(t, health.HlStOK, checks["db.mint"].Status)
+	assert.Equal(t, health.HlStFailing, checks["async.worker"].Status)
+	assert.Equal(t, "none", checks["async.worker"].Details["last_run"])
+
+	async.TestRunOne(m.Ctx)
+
+	status, checks = getHealth(t, m, "/healthz")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, health.HlStOK, checks["async.worker"].Status)
+	_, ok := checks["async.tasks"]
+	assert.False(t, ok)
+
+	status, checks = getHealth(t, m, "/readyz")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, health.HlStOK, checks["db.mint"].Status)
+	assert.Equal(t, health.HlStOK, checks["async.worker"].Status)
+	assert.Equal(t, health.HlStOK, checks["async.tasks"].Status)
+	assert.Equal(t, "0", checks["async.tasks"].Details["failed"])
+	_, ok = checks["certificate"]
+	assert.False(t, ok)
+	assert.Contains(t, getMetrics(t, m), "mint_async_failed_tasks 0\n")
+
+	// Failed tasks above the threshold make the mint not ready but alive.
+	for i := 0; i <= mint.HealthMaxFailedTasks; i++ {
+		_, err := model.CreateTask(m.Ctx, time.Now(), task.TkPropagateOffer,
+			"subject", mint.TkStFailed, 0, "")
+		assert.Nil(t, err)
+	}
+
+	status, checks = getHealth(t, m, "/readyz")
+	assert.Equal(t, 503, status)
+	assert.Equal(t, health.HlStFailing, checks["async.tasks"].Status)
+	assert.Equal(t, health.HlStOK, checks["db.mint"].Status)
+	assert.Equal(t, "10", checks["async.tasks"].Details["max_failed"])
+
+	// The count cached by the readiness check is reported by the metrics.
+	assert.Contains(t, getMetrics(t, m), "mint_async_failed_tasks 11\n")
+
+	// The threshold is configurable.
+	m.Env.Config[mint.EnvCfgHealthMaxFailedTasks] = "20"
+
+	status, checks = getHealth(t, m, "/readyz")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, health.HlStOK, checks["async.tasks"].Status)
+	assert.Equal(t, "20", checks["async.tasks"].Details["max_failed"])
+
+	status, _ = getHealth(t, m, "/healthz")
+	assert.Equal(t, 200, status)
+}
@@ -0,0 +1,194 @@
+package functional
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func postIdempotent(
+	t *testing.T,
+	u *test.MintUser,
+	key string,
+	path string,
+	params url.Values,
+) (int, http.Header, svc.Resp) {
+	req, err := http.NewRequest("POST",
+		fmt.Sprintf("%s%s", u.Mint.Server.URL, path),
+		strings.NewReader(params.Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set(mint.HeaderIdempotencyKey, key)
+	req.SetBasicAuth(u.Username, u.Password)
+
+	r, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Body.Close()
+
+	var raw svc.Resp
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+		t.Fatal(err)
+	}
+
+	return r.StatusCode, r.Header, raw
+}
+
+func TestIdempotencyKeyReplay(
+	t *testing.T,
+) {
+	t.Parallel()
+	m := test.CreateMint(t)
+	defer m.Close()
+	u := m.CreateUser(t)
+
+	a := u.CreateAsset(t, "USD", 2)
+	params := url.Values{
+		"pair":        {fmt.Sprintf("%s/%s", a.Name, a.Name)},
+		"amount":      {"10"},
+		"destination": {fmt.Sprintf("dest@%s", m.Env.Config[mint.EnvCfgHost])},
+	}
+
+	status, header, raw := postIdempotent(t, u, "tx-1", "/transactions", params)
+	assert.Equal(t, 201, status)
+	assert.Equal(t, "", header.Get("Idempotent-Replayed"))
+	var tx0 mint.TransactionResource
+	err := raw.Extract("transaction", &tx0)
+	assert.Nil(t, err)
+
+	status, header, raw = postIdempotent(t, u, "tx-1", "/transactions", params)
+	assert.Equal(t, 201, status)
+	assert.Equal(t, "true", header.Get("Idempotent-Replayed"))
+	var tx1 mint.TransactionResource
+	err = raw.Extract("transaction", &tx1)
+	assert.Nil(t, err)
+	assert.Equal(t, tx0.ID, tx1.ID)
+
+	// A different key creates a different transaction.
+	status, _, raw = postIdempotent(t, u, "tx-2", "/transactions", params)
+	assert.Equal(t, 201, status)
+	var tx2 mint.TransactionResource
+	err = raw.Extract("transaction", &tx2)
+	assert.Nil(t, err)
+	assert.NotEqual(t, tx0.ID, tx2.ID)
+
+	// Keys are scoped per user.
+	v := m.CreateUser(t)
+	status, _, raw = postIdempotent(t, v, "tx-1", "/assets", url.Values{
+		"code":  {"EUR"},
+		"scale": {"2"},
+	})
+	assert.Equal(t, 201, status)
+}
+
+func TestIdempotencyKeyReused(
+	t *testing.T,
+) {
+	t.Parallel()
+	m := test.CreateMint(t)
+	defer m.Close()
+	u := m.CreateUser(t)
+
+	a := u.CreateAsset(t, "USD", 2)
+	b := u.CreateAsset(t, "EUR", 2)
+	params := url.Values{
+		"pair":   {fmt.Sprintf("%s/%s", a.Name, b.Name)},
+		"price":  {"100/100"},
+		"amount": {big.NewInt(10).String()},
+	}
+
+	status, _, _ := postIdempotent(t, u, "offer-1", "/offers", params)
+	assert.Equal(t, 201, status)
+
+	params.Set("amount", "20")
+	status, _, raw := postIdempotent(t, u, "offer-1", "/offers", params)
+	assert.Equal(t, 422, status)
+	assert.Equal(t, "idempotency_key_reused", errorCode(t, raw))
+
+	// Requests that fail to validate release their key.
+	status, _, raw = postIdempotent(t, u, "asset-1", "/assets", url.Values{
+		"code":  {"USD"},
+		"scale": {"foo"},
+	})
+	assert.Equal(t, 400, status)
+
+	status, _, _ = postIdempotent(t, u, "asset-1", "/assets", url.Values{
+		"code":  {"GBP"},
+		"scale": {"2"},
+	})
+	assert.Equal(t, 201, status)
+
+	// Requests that fail once executed replay their error.
+	status, _, raw = postIdempotent(t, u, "asset-2", "/assets", url.Values{
+		"code":  {"USD"},
+		"scale": {"2"},
+	})
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "asset_already_exists", errorCode(t, raw))
+
+	status, header, raw := postIdempotent(t, u, "asset-2", "/assets",
+		url.Values{
+			"code":  {"USD"},
+			"scale": {"2"},
+		})
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "true", header.Get("Idempotent-Replayed"))
+	assert.Equal(t, "asset_already_exists", errorCode(t, raw))
+}
+
+func TestIdempotencyKeyFailedPropagation(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, u, _, o := setupCreateTransactionFailure(t)
+	defer tearDownCreateTransactionFailure(t, m)
+
+	// Transaction propagation to m[1] fails once the transaction is created
+	// on m[0].
+	m[1].Mux.Use(func(inner http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "POST" &&
+				strings.HasPrefix(r.URL.Path, "/transactions/") {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			inner.ServeHTTP(w, r)
+		})
+	})
+
+	params := url.Values{
+		"pair": {fmt.Sprintf("%s[USD.2]/%s[USD.2]",
+			u[0].Address, u[2].Address)},
+		"amount":      {"10"},
+		"destination": {u[2].Address},
+		"path[]":      {o[1].ID, o[2].ID},
+	}
+
+	status, _, raw := postIdempotent(t, u[0], "tx-1", "/transactions", params)
+	assert.NotEqual(t, 201, status)
+	code := errorCode(t, raw)
+
+	// The retry replays the error instead of creating a second transaction.
+	status2, header, raw := postIdempotent(t, u[0], "tx-1", "/transactions",
+		params)
+	assert.Equal(t, status, status2)
+	assert.Equal(t, "true", header.Get("Idempotent-Replayed"))
+	assert.Equal(t, code, errorCode(t, raw))
+
+	var count int
+	err := m[0].DB.Get(&count, "SELECT COUNT(*) FROM transactions")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+}
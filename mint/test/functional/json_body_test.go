@@ -0,0 +1,83 @@
+package functional
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONBodyTransaction(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, u, a, o := setupCreateTransaction(t)
+	defer tearDownCreateTransaction(t, m)
+
+	// Propagation requests between mints are JSON encoded as well.
+	for _, mm := range m {
+		mm.Env.Config[mint.EnvCfgClientEncoding] = "json"
+	}
+
+	status, raw := m[0].PostJSON(t, u[0], "/transactions", fmt.Sprintf(`{
+		"pair": "%s/%s",
+		"amount": 10,
+		"destination": "%s",
+		"path": ["%s", "%s"]
+	}`, a[0].Name, a[2].Name, u[2].Address, o[1].ID, o[2].ID))
+
+	var tx mint.TransactionResource
+	err := raw.Extract("transaction", &tx)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 201, status)
+	assert.Equal(t, mint.TxStReserved, tx.Status)
+	assert.Equal(t, big.NewInt(10), tx.Amount)
+
+	c := sdkClient(t, u[0])
+	c.JSON = true
+
+	tx0, err := c.SettleTransaction(m[0].Ctx, tx.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, mint.TxStSettled, tx0.Status)
+}
+
+func TestJSONBodyInvalid(
+	t *testing.T,
+) {
+	t.Parallel()
+	m := test.CreateMint(t)
+	defer m.Close()
+
+	u := m.CreateUser(t)
+
+	for _, body := range []string{
+		`{"code": "USD", "scale": `,
+		`["USD", 2]`,
+		`{"code": {"value": "USD"}, "scale": 2}`,
+		`{"code": "USD", "scale": 2} {}`,
+	} {
+		status, raw := m.PostJSON(t, u, "/assets", body)
+
+		var e errors.ConcreteUserError
+		err := raw.Extract("error", &e)
+		assert.Nil(t, err)
+
+		assert.Equal(t, 400, status, body)
+		assert.Equal(t, "body_invalid", e.ErrCode, body)
+	}
+
+	// Numbers are accepted for numeric parameters.
+	status, raw := m.PostJSON(t, u, "/assets", `{"code": "USD", "scale": 2}`)
+
+	var asset mint.AssetResource
+	err := raw.Extract("asset", &asset)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 201, status)
+	assert.Equal(t, int8(2), asset.Scale)
+}
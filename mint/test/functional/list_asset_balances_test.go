@@ -124,3 +124,113 @@ func TestListAssetBalancesUnauthorized(
 	assert.Equal(t, 400, status)
 	assert.Equal(t, "not_authorized", e.ErrCode)
 }
+
+func TestListAssetBalancesSortedByValue(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, u, a := setupListAssetBalances(t)
+	defer tearDownListAssetBalances(t, m)
+
+	status, raw := u[0].Get(t,
+		fmt.Sprintf("/assets/%s/balances?sort=value", a[0].Name))
+	assert.Equal(t, 200, status)
+
+	var balances []mint.BalanceResource
+	err := raw.Extract("balances", &balances)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 2, len(balances))
+	assert.Equal(t, big.NewInt(42), balances[0].Value)
+	assert.Equal(t, big.NewInt(27), balances[1].Value)
+
+	status, raw = u[0].Get(t,
+		fmt.Sprintf("/assets/%s/balances?sort=value&order=asc&limit=1",
+			a[0].Name))
+	assert.Equal(t, 200, status)
+
+	err = raw.Extract("balances", &balances)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1, len(balances))
+	assert.Equal(t, big.NewInt(27), balances[0].Value)
+
+	var next string
+	err = raw.Extract("next_cursor", &next)
+	assert.Nil(t, err)
+
+	status, raw = u[0].Get(t,
+		fmt.Sprintf("/assets/%s/balances?sort=value&order=asc&limit=1"+
+			"&starting_after=%s", a[0].Name, next))
+	assert.Equal(t, 200, status)
+
+	err = raw.Extract("balances", &balances)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1, len(balances))
+	assert.Equal(t, big.NewInt(42), balances[0].Value)
+
+	var more bool
+	err = raw.Extract("has_more", &more)
+	assert.Nil(t, err)
+	assert.False(t, more)
+
+	status, raw = u[0].Get(t,
+		fmt.Sprintf("/assets/%s/balances?sort=price", a[0].Name))
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "sort_invalid", errorCode(t, raw))
+}
+
+func TestListAssetBalancesFiltered(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, u, a := setupListAssetBalances(t)
+	defer tearDownListAssetBalances(t, m)
+
+	status, raw := u[0].Get(t,
+		fmt.Sprintf("/assets/%s/balances?min_value=30", a[0].Name))
+	assert.Equal(t, 200, status)
+
+	var balances []mint.BalanceResource
+	err := raw.Extract("balances", &balances)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1, len(balances))
+	assert.Equal(t, u[1].Address, balances[0].Holder)
+
+	status, raw = u[0].Get(t,
+		fmt.Sprintf("/assets/%s/balances?min_value=27&max_value=27",
+			a[0].Name))
+	assert.Equal(t, 200, status)
+
+	err = raw.Extract("balances", &balances)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1, len(balances))
+	assert.Equal(t, u[2].Address, balances[0].Holder)
+
+	status, raw = u[0].Get(t,
+		fmt.Sprintf("/assets/%s/balances?max_value=9", a[0].Name))
+	assert.Equal(t, 200, status)
+
+	err = raw.Extract("balances", &balances)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 0, len(balances))
+
+	status, raw = u[0].Get(t,
+		fmt.Sprintf("/assets/%s/balances?holder=%s", a[0].Name, u[1].Address))
+	assert.Equal(t, 200, status)
+
+	err = raw.Extract("balances", &balances)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1, len(balances))
+	assert.Equal(t, big.NewInt(42), balances[0].Value)
+
+	status, raw = u[0].Get(t,
+		fmt.Sprintf("/assets/%s/balances?min_value=foo", a[0].Name))
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "amount_invalid", errorCode(t, raw))
+}
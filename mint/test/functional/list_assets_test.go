@@ -125,3 +125,125 @@ func TestListAssetsWithLimitAndCreatedBefore(
 	assert.Equal(t, "KRN", assets[1].Code)
 	assert.Equal(t, int8(2), assets[1].Scale)
 }
+
+func TestListAssetsWithCursors(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, u, a := setupListAssets(t)
+	defer tearDownListAssets(t, m)
+
+	codes := []string{}
+	cursors := []string{}
+	path := "/assets?limit=3"
+	for {
+		status, raw := u[0].Get(t, path)
+		assert.Equal(t, 200, status)
+
+		var assets []mint.AssetResource
+		err := raw.Extract("assets", &assets)
+		assert.Nil(t, err)
+		for _, a := range assets {
+			codes = append(codes, a.Code)
+		}
+
+		var more bool
+		err = raw.Extract("has_more", &more)
+		assert.Nil(t, err)
+		if !more {
+			assert.NotNil(t, raw.Extract("next_cursor", new(string)))
+			break
+		}
+		assert.Equal(t, 3, len(assets))
+
+		var next string
+		err = raw.Extract("next_cursor", &next)
+		assert.Nil(t, err)
+		cursors = append(cursors, next)
+		path = fmt.Sprintf("/assets?limit=3&starting_after=%s", next)
+	}
+
+	assert.Equal(t, len(a), len(codes))
+	for i := range a {
+		assert.Equal(t, a[len(a)-1-i].Code, codes[i])
+	}
+	assert.Equal(t, 2, len(cursors))
+
+	// Page backward from the last page.
+	status, raw := u[0].Get(t,
+		fmt.Sprintf("/assets?limit=2&ending_before=%s", cursors[1]))
+	assert.Equal(t, 200, status)
+
+	var assets []mint.AssetResource
+	err := raw.Extract("assets", &assets)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 2, len(assets))
+	assert.Equal(t, "KRN", assets[0].Code)
+	assert.Equal(t, "GBP", assets[1].Code)
+
+	var more bool
+	err = raw.Extract("has_more", &more)
+	assert.Nil(t, err)
+	assert.True(t, more)
+
+	var next string
+	err = raw.Extract("next_cursor", &next)
+	assert.Nil(t, err)
+
+	status, raw = u[0].Get(t,
+		fmt.Sprintf("/assets?limit=2&ending_before=%s", next))
+	assert.Equal(t, 200, status)
+
+	err = raw.Extract("assets", &assets)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 2, len(assets))
+	assert.Equal(t, "AU-LAIT", assets[0].Code)
+	assert.Equal(t, "NGN", assets[1].Code)
+
+	err = raw.Extract("has_more", &more)
+	assert.Nil(t, err)
+	assert.True(t, more)
+
+	err = raw.Extract("next_cursor", &next)
+	assert.Nil(t, err)
+
+	status, raw = u[0].Get(t,
+		fmt.Sprintf("/assets?limit=2&ending_before=%s", next))
+	assert.Equal(t, 200, status)
+
+	err = raw.Extract("assets", &assets)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1, len(assets))
+	assert.Equal(t, "HOUR-OF-WORK", assets[0].Code)
+
+	err = raw.Extract("has_more", &more)
+	assert.Nil(t, err)
+	assert.False(t, more)
+}
+
+func TestListAssetsWithInvalidCursors(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, u, _ := setupListAssets(t)
+	defer tearDownListAssets(t, m)
+
+	status, raw := u[0].Get(t, "/assets?starting_after=foo")
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "cursor_invalid", errorCode(t, raw))
+
+	status, raw = u[0].Get(t, "/assets?limit=1")
+	assert.Equal(t, 200, status)
+
+	var next string
+	err := raw.Extract("next_cursor", &next)
+	assert.Nil(t, err)
+
+	status, raw = u[0].Get(t, fmt.Sprintf(
+		"/assets?starting_after=%s&ending_before=%s", next, next))
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "cursor_invalid", errorCode(t, raw))
+}
@@ -0,0 +1,306 @@
+package functional
+
+import (
+	"fmt"
+	"math/big"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/model"
+	"github.com/spolu/settle/mint/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupListOffers(
+	t *testing.T,
+) ([]*test.Mint, []*test.MintUser, []mint.AssetResource, []mint.OfferResource) {
+	m := []*test.Mint{
+		test.CreateMint(t),
+	}
+	u := []*test.MintUser{
+		m[0].CreateUser(t),
+		m[0].CreateUser(t),
+	}
+	a := []mint.AssetResource{
+		u[0].CreateAsset(t, "USD", 2),
+		u[0].CreateAsset(t, "EUR", 2),
+		u[0].CreateAsset(t, "GBP", 2),
+		u[1].CreateAsset(t, "USD", 2),
+	}
+
+	o := []mint.OfferResource{
+		u[0].CreateOffer(t,
+			fmt.Sprintf("%s/%s", a[0].Name, a[1].Name),
+			"100/110", big.NewInt(1000)),
+		u[0].CreateOffer(t,
+			fmt.Sprintf("%s/%s", a[0].Name, a[2].Name),
+			"100/80", big.NewInt(200)),
+		u[0].CreateOffer(t,
+			fmt.Sprintf("%s/%s", a[1].Name, a[2].Name),
+			"100/90", big.NewInt(50)),
+		u[1].CreateOffer(t,
+			fmt.Sprintf("%s/%s", a[3].Name, a[0].Name),
+			"100/100", big.NewInt(100)),
+	}
+
+	status, _ := u[0].Post(t,
+		fmt.Sprintf("/offers/%s/close", o[2].ID),
+		url.Values{})
+	assert.Equal(t, 200, status)
+
+	return m, u, a, o
+}
+
+func tearDownListOffers(
+	t *testing.T,
+	mints []*test.Mint,
+) {
+	for _, m := range mints {
+		m.Close()
+	}
+}
+
+func TestListOffersSimple(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, u, _, o := setupListOffers(t)
+	defer tearDownListOffers(t, m)
+
+	status, raw := u[0].Get(t, "/offers")
+	assert.Equal(t, 200, status)
+
+	var offers []mint.OfferResource
+	err := raw.Extract("offers", &offers)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 3, len(offers))
+	assert.Equal(t, o[2].ID, offers[0].ID)
+	assert.Equal(t, o[1].ID, offers[1].ID)
+	assert.Equal(t, o[0].ID, offers[2].ID)
+
+	status, raw = u[1].Get(t, "/offers")
+	assert.Equal(t, 200, status)
+
+	err = raw.Extract("offers", &offers)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1, len(offers))
+	assert.Equal(t, o[3].ID, offers[0].ID)
+}
+
+func TestListOffersFiltered(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, u, _, o := setupListOffers(t)
+	defer tearDownListOffers(t, m)
+
+	status, raw := u[0].Get(t, "/offers?status=active")
+	assert.Equal(t, 200, status)
+
+	var offers []mint.OfferResource
+	err := raw.Extract("offers", &offers)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 2, len(offers))
+	assert.Equal(t, o[1].ID, offers[0].ID)
+	assert.Equal(t, o[0].ID, offers[1].ID)
+
+	status, raw = u[0].Get(t, "/offers?status=closed")
+	assert.Equal(t, 200, status)
+
+	err = raw.Extract("offers", &offers)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1, len(offers))
+	assert.Equal(t, o[2].ID, offers[0].ID)
+
+	status, raw = u[0].Get(t, "/offers?asset_code=GBP&status=active")
+	assert.Equal(t, 200, status)
+
+	err = raw.Extract("offers", &offers)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1, len(offers))
+	assert.Equal(t, o[1].ID, offers[0].ID)
+
+	status, raw = u[0].Get(t, "/offers?min_remainder=100&max_remainder=999")
+	assert.Equal(t, 200, status)
+
+	err = raw.Extract("offers", &offers)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1, len(offers))
+	assert.Equal(t, o[1].ID, offers[0].ID)
+
+	status, raw = u[0].Get(t, "/offers?status=pending")
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "status_invalid", errorCode(t, raw))
+
+	status, raw = u[0].Get(t, "/offers?asset_code=usd")
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "asset_code_invalid", errorCode(t, raw))
+}
+
+func TestListOffersSorted(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, u, _, o := setupListOffers(t)
+	defer tearDownListOffers(t, m)
+
+	status, raw := u[0].Get(t, "/offers?sort=price&order=asc")
+	assert.Equal(t, 200, status)
+
+	var offers []mint.OfferResource
+	err := raw.Extract("offers", &offers)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 3, len(offers))
+	assert.Equal(t, o[0].ID, offers[0].ID)
+	assert.Equal(t, o[2].ID, offers[1].ID)
+	assert.Equal(t, o[1].ID, offers[2].ID)
+
+	status, raw = u[0].Get(t, "/offers?sort=remainder&limit=2")
+	assert.Equal(t, 200, status)
+
+	err = raw.Extract("offers", &offers)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 2, len(offers))
+	assert.Equal(t, o[0].ID, offers[0].ID)
+	assert.Equal(t, o[1].ID, offers[1].ID)
+
+	var next string
+	err = raw.Extract("next_cursor", &next)
+	assert.Nil(t, err)
+
+	status, raw = u[0].Get(t,
+		fmt.Sprintf("/offers?sort=remainder&limit=2&starting_after=%s", next))
+	assert.Equal(t, 200, status)
+
+	err = raw.Extract("offers", &offers)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1, len(offers))
+	assert.Equal(t, o[2].ID, offers[0].ID)
+
+	var more bool
+	err = raw.Extract("has_more", &more)
+	assert.Nil(t, err)
+	assert.False(t, more)
+
+	status, raw = u[0].Get(t,
+		fmt.Sprintf("/offers?sort=remainder&limit=1&ending_before=%s", next))
+	assert.Equal(t, 200, status)
+
+	err = raw.Extract("offers", &offers)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1, len(offers))
+	assert.Equal(t, o[0].ID, offers[0].ID)
+
+	// A cursor taken from a list with another sort is rejected.
+	status, raw = u[0].Get(t,
+		fmt.Sprintf("/offers?sort=price&starting_after=%s", next))
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "cursor_invalid", errorCode(t, raw))
+
+	status, raw = u[0].Get(t,
+		fmt.Sprintf("/offers?starting_after=%s", next))
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "cursor_invalid", errorCode(t, raw))
+}
+
+func TestListOffersSortedCursorLeftList(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, u, _, o := setupListOffers(t)
+	defer tearDownListOffers(t, m)
+
+	status, raw := u[0].Get(t,
+		"/offers?status=active&sort=price&order=asc&limit=1")
+	assert.Equal(t, 200, status)
+
+	var offers []mint.OfferResource
+	err := raw.Extract("offers", &offers)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1, len(offers))
+	assert.Equal(t, o[0].ID, offers[0].ID)
+
+	var next string
+	err = raw.Extract("next_cursor", &next)
+	assert.Nil(t, err)
+
+	status, _ = u[0].Post(t,
+		fmt.Sprintf("/offers/%s/close", o[0].ID),
+		url.Values{})
+	assert.Equal(t, 200, status)
+
+	// Paging seeks on the price of the cursor offer although it is not
+	// active anymore.
+	status, raw = u[0].Get(t, fmt.Sprintf(
+		"/offers?status=active&sort=price&order=asc&starting_after=%s", next))
+	assert.Equal(t, 200, status)
+
+	err = raw.Extract("offers", &offers)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1, len(offers))
+	assert.Equal(t, o[1].ID, offers[0].ID)
+}
+
+func TestListOffersSortedExactPrice(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, u, a, o := setupListOffers(t)
+	defer tearDownListOffers(t, m)
+
+	// Prices that can't be told apart as floating point numbers are still
+	// sorted exactly.
+	high := u[1].CreateOffer(t,
+		fmt.Sprintf("%s/%s", a[3].Name, a[1].Name),
+		"100000000000000000001/100000000000000000000", big.NewInt(100))
+	low := u[1].CreateOffer(t,
+		fmt.Sprintf("%s/%s", a[3].Name, a[2].Name),
+		"99999999999999999999/100000000000000000000", big.NewInt(100))
+
+	status, raw := u[1].Get(t, "/offers?sort=price&order=asc")
+	assert.Equal(t, 200, status)
+
+	var offers []mint.OfferResource
+	err := raw.Extract("offers", &offers)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 3, len(offers))
+	assert.Equal(t, low.ID, offers[0].ID)
+	assert.Equal(t, o[3].ID, offers[1].ID)
+	assert.Equal(t, high.ID, offers[2].ID)
+}
+
+func TestListOffersAssetCodePattern(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, u, _, _ := setupListOffers(t)
+	defer tearDownListOffers(t, m)
+
+	// LIKE wildcards in asset codes are matched literally.
+	for _, code := range []string{"U_D", "%", "US%"} {
+		offers, _, err := model.LoadCanonicalOfferListByOwner(m[0].Ctx,
+			model.Page{
+				CreatedBefore: time.Now(),
+				Limit:         10,
+				Sort:          model.Sort{Key: model.SrKeyCreated},
+			},
+			model.OfferFilter{AssetCode: &code}, u[0].Address)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(offers))
+	}
+}
@@ -0,0 +1,116 @@
+package functional
+
+import (
+	"fmt"
+	"math/big"
+	"net/url"
+	"testing"
+
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupListPeers(
+	t *testing.T,
+) ([]*test.Mint, []*test.MintUser) {
+	m := []*test.Mint{
+		test.CreateMint(t),
+		test.CreateMint(t),
+	}
+	u := []*test.MintUser{
+		m[0].CreateUser(t),
+		m[1].CreateUser(t),
+	}
+	u[0].CreateAsset(t, "USD", 2)
+	u[1].CreateAsset(t, "USD", 2)
+
+	return m, u
+}
+
+func tearDownListPeers(
+	t *testing.T,
+	mints []*test.Mint,
+) {
+	for _, m := range mints {
+		m.Close()
+	}
+}
+
+func TestListPeers(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, u := setupListPeers(t)
+	defer tearDownListPeers(t, m)
+
+	// Propagate an offer from each mint to the other, which then retrieves it
+	// along with the identity key of the emitting mint to verify its
+	// signature.
+	offer := u[0].CreateOffer(t,
+		fmt.Sprintf("%s[USD.2]/%s[USD.2]", u[0].Address, u[1].Address),
+		"100/100", big.NewInt(100))
+	status, _ := m[1].PostFrom(t, m[0],
+		fmt.Sprintf("/offers/%s", offer.ID), url.Values{})
+	assert.Equal(t, 201, status)
+
+	offer = u[1].CreateOffer(t,
+		fmt.Sprintf("%s[USD.2]/%s[USD.2]", u[1].Address, u[0].Address),
+		"100/100", big.NewInt(100))
+	status, _ = m[0].PostFrom(t, m[1],
+		fmt.Sprintf("/offers/%s", offer.ID), url.Values{})
+	assert.Equal(t, 201, status)
+
+	for i, host := range []string{
+		mint.GetHost(m[1].Ctx), mint.GetHost(m[0].Ctx),
+	} {
+		status, raw := u[i].Get(t, "/peers")
+
+		var peers []mint.PeerResource
+		err := raw.Extract("peers", &peers)
+		assert.Nil(t, err)
+
+		assert.Equal(t, 200, status)
+		assert.Equal(t, 1, len(peers))
+		assert.Equal(t, host, peers[0].Host)
+		assert.Equal(t, uint(0), peers[0].Failures)
+		assert.Equal(t, mint.ProtocolVersion, *peers[0].ProtocolVersion)
+		assert.Equal(t, mint.BkStClosed, peers[0].Breaker)
+		assert.True(t, peers[0].FirstContact <= peers[0].LastContact)
+		assert.NotNil(t, peers[0].Latency.P50)
+		assert.NotNil(t, peers[0].Latency.P99)
+	}
+}
+
+func TestListPeersUnauthenticated(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, _ := setupListPeers(t)
+	defer tearDownListPeers(t, m)
+
+	status, _ := m[0].Get(t, nil, "/peers")
+	assert.Equal(t, 400, status)
+}
+
+func TestListPeersUnsignedPropagation(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, u := setupListPeers(t)
+	defer tearDownListPeers(t, m)
+
+	// An unsigned propagation claiming to originate from an unknown mint does
+	// not insert it in the peer registry.
+	m[0].Post(t, nil,
+		"/offers/foo@127.0.0.1:1[offer_FvGXFlChxPZvSNuK]", url.Values{})
+
+	status, raw := u[0].Get(t, "/peers")
+
+	var peers []mint.PeerResource
+	err := raw.Extract("peers", &peers)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 200, status)
+	assert.Equal(t, 0, len(peers))
+}
@@ -0,0 +1,124 @@
+package functional
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/spolu/settle/lib/metrics"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/async"
+	"github.com/spolu/settle/mint/async/task"
+	"github.com/spolu/settle/mint/test"
+	"github.com/stretchr/testify/assert"
+)
+
+// getMetrics retrieves the metrics exposed by a mint.
+func getMetrics(
+	t *testing.T,
+	m *test.Mint,
+) string {
+	r, err := http.Get(fmt.Sprintf("%s/metrics", m.MetricsServer.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Body.Close()
+
+	assert.Equal(t, 200, r.StatusCode)
+	assert.Equal(t, metrics.ContentType, r.Header.Get("Content-Type"))
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestMetrics(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, u, a, o := setupCreateTransaction(t)
+	defer tearDownCreateTransaction(t, m)
+
+	_, raw := u[0].Post(t,
+		fmt.Sprintf("/transactions"),
+		url.Values{
+			"pair":        {fmt.Sprintf("%s/%s", a[0].Name, a[2].Name)},
+			"amount":      {"10"},
+			"destination": {u[2].Address},
+			"path[]":      {o[1].ID, o[2].ID},
+		})
+
+	var tx mint.TransactionResource
+	err := raw.Extract("transaction", &tx)
+	assert.Nil(t, err)
+
+	_, err = sdkClient(t, u[0]).SettleTransaction(m[0].Ctx, tx.ID)
+	assert.Nil(t, err)
+
+	async.TestRunOne(m[1].Ctx)
+
+	m0 := getMetrics(t, m[0])
+	assert.Contains(t, m0,
+		`mint_requests_total{endpoint="CreateTransaction",status="201"}`)
+	assert.Contains(t, m0,
+		`mint_request_duration_seconds_count{endpoint="SettleTransaction"} `)
+	assert.Contains(t, m0,
+		`mint_transactions_total{status="settled",role="canonical"} 1`)
+	assert.Contains(t, m0,
+		`mint_client_request_duration_seconds_count{peer="other"} `)
+	assert.Contains(t, m0,
+		`db_transaction_duration_seconds_count{tag="mint",result="commit"} `)
+
+	m1 := getMetrics(t, m[1])
+	assert.Contains(t, m1,
+		`mint_transactions_total{status="settled",role="propagated"} 1`)
+	assert.Contains(t, m1,
+		`mint_async_task_runs_total{task="PropagateOffer",result="succeeded"} 1`)
+	assert.Contains(t, m1, `mint_async_pending_tasks{task="PropagateOperation"} `)
+
+	// Peers are labelled by host once recorded in the peer registry.
+	_, err = task.RecordPeerContacts(m[0].Ctx)
+	assert.Nil(t, err)
+	client := &mint.Client{}
+	err = client.Init(m[0].Ctx)
+	assert.Nil(t, err)
+	_, err = client.RetrieveKey(m[0].Ctx, m[1].Env.Config[mint.EnvCfgHost])
+	assert.Nil(t, err)
+
+	m0 = getMetrics(t, m[0])
+	assert.Contains(t, m0, fmt.Sprintf(
+		`mint_client_request_duration_seconds_count{peer="%s"} `,
+		m[1].Env.Config[mint.EnvCfgHost]))
+}
+
+func TestMetricsRejectedRequests(
+	t *testing.T,
+) {
+	t.Parallel()
+	m := test.CreateMint(t)
+	defer m.Close()
+	u := m.CreateUser(t)
+
+	// Requests rejected by the authentication middleware are recorded, by
+	// endpoint if they match a route.
+	u.Password = "invalid"
+	status, _ := u.Get(t, "/assets")
+	assert.Equal(t, 400, status)
+
+	r, err := http.Get(fmt.Sprintf("%s/unknown", m.Server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Body.Close()
+	assert.Equal(t, 400, r.StatusCode)
+
+	metrics := getMetrics(t, m)
+	assert.Contains(t, metrics,
+		`mint_requests_total{endpoint="ListAssets",status="400"} 1`)
+	assert.Contains(t, metrics,
+		`mint_requests_total{endpoint="unknown",status="400"} 1`)
+}
@@ -0,0 +1,257 @@
+package functional
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/spolu/settle/lib/db"
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/token"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// createLegacyDB creates a mint DB with tables as created by a prior version
+// of the schema, returning a context bound to it.
+func createLegacyDB(
+	t *testing.T,
+	schemas ...string,
+) (context.Context, *sqlx.DB) {
+	ctx := context.Background()
+
+	tmpFile := filepath.Join(os.TempDir(), token.New("test")+".db")
+	mintDB, err := db.NewSqlite3DBForPath(ctx, tmpFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range schemas {
+		if _, err := mintDB.Exec(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return db.WithDB(ctx, "mint", mintDB), mintDB
+}
+
+func TestMigrationTasksTrace(
+	t *testing.T,
+) {
+	t.Parallel()
+	ctx, mintDB := createLegacyDB(t, `
+CREATE TABLE tasks(
+  token VARCHAR(256) NOT NULL,
+  created TIMESTAMP NOT NULL,
+  name VARCHAR(256) NOT NULL,
+  subject VARCHAR(256) NOT NULL,
+  status VARCHAR(32) NOT NULL,
+  retry INT,
+  PRIMARY KEY(token)
+);
+INSERT INTO tasks VALUES
+  ('task_legacy', CURRENT_TIMESTAMP, 'propagate_offer', 'foo', 'pending', 0);
+`)
+	defer mintDB.Close()
+
+	err := db.CreateDBTables(ctx, "mint", mintDB)
+	assert.Nil(t, err)
+	// Migrations are idempotent.
+	err = db.CreateDBTables(ctx, "mint", mintDB)
+	assert.Nil(t, err)
+
+	_, err = model.CreateTask(ctx, time.Now(), "propagate_offer", "bar",
+		mint.TkStPending, 0, "")
+	assert.Nil(t, err)
+
+	tasks, err := model.LoadPendingTasks(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(tasks))
+}
+
+func TestMigrationUsersRoleStatus(
+	t *testing.T,
+) {
+	t.Parallel()
+	ctx, mintDB := createLegacyDB(t, `
+CREATE TABLE users(
+  token VARCHAR(256) NOT NULL,
+  created TIMESTAMP NOT NULL,
+  username VARCHAR(256) NOT NULL,
+  password_hash VARCHAR(256) NOT NULL,
+  PRIMARY KEY(token),
+  CONSTRAINT users_username_u UNIQUE (username)
+);
+INSERT INTO users VALUES
+  ('user_legacy', CURRENT_TIMESTAMP, 'legacy', 'hash');
+`)
+	defer mintDB.Close()
+
+	err := db.CreateDBTables(ctx, "mint", mintDB)
+	assert.Nil(t, err)
+
+	user, err := model.LoadUserByUsername(ctx, "legacy")
+	assert.Nil(t, err)
+	assert.Equal(t, mint.UsRlUser, user.Role)
+	assert.Equal(t, mint.UsStActive, user.Status)
+
+	_, err = model.CreateUser(ctx, "admin", "password", mint.UsRlAdmin)
+	assert.Nil(t, err)
+}
+
+func TestMigrationEventsSeq(
+	t *testing.T,
+) {
+	t.Parallel()
+	ctx, mintDB := createLegacyDB(t, `
+CREATE TABLE events(
+  token VARCHAR(256) NOT NULL,
+  created TIMESTAMP NOT NULL,
+  address VARCHAR(256) NOT NULL,
+  type VARCHAR(64) NOT NULL,
+  object VARCHAR(256) NOT NULL,
+  payload TEXT NOT NULL,
+  PRIMARY KEY(token)
+);
+INSERT INTO events VALUES
+  ('event_legacy', CURRENT_TIMESTAMP, 'foo@mint', 'offer.active', 'foo', '{}');
+`)
+	defer mintDB.Close()
+
+	err := db.CreateDBTables(ctx, "mint", mintDB)
+	assert.Nil(t, err)
+	err = db.CreateDBTables(ctx, "mint", mintDB)
+	assert.Nil(t, err)
+
+	// Events sequenced before the sequence existed are followed.
+	_, err = mintDB.Exec(`
+INSERT INTO events VALUES
+  ('event_sequenced', CURRENT_TIMESTAMP, 'bar@mint', 'offer.active', 'bar',
+   '{}', 5)
+`)
+	assert.Nil(t, err)
+
+	err = model.SequenceEventsByAddress(ctx, "foo@mint")
+	assert.Nil(t, err)
+
+	events, err := model.LoadEventListByAddress(ctx, "foo@mint", nil, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, "6", events[0].ID())
+
+	// Sequence numbers are unique.
+	_, err = mintDB.Exec(`
+INSERT INTO events VALUES
+  ('event_duplicate', CURRENT_TIMESTAMP, 'bar@mint', 'offer.active', 'baz',
+   '{}', 6)
+`)
+	assert.NotNil(t, err)
+}
+
+func TestMigrationOffersSortPrice(
+	t *testing.T,
+) {
+	t.Parallel()
+	ctx, mintDB := createLegacyDB(t, `
+CREATE TABLE offers(
+  owner VARCHAR(256) NOT NULL,
+  token VARCHAR(256) NOT NULL,
+  created TIMESTAMP NOT NULL,
+  propagation VARCHAR(32) NOT NULL,
+  base_asset VARCHAR(256) NOT NULL,
+  quote_asset VARCHAR(256) NOT NULL,
+  base_price VARCHAR(64) NOT NULL,
+  quote_price VARCHAR(64) NOT NULL,
+  amount VARCHAR(64) NOT NULL,
+  status VARCHAR(32) NOT NULL,
+  remainder VARCHAR(64) NOT NULL,
+  PRIMARY KEY(owner, token)
+);
+INSERT INTO offers VALUES
+  ('foo@mint', 'offer_high', CURRENT_TIMESTAMP, 'canonical',
+   'foo@mint[USD.2]', 'foo@mint[EUR.2]', '3', '2', '10', 'active', '10'),
+  ('foo@mint', 'offer_low', CURRENT_TIMESTAMP, 'canonical',
+   'foo@mint[USD.2]', 'foo@mint[EUR.2]', '1', '3', '10', 'active', '10');
+`)
+	defer mintDB.Close()
+
+	err := db.CreateDBTables(ctx, "mint", mintDB)
+	assert.Nil(t, err)
+
+	// The sort price of existing offers is backfilled.
+	offers, _, err := model.LoadCanonicalOfferListByOwner(ctx,
+		model.Page{
+			CreatedBefore: time.Now().Add(time.Hour),
+			Limit:         10,
+			Sort:          model.Sort{Key: model.SrKeyPrice, Asc: true},
+		},
+		model.OfferFilter{}, "foo@mint")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(offers))
+	assert.Equal(t, "offer_low", offers[0].Token)
+	assert.Equal(t, "offer_high", offers[1].Token)
+	assert.Equal(t,
+		model.SortPrice(big.NewInt(3), big.NewInt(2)), offers[1].SortPrice)
+}
+
+func TestMigrationSpendingPoliciesTotals(
+	t *testing.T,
+) {
+	t.Parallel()
+	ctx, mintDB := createLegacyDB(t, `
+CREATE TABLE spending_policies(
+  user_token VARCHAR(256) NOT NULL,
+  asset VARCHAR(256) NOT NULL,
+  created TIMESTAMP NOT NULL,
+  per_transaction VARCHAR(64),
+  per_day VARCHAR(64),
+  per_window VARCHAR(64),
+  window_ms BIGINT,
+  version BIGINT NOT NULL,
+  PRIMARY KEY(user_token, asset)
+);
+CREATE TABLE spendings(
+  token VARCHAR(256) NOT NULL,
+  created TIMESTAMP NOT NULL,
+  user_token VARCHAR(256) NOT NULL,
+  asset VARCHAR(256) NOT NULL,
+  amount VARCHAR(64) NOT NULL,
+  txn VARCHAR(256) NOT NULL,
+  PRIMARY KEY(token)
+);
+`)
+	defer mintDB.Close()
+
+	now := time.Now().UTC()
+	_, err := mintDB.Exec(`
+INSERT INTO spending_policies VALUES
+  ('user_foo', 'foo@mint[USD.2]', ?, NULL, '15', '15', 3600000, 2)
+`, now)
+	assert.Nil(t, err)
+	_, err = mintDB.Exec(`
+INSERT INTO spendings VALUES
+  ('spending_old', ?, 'user_foo', 'foo@mint[USD.2]', '20', 'tx0'),
+  ('spending_new', ?, 'user_foo', 'foo@mint[USD.2]', '10', 'tx1')
+`, now.Add(-30*24*time.Hour), now)
+	assert.Nil(t, err)
+
+	err = db.CreateDBTables(ctx, "mint", mintDB)
+	assert.Nil(t, err)
+
+	// The running totals of existing policies are backfilled from the
+	// spendings of the current day and window.
+	p, err := model.LoadSpendingPolicy(ctx, "user_foo", "foo@mint[USD.2]")
+	assert.Nil(t, err)
+	daySpent, windowSpent := p.Usage(time.Now())
+	assert.Equal(t, big.NewInt(10), daySpent)
+	assert.Equal(t, big.NewInt(10), windowSpent)
+
+	err = p.Spend(ctx, "tx2", big.NewInt(10))
+	assert.IsType(t, model.ErrSpendingLimitExceeded{}, errors.Cause(err))
+	err = p.Spend(ctx, "tx2", big.NewInt(5))
+	assert.Nil(t, err)
+}
@@ -0,0 +1,100 @@
+package functional
+
+import (
+	"testing"
+
+	"github.com/spolu/settle/lib/openapi"
+	"github.com/spolu/settle/mint/test"
+	register "github.com/spolu/settle/register/endpoint"
+	"github.com/stretchr/testify/assert"
+)
+
+// assertRoutesSpecified asserts that all the routes bound by a controller
+// have an operation in the document.
+func assertRoutesSpecified(
+	t *testing.T,
+	d *openapi.Document,
+	controller string,
+) {
+	routes, err := openapi.ParseRoutes(controller)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, routes)
+
+	for _, r := range routes {
+		assert.NotNil(t, d.Operation(r.Method, r.Path),
+			"%s %s (%s) has no spec entry, run `go generate` on the "+
+				"endpoint package", r.Method, r.Path, r.Endpoint)
+	}
+}
+
+func TestOpenAPI(
+	t *testing.T,
+) {
+	t.Parallel()
+	m := test.CreateMint(t)
+	defer m.Close()
+
+	status, raw := m.Get(t, nil, "/openapi.json")
+	assert.Equal(t, 200, status)
+
+	d := openapi.Document{}
+	assert.Nil(t, raw.Extract("openapi", &d.OpenAPI))
+	assert.Nil(t, raw.Extract("paths", &d.Paths))
+	assert.Nil(t, raw.Extract("components", &d.Components))
+	assert.Equal(t, openapi.Version, d.OpenAPI)
+
+	assertRoutesSpecified(t, &d, "../../app/controller.go")
+
+	op := d.Operation("POST", "/offers")
+	body := op.RequestBody.Content["application/x-www-form-urlencoded"]
+	assert.Contains(t, body.Schema.Properties, "pair")
+	assert.Contains(t, body.Schema.Properties, "price")
+	assert.Contains(t, body.Schema.Properties, "amount")
+	assert.Equal(t, "#/components/schemas/OfferResource",
+		op.Responses["201"].Content["application/json"].Schema.
+			Properties["offer"].Ref)
+	assert.Contains(t,
+		op.Responses["400"].Content["application/json"].Schema.
+			Properties["error"].Properties["code"].Enum,
+		"pair_invalid")
+	assert.NotEmpty(t, op.Security)
+
+	// Public endpoints do not require authentication.
+	op = d.Operation("GET", "/offers/:offer")
+	assert.Empty(t, op.Security)
+	assert.Equal(t, "offer", op.Parameters[0].Name)
+	assert.Equal(t, "path", op.Parameters[0].In)
+
+	op = d.Operation("GET", "/offers")
+	params := []string{}
+	for _, p := range op.Parameters {
+		params = append(params, p.Name)
+	}
+	assert.Contains(t, params, "starting_after")
+	assert.Contains(t, params, "status")
+	assert.Equal(t, "array",
+		op.Responses["200"].Content["application/json"].Schema.
+			Properties["offers"].Type)
+
+	offer := d.Components.Schemas["OfferResource"]
+	assert.NotNil(t, offer)
+	assert.Equal(t, "integer", offer.Properties["remainder"].Type)
+	assert.Contains(t, d.Components.Schemas, "CrossingResource")
+}
+
+func TestOpenAPIRegister(
+	t *testing.T,
+) {
+	t.Parallel()
+	m := test.CreateMint(t)
+	defer m.Close()
+
+	d := register.OpenAPI(m.Ctx)
+	assertRoutesSpecified(t, d, "../../../register/app/controller.go")
+
+	op := d.Operation("POST", "/users")
+	assert.Contains(t,
+		op.RequestBody.Content["application/x-www-form-urlencoded"].Schema.
+			Properties, "email")
+	assert.Contains(t, d.Components.Schemas, "UserResource")
+}
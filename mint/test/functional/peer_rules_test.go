@@ -0,0 +1,77 @@
+package functional
+
+import (
+	"fmt"
+	"math/big"
+	"net/url"
+	"testing"
+
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/mint"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeerRulesPropagation(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, u := setupListPeers(t)
+	defer tearDownListPeers(t, m)
+
+	offer := u[0].CreateOffer(t,
+		fmt.Sprintf("%s[USD.2]/%s[USD.2]", u[0].Address, u[1].Address),
+		"100/100", big.NewInt(100))
+
+	for _, tc := range []struct {
+		allow  string
+		deny   string
+		status int
+	}{
+		{"", mint.GetHost(m[0].Ctx), 403},
+		{"*.example.com", "", 403},
+		{"mint.example.com, 127.0.0.1", "*.example.com", 201},
+	} {
+		m[1].Env.Config[mint.EnvCfgPeerAllowList] = tc.allow
+		m[1].Env.Config[mint.EnvCfgPeerDenyList] = tc.deny
+
+		status, raw := m[1].PostFrom(t, m[0],
+			fmt.Sprintf("/offers/%s", offer.ID), url.Values{})
+
+		assert.Equal(t, tc.status, status)
+		if tc.status == 403 {
+			var e errors.ConcreteUserError
+			err := raw.Extract("error", &e)
+			assert.Nil(t, err)
+			assert.Equal(t, "peer_not_allowed", e.ErrCode)
+		}
+	}
+}
+
+func TestPeerRulesTransactionPath(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, u, a, o := setupCreateTransaction(t)
+	defer tearDownCreateTransaction(t, m)
+
+	m[0].Env.Config[mint.EnvCfgPeerDenyList] = mint.GetHost(m[1].Ctx)
+
+	status, raw := u[0].Post(t,
+		fmt.Sprintf("/transactions"),
+		url.Values{
+			"pair":        {fmt.Sprintf("%s/%s", a[0].Name, a[2].Name)},
+			"amount":      {"10"},
+			"destination": {u[2].Address},
+			"path[]": {
+				o[1].ID,
+				o[2].ID,
+			},
+		})
+
+	var e errors.ConcreteUserError
+	err := raw.Extract("error", &e)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 403, status)
+	assert.Equal(t, "peer_not_allowed", e.ErrCode)
+}
@@ -106,7 +106,7 @@ func TestPropagateOperationNotSettled(
 
 	assert.Equal(t, 201, status)
 
-	status, raw = m[1].Post(t, nil,
+	status, raw = m[1].PostFrom(t, m[0],
 		fmt.Sprintf("/operations/%s", tx.Operations[0].ID),
 		url.Values{})
 
@@ -0,0 +1,118 @@
+package functional
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func getWithProtocolVersion(
+	t *testing.T,
+	user *test.MintUser,
+	path string,
+	version string,
+) (int, http.Header, svc.Resp) {
+	req, err := http.NewRequest("GET",
+		fmt.Sprintf("%s%s", user.Mint.Server.URL, path), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.SetBasicAuth(user.Username, user.Password)
+	if version != "" {
+		req.Header.Add("Mint-Protocol-Version", version)
+	}
+
+	r, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Body.Close()
+
+	var raw svc.Resp
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+		t.Fatal(err)
+	}
+
+	return r.StatusCode, r.Header, raw
+}
+
+func TestProtocolVersionSupported(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, u := setupDiscovery(t)
+	defer tearDownDiscovery(t, m)
+
+	for _, v := range []string{mint.ProtocolVersion, ""} {
+		status, header, _ := getWithProtocolVersion(t, u[0],
+			"/key", v)
+
+		assert.Equal(t, 200, status)
+		assert.Equal(t, mint.ProtocolVersion,
+			header.Get("Mint-Protocol-Version"))
+	}
+}
+
+func TestProtocolVersionUnsupported(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, u := setupDiscovery(t)
+	defer tearDownDiscovery(t, m)
+
+	status, _, raw := getWithProtocolVersion(t, u[0],
+		"/key", "9999")
+
+	var e errors.ConcreteUserError
+	err := raw.Extract("error", &e)
+	assert.Nil(t, err)
+	var rng mint.ProtocolVersionRange
+	err = raw.Extract("protocol_versions", &rng)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "protocol_version_unsupported", e.ErrCode)
+	assert.Equal(t, mint.MinProtocolVersion, rng.Min)
+	assert.Equal(t, mint.ProtocolVersion, rng.Max)
+	assert.Equal(t, mint.ProtocolVersions, rng.Supported)
+
+	// The discovery document is served regardless of the requested version.
+	status, _, _ = getWithProtocolVersion(t, u[0], mint.DiscoveryPath, "9999")
+	assert.Equal(t, 200, status)
+}
+
+func TestProtocolVersionRetrieveKey(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, u := setupDiscovery(t)
+	defer tearDownDiscovery(t, m)
+
+	// Identity keys are listed from protocol version 1 only.
+	status, _, raw := getWithProtocolVersion(t, u[0], "/key", "1")
+	assert.Equal(t, 200, status)
+
+	var key mint.KeyResource
+	err := raw.Extract("key", &key)
+	assert.Nil(t, err)
+	var keys []mint.KeyResource
+	err = raw.Extract("keys", &keys)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(keys))
+	assert.Equal(t, key.ID, keys[0].ID)
+
+	status, _, raw = getWithProtocolVersion(t, u[0], "/key", "0")
+	assert.Equal(t, 200, status)
+
+	err = raw.Extract("key", &key)
+	assert.Nil(t, err)
+	_, ok := raw["keys"]
+	assert.False(t, ok)
+}
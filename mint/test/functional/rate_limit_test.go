@@ -0,0 +1,153 @@
+package functional
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/spolu/settle/lib/ratelimit"
+	"github.com/spolu/settle/mint/app"
+	"github.com/spolu/settle/mint/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRateLimit(
+	t *testing.T,
+) (*test.Mint, *test.MintUser) {
+	m := test.CreateMint(t)
+	u := m.CreateUser(t)
+
+	m.Limiter.Limits = map[string]ratelimit.Limit{
+		app.RlGrPublic:        ratelimit.Limit{Rate: 0.1, Burst: 2},
+		app.RlGrAuthenticated: ratelimit.Limit{Rate: 0.1, Burst: 3},
+	}
+
+	return m, u
+}
+
+func getRateLimited(
+	t *testing.T,
+	m *test.Mint,
+	user *test.MintUser,
+	path string,
+) (int, http.Header) {
+	req, err := http.NewRequest("GET",
+		fmt.Sprintf("%s%s", m.Server.URL, path), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user != nil {
+		req.SetBasicAuth(user.Username, user.Password)
+	}
+
+	r, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Body.Close()
+
+	return r.StatusCode, r.Header
+}
+
+func TestRateLimitPublic(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, u := setupRateLimit(t)
+	defer m.Close()
+
+	for i := 0; i < 2; i++ {
+		status, _ := getRateLimited(t, m, nil, "/key")
+		assert.Equal(t, 200, status)
+	}
+
+	status, header := getRateLimited(t, m, nil, "/key")
+	assert.Equal(t, 429, status)
+	assert.Equal(t, "10", header.Get("Retry-After"))
+
+	// Authenticated requests are limited separately, per user.
+	for i := 0; i < 3; i++ {
+		status, _ := getRateLimited(t, m, u, "/assets")
+		assert.Equal(t, 200, status)
+	}
+	status, _ = getRateLimited(t, m, u, "/assets")
+	assert.Equal(t, 429, status)
+}
+
+func TestRateLimitParseLimits(
+	t *testing.T,
+) {
+	t.Parallel()
+
+	limits, err := ratelimit.ParseLimits(
+		"public=5, propagation=0", app.DefaultRateLimits)
+	assert.Nil(t, err)
+	assert.Equal(t, ratelimit.Limit{Rate: 5, Burst: 5}, limits[app.RlGrPublic])
+	assert.Equal(t, float64(0), limits[app.RlGrPropagation].Rate)
+	assert.Equal(t,
+		app.DefaultRateLimits[app.RlGrAuthenticated],
+		limits[app.RlGrAuthenticated])
+
+	_, err = ratelimit.ParseLimits("unknown=1:2", app.DefaultRateLimits)
+	assert.NotNil(t, err)
+}
+
+func TestRateLimitIP(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, u := setupRateLimit(t)
+	defer m.Close()
+
+	m.IPLimiter.Limits = map[string]ratelimit.Limit{
+		app.RlGrIP: ratelimit.Limit{Rate: 0.1, Burst: 3},
+	}
+
+	// Failed authentications are limited per client IP.
+	u.Password = "invalid"
+	for i := 0; i < 3; i++ {
+		status, _ := getRateLimited(t, m, u, "/assets")
+		assert.Equal(t, 400, status)
+	}
+	status, header := getRateLimited(t, m, u, "/assets")
+	assert.Equal(t, 429, status)
+	assert.Equal(t, "10", header.Get("Retry-After"))
+
+	// Health checks and metrics are never limited.
+	for _, path := range []string{"/healthz", "/readyz", "/metrics"} {
+		status, _ = getRateLimited(t, m, nil, path)
+		assert.NotEqual(t, 429, status)
+	}
+}
+
+func TestRateLimitClientIP(
+	t *testing.T,
+) {
+	t.Parallel()
+
+	proxies, err := ratelimit.ParseProxies("10.0.0.1, 192.168.0.0/16")
+	assert.Nil(t, err)
+
+	ip := func(remote string, forwarded ...string) string {
+		r, err := http.NewRequest("GET", "/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.RemoteAddr = remote
+		for _, f := range forwarded {
+			r.Header.Add("X-Forwarded-For", f)
+		}
+		return proxies.ClientIP(r)
+	}
+
+	assert.Equal(t, "1.2.3.4", ip("1.2.3.4:1234", "5.6.7.8"))
+	assert.Equal(t, "5.6.7.8", ip("10.0.0.1:1234", "5.6.7.8"))
+	assert.Equal(t, "5.6.7.8", ip("10.0.0.1:1234", "9.9.9.9, 5.6.7.8"))
+	assert.Equal(t, "5.6.7.8",
+		ip("10.0.0.1:1234", "9.9.9.9", "5.6.7.8, 192.168.1.1"))
+	assert.Equal(t, "10.0.0.1", ip("10.0.0.1:1234"))
+	assert.Equal(t, "10.0.0.1", ip("10.0.0.1:1234", "garbage"))
+
+	_, err = ratelimit.ParseProxies("10.0.0")
+	assert.NotNil(t, err)
+}
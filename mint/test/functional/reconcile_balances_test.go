@@ -0,0 +1,153 @@
+package functional
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/async"
+	"github.com/spolu/settle/mint/async/task"
+	"github.com/spolu/settle/mint/model"
+	"github.com/spolu/settle/mint/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupReconcileBalances(
+	t *testing.T,
+) ([]*test.Mint, []*test.MintUser, []mint.AssetResource) {
+	m := []*test.Mint{
+		test.CreateMint(t),
+		test.CreateMint(t),
+	}
+	u := []*test.MintUser{
+		m[0].CreateUser(t),
+		m[1].CreateUser(t),
+	}
+	a := []mint.AssetResource{
+		u[0].CreateAsset(t, "USD", 2),
+	}
+
+	return m, u, a
+}
+
+func tearDownReconcileBalances(
+	t *testing.T,
+	mints []*test.Mint,
+) {
+	for _, m := range mints {
+		m.Close()
+	}
+}
+
+func TestReconcileBalancesValueMismatch(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, u, a := setupReconcileBalances(t)
+	defer tearDownReconcileBalances(t, m)
+
+	canonical, err := model.CreateCanonicalBalance(m[0].Ctx,
+		u[0].Address, a[0].Name, u[1].Address, model.Amount(*big.NewInt(42)))
+	assert.Nil(t, err)
+
+	_, err = model.CreatePropagatedBalance(m[1].Ctx,
+		canonical.Owner, canonical.Token, canonical.Created,
+		canonical.Asset, canonical.Holder, model.Amount(*big.NewInt(12)))
+	assert.Nil(t, err)
+
+	checked, discrepancies, err := task.ReconcilePropagatedBalances(m[1].Ctx)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1, checked)
+	assert.Equal(t, 1, len(discrepancies))
+	assert.Equal(t, canonical.ID(), discrepancies[0].Balance)
+	assert.Equal(t, mint.DsTpValueMismatch, discrepancies[0].Type)
+	assert.Equal(t, big.NewInt(12), (*big.Int)(&discrepancies[0].LocalValue))
+	assert.Equal(t,
+		big.NewInt(42), (*big.Int)(discrepancies[0].CanonicalValue))
+
+	balance, err := model.LoadPropagatedBalanceByOwnerToken(m[1].Ctx,
+		canonical.Owner, canonical.Token)
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewInt(42), (*big.Int)(&balance.Value))
+
+	stored, err := model.LoadBalanceDiscrepanciesByBalance(m[1].Ctx,
+		canonical.ID())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(stored))
+
+	// A second run finds nothing to repair.
+	checked, discrepancies, err = task.ReconcilePropagatedBalances(m[1].Ctx)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1, checked)
+	assert.Equal(t, 0, len(discrepancies))
+}
+
+func TestReconcileBalancesCanonicalMissing(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, u, a := setupReconcileBalances(t)
+	defer tearDownReconcileBalances(t, m)
+
+	balance, err := model.CreatePropagatedBalance(m[1].Ctx,
+		u[0].Address, "balance_foo", time.Now(),
+		a[0].Name, u[1].Address, model.Amount(*big.NewInt(12)))
+	assert.Nil(t, err)
+
+	checked, discrepancies, err := task.ReconcilePropagatedBalances(m[1].Ctx)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1, checked)
+	assert.Equal(t, 1, len(discrepancies))
+	assert.Equal(t, balance.ID(), discrepancies[0].Balance)
+	assert.Equal(t, mint.DsTpCanonicalMissing, discrepancies[0].Type)
+	assert.Nil(t, discrepancies[0].CanonicalValue)
+
+	// The propagated balance is left untouched.
+	b, err := model.LoadPropagatedBalanceByOwnerToken(m[1].Ctx,
+		balance.Owner, balance.Token)
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewInt(12), (*big.Int)(&b.Value))
+}
+
+func TestReconcileBalancesReschedule(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, _, _ := setupReconcileBalances(t)
+	defer tearDownReconcileBalances(t, m)
+
+	a := async.Get(m[1].Ctx)
+
+	err := task.EnsureReconcileBalances(m[1].Ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(a.Pending))
+
+	// The next run is queued once the task succeeded.
+	async.TestRunOne(m[1].Ctx)
+
+	assert.Equal(t, 1, len(a.Pending))
+	assert.Equal(t, task.TkReconcileBalances, a.Pending[0].Task.Name())
+	assert.Equal(t, "", a.Pending[0].Task.Subject())
+	assert.True(t, a.Pending[0].Deadline().After(time.Now().Add(
+		time.Duration(mint.ReconciliationIntervalMs)*time.Millisecond/2)))
+
+	// The next run is queued as well once the task ran out of retries.
+	a.Pending[0].Model.Retry = a.Pending[0].Task.MaxRetries()
+	_, err = m[1].DB.Exec("DROP TABLE balances")
+	assert.Nil(t, err)
+
+	async.TestRunOne(m[1].Ctx)
+
+	failed, err := model.CountTasks(m[1].Ctx,
+		mint.TkStFailed, time.Now().Add(-time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, failed)
+
+	assert.Equal(t, 1, len(a.Pending))
+	assert.Equal(t, task.TkReconcileBalances, a.Pending[0].Task.Name())
+	assert.Equal(t, uint(0), a.Pending[0].Model.Retry)
+}
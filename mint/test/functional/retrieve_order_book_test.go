@@ -0,0 +1,136 @@
+package functional
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/async"
+	"github.com/spolu/settle/mint/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRetrieveOrderBook(
+	t *testing.T,
+) ([]*test.Mint, []*test.MintUser, []mint.AssetResource) {
+	m := []*test.Mint{
+		test.CreateMint(t),
+		test.CreateMint(t),
+	}
+	u := []*test.MintUser{
+		m[0].CreateUser(t),
+		m[1].CreateUser(t),
+	}
+	a := []mint.AssetResource{
+		u[0].CreateAsset(t, "USD", 2),
+		u[1].CreateAsset(t, "EUR", 2),
+		u[1].CreateAsset(t, "HOUR", 0),
+	}
+
+	// Asks.
+	u[0].CreateOffer(t,
+		fmt.Sprintf("%s/%s", a[0].Name, a[1].Name),
+		"100/110", big.NewInt(1100))
+	u[0].CreateOffer(t,
+		fmt.Sprintf("%s/%s", a[0].Name, a[1].Name),
+		"100/120", big.NewInt(1200))
+	u[0].CreateOffer(t,
+		fmt.Sprintf("%s/%s", a[0].Name, a[1].Name),
+		"200/220", big.NewInt(550))
+	u[0].CreateOffer(t,
+		fmt.Sprintf("%s/%s", a[0].Name, a[2].Name),
+		"2000/1", big.NewInt(3))
+
+	// Bids, propagated to m[0].
+	u[1].CreateOffer(t,
+		fmt.Sprintf("%s/%s", a[1].Name, a[0].Name),
+		"100/95", big.NewInt(950))
+	u[1].CreateOffer(t,
+		fmt.Sprintf("%s/%s", a[1].Name, a[0].Name),
+		"100/90", big.NewInt(300))
+	async.TestRunOne(m[1].Ctx)
+	async.TestRunOne(m[1].Ctx)
+
+	return m, u, a
+}
+
+func tearDownRetrieveOrderBook(
+	t *testing.T,
+	mints []*test.Mint,
+) {
+	for _, m := range mints {
+		m.Close()
+	}
+}
+
+func TestRetrieveOrderBook(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, _, a := setupRetrieveOrderBook(t)
+	defer tearDownRetrieveOrderBook(t, m)
+
+	status, raw := m[0].Get(t, nil,
+		fmt.Sprintf("/assets/%s/book", a[0].Name))
+	assert.Equal(t, 200, status)
+
+	var book mint.OrderBookResource
+	err := raw.Extract("book", &book)
+	assert.Nil(t, err)
+
+	assert.Equal(t, a[0].Name, book.Asset)
+	assert.Equal(t, 2, len(book.Pairs))
+
+	// Pairs are ordered by counter asset name.
+	eur, hour := book.Pairs[0], book.Pairs[1]
+	assert.Equal(t, a[1].Name, eur.CounterAsset)
+	assert.Equal(t, a[2].Name, hour.CounterAsset)
+
+	assert.Equal(t, 2, len(eur.Asks))
+	assert.Equal(t, "1.100000000000", eur.Asks[0].Rate)
+	assert.Equal(t, big.NewInt(1500), eur.Asks[0].Amount)
+	assert.Equal(t, big.NewInt(1500), eur.Asks[0].Depth)
+	assert.Equal(t, 2, eur.Asks[0].Offers)
+	assert.Equal(t, "1.200000000000", eur.Asks[1].Rate)
+	assert.Equal(t, big.NewInt(1000), eur.Asks[1].Amount)
+	assert.Equal(t, big.NewInt(2500), eur.Asks[1].Depth)
+	assert.Equal(t, 1, eur.Asks[1].Offers)
+
+	assert.Equal(t, 2, len(eur.Bids))
+	assert.Equal(t, "1.111111111111", eur.Bids[0].Rate)
+	assert.Equal(t, big.NewInt(300), eur.Bids[0].Amount)
+	assert.Equal(t, big.NewInt(300), eur.Bids[0].Depth)
+	assert.Equal(t, "1.052631578947", eur.Bids[1].Rate)
+	assert.Equal(t, big.NewInt(950), eur.Bids[1].Amount)
+	assert.Equal(t, big.NewInt(1250), eur.Bids[1].Depth)
+
+	// 20.00 USD for 1 HOUR.
+	assert.Equal(t, 1, len(hour.Asks))
+	assert.Equal(t, "0.050000000000", hour.Asks[0].Rate)
+	assert.Equal(t, big.NewInt(6000), hour.Asks[0].Amount)
+	assert.Equal(t, 0, len(hour.Bids))
+}
+
+func TestRetrieveOrderBookEmpty(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, _, a := setupRetrieveOrderBook(t)
+	defer tearDownRetrieveOrderBook(t, m)
+
+	status, raw := m[1].Get(t, nil,
+		fmt.Sprintf("/assets/%s/book", a[2].Name))
+	assert.Equal(t, 200, status)
+
+	var book mint.OrderBookResource
+	err := raw.Extract("book", &book)
+	assert.Nil(t, err)
+
+	assert.Equal(t, a[2].Name, book.Asset)
+	assert.Equal(t, 0, len(book.Pairs))
+
+	status, raw = m[1].Get(t, nil, "/assets/foo/book")
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "asset_invalid", errorCode(t, raw))
+}
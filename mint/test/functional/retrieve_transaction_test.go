@@ -0,0 +1,136 @@
+package functional
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRetrieveTransaction(
+	t *testing.T,
+) (*test.Mint, []*test.MintUser, mint.TransactionResource) {
+	m := test.CreateMint(t)
+	u := []*test.MintUser{
+		m.CreateUser(t),
+		m.CreateUser(t),
+	}
+	a := u[0].CreateAsset(t, "USD", 2)
+
+	status, raw := u[0].Post(t,
+		fmt.Sprintf("/transactions"),
+		url.Values{
+			"pair":        {fmt.Sprintf("%s/%s", a.Name, a.Name)},
+			"amount":      {"10"},
+			"destination": {u[1].Address},
+		})
+	assert.Equal(t, 201, status)
+
+	var tx mint.TransactionResource
+	err := raw.Extract("transaction", &tx)
+	assert.Nil(t, err)
+	assert.Equal(t, mint.TxStReserved, tx.Status)
+
+	return m, u, tx
+}
+
+func TestRetrieveTransactionWaitForSettled(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, u, tx := setupRetrieveTransaction(t)
+	defer m.Close()
+
+	settled := make(chan int)
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		status, _ := u[0].Post(t,
+			fmt.Sprintf("/transactions/%s/settle", tx.ID),
+			url.Values{})
+		settled <- status
+	}()
+
+	start := time.Now()
+	status, raw := m.Get(t, nil,
+		fmt.Sprintf("/transactions/%s?wait_for=settled&timeout=10s", tx.ID))
+	assert.Equal(t, 200, status)
+
+	var tx0 mint.TransactionResource
+	err := raw.Extract("transaction", &tx0)
+	assert.Nil(t, err)
+	assert.Equal(t, mint.TxStSettled, tx0.Status)
+	assert.Equal(t, 1, len(tx0.Operations))
+
+	// The waiter is woken by the settlement, well before the poll interval.
+	assert.WithinDuration(t, start, time.Now(),
+		time.Duration(mint.TransactionWaitPollMs)*time.Millisecond)
+
+	assert.Equal(t, 200, <-settled)
+}
+
+func TestRetrieveTransactionWaitForTimeout(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, _, tx := setupRetrieveTransaction(t)
+	defer m.Close()
+
+	start := time.Now()
+	status, raw := m.Get(t, nil,
+		fmt.Sprintf("/transactions/%s?wait_for=settled&timeout=300ms", tx.ID))
+	assert.Equal(t, 200, status)
+	assert.True(t, time.Since(start) >= 300*time.Millisecond)
+
+	var tx0 mint.TransactionResource
+	err := raw.Extract("transaction", &tx0)
+	assert.Nil(t, err)
+	assert.Equal(t, mint.TxStReserved, tx0.Status)
+}
+
+func TestRetrieveTransactionWaitForFinalStatus(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, u, tx := setupRetrieveTransaction(t)
+	defer m.Close()
+
+	status, _ := u[0].Post(t,
+		fmt.Sprintf("/transactions/%s/cancel", tx.ID),
+		url.Values{})
+	assert.Equal(t, 200, status)
+
+	// A canceled transaction will never settle so the request returns
+	// immediately.
+	start := time.Now()
+	status, raw := m.Get(t, nil,
+		fmt.Sprintf("/transactions/%s?wait_for=settled&timeout=10s", tx.ID))
+	assert.Equal(t, 200, status)
+	assert.WithinDuration(t, start, time.Now(), time.Second)
+
+	var tx0 mint.TransactionResource
+	err := raw.Extract("transaction", &tx0)
+	assert.Nil(t, err)
+	assert.Equal(t, mint.TxStCanceled, tx0.Status)
+}
+
+func TestRetrieveTransactionWaitForInvalid(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, _, tx := setupRetrieveTransaction(t)
+	defer m.Close()
+
+	status, raw := m.Get(t, nil,
+		fmt.Sprintf("/transactions/%s?wait_for=reserved", tx.ID))
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "wait_for_invalid", errorCode(t, raw))
+
+	status, raw = m.Get(t, nil,
+		fmt.Sprintf("/transactions/%s?wait_for=settled&timeout=1h", tx.ID))
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "timeout_invalid", errorCode(t, raw))
+}
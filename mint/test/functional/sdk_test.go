@@ -0,0 +1,177 @@
+package functional
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/sdk"
+	"github.com/spolu/settle/mint/test"
+	"github.com/stretchr/testify/assert"
+)
+
+// sdkClient returns an SDK client for the mint authenticated as the user.
+func sdkClient(
+	t *testing.T,
+	u *test.MintUser,
+) *sdk.Client {
+	c := &sdk.Client{
+		Host: u.Mint.Server.URL[7:],
+		Credentials: &sdk.Credentials{
+			Username: u.Username,
+			Password: u.Password,
+		},
+	}
+	if err := c.Init(u.Mint.Ctx); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestSDKAssetsAndOffers(
+	t *testing.T,
+) {
+	t.Parallel()
+	m := test.CreateMint(t)
+	defer m.Close()
+
+	u := m.CreateUser(t)
+	c := sdkClient(t, u)
+
+	usd, err := c.CreateAsset(m.Ctx, &sdk.CreateAssetParams{
+		Code: "USD", Scale: 2,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, fmt.Sprintf("%s[USD.2]", u.Address), usd.Name)
+
+	eur, err := c.CreateAsset(m.Ctx, &sdk.CreateAssetParams{
+		Code: "EUR", Scale: 2,
+	})
+	assert.Nil(t, err)
+
+	// Pages of one asset are followed until all assets are iterated over.
+	names := []string{}
+	it := c.ListAssets(m.Ctx, &sdk.AssetListParams{
+		ListParams: sdk.ListParams{Limit: 1},
+	})
+	for it.Next() {
+		names = append(names, it.Asset().Name)
+	}
+	assert.Nil(t, it.Err())
+	assert.Equal(t, []string{eur.Name, usd.Name}, names)
+
+	o, err := c.CreateOffer(m.Ctx, &sdk.CreateOfferParams{
+		Pair:   fmt.Sprintf("%s/%s", usd.Name, eur.Name),
+		Price:  "100/100",
+		Amount: big.NewInt(100),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, mint.OfStActive, o.Status)
+
+	o0, err := c.RetrieveOffer(m.Ctx, o.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, o.ID, o0.ID)
+
+	o1, err := c.CloseOffer(m.Ctx, o.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, mint.OfStClosed, o1.Status)
+
+	offers := []mint.OfferResource{}
+	oit := c.ListOffers(m.Ctx, &sdk.OfferListParams{
+		Status: mint.OfStClosed,
+	})
+	for oit.Next() {
+		offers = append(offers, oit.Offer())
+	}
+	assert.Nil(t, oit.Err())
+	assert.Equal(t, 1, len(offers))
+	assert.Equal(t, o.ID, offers[0].ID)
+}
+
+func TestSDKTransaction(
+	t *testing.T,
+) {
+	t.Parallel()
+	m := test.CreateMint(t)
+	defer m.Close()
+
+	u := []*test.MintUser{
+		m.CreateUser(t),
+		m.CreateUser(t),
+	}
+	c := sdkClient(t, u[0])
+	a := u[0].CreateAsset(t, "USD", 2)
+
+	tx, err := c.CreateTransaction(m.Ctx, &sdk.CreateTransactionParams{
+		Pair:        fmt.Sprintf("%s/%s", a.Name, a.Name),
+		Amount:      big.NewInt(10),
+		Destination: u[1].Address,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, mint.TxStReserved, tx.Status)
+
+	tx0, err := c.SettleTransaction(m.Ctx, tx.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, mint.TxStSettled, tx0.Status)
+
+	tx1, err := c.RetrieveTransaction(m.Ctx, tx.ID,
+		&sdk.RetrieveTransactionParams{
+			WaitFor: mint.TxStSettled,
+			Timeout: time.Second,
+		})
+	assert.Nil(t, err)
+	assert.Equal(t, mint.TxStSettled, tx1.Status)
+
+	// Credentials stored in the context override the client's.
+	ctx := sdk.WithCredentials(m.Ctx, &sdk.Credentials{
+		Username: u[1].Username,
+		Password: u[1].Password,
+	})
+	balances := []mint.BalanceResource{}
+	it := c.ListBalances(ctx, nil)
+	for it.Next() {
+		balances = append(balances, it.Balance())
+	}
+	assert.Nil(t, it.Err())
+	assert.Equal(t, 1, len(balances))
+	assert.Equal(t, u[1].Address, balances[0].Holder)
+	assert.Equal(t, big.NewInt(10), balances[0].Value)
+}
+
+func TestSDKErrors(
+	t *testing.T,
+) {
+	t.Parallel()
+	m := test.CreateMint(t)
+	defer m.Close()
+
+	u := m.CreateUser(t)
+	c := sdkClient(t, u)
+
+	_, err := c.RetrieveAsset(m.Ctx, fmt.Sprintf("%s[GBP.2]", u.Address))
+	assert.True(t, sdk.IsNotFound(err))
+	e, ok := errors.Cause(err).(mint.ErrMintClient)
+	assert.True(t, ok)
+	assert.Equal(t, "asset_not_found", e.ErrCode)
+
+	_, err = c.CreateAsset(m.Ctx, &sdk.CreateAssetParams{
+		Code: "usd", Scale: 2,
+	})
+	e, ok = errors.Cause(err).(mint.ErrMintClient)
+	assert.True(t, ok)
+	assert.Equal(t, 400, e.StatusCode)
+	assert.False(t, sdk.IsNotFound(err))
+
+	ctx := sdk.WithCredentials(m.Ctx, &sdk.Credentials{
+		Username: u.Username,
+		Password: "foo",
+	})
+	it := c.ListAssets(ctx, nil)
+	assert.False(t, it.Next())
+	e, ok = errors.Cause(it.Err()).(mint.ErrMintClient)
+	assert.True(t, ok)
+	assert.Equal(t, "password_invalid", e.ErrCode)
+}
@@ -0,0 +1,317 @@
+package functional
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupSignature(
+	t *testing.T,
+) ([]*test.Mint, []*test.MintUser, []mint.OfferResource) {
+	m := []*test.Mint{
+		test.CreateMint(t),
+		test.CreateMint(t),
+	}
+	u := []*test.MintUser{
+		m[0].CreateUser(t),
+		m[1].CreateUser(t),
+	}
+	u[0].CreateAsset(t, "USD", 2)
+	u[1].CreateAsset(t, "USD", 2)
+
+	// The offer propagation task is not run so that the propagation can be
+	// emitted manually.
+	o := []mint.OfferResource{
+		u[0].CreateOffer(t,
+			fmt.Sprintf("%s[USD.2]/%s[USD.2]", u[0].Address, u[1].Address),
+			"100/100", big.NewInt(100)),
+	}
+
+	return m, u, o
+}
+
+func tearDownSignature(
+	t *testing.T,
+	mints []*test.Mint,
+) {
+	for _, m := range mints {
+		m.Close()
+	}
+}
+
+// signedOfferPropagation generates a propagation request for the offer to m[1]
+// signed by m[0], with the signature headers of the provided request if any.
+func signedOfferPropagation(
+	t *testing.T,
+	m []*test.Mint,
+	offer string,
+	body string,
+	from *http.Request,
+) *http.Request {
+	req, err := http.NewRequest("POST",
+		fmt.Sprintf("%s/offers/%s", m[1].Server.URL, offer),
+		strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if from != nil {
+		for _, h := range []string{
+			mint.HeaderSignature, mint.HeaderSignatureHost,
+			mint.HeaderSignatureDate, mint.HeaderSignatureNonce,
+		} {
+			req.Header.Set(h, from.Header.Get(h))
+		}
+	} else if err := mint.SignRequest(
+		m[0].Ctx, req, mint.GetHost(m[1].Ctx)); err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func doSignatureRequest(
+	t *testing.T,
+	req *http.Request,
+) (int, svc.Resp) {
+	r, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Body.Close()
+
+	var raw svc.Resp
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+		t.Fatal(err)
+	}
+	return r.StatusCode, raw
+}
+
+func TestSignatureRetrieveKey(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, _, _ := setupSignature(t)
+	defer tearDownSignature(t, m)
+
+	status, raw := m[0].Get(t, nil, "/key")
+
+	var key mint.KeyResource
+	err := raw.Extract("key", &key)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 200, status)
+	assert.Equal(t, mint.GetHost(m[0].Ctx), key.Host)
+	assert.Equal(t, mint.KeyAlgorithm, key.Algorithm)
+	assert.NotEmpty(t, key.PublicKey)
+}
+
+func TestSignatureSignedPropagation(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, _, o := setupSignature(t)
+	defer tearDownSignature(t, m)
+
+	status, raw := m[1].PostFrom(t, m[0],
+		fmt.Sprintf("/offers/%s", o[0].ID), url.Values{})
+
+	var offer mint.OfferResource
+	err := raw.Extract("offer", &offer)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 201, status)
+	assert.Equal(t, o[0].ID, offer.ID)
+}
+
+func TestSignatureUnsignedPropagation(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, _, o := setupSignature(t)
+	defer tearDownSignature(t, m)
+
+	status, raw := m[1].Post(t, nil,
+		fmt.Sprintf("/offers/%s", o[0].ID), url.Values{})
+
+	var e errors.ConcreteUserError
+	err := raw.Extract("error", &e)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "signature_required", e.ErrCode)
+}
+
+func TestSignatureUnexpectedSigner(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, _, o := setupSignature(t)
+	defer tearDownSignature(t, m)
+
+	status, raw := m[1].PostFrom(t, m[1],
+		fmt.Sprintf("/offers/%s", o[0].ID), url.Values{})
+
+	var e errors.ConcreteUserError
+	err := raw.Extract("error", &e)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "signature_invalid", e.ErrCode)
+}
+
+func TestSignatureTamperedBody(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, _, o := setupSignature(t)
+	defer tearDownSignature(t, m)
+
+	signed := signedOfferPropagation(t, m, o[0].ID, "", nil)
+	req := signedOfferPropagation(t, m, o[0].ID, "foo=bar", signed)
+
+	status, raw := doSignatureRequest(t, req)
+
+	var e errors.ConcreteUserError
+	err := raw.Extract("error", &e)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "signature_invalid", e.ErrCode)
+}
+
+func TestSignatureReplayed(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, _, o := setupSignature(t)
+	defer tearDownSignature(t, m)
+
+	signed := signedOfferPropagation(t, m, o[0].ID, "", nil)
+	replayed := signedOfferPropagation(t, m, o[0].ID, "", signed)
+
+	status, _ := doSignatureRequest(t, signed)
+	assert.Equal(t, 201, status)
+
+	status, raw := doSignatureRequest(t, replayed)
+
+	var e errors.ConcreteUserError
+	err := raw.Extract("error", &e)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "signature_replayed", e.ErrCode)
+}
+
+func TestSignatureForgedHost(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, _, o := setupSignature(t)
+	defer tearDownSignature(t, m)
+
+	var contacts int32
+	forged := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&contacts, 1)
+			http.NotFound(w, r)
+		}))
+	defer forged.Close()
+
+	forge := func(method string, path string) int {
+		req, err := http.NewRequest(method,
+			fmt.Sprintf("%s%s", m[1].Server.URL, path), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(mint.HeaderSignature, "Zm9v")
+		req.Header.Set(mint.HeaderSignatureHost,
+			strings.TrimPrefix(forged.URL, "http://"))
+		req.Header.Set(mint.HeaderSignatureDate, fmt.Sprintf("%d",
+			time.Now().UnixNano()/mint.TimeResolutionNs))
+		req.Header.Set(mint.HeaderSignatureNonce, fmt.Sprintf("%d",
+			time.Now().UnixNano()))
+		status, _ := doSignatureRequest(t, req)
+		return status
+	}
+
+	// Signatures are ignored outside of propagation endpoints.
+	status := forge("GET", "/key")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&contacts))
+
+	// The forged host is contacted once and its lookup cached.
+	status = forge("POST", fmt.Sprintf("/offers/%s", o[0].ID))
+	assert.Equal(t, 400, status)
+	seen := atomic.LoadInt32(&contacts)
+	assert.True(t, seen > 0)
+
+	status = forge("POST", fmt.Sprintf("/offers/%s", o[0].ID))
+	assert.Equal(t, 400, status)
+	assert.Equal(t, seen, atomic.LoadInt32(&contacts))
+}
+
+func TestSignatureOtherTarget(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, _, o := setupSignature(t)
+	defer tearDownSignature(t, m)
+
+	// A request signed for another mint can't be replayed to m[1].
+	req, err := http.NewRequest("POST",
+		fmt.Sprintf("%s/offers/%s", m[1].Server.URL, o[0].ID),
+		strings.NewReader(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if err := mint.SignRequest(
+		m[0].Ctx, req, mint.GetHost(m[0].Ctx)); err != nil {
+		t.Fatal(err)
+	}
+
+	status, raw := doSignatureRequest(t, req)
+
+	var e errors.ConcreteUserError
+	err = raw.Extract("error", &e)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "signature_invalid", e.ErrCode)
+}
+
+func TestSignaturePeerKeyUnavailable(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, _, o := setupSignature(t)
+	defer tearDownSignature(t, m)
+
+	// The key of m[0] can't be retrieved so unsigned propagations from it are
+	// denied.
+	m[0].Server.Close()
+
+	status, raw := m[1].Post(t, nil,
+		fmt.Sprintf("/offers/%s", o[0].ID), url.Values{})
+
+	var e errors.ConcreteUserError
+	err := raw.Extract("error", &e)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 503, status)
+	assert.Equal(t, "peer_key_unavailable", e.ErrCode)
+}
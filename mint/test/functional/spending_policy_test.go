@@ -0,0 +1,149 @@
+package functional
+
+import (
+	"fmt"
+	"math/big"
+	"net/url"
+	"testing"
+
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/model"
+	"github.com/spolu/settle/mint/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupSpendingPolicy(
+	t *testing.T,
+) (*test.Mint, *test.MintUser, *test.MintUser, mint.AssetResource) {
+	m := test.CreateMint(t)
+	admin := m.CreateAdmin(t)
+	u := m.CreateUser(t)
+
+	a := u.CreateAsset(t, "USD", 2)
+
+	return m, admin, u, a
+}
+
+func payWithPolicy(
+	t *testing.T,
+	u *test.MintUser,
+	a mint.AssetResource,
+	amount string,
+) (int, svc.Resp) {
+	return u.Post(t, "/transactions", url.Values{
+		"pair":        {fmt.Sprintf("%s/%s", a.Name, a.Name)},
+		"amount":      {amount},
+		"destination": {fmt.Sprintf("dest@%s", u.Mint.Env.Config[mint.EnvCfgHost])},
+	})
+}
+
+func TestSpendingPolicyLimits(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, admin, u, a := setupSpendingPolicy(t)
+	defer m.Close()
+
+	status, raw := admin.Post(t,
+		fmt.Sprintf("/admin/users/%s/policies", u.Username),
+		url.Values{
+			"asset":           {a.Name},
+			"per_transaction": {"10"},
+			"per_day":         {"100"},
+			"per_window":      {"25"},
+			"window_ms":       {"60000"},
+		})
+	assert.Equal(t, 200, status)
+	var policy mint.SpendingPolicyResource
+	err := raw.Extract("spending_policy", &policy)
+	assert.Nil(t, err)
+	assert.Equal(t, a.Name, policy.Asset)
+	assert.Equal(t, big.NewInt(10), policy.PerTransaction)
+	assert.Equal(t, int64(60000), *policy.WindowMs)
+	assert.Equal(t, big.NewInt(0), policy.DaySpent)
+
+	status, raw = payWithPolicy(t, u, a, "11")
+	assert.Equal(t, 403, status)
+	assert.Equal(t, "spending_limit_exceeded", errorCode(t, raw))
+
+	for i := 0; i < 2; i++ {
+		status, _ = payWithPolicy(t, u, a, "10")
+		assert.Equal(t, 201, status)
+	}
+
+	status, raw = payWithPolicy(t, u, a, "10")
+	assert.Equal(t, 403, status)
+	assert.Equal(t, "spending_limit_exceeded", errorCode(t, raw))
+
+	status, _ = payWithPolicy(t, u, a, "5")
+	assert.Equal(t, 201, status)
+
+	status, raw = u.Get(t, "/usage")
+	assert.Equal(t, 200, status)
+	var policies []mint.SpendingPolicyResource
+	err = raw.Extract("spending_policies", &policies)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(policies))
+	assert.Equal(t, big.NewInt(25), policies[0].DaySpent)
+	assert.Equal(t, big.NewInt(25), policies[0].WindowSpent)
+
+	status, raw = admin.Get(t,
+		fmt.Sprintf("/admin/users/%s/usage", u.Username))
+	assert.Equal(t, 200, status)
+	err = raw.Extract("spending_policies", &policies)
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewInt(25), policies[0].DaySpent)
+}
+
+func TestSpendingPolicyInvalidWindow(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, admin, u, a := setupSpendingPolicy(t)
+	defer m.Close()
+
+	status, raw := admin.Post(t,
+		fmt.Sprintf("/admin/users/%s/policies", u.Username),
+		url.Values{
+			"asset":      {a.Name},
+			"per_window": {"25"},
+		})
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "window_invalid", errorCode(t, raw))
+}
+
+func TestSpendingPolicyConcurrentSpend(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, _, u, a := setupSpendingPolicy(t)
+	defer m.Close()
+
+	user, err := model.LoadUserByUsername(m.Ctx, u.Username)
+	assert.Nil(t, err)
+
+	_, err = model.CreateOrUpdateSpendingPolicy(m.Ctx,
+		user.Token, a.Name, nil, big.NewInt(100), nil, nil)
+	assert.Nil(t, err)
+
+	p0, err := model.LoadSpendingPolicy(m.Ctx, user.Token, a.Name)
+	assert.Nil(t, err)
+	p1, err := model.LoadSpendingPolicy(m.Ctx, user.Token, a.Name)
+	assert.Nil(t, err)
+
+	err = p0.Spend(m.Ctx, "tx0", big.NewInt(60))
+	assert.Nil(t, err)
+
+	// The second policy was loaded before the first spending was recorded.
+	err = p1.Spend(m.Ctx, "tx1", big.NewInt(30))
+	assert.IsType(t, model.ErrConcurrentModification{}, errors.Cause(err))
+
+	p1, err = model.LoadSpendingPolicy(m.Ctx, user.Token, a.Name)
+	assert.Nil(t, err)
+	err = p1.Spend(m.Ctx, "tx1", big.NewInt(60))
+	assert.IsType(t, model.ErrSpendingLimitExceeded{}, errors.Cause(err))
+	err = p1.Spend(m.Ctx, "tx1", big.NewInt(30))
+	assert.Nil(t, err)
+}
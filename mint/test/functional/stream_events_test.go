@@ -0,0 +1,213 @@
+package functional
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/test"
+	"github.com/stretchr/testify/assert"
+)
+
+// streamEvents connects to the event stream of the user and reads events
+// until count of them have been received or the stream is closed.
+func streamEvents(
+	t *testing.T,
+	u *test.MintUser,
+	lastEventID string,
+	count int,
+) []mint.EventResource {
+	req, err := http.NewRequest("GET",
+		fmt.Sprintf("%s/events", u.Mint.Server.URL), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.SetBasicAuth(u.Username, u.Password)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	r, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Body.Close()
+
+	assert.Equal(t, 200, r.StatusCode)
+	assert.Equal(t, "text/event-stream", r.Header.Get("Content-Type"))
+
+	events := []mint.EventResource{}
+	scanner := bufio.NewScanner(r.Body)
+	for len(events) < count && scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		var ev mint.EventResource
+		err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &ev)
+		if err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, ev)
+	}
+
+	return events
+}
+
+func TestStreamEvents(
+	t *testing.T,
+) {
+	t.Parallel()
+	m := test.CreateMint(t)
+	defer m.Close()
+
+	u := []*test.MintUser{
+		m.CreateUser(t),
+		m.CreateUser(t),
+	}
+	a := u[0].CreateAsset(t, "USD", 2)
+
+	status, raw := u[0].Post(t,
+		fmt.Sprintf("/transactions"),
+		url.Values{
+			"pair":        {fmt.Sprintf("%s/%s", a.Name, a.Name)},
+			"amount":      {"10"},
+			"destination": {u[1].Address},
+		})
+	assert.Equal(t, 201, status)
+
+	var tx mint.TransactionResource
+	err := raw.Extract("transaction", &tx)
+	assert.Nil(t, err)
+
+	status, _ = u[0].Post(t,
+		fmt.Sprintf("/transactions/%s/settle", tx.ID),
+		url.Values{})
+	assert.Equal(t, 200, status)
+
+	// The balance of the destination is created when the transaction is
+	// reserved and credited when it is settled.
+	events := streamEvents(t, u[1], "", 4)
+	assert.Equal(t, 4, len(events))
+	assert.Equal(t, mint.EvTpBalanceUpdated, events[0].Type)
+	assert.Equal(t, mint.EvTpTransactionReserved, events[1].Type)
+	assert.Equal(t, tx.ID, events[1].Object)
+	assert.Equal(t, mint.EvTpTransactionSettled, events[2].Type)
+	assert.Equal(t, tx.ID, events[2].Object)
+	assert.Equal(t, mint.EvTpBalanceUpdated, events[3].Type)
+
+	var balance mint.BalanceResource
+	err = json.Unmarshal(*events[3].Data, &balance)
+	assert.Nil(t, err)
+	assert.Equal(t, u[1].Address, balance.Holder)
+	assert.Equal(t, big.NewInt(10), balance.Value)
+
+	// Resuming from an event only returns the following ones.
+	resumed := streamEvents(t, u[1], events[1].ID, 2)
+	assert.Equal(t, 2, len(resumed))
+	assert.Equal(t, events[2].ID, resumed[0].ID)
+	assert.Equal(t, events[3].ID, resumed[1].ID)
+
+	// Offers are reported to their owner only.
+	b := u[1].CreateAsset(t, "EUR", 2)
+	o := u[0].CreateOffer(t,
+		fmt.Sprintf("%s/%s", a.Name, b.Name), "100/100", big.NewInt(100))
+	status, _ = u[0].Post(t,
+		fmt.Sprintf("/offers/%s/close", o.ID), url.Values{})
+	assert.Equal(t, 200, status)
+
+	events = streamEvents(t, u[0], "", 4)
+	assert.Equal(t, 4, len(events))
+	types := []mint.EvType{}
+	for _, ev := range events {
+		types = append(types, ev.Type)
+	}
+	assert.Contains(t, types, mint.EvTpOfferActive)
+	assert.Contains(t, types, mint.EvTpOfferClosed)
+	assert.NotContains(t, types, mint.EvTpBalanceUpdated)
+}
+
+func TestStreamEventsWithInvalidLastEventID(
+	t *testing.T,
+) {
+	t.Parallel()
+	m := test.CreateMint(t)
+	defer m.Close()
+
+	u := m.CreateUser(t)
+
+	status, raw := u.Get(t, "/events?last_event_id=foo")
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "last_event_id_invalid", errorCode(t, raw))
+}
+
+func TestStreamEventsLateCommit(
+	t *testing.T,
+) {
+	t.Parallel()
+	m := test.CreateMint(t)
+	defer m.Close()
+
+	u := m.CreateUser(t)
+	a := u.CreateAsset(t, "USD", 2)
+	b := u.CreateAsset(t, "EUR", 2)
+	u.CreateOffer(t,
+		fmt.Sprintf("%s/%s", a.Name, b.Name), "100/100", big.NewInt(100))
+
+	events := streamEvents(t, u, "", 1)
+	assert.Equal(t, 1, len(events))
+
+	// An event created before the last one received but committed after it
+	// (by a longer DB transaction) is still streamed on resumption.
+	_, err := m.DB.Exec(`
+INSERT INTO events
+  (token, created, address, type, object, payload)
+VALUES
+  ('event_late', ?, ?, ?, 'foo', '{}')
+`, time.Now().Add(-time.Hour).UTC(), u.Address, mint.EvTpOfferClosed)
+	assert.Nil(t, err)
+
+	resumed := streamEvents(t, u, events[0].ID, 1)
+	assert.Equal(t, 1, len(resumed))
+	assert.Equal(t, mint.EvTpOfferClosed, resumed[0].Type)
+	assert.Equal(t, "foo", resumed[0].Object)
+}
+
+func TestStreamEventsNotified(
+	t *testing.T,
+) {
+	t.Parallel()
+	m := test.CreateMint(t)
+	defer m.Close()
+
+	u := m.CreateUser(t)
+	a := u.CreateAsset(t, "USD", 2)
+	b := u.CreateAsset(t, "EUR", 2)
+
+	received := make(chan []mint.EventResource)
+	go func() {
+		received <- streamEvents(t, u, "", 1)
+	}()
+
+	// Let the stream connect before the event is recorded.
+	time.Sleep(100 * time.Millisecond)
+	u.CreateOffer(t,
+		fmt.Sprintf("%s/%s", a.Name, b.Name), "100/100", big.NewInt(100))
+
+	// The stream is woken up by the commit well before its keep-alive.
+	select {
+	case events := <-received:
+		assert.Equal(t, 1, len(events))
+		assert.Equal(t, mint.EvTpOfferActive, events[0].Type)
+	case <-time.After(
+		time.Duration(mint.EventsKeepAliveMs) * time.Millisecond / 2):
+		t.Fatal("stream not notified of the event")
+	}
+}
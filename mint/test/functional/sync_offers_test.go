@@ -0,0 +1,131 @@
+package functional
+
+import (
+	"fmt"
+	"math/big"
+	"net/url"
+	"testing"
+
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/async"
+	"github.com/spolu/settle/mint/async/task"
+	"github.com/spolu/settle/mint/model"
+	"github.com/spolu/settle/mint/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupSyncOffers(
+	t *testing.T,
+) ([]*test.Mint, []*test.MintUser, []mint.AssetResource, []mint.OfferResource) {
+	m := []*test.Mint{
+		test.CreateMint(t),
+		test.CreateMint(t),
+	}
+	u := []*test.MintUser{
+		m[0].CreateUser(t),
+		m[1].CreateUser(t),
+	}
+	a := []mint.AssetResource{
+		u[0].CreateAsset(t, "USD", 2),
+		u[1].CreateAsset(t, "USD", 2),
+	}
+	o := []mint.OfferResource{
+		u[0].CreateOffer(t,
+			fmt.Sprintf("%s[USD.2]/%s[USD.2]", u[0].Address, u[1].Address),
+			"100/100", big.NewInt(100)),
+	}
+
+	// Propagate m[0] offer to m[1].
+	async.TestRunOne(m[0].Ctx)
+
+	return m, u, a, o
+}
+
+func tearDownSyncOffers(
+	t *testing.T,
+	mints []*test.Mint,
+) {
+	for _, m := range mints {
+		m.Close()
+	}
+}
+
+func TestSyncOffersRefresh(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, u, _, o := setupSyncOffers(t)
+	defer tearDownSyncOffers(t, m)
+
+	// Close the offer without running the propagation task.
+	status, _ := u[0].Post(t,
+		fmt.Sprintf("/offers/%s/close", o[0].ID),
+		url.Values{})
+	assert.Equal(t, 200, status)
+
+	refreshed, failed, err := task.SyncPropagatedOffers(m[1].Ctx)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1, refreshed)
+	assert.Equal(t, 0, failed)
+
+	of, err := model.LoadPropagatedOfferByID(m[1].Ctx, o[0].ID)
+	assert.Nil(t, err)
+	assert.Equal(t, mint.OfStClosed, of.Status)
+
+	// Closed offers are not refreshed anymore.
+	refreshed, failed, err = task.SyncPropagatedOffers(m[1].Ctx)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 0, refreshed)
+	assert.Equal(t, 0, failed)
+}
+
+func TestSyncOffersSuspect(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, _, a, o := setupSyncOffers(t)
+	defer tearDownSyncOffers(t, m)
+
+	m[1].Env.Config[mint.EnvCfgOfferSuspectFailures] = "2"
+
+	// Make m[0] unreachable.
+	m[0].Server.Close()
+
+	listOffers := func() []mint.OfferResource {
+		status, raw := m[1].Get(t, nil,
+			fmt.Sprintf("/assets/%s/offers?propagation=propagated", a[1].Name))
+		assert.Equal(t, 200, status)
+
+		var offers []mint.OfferResource
+		err := raw.Extract("offers", &offers)
+		assert.Nil(t, err)
+
+		return offers
+	}
+
+	refreshed, failed, err := task.SyncPropagatedOffers(m[1].Ctx)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 0, refreshed)
+	assert.Equal(t, 1, failed)
+
+	offers := listOffers()
+	assert.Equal(t, 1, len(offers))
+	assert.Equal(t, o[0].ID, offers[0].ID)
+
+	refreshed, failed, err = task.SyncPropagatedOffers(m[1].Ctx)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 0, refreshed)
+	assert.Equal(t, 1, failed)
+
+	sync, err := model.LoadMintSyncByHost(m[1].Ctx, mint.GetHost(m[0].Ctx))
+	assert.Nil(t, err)
+	assert.Equal(t, uint(2), sync.Failures)
+	assert.Equal(t, mint.SyStSuspect, sync.Status)
+
+	offers = listOffers()
+	assert.Equal(t, 0, len(offers))
+}
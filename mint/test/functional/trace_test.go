@@ -0,0 +1,140 @@
+package functional
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/mint"
+	"github.com/spolu/settle/mint/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionTrace(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, u, a, o := setupCreateTransaction(t)
+	defer tearDownCreateTransaction(t, m)
+
+	_, raw := u[0].Post(t,
+		fmt.Sprintf("/transactions"),
+		url.Values{
+			"pair":        {fmt.Sprintf("%s/%s", a[0].Name, a[2].Name)},
+			"amount":      {"10"},
+			"destination": {u[2].Address},
+			"path[]":      {o[1].ID, o[2].ID},
+		})
+
+	var tx mint.TransactionResource
+	err := raw.Extract("transaction", &tx)
+	assert.Nil(t, err)
+
+	c := sdkClient(t, u[0])
+
+	_, err = c.SettleTransaction(m[0].Ctx, tx.ID)
+	assert.Nil(t, err)
+
+	tr, err := c.RetrieveTransactionTrace(m[0].Ctx, tx.ID)
+	assert.Nil(t, err)
+
+	assert.Equal(t, tx.ID, tr.Transaction)
+	assert.Equal(t, []string{}, tr.Unreachable)
+
+	// Spans are stitched together from the three mints and ordered.
+	names := map[string]map[string]bool{}
+	traces := map[string]bool{}
+	for i, s := range tr.Spans {
+		if names[s.Mint] == nil {
+			names[s.Mint] = map[string]bool{}
+		}
+		names[s.Mint][s.Name] = true
+		if s.Name == "transaction.create" {
+			traces[s.TraceID] = true
+		}
+		if i > 0 {
+			assert.True(t, tr.Spans[i-1].Start <= s.Start)
+		}
+	}
+	assert.Equal(t, 3, len(names))
+
+	for _, mm := range m {
+		host := mm.Env.Config[mint.EnvCfgHost]
+		assert.True(t, names[host]["transaction.create"], host)
+		assert.True(t, names[host]["transaction.settle"], host)
+		assert.True(t, names[host]["plan.compute"], host)
+		assert.True(t, names[host]["db.transaction"], host)
+	}
+	assert.True(t, names[m[0].Env.Config[mint.EnvCfgHost]]["transaction.propagate"])
+
+	// The creation spans share the trace propagated across hops.
+	assert.Equal(t, 1, len(traces))
+
+	// Peers involved only return the spans they recorded to signed requests.
+	client := &mint.Client{}
+	err = client.Init(m[0].Ctx)
+	assert.Nil(t, err)
+
+	tr1, err := client.RetrieveTransactionTrace(m[0].Ctx,
+		tx.ID, m[1].Env.Config[mint.EnvCfgHost])
+	assert.Nil(t, err)
+
+	assert.NotEqual(t, 0, len(tr1.Spans))
+	for _, s := range tr1.Spans {
+		assert.Equal(t, m[1].Env.Config[mint.EnvCfgHost], s.Mint)
+	}
+
+	_, err = client.RetrieveTransactionTrace(m[0].Ctx,
+		o[1].ID, m[1].Env.Config[mint.EnvCfgHost])
+	e, ok := errors.Cause(err).(mint.ErrMintClient)
+	assert.True(t, ok)
+	assert.Equal(t, 404, e.StatusCode)
+}
+
+func TestTransactionTraceForbidden(
+	t *testing.T,
+) {
+	t.Parallel()
+	m, u, a, o := setupCreateTransaction(t)
+	defer tearDownCreateTransaction(t, m)
+
+	_, raw := u[0].Post(t,
+		fmt.Sprintf("/transactions"),
+		url.Values{
+			"pair":        {fmt.Sprintf("%s/%s", a[0].Name, a[2].Name)},
+			"amount":      {"10"},
+			"destination": {u[2].Address},
+			"path[]":      {o[1].ID, o[2].ID},
+		})
+
+	var tx mint.TransactionResource
+	err := raw.Extract("transaction", &tx)
+	assert.Nil(t, err)
+
+	path := fmt.Sprintf("/transactions/%s/trace", tx.ID)
+
+	// Anonymous requests are not authenticated.
+	status, raw := m[1].Get(t, nil, path)
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "username_invalid", errorCode(t, raw))
+
+	// Users that are neither the owner nor the destination are forbidden.
+	status, raw = m[1].CreateUser(t).Get(t, path)
+	assert.Equal(t, 403, status)
+	assert.Equal(t, "trace_forbidden", errorCode(t, raw))
+
+	// Mints not involved in the transaction are forbidden.
+	other := test.CreateMint(t)
+	defer other.Close()
+
+	client := &mint.Client{}
+	err = client.Init(other.Ctx)
+	assert.Nil(t, err)
+
+	_, err = client.RetrieveTransactionTrace(other.Ctx,
+		tx.ID, m[1].Env.Config[mint.EnvCfgHost])
+	e, ok := errors.Cause(err).(mint.ErrMintClient)
+	assert.True(t, ok)
+	assert.Equal(t, "trace_forbidden", e.ErrCode)
+}
@@ -0,0 +1,27 @@
+package mint
+
+import (
+	"context"
+	"time"
+
+	"github.com/spolu/settle/lib/trace"
+)
+
+// NewSpanResource generates a new resource for a span recorded by this mint.
+func NewSpanResource(
+	ctx context.Context,
+	span trace.SpanData,
+) SpanResource {
+	return SpanResource{
+		TraceID:  span.TraceID,
+		ID:       span.ID,
+		ParentID: span.ParentID,
+		Mint:     GetHost(ctx),
+
+		Name:       span.Name,
+		Start:      span.Start.UnixNano() / TimeResolutionNs,
+		Duration:   int64(span.End.Sub(span.Start) / time.Microsecond),
+		Error:      span.Error,
+		Attributes: span.Attributes,
+	}
+}
@@ -107,3 +107,43 @@ func (s *TkName) Scan(src interface{}) error {
 
 	return nil
 }
+
+// Value implements driver.Valuer.
+func (t DsType) Value() (value driver.Value, err error) {
+	return string(t), nil
+}
+
+// Scan implements sql.Scanner.
+func (t *DsType) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		*t = DsType(src)
+	case string:
+		*t = DsType(src)
+	default:
+		return errors.Newf(
+			"Incompatible type for DsType with value: %q", src)
+	}
+
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (s SyStatus) Value() (value driver.Value, err error) {
+	return string(s), nil
+}
+
+// Scan implements sql.Scanner.
+func (s *SyStatus) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		*s = SyStatus(src)
+	case string:
+		*s = SyStatus(src)
+	default:
+		return errors.Newf(
+			"Incompatible status for SyStatus with value: %q", src)
+	}
+
+	return nil
+}
@@ -7,34 +7,52 @@ import (
 	"time"
 
 	goji "goji.io"
+	"goji.io/pat"
 
 	"github.com/facebookgo/grace/gracehttp"
 	"github.com/spolu/settle/lib/db"
 	"github.com/spolu/settle/lib/env"
 	"github.com/spolu/settle/lib/errors"
 	"github.com/spolu/settle/lib/logging"
+	"github.com/spolu/settle/lib/metrics"
+	"github.com/spolu/settle/lib/ratelimit"
 	"github.com/spolu/settle/lib/recoverer"
 	"github.com/spolu/settle/lib/requestlogger"
 	"github.com/spolu/settle/register"
+	"github.com/spolu/settle/register/endpoint"
 
 	// force initialization of schemas
 	_ "github.com/spolu/settle/register/model/schemas"
 )
 
+// Config holds the values of the flags the register service is run with.
+type Config struct {
+	Env string // environment
+
+	Host    string // register host
+	Port    string // register port
+	CrtFile string // production certificate file
+
+	DSN      string // register DSN
+	CredsURL string // credentials URL
+
+	Mint    string // mint host
+	MintDSN string // mint DSN
+
+	SMTPLogin    string // SMTP login
+	SMTPPassword string // SMTP password
+	SMTPHost     string // SMTP host
+	From         string // from address
+
+	RateLimits     string // rate limits
+	TrustedProxies string // trusted proxies
+	MetricsAddr    string // metrics address
+}
+
 // BackgroundContextFromFlags initializes a background context fully loaded
 // with everything that could be extracted from the flags.
 func BackgroundContextFromFlags(
-	envFlag string, // environment
-	hstFlag string, // register host
-	prtFlag string, // register port
-	dsnFlag string, // register DSN
-	crdFlag string, // credentials URL
-	mntFlag string, // mint host
-	mdsFlag string, // mint DSN
-	smlFlag string, // SMTP login
-	smpFlag string, // SMTP password
-	smhFlag string, // SMTP host
-	frmFlag string, // from address
+	cfg Config,
 ) (context.Context, error) {
 	ctx := context.Background()
 
@@ -42,26 +60,31 @@ func BackgroundContextFromFlags(
 		Environment: env.QA,
 		Config:      map[env.ConfigKey]string{},
 	}
-	if envFlag == "production" || envFlag == "prod" {
+	if cfg.Env == "production" || cfg.Env == "prod" {
 		registerEnv.Environment = env.Production
 	}
 
-	registerEnv.Config[register.EnvCfgHost] = hstFlag
-	registerEnv.Config[register.EnvCfgPort] = prtFlag
+	registerEnv.Config[register.EnvCfgHost] = cfg.Host
+	registerEnv.Config[register.EnvCfgPort] = cfg.Port
+	registerEnv.Config[register.EnvCfgCrtFile] = cfg.CrtFile
+
+	registerEnv.Config[register.EnvCfgCredsURL] = cfg.CredsURL
+	registerEnv.Config[register.EnvCfgMint] = cfg.Mint
 
-	registerEnv.Config[register.EnvCfgCredsURL] = crdFlag
-	registerEnv.Config[register.EnvCfgMint] = mntFlag
+	registerEnv.Config[register.EnvCfgSMTPLogin] = cfg.SMTPLogin
+	registerEnv.Config[register.EnvCfgSMTPPassword] = cfg.SMTPPassword
+	registerEnv.Config[register.EnvCfgSMTPHost] = cfg.SMTPHost
+	registerEnv.Config[register.EnvCfgFrom] = cfg.From
 
-	registerEnv.Config[register.EnvCfgSMTPLogin] = smlFlag
-	registerEnv.Config[register.EnvCfgSMTPPassword] = smpFlag
-	registerEnv.Config[register.EnvCfgSMTPHost] = smhFlag
-	registerEnv.Config[register.EnvCfgFrom] = frmFlag
+	registerEnv.Config[register.EnvCfgRateLimits] = cfg.RateLimits
+	registerEnv.Config[register.EnvCfgTrustedProxies] = cfg.TrustedProxies
+	registerEnv.Config[register.EnvCfgMetricsAddr] = cfg.MetricsAddr
 
 	ctx = env.With(ctx, &registerEnv)
 
 	// registerDB is the DB backing the register service.
 	registerDB, err := db.NewDBForDSN(ctx,
-		dsnFlag,
+		cfg.DSN,
 		fmt.Sprintf("sqlite3://~/.mint/register-%s.db",
 			env.Get(ctx).Environment))
 	if err != nil {
@@ -78,14 +101,16 @@ func BackgroundContextFromFlags(
 	// The tables don't get created here as we want to mimimize the
 	// interference with the mintDB.
 	mintDB, err := db.NewDBForDSN(ctx,
+		cfg.MintDSN,
 		fmt.Sprintf("sqlite3://~/.mint/mint-%s.db",
-			env.Get(ctx).Environment),
-		dsnFlag)
+			env.Get(ctx).Environment))
 	if err != nil {
 		return nil, err
 	}
 	ctx = db.WithDB(ctx, "mint", mintDB)
 
+	ctx = metrics.With(ctx, metrics.NewRegistry())
+
 	return ctx, nil
 }
 
@@ -101,19 +126,36 @@ func Build(
 		return nil, errors.Trace(errors.Newf(
 			"You must set the `-port` flag"))
 	}
+	clientIP, err := NewClientIPMiddleware(ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	limiter, err := NewRateLimiter(ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
 	mux := goji.NewMux()
 	mux.Use(requestlogger.Middleware)
+	mux.Use(metrics.Middleware(metrics.Get(ctx)))
+	mux.Use(endpoint.Instrument)
 	mux.Use(recoverer.Middleware)
+	mux.Use(clientIP)
+	mux.Use(ratelimit.Middleware(limiter))
 	mux.Use(db.Middleware(db.GetDBMap(ctx)))
 	mux.Use(env.Middleware(env.Get(ctx)))
 
-	logging.Logf(ctx, "Initializing: environment=%s host=%s port=%s mint=%s",
-		env.Get(ctx).Environment,
-		register.GetHost(ctx), register.GetPort(ctx),
-		register.GetMint(ctx))
+	logging.Info(ctx, "Initializing",
+		"environment", env.Get(ctx).Environment, "host", register.GetHost(ctx),
+		"port", register.GetPort(ctx), "mint", register.GetMint(ctx))
 
 	(&Controller{}).Bind(mux)
 
+	// Metrics are served on the API if no separate address is configured.
+	if register.GetMetricsAddr(ctx) == "" {
+		mux.Handle(pat.Get("/metrics"), metrics.Handler(metrics.Get(ctx)))
+	}
+
 	return mux, nil
 }
 
@@ -130,9 +172,25 @@ func Serve(
 		Handler:      mux,
 	}
 
-	logging.Logf(ctx, "Listening: port=%s", register.GetPort(ctx))
+	servers := []*http.Server{s}
+
+	// Metrics are served on a separate address, if configured, so that they
+	// are not exposed publicly.
+	if addr := register.GetMetricsAddr(ctx); addr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler(metrics.Get(ctx)))
+		servers = append(servers, &http.Server{
+			Addr:         addr,
+			ReadTimeout:  5 * time.Second,
+			WriteTimeout: 5 * time.Second,
+			Handler:      mux,
+		})
+		logging.Info(ctx, "Serving metrics", "addr", addr)
+	}
+
+	logging.Info(ctx, "Listening", "port", register.GetPort(ctx))
 
-	err := gracehttp.Serve(s)
+	err := gracehttp.Serve(servers...)
 	if err != nil {
 		return errors.Trace(err)
 	}
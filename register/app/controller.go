@@ -17,5 +17,8 @@ func (c *Controller) Bind(
 	mux.HandleFunc(pat.Post("/users"), endpoint.HandlerFor(endpoint.EndPtCreateUser))
 	mux.HandleFunc(pat.Get("/users/:username"), endpoint.HandlerFor(endpoint.EndPtRetrieveUser))
 	mux.HandleFunc(pat.Post("/users/:username/roll"), endpoint.HandlerFor(endpoint.EndPtRollUser))
+	mux.HandleFunc(pat.Get("/openapi.json"), endpoint.HandlerFor(endpoint.EndPtRetrieveOpenAPI))
+	mux.HandleFunc(pat.Get("/healthz"), endpoint.HandlerFor(endpoint.EndPtRetrieveHealth))
+	mux.HandleFunc(pat.Get("/readyz"), endpoint.HandlerFor(endpoint.EndPtRetrieveReadiness))
 
 }
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/ratelimit"
+	"github.com/spolu/settle/register"
+)
+
+const (
+	// RlGrPublic is the rate limit group of all register requests.
+	RlGrPublic string = "public"
+)
+
+// DefaultRateLimits are the default rate limits per route group.
+var DefaultRateLimits = map[string]ratelimit.Limit{
+	RlGrPublic: ratelimit.Limit{Rate: 1, Burst: 10},
+}
+
+// exemptPaths are the paths of the requests that are never rate limited
+// (health checks and metrics scraping).
+var exemptPaths = map[string]bool{
+	"/healthz": true,
+	"/readyz":  true,
+	"/metrics": true,
+}
+
+// rateLimitGroup returns the rate limit group of a request.
+func rateLimitGroup(
+	r *http.Request,
+) string {
+	if exemptPaths[r.URL.Path] {
+		return ""
+	}
+	return RlGrPublic
+}
+
+// rateLimitKey returns the key by which a request is rate limited: the
+// client IP.
+func rateLimitKey(
+	r *http.Request,
+) string {
+	return fmt.Sprintf("ip:%s", ratelimit.IP(r))
+}
+
+// NewRateLimiter constructs the register rate limiter based on the rate
+// limits configured in the context.
+func NewRateLimiter(
+	ctx context.Context,
+) (*ratelimit.Limiter, error) {
+	limits, err := ratelimit.ParseLimits(
+		register.GetRateLimits(ctx), DefaultRateLimits)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return ratelimit.NewLimiter(limits, rateLimitGroup, rateLimitKey), nil
+}
+
+// NewClientIPMiddleware constructs the middleware resolving the IP of
+// clients based on the trusted proxies configured in the context.
+func NewClientIPMiddleware(
+	ctx context.Context,
+) (func(http.Handler) http.Handler, error) {
+	proxies, err := ratelimit.ParseProxies(register.GetTrustedProxies(ctx))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return ratelimit.ClientIPMiddleware(proxies), nil
+}
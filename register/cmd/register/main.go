@@ -5,6 +5,7 @@ import (
 	"log"
 
 	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/logging"
 	"github.com/spolu/settle/register/app"
 )
 
@@ -12,6 +13,7 @@ var envFlag string
 
 var hstFlag string
 var prtFlag string
+var crfFlag string
 
 var dsnFlag string
 var crdFlag string
@@ -24,6 +26,14 @@ var smpFlag string
 var smhFlag string
 var frmFlag string
 
+var rtlFlag string
+var tprFlag string
+var mtaFlag string
+
+var lgfFlag string
+var lglFlag string
+var lgoFlag string
+
 func init() {
 	flag.StringVar(&envFlag, "env",
 		"qa", "The environment to run in (qa, production), default: qa")
@@ -32,6 +42,8 @@ func init() {
 		"", "The host on which the register service is running")
 	flag.StringVar(&prtFlag, "port",
 		"", "The port on which the register service is running")
+	flag.StringVar(&crfFlag, "crt_file",
+		"", "The production TLS certificate file whose expiry is checked by /readyz, default: none")
 
 	flag.StringVar(&dsnFlag, "db_dsn",
 		"", "The DSN of the database to use, default: sqlite3://~/.mint/register-$env.db")
@@ -52,6 +64,20 @@ func init() {
 	flag.StringVar(&frmFlag, "from",
 		"", "The address the registration email are sent from")
 
+	flag.StringVar(&rtlFlag, "rate_limits",
+		"", "The rate limit of requests per client IP in requests per second with an optional burst (public=1:10), 0 disables it, default: public=1:10")
+	flag.StringVar(&mtaFlag, "metrics_addr",
+		"", "The address on which Prometheus metrics are served at /metrics, separately from the API (127.0.0.1:9408), default: none (metrics served on the API)")
+	flag.StringVar(&tprFlag, "trusted_proxies",
+		"", "Comma separated list of the IPs or CIDRs of the proxies whose X-Forwarded-For header is trusted to determine the client IP, default: none")
+
+	flag.StringVar(&lgfFlag, "log_format",
+		"", "The format of the logs (text, logfmt, json), default: text")
+	flag.StringVar(&lglFlag, "log_level",
+		"", "The minimum level of the logs (debug, info, warn, error), default: info")
+	flag.StringVar(&lgoFlag, "log_file",
+		"", "The file the logs are appended to, default: standard error")
+
 	if fl := log.Flags(); fl&log.Ltime != 0 {
 		log.SetFlags(fl | log.Lmicroseconds)
 	}
@@ -62,13 +88,33 @@ func main() {
 		flag.Parse()
 	}
 
-	ctx, err := app.BackgroundContextFromFlags(
-		envFlag,
-		hstFlag, prtFlag,
-		dsnFlag, crdFlag,
-		mntFlag, mdsFlag,
-		smlFlag, smpFlag, smhFlag, frmFlag,
-	)
+	err := logging.Configure(lgfFlag, lglFlag, lgoFlag)
+	if err != nil {
+		log.Fatal(errors.Details(err))
+	}
+
+	ctx, err := app.BackgroundContextFromFlags(app.Config{
+		Env: envFlag,
+
+		Host:    hstFlag,
+		Port:    prtFlag,
+		CrtFile: crfFlag,
+
+		DSN:      dsnFlag,
+		CredsURL: crdFlag,
+
+		Mint:    mntFlag,
+		MintDSN: mdsFlag,
+
+		SMTPLogin:    smlFlag,
+		SMTPPassword: smpFlag,
+		SMTPHost:     smhFlag,
+		From:         frmFlag,
+
+		RateLimits:     rtlFlag,
+		TrustedProxies: tprFlag,
+		MetricsAddr:    mtaFlag,
+	})
 	if err != nil {
 		log.Fatal(errors.Details(err))
 	}
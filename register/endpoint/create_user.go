@@ -45,15 +45,20 @@ func (e *CreateUser) Validate(
 ) error {
 	ctx := r.Context()
 
+	params, err := svc.ParseParams(r)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
 	// Validate username.
-	username, err := ValidateUsername(ctx, r.PostFormValue("username"))
+	username, err := ValidateUsername(ctx, params.Get("username"))
 	if err != nil {
 		return errors.Trace(err) // 400
 	}
 	e.Username = *username
 
 	// Validate email.
-	email, err := ValidateEmail(ctx, r.PostFormValue("email"))
+	email, err := ValidateEmail(ctx, params.Get("email"))
 	if err != nil {
 		return errors.Trace(err) // 400
 	}
@@ -87,9 +92,9 @@ func (e *CreateUser) Execute(
 	}
 
 	if auth, host := register.GetSMTP(ctx); auth != nil {
-		logging.Logf(ctx,
-			"Sending email: from=%s username=%s email=%s",
-			register.GetFrom(ctx), user.Username, user.Email)
+		logging.Info(ctx, "Sending email",
+			"from", register.GetFrom(ctx), "username", user.Username,
+			"email", user.Email)
 
 		buf := new(bytes.Buffer)
 		err := emailTemplate.Execute(buf, EmailData{
@@ -119,9 +124,9 @@ func (e *CreateUser) Execute(
 
 	db.Commit(ctx)
 
-	logging.Logf(ctx,
-		"Created user: id=%s created=%q username=%s status=%s email=%s",
-		user.Token, user.Created, user.Username, user.Status, user.Email)
+	logging.Info(ctx, "Created user",
+		"id", user.Token, "created", user.Created, "username", user.Username,
+		"status", user.Status, "email", user.Email)
 
 	return ptr.Int(http.StatusCreated), &svc.Resp{
 		"user": format.JSONPtr(model.NewUserResource(ctx, user)),
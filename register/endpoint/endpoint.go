@@ -3,10 +3,16 @@ package endpoint
 import (
 	"context"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spolu/settle/lib/errors"
+	"github.com/spolu/settle/lib/metrics"
 	"github.com/spolu/settle/lib/respond"
 	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/register"
+	"github.com/zenazn/goji/web/mutil"
 )
 
 const (
@@ -49,6 +55,11 @@ func HandlerFor(
 			return
 		}
 
+		if _, err := svc.ParseParams(r); err != nil {
+			respond.Error(ctx, w, errors.Trace(err))
+			return
+		}
+
 		err = endpt.Validate(r)
 		if err != nil {
 			respond.Error(ctx, w, errors.Trace(err))
@@ -66,3 +77,58 @@ func HandlerFor(
 		respond.Respond(ctx, w, *status, nil, *resp)
 	}
 }
+
+// Instrument is a middleware recording the status and latency of requests by
+// endpoint. It is mounted before the middlewares that may reject requests so
+// that their rejections are recorded as well.
+func Instrument(
+	h http.Handler,
+) http.Handler {
+	return http.HandlerFunc(func(
+		w http.ResponseWriter,
+		r *http.Request,
+	) {
+		start := time.Now()
+		wp := mutil.WrapWriter(w)
+
+		h.ServeHTTP(wp, r)
+
+		name := endpointFor(r)
+		metrics.Inc(r.Context(), register.MtRequests,
+			name, strconv.Itoa(wp.Status()))
+		metrics.Observe(r.Context(), register.MtRequestDuration,
+			time.Now().Sub(start).Seconds(), name)
+	})
+}
+
+// endpointFor returns the name of the endpoint a request is routed to based on
+// the routes of the API spec, "unknown" if none.
+func endpointFor(
+	r *http.Request,
+) string {
+	segments := strings.Split(r.URL.Path, "/")
+	for _, route := range openAPISpec.Routes {
+		if route.Method != r.Method {
+			continue
+		}
+		pattern := strings.Split(route.Path, "/")
+		if len(pattern) != len(segments) {
+			continue
+		}
+		match := true
+		for i, p := range pattern {
+			if strings.HasPrefix(p, ":") {
+				match = segments[i] != ""
+			} else {
+				match = p == segments[i]
+			}
+			if !match {
+				break
+			}
+		}
+		if match {
+			return route.Endpoint
+		}
+	}
+	return "unknown"
+}
@@ -0,0 +1,104 @@
+// Code generated by openapi-gen. DO NOT EDIT.
+
+package endpoint
+
+import (
+	"github.com/spolu/settle/lib/openapi"
+	"github.com/spolu/settle/register"
+)
+
+// openAPISpec is the spec of the API extracted from the routes and endpoints.
+var openAPISpec = openapi.Spec{
+	Routes: []openapi.Route{
+		{Method: "POST", Path: "/users", Endpoint: "CreateUser"},
+		{Method: "GET", Path: "/users/:username", Endpoint: "RetrieveUser"},
+		{Method: "POST", Path: "/users/:username/roll", Endpoint: "RollUser"},
+		{Method: "GET", Path: "/openapi.json", Endpoint: "RetrieveOpenAPI"},
+		{Method: "GET", Path: "/healthz", Endpoint: "RetrieveHealth"},
+		{Method: "GET", Path: "/readyz", Endpoint: "RetrieveReadiness"},
+	},
+	Endpoints: map[string]openapi.Endpoint{
+		"CreateUser": openapi.Endpoint{
+			Name:        "CreateUser",
+			Description: "CreateUser a new user by username and email and send its secret over eail.",
+			Status:      201,
+			Params: []openapi.Param{
+				{Name: "username", In: "form"},
+				{Name: "email", In: "form"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "email_failed"},
+				{Status: 400, Code: "email_invalid"},
+				{Status: 400, Code: "username_invalid"},
+				{Status: 400, Code: "username_taken"},
+			},
+			Fields: []openapi.Field{
+				{Key: "user", Resource: "UserResource"},
+			},
+		},
+		"RetrieveHealth": openapi.Endpoint{
+			Name:        "RetrieveHealth",
+			Description: "RetrieveHealth checks whether the register is alive: its database and the database of its mint are reachable. It responds with a 503 if any check fails.",
+			Fields: []openapi.Field{
+				{Key: "health", Resource: "HealthResource"},
+			},
+		},
+		"RetrieveOpenAPI": openapi.Endpoint{
+			Name:        "RetrieveOpenAPI",
+			Description: "RetrieveOpenAPI retrieves the OpenAPI document describing the API of the register. The document is built from the spec generated from the routes and endpoints (see `go generate`).",
+			Status:      200,
+			Fields: []openapi.Field{
+				{Key: "components"},
+				{Key: "info"},
+				{Key: "openapi"},
+				{Key: "paths"},
+			},
+		},
+		"RetrieveReadiness": openapi.Endpoint{
+			Name:        "RetrieveReadiness",
+			Description: "RetrieveReadiness checks whether the register is ready to serve requests: on top of the health checks, the certificate file must not be about to expire in production. It responds with a 503 if any check fails.",
+			Fields: []openapi.Field{
+				{Key: "health", Resource: "HealthResource"},
+			},
+		},
+		"RetrieveUser": openapi.Endpoint{
+			Name:        "RetrieveUser",
+			Description: "RetrieveUser a new user by username and email and send its secret over eail.",
+			Status:      201,
+			Params: []openapi.Param{
+				{Name: "username", In: "path"},
+				{Name: "secret", In: "query"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "user_not_found"},
+				{Status: 400, Code: "username_invalid"},
+			},
+			Fields: []openapi.Field{
+				{Key: "credentials", Resource: "CredentialsResource"},
+				{Key: "user", Resource: "UserResource"},
+			},
+		},
+		"RollUser": openapi.Endpoint{
+			Name:        "RollUser",
+			Description: "RollUser a new user by username and email and send its secret over eail.",
+			Status:      201,
+			Params: []openapi.Param{
+				{Name: "username", In: "path"},
+				{Name: "secret", In: "form"},
+			},
+			Errors: []openapi.Error{
+				{Status: 400, Code: "user_not_found"},
+				{Status: 400, Code: "username_invalid"},
+			},
+			Fields: []openapi.Field{
+				{Key: "credentials", Resource: "CredentialsResource"},
+				{Key: "user", Resource: "UserResource"},
+			},
+		},
+	},
+	Resources: []interface{}{
+		register.CredentialsResource{},
+		register.UserResource{},
+		register.HealthResource{},
+	},
+}
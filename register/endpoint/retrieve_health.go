@@ -0,0 +1,58 @@
+package endpoint
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/spolu/settle/lib/format"
+	"github.com/spolu/settle/lib/health"
+	"github.com/spolu/settle/lib/ptr"
+	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/register"
+)
+
+const (
+	// EndPtRetrieveHealth retrieves the health of the register.
+	EndPtRetrieveHealth EndPtName = "RetrieveHealth"
+)
+
+func init() {
+	registrar[EndPtRetrieveHealth] = NewRetrieveHealth
+}
+
+// RetrieveHealth checks whether the register is alive: its database and the
+// database of its mint are reachable. It responds with a 503 if any check
+// fails.
+type RetrieveHealth struct{}
+
+// NewRetrieveHealth constructs and initialiezes the endpoint.
+func NewRetrieveHealth(
+	r *http.Request,
+) (Endpoint, error) {
+	return &RetrieveHealth{}, nil
+}
+
+// Validate validates the input parameters.
+func (e *RetrieveHealth) Validate(
+	r *http.Request,
+) error {
+	return nil
+}
+
+// Execute executes the endpoint.
+func (e *RetrieveHealth) Execute(
+	ctx context.Context,
+) (*int, *svc.Resp, error) {
+	checks := []health.Check{
+		health.DB(ctx, "register"),
+		health.DB(ctx, "mint"),
+	}
+	status, code := health.Evaluate(checks)
+
+	return ptr.Int(code), &svc.Resp{
+		"health": format.JSONPtr(register.HealthResource{
+			Status: status,
+			Checks: checks,
+		}),
+	}, nil
+}
@@ -0,0 +1,70 @@
+package endpoint
+
+//go:generate go run ../../lib/openapi/cmd/openapi-gen/main.go -routes ../app/controller.go -protocol ../protocol.go -protocol_import github.com/spolu/settle/register
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/spolu/settle/lib/format"
+	"github.com/spolu/settle/lib/openapi"
+	"github.com/spolu/settle/lib/ptr"
+	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/register"
+)
+
+const (
+	// EndPtRetrieveOpenAPI retrieves the OpenAPI document of the register
+	// API.
+	EndPtRetrieveOpenAPI EndPtName = "RetrieveOpenAPI"
+)
+
+func init() {
+	registrar[EndPtRetrieveOpenAPI] = NewRetrieveOpenAPI
+}
+
+// RetrieveOpenAPI retrieves the OpenAPI document describing the API of the
+// register. The document is built from the spec generated from the routes and
+// endpoints (see `go generate`).
+type RetrieveOpenAPI struct{}
+
+// NewRetrieveOpenAPI constructs and initialiezes the endpoint.
+func NewRetrieveOpenAPI(
+	r *http.Request,
+) (Endpoint, error) {
+	return &RetrieveOpenAPI{}, nil
+}
+
+// Validate validates the input parameters.
+func (e *RetrieveOpenAPI) Validate(
+	r *http.Request,
+) error {
+	return nil
+}
+
+// Execute executes the endpoint.
+func (e *RetrieveOpenAPI) Execute(
+	ctx context.Context,
+) (*int, *svc.Resp, error) {
+	d := OpenAPI(ctx)
+
+	return ptr.Int(http.StatusOK), &svc.Resp{
+		"openapi":    format.JSONPtr(d.OpenAPI),
+		"info":       format.JSONPtr(d.Info),
+		"paths":      format.JSONPtr(d.Paths),
+		"components": format.JSONPtr(d.Components),
+	}, nil
+}
+
+// OpenAPI returns the OpenAPI document of the register API.
+func OpenAPI(
+	ctx context.Context,
+) *openapi.Document {
+	return openAPISpec.Document(
+		openapi.Info{
+			Title:   "Settle Register API",
+			Version: register.Version,
+		},
+		nil, nil,
+	)
+}
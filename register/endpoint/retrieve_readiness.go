@@ -0,0 +1,66 @@
+package endpoint
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/spolu/settle/lib/env"
+	"github.com/spolu/settle/lib/format"
+	"github.com/spolu/settle/lib/health"
+	"github.com/spolu/settle/lib/ptr"
+	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/register"
+)
+
+const (
+	// EndPtRetrieveReadiness retrieves the readiness of the register.
+	EndPtRetrieveReadiness EndPtName = "RetrieveReadiness"
+)
+
+func init() {
+	registrar[EndPtRetrieveReadiness] = NewRetrieveReadiness
+}
+
+// RetrieveReadiness checks whether the register is ready to serve requests:
+// on top of the health checks, the certificate file must not be about to
+// expire in production. It responds with a 503 if any check fails.
+type RetrieveReadiness struct{}
+
+// NewRetrieveReadiness constructs and initialiezes the endpoint.
+func NewRetrieveReadiness(
+	r *http.Request,
+) (Endpoint, error) {
+	return &RetrieveReadiness{}, nil
+}
+
+// Validate validates the input parameters.
+func (e *RetrieveReadiness) Validate(
+	r *http.Request,
+) error {
+	return nil
+}
+
+// Execute executes the endpoint.
+func (e *RetrieveReadiness) Execute(
+	ctx context.Context,
+) (*int, *svc.Resp, error) {
+	checks := []health.Check{
+		health.DB(ctx, "register"),
+		health.DB(ctx, "mint"),
+	}
+
+	file := env.Get(ctx).Config[register.EnvCfgCrtFile]
+	if env.Get(ctx).Environment == env.Production && file != "" {
+		checks = append(checks, health.Certificate(ctx, file,
+			time.Duration(register.HealthCertificateExpiryMs)*time.Millisecond))
+	}
+	status, code := health.Evaluate(checks)
+
+	return ptr.Int(code), &svc.Resp{
+		"health": format.JSONPtr(register.HealthResource{
+			Status: status,
+			Checks: checks,
+		}),
+	}, nil
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/spolu/settle/lib/logging"
 	"github.com/spolu/settle/lib/ptr"
 	"github.com/spolu/settle/lib/svc"
+	"github.com/spolu/settle/mint"
 	mintmodel "github.com/spolu/settle/mint/model"
 	"github.com/spolu/settle/register"
 	"github.com/spolu/settle/register/model"
@@ -67,8 +68,7 @@ func (e *RetrieveUser) Execute(
 	regCtx := db.Begin(ctx, "register")
 	defer db.LoggedRollback(regCtx)
 
-	logging.Logf(regCtx,
-		"User retrieval: username=%s", e.Username)
+	logging.Info(regCtx, "User retrieval", "username", e.Username)
 
 	user, err := model.LoadUserByUsername(regCtx, e.Username)
 	if err != nil {
@@ -87,9 +87,9 @@ func (e *RetrieveUser) Execute(
 		if err != nil {
 			return nil, nil, errors.Trace(err) // 500
 		}
-		logging.Logf(regCtx,
-			"Updated user: id=%s created=%q username=%s status=%s",
-			user.Token, user.Created, user.Username, user.Status)
+		logging.Info(regCtx, "Updated user",
+			"id", user.Token, "created", user.Created,
+			"username", user.Username, "status", user.Status)
 	}
 
 	// If the user was not yet created on the mint, do so with two successive
@@ -115,18 +115,17 @@ func (e *RetrieveUser) Execute(
 				return nil, nil, errors.Trace(err) // 500
 			}
 
-			logging.Logf(mintCtx,
-				"Updated mint user: id=%s created=%q username=%s",
-				u.Token, u.Created, u.Username)
+			logging.Info(mintCtx, "Updated mint user",
+				"id", u.Token, "created", u.Created, "username", u.Username)
 		} else {
-			u, err = mintmodel.CreateUser(mintCtx, user.Username, user.Password)
+			u, err = mintmodel.CreateUser(mintCtx,
+				user.Username, user.Password, mint.UsRlUser)
 			if err != nil {
 				log.Fatal(errors.Details(err))
 			}
 
-			logging.Logf(mintCtx,
-				"Created mint user: id=%s created=%q username=%s",
-				u.Token, u.Created, u.Username)
+			logging.Info(mintCtx, "Created mint user",
+				"id", u.Token, "created", u.Created, "username", u.Username)
 		}
 
 		user.MintToken = &u.Token
@@ -46,6 +46,11 @@ func (e *RollUser) Validate(
 ) error {
 	ctx := r.Context()
 
+	params, err := svc.ParseParams(r)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
 	// Validate username.
 	username, err := ValidateUsername(ctx, pat.Param(r, "username"))
 	if err != nil {
@@ -54,7 +59,7 @@ func (e *RollUser) Validate(
 	e.Username = *username
 
 	// Validate secret.
-	e.Secret = r.PostFormValue("secret")
+	e.Secret = params.Get("secret")
 
 	return nil
 }
@@ -107,16 +112,15 @@ func (e *RollUser) Execute(
 			return nil, nil, errors.Trace(err) // 500
 		}
 
-		logging.Logf(mintCtx,
-			"Updated mint user: id=%s created=%q username=%s",
-			u.Token, u.Created, u.Username)
+		logging.Info(mintCtx, "Updated mint user",
+			"id", u.Token, "created", u.Created, "username", u.Username)
 
 		db.Commit(mintCtx)
 	}
 
-	logging.Logf(regCtx,
-		"Rolled user: id=%s created=%q username=%s status=%s",
-		user.Token, user.Created, user.Username, user.Status)
+	logging.Info(regCtx, "Rolled user",
+		"id", user.Token, "created", user.Created, "username", user.Username,
+		"status", user.Status)
 
 	db.Commit(regCtx)
 
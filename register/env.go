@@ -13,6 +13,8 @@ const (
 	EnvCfgHost env.ConfigKey = "host"
 	// EnvCfgPort is the port on which to run the register.
 	EnvCfgPort env.ConfigKey = "port"
+	// EnvCfgCrtFile is the production certificate file.
+	EnvCfgCrtFile env.ConfigKey = "crt_file"
 	// EnvCfgCredsURL is the URL that is sent to the user over email to
 	// retrieve their credentials.
 	EnvCfgCredsURL env.ConfigKey = "credentials_url"
@@ -30,6 +32,15 @@ const (
 	EnvCfgSMTPHost env.ConfigKey = "smtp_host"
 	// EnvCfgFrom is the email address to send registration emails from.
 	EnvCfgFrom env.ConfigKey = "from"
+	// EnvCfgRateLimits is the comma separated list of rate limits per route
+	// group (`group=rate:burst`).
+	EnvCfgRateLimits env.ConfigKey = "rate_limits"
+	// EnvCfgTrustedProxies is the comma separated list of IPs or CIDRs of the
+	// proxies whose X-Forwarded-For header is trusted.
+	EnvCfgTrustedProxies env.ConfigKey = "trusted_proxies"
+	// EnvCfgMetricsAddr is the address on which metrics are served, separately
+	// from the API.
+	EnvCfgMetricsAddr env.ConfigKey = "metrics_addr"
 )
 
 // GetHost retrieves the current register host from the given contest.
@@ -85,3 +96,27 @@ func GetFrom(
 ) string {
 	return env.Get(ctx).Config[EnvCfgFrom]
 }
+
+// GetRateLimits retrieves the rate limits specification from the given
+// context.
+func GetRateLimits(
+	ctx context.Context,
+) string {
+	return env.Get(ctx).Config[EnvCfgRateLimits]
+}
+
+// GetTrustedProxies retrieves the trusted proxies specification from the
+// given context.
+func GetTrustedProxies(
+	ctx context.Context,
+) string {
+	return env.Get(ctx).Config[EnvCfgTrustedProxies]
+}
+
+// GetMetricsAddr retrieves the address on which metrics are served from the
+// given context, empty if they are served on the API.
+func GetMetricsAddr(
+	ctx context.Context,
+) string {
+	return env.Get(ctx).Config[EnvCfgMetricsAddr]
+}
@@ -0,0 +1,18 @@
+package register
+
+import "github.com/spolu/settle/lib/metrics"
+
+var (
+	// MtRequests counts the requests handled by endpoint and status.
+	MtRequests = metrics.NewCounter(
+		"register_requests_total",
+		"Requests handled by endpoint and status.",
+		"endpoint", "status",
+	)
+	// MtRequestDuration is the latency of requests by endpoint.
+	MtRequestDuration = metrics.NewHistogram(
+		"register_request_duration_seconds",
+		"Latency of requests by endpoint.",
+		nil, "endpoint",
+	)
+)
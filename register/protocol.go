@@ -1,11 +1,16 @@
 package register
 
+import "github.com/spolu/settle/lib/health"
+
 const (
 	// Version is the current protocol version.
 	Version string = "0"
 	// TimeResolutionNs is the resolution of our time variables in nanoseconds
 	// (aka resolution in milliseconds).
 	TimeResolutionNs int64 = 1000 * 1000
+	// HealthCertificateExpiryMs is the validity left under which the
+	// production certificate makes the register not ready.
+	HealthCertificateExpiryMs int64 = 1000 * 60 * 60 * 24 * 7
 )
 
 // UsrStatus is the status of a user.
@@ -35,3 +40,10 @@ type UserResource struct {
 
 	Credentials *CredentialsResource `json:"credentials"`
 }
+
+// HealthResource is the representation of the health of the register in the
+// register API: the overall status and the result of each check.
+type HealthResource struct {
+	Status health.Status  `json:"status"`
+	Checks []health.Check `json:"checks"`
+}